@@ -19,10 +19,10 @@ import (
 
 	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
 	"github.com/harvester/pcidevices/pkg/controller"
+	"github.com/harvester/pcidevices/pkg/version"
 )
 
 const (
-	VERSION        = "v0.1.0"
 	controllerName = "pcidevices-controller"
 )
 
@@ -50,7 +50,7 @@ func main() {
 	var kubeConfig string
 	app := cli.NewApp()
 	app.Name = controllerName
-	app.Version = VERSION
+	app.Version = version.Version
 	app.Usage = "Harvester PCI Devices Controller, to discover PCI devices on the nodes of a cluster. Also manages PCI Device Claims, for use in PCI passthrough."
 	app.Flags = []cli.Flag{
 		&cli.StringFlag{
@@ -0,0 +1,92 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AutoClaimPolicy claims matching PCIDevices and USBDevices on the selected nodes as soon as the devices are discovered.
+// Devices which are already claimed are left as is. Claims created by the policy are released once their device no longer
+// matches the policy or the policy is removed, except for claims in use by a VM which are released once the VM stops using them
+type AutoClaimPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AutoClaimPolicySpec   `json:"spec,omitempty"`
+	Status AutoClaimPolicyStatus `json:"status,omitempty"`
+}
+
+type AutoClaimPolicySpec struct {
+	// NodeSelector selects the nodes devices are claimed on, devices on all nodes are claimed when it is not set
+	// +kubebuilder:validation:Optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+	// Devices selects the devices to claim. ClassID is only matched against pci devices, as the class code of
+	// usb devices is not recorded on the USBDevice
+	// +kubebuilder:validation:Required
+	Devices []DeviceDiscoveryRule `json:"devices"`
+	// UserName is recorded on the claims created by the policy
+	// +kubebuilder:validation:Optional
+	UserName string `json:"userName,omitempty"`
+}
+
+// Matches returns true if dev is matched by any of the device rules of the policy
+func (s AutoClaimPolicySpec) Matches(dev DiscoveredDevice) bool {
+	for _, v := range s.Devices {
+		if v.Matches(dev) {
+			return true
+		}
+	}
+	return false
+}
+
+type AutoClaimPolicyStatus struct {
+	// Claims are the PCIDeviceClaims and USBDeviceClaims held by the policy
+	// +kubebuilder:validation:Optional
+	Claims []AutoClaimPolicyClaim `json:"claims,omitempty"`
+	// Actions are the most recent actions taken for the policy, oldest first
+	// +kubebuilder:validation:Optional
+	Actions []AutoClaimPolicyAction `json:"actions,omitempty"`
+	// +kubebuilder:validation:Optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// AutoClaimPolicyClaim is a claim held by an AutoClaimPolicy. The claim has the same name as the claimed device
+type AutoClaimPolicyClaim struct {
+	Subsystem NodeSubsystem `json:"subsystem"`
+	Name      string        `json:"name"`
+	NodeName  string        `json:"nodeName"`
+}
+
+// AutoClaimAction is an action taken by the controller for an AutoClaimPolicy
+type AutoClaimAction string
+
+const (
+	// AutoClaimActionClaimed is recorded when a claim is created for a device
+	AutoClaimActionClaimed AutoClaimAction = "Claimed"
+	// AutoClaimActionClaimFailed is recorded when a claim for a device is rejected, such as for devices in use by the host
+	AutoClaimActionClaimFailed AutoClaimAction = "ClaimFailed"
+	// AutoClaimActionReleased is recorded when a claim is removed
+	AutoClaimActionReleased AutoClaimAction = "Released"
+	// AutoClaimActionRetained is recorded when a claim is kept as it is still in use by a VM
+	AutoClaimActionRetained AutoClaimAction = "Retained"
+	// AutoClaimActionReleaseFailed is recorded when a claim could not be removed
+	AutoClaimActionReleaseFailed AutoClaimAction = "ReleaseFailed"
+)
+
+// AutoClaimPolicyAction records an action taken for a device matched by an AutoClaimPolicy
+type AutoClaimPolicyAction struct {
+	Time      metav1.Time     `json:"time"`
+	Action    AutoClaimAction `json:"action"`
+	Subsystem NodeSubsystem   `json:"subsystem"`
+	Name      string          `json:"name"`
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+}
+
+const (
+	// AutoClaimPolicyLabel is set on claims created for an AutoClaimPolicy to the name of the policy
+	AutoClaimPolicyLabel = "pcidevices.harvesterhci.io/autoclaimpolicy"
+)
@@ -0,0 +1,35 @@
+package v1beta1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClaimLeaseStatus reports the lease of a PCIDeviceClaim or USBDeviceClaim with a lease duration
+type ClaimLeaseStatus struct {
+	State ClaimLeaseState `json:"state"`
+	// ExpiryTime is the creation time of the claim plus the lease duration
+	ExpiryTime metav1.Time `json:"expiryTime"`
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+}
+
+// ClaimLeaseState is the state of the lease of a claim
+type ClaimLeaseState string
+
+const (
+	ClaimLeaseActive ClaimLeaseState = "Active"
+	// ClaimLeaseExpiring warns the lease expires within ClaimLeaseWarningPeriod
+	ClaimLeaseExpiring ClaimLeaseState = "Expiring"
+	// ClaimLeaseHeld is reported once the lease has expired, while the device is in use by a running VMI
+	ClaimLeaseHeld ClaimLeaseState = "Held"
+	// ClaimLeaseBlocked is reported once the lease has expired, while the device is no longer in use but still
+	// allocated to stopped VMs. Stopped VMs block the expiry, as the claim delete webhooks reject the removal of
+	// claims allocated to a VM. The claim is released once the device is removed from the VMs, or the VMs are deleted
+	ClaimLeaseBlocked ClaimLeaseState = "Blocked"
+	// ClaimLeaseExpired is reported once the lease has expired and the claim is being released
+	ClaimLeaseExpired ClaimLeaseState = "Expired"
+
+	ClaimLeaseWarningPeriod = 15 * time.Minute
+)
@@ -8,3 +8,44 @@ type AllocationDetails struct {
 	GPUs        map[string][]string `json:"gpus,omitempty"`
 	HostDevices map[string][]string `json:"hostdevices,omitempty"`
 }
+
+// Condition types reported on device and claim status
+const (
+	// ConditionReady is true once the device or claim is fully configured and usable
+	ConditionReady = "Ready"
+	// ConditionBound is true once a claimed device is bound to the passthrough driver
+	ConditionBound = "Bound"
+	// ConditionPluginRegistered is true once a device plugin is serving the device to kubelet
+	ConditionPluginRegistered = "PluginRegistered"
+	// ConditionDegraded is true when the last reconcile failed to apply the desired state
+	ConditionDegraded = "Degraded"
+	// ConditionNodeAgentReady is Unknown when the agent on the node managing the object has stopped reporting
+	ConditionNodeAgentReady = "NodeAgentReady"
+	// ConditionResourceNameCollision is true when devices with different vendor and device ids share a resource name
+	ConditionResourceNameCollision = "ResourceNameCollision"
+	// ConditionReleased is true once a claim is being removed by its release policy
+	ConditionReleased = "Released"
+)
+
+// Condition reasons reported on device and claim status
+const (
+	ReasonReconciled          = "Reconciled"
+	ReasonPending             = "Pending"
+	ReasonDisabled            = "Disabled"
+	ReasonDriverBindFailed    = "DriverBindFailed"
+	ReasonDriverBound         = "DriverBound"
+	ReasonBindingSkipped      = "BindingSkipped"
+	ReasonPluginRegistered    = "PluginRegistered"
+	ReasonPluginFailed        = "PluginFailed"
+	ReasonConfigurationError  = "ConfigurationError"
+	ReasonOrphaned            = "Orphaned"
+	ReasonResetFailed         = "ResetFailed"
+	ReasonHeartbeatReceived   = "HeartbeatReceived"
+	ReasonNodeAgentStale      = "NodeAgentStale"
+	ReasonResourceNameShared  = "ResourceNameShared"
+	ReasonResourceNameUnique  = "ResourceNameUnique"
+	ReasonInsufficientDevices = "InsufficientDevices"
+	ReasonVMDeleted           = "VMDeleted"
+	ReasonIdleTimeout         = "IdleTimeout"
+	ReasonClaimFailed         = "ClaimFailed"
+)
@@ -0,0 +1,99 @@
+package v1beta1
+
+import (
+	"fmt"
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DeviceAccessPolicy restricts the devices selected by the policy to VMs in the granted namespaces, or VMs submitted by
+// the granted service accounts. Devices which are not selected by any policy may be used by VMs in all namespaces.
+// A device selected by several policies may be used by any subject granted by one of them
+type DeviceAccessPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DeviceAccessPolicySpec `json:"spec,omitempty"`
+}
+
+type DeviceAccessPolicySpec struct {
+	// Namespaces are the namespaces whose VMs may use the selected devices
+	// +kubebuilder:validation:Optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// ServiceAccounts may use the selected devices in VMs of any namespace
+	// +kubebuilder:validation:Optional
+	ServiceAccounts []ServiceAccountReference `json:"serviceAccounts,omitempty"`
+	// Devices select the devices governed by the policy
+	// +kubebuilder:validation:Required
+	Devices []DeviceAccessRule `json:"devices"`
+}
+
+type ServiceAccountReference struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// Username returns the name service account requests are authenticated as
+func (r ServiceAccountReference) Username() string {
+	return fmt.Sprintf("system:serviceaccount:%s:%s", r.Namespace, r.Name)
+}
+
+// DeviceAccessKind is the kind of device selected by a DeviceAccessRule
+type DeviceAccessKind string
+
+const (
+	DeviceAccessKindPCIDevice  DeviceAccessKind = "PCIDevice"
+	DeviceAccessKindUSBDevice  DeviceAccessKind = "USBDevice"
+	DeviceAccessKindVGPUDevice DeviceAccessKind = "VGPUDevice"
+)
+
+// DeviceAccessRule selects devices of a kind by their resource name, their names, or both
+type DeviceAccessRule struct {
+	// +kubebuilder:validation:Enum=PCIDevice;USBDevice;VGPUDevice
+	Kind DeviceAccessKind `json:"kind"`
+	// ResourceName selects the devices exposed under the kubelet resource name
+	// +kubebuilder:validation:Optional
+	ResourceName string `json:"resourceName,omitempty"`
+	// Names selects devices by the name of the device object
+	// +kubebuilder:validation:Optional
+	Names []string `json:"names,omitempty"`
+}
+
+// Matches returns true if the device of kind with name and resourceName is selected by the rule
+func (r DeviceAccessRule) Matches(kind DeviceAccessKind, name, resourceName string) bool {
+	if r.Kind != kind {
+		return false
+	}
+	if r.ResourceName != "" && r.ResourceName != resourceName {
+		return false
+	}
+	return len(r.Names) == 0 || slices.Contains(r.Names, name)
+}
+
+// Selects returns true if the device of kind with name and resourceName is governed by the policy
+func (s DeviceAccessPolicySpec) Selects(kind DeviceAccessKind, name, resourceName string) bool {
+	for _, v := range s.Devices {
+		if v.Matches(kind, name, resourceName) {
+			return true
+		}
+	}
+	return false
+}
+
+// Grants returns true if VMs in namespace, or submitted by username, may use the devices selected by the policy
+func (s DeviceAccessPolicySpec) Grants(namespace, username string) bool {
+	if slices.Contains(s.Namespaces, namespace) {
+		return true
+	}
+	for _, v := range s.ServiceAccounts {
+		if v.Username() == username {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package v1beta1
+
+import (
+	"path"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DeviceDiscoveryPolicy limits the devices discovered by the agents on the nodes selected by the policy.
+// A device is only discovered when it is allowed by every policy which applies to its node
+type DeviceDiscoveryPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DeviceDiscoveryPolicySpec `json:"spec,omitempty"`
+}
+
+type DeviceDiscoveryPolicySpec struct {
+	// NodeSelector selects the nodes the policy applies to, the policy applies to all nodes when it is not set
+	// +kubebuilder:validation:Optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+	// Include rules limit discovery to matching devices. Rules only apply to devices of their subsystem,
+	// so devices of a subsystem without include rules are not limited
+	// +kubebuilder:validation:Optional
+	Include []DeviceDiscoveryRule `json:"include,omitempty"`
+	// Exclude rules hide matching devices, and take precedence over include rules
+	// +kubebuilder:validation:Optional
+	Exclude []DeviceDiscoveryRule `json:"exclude,omitempty"`
+}
+
+// DeviceDiscoveryRule matches devices by their ids and address. Fields which are not set match all devices
+type DeviceDiscoveryRule struct {
+	// Subsystem is PCI or USB, rules without a subsystem match devices of both subsystems
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=PCI;USB
+	Subsystem NodeSubsystem `json:"subsystem,omitempty"`
+	// +kubebuilder:validation:Optional
+	VendorID string `json:"vendorID,omitempty"`
+	// DeviceID is matched against the product id of usb devices
+	// +kubebuilder:validation:Optional
+	DeviceID string `json:"deviceID,omitempty"`
+	// ClassID is matched as a prefix of the class and subclass of pci devices, and the class code of usb devices.
+	// A ClassID of 02 matches all pci network controllers
+	// +kubebuilder:validation:Optional
+	ClassID string `json:"classID,omitempty"`
+	// Address is a glob matched against the pci address of pci devices, and the device path of usb devices
+	// such as /dev/bus/usb/001/*
+	// +kubebuilder:validation:Optional
+	Address string `json:"address,omitempty"`
+}
+
+// DiscoveredDevice identifies a device which is evaluated against a DeviceDiscoveryPolicy
+type DiscoveredDevice struct {
+	Subsystem NodeSubsystem
+	VendorID  string
+	DeviceID  string
+	ClassID   string
+	Address   string
+}
+
+// Matches returns true if dev is matched by the rule
+func (r DeviceDiscoveryRule) Matches(dev DiscoveredDevice) bool {
+	if r.Subsystem != "" && r.Subsystem != dev.Subsystem {
+		return false
+	}
+	if r.VendorID != "" && !strings.EqualFold(r.VendorID, dev.VendorID) {
+		return false
+	}
+	if r.DeviceID != "" && !strings.EqualFold(r.DeviceID, dev.DeviceID) {
+		return false
+	}
+	if !strings.HasPrefix(strings.ToLower(dev.ClassID), strings.ToLower(r.ClassID)) {
+		return false
+	}
+	if r.Address == "" {
+		return true
+	}
+	// invalid patterns are rejected by the webhook, and never match
+	ok, _ := path.Match(r.Address, dev.Address)
+	return ok
+}
+
+// Allows returns true if dev is discovered on the nodes the policy applies to
+func (s DeviceDiscoveryPolicySpec) Allows(dev DiscoveredDevice) bool {
+	for _, v := range s.Exclude {
+		if v.Matches(dev) {
+			return false
+		}
+	}
+
+	var includeRules int
+	for _, v := range s.Include {
+		if v.Subsystem != "" && v.Subsystem != dev.Subsystem {
+			continue
+		}
+		if v.Matches(dev) {
+			return true
+		}
+		includeRules++
+	}
+	return includeRules == 0
+}
@@ -0,0 +1,70 @@
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceDiscoveryPolicySpecAllows(t *testing.T) {
+	managementEngine := DiscoveredDevice{Subsystem: SubsystemPCI, VendorID: "8086", DeviceID: "a0e0", ClassID: "0780", Address: "0000:00:16.0"}
+	gpu := DiscoveredDevice{Subsystem: SubsystemPCI, VendorID: "10de", DeviceID: "2236", ClassID: "0302", Address: "0000:08:00.0"}
+	nic := DiscoveredDevice{Subsystem: SubsystemPCI, VendorID: "8086", DeviceID: "1521", ClassID: "0200", Address: "0000:04:00.0"}
+	usbStorage := DiscoveredDevice{Subsystem: SubsystemUSB, VendorID: "0951", DeviceID: "1666", ClassID: "08", Address: "/dev/bus/usb/001/002"}
+
+	tests := []struct {
+		name    string
+		spec    DeviceDiscoveryPolicySpec
+		allowed []DiscoveredDevice
+		denied  []DiscoveredDevice
+	}{
+		{
+			name:    "empty policy allows all devices",
+			allowed: []DiscoveredDevice{managementEngine, gpu, nic, usbStorage},
+		},
+		{
+			name: "exclude by vendor and class",
+			spec: DeviceDiscoveryPolicySpec{
+				Exclude: []DeviceDiscoveryRule{{VendorID: "8086", ClassID: "078"}},
+			},
+			allowed: []DiscoveredDevice{gpu, nic, usbStorage},
+			denied:  []DiscoveredDevice{managementEngine},
+		},
+		{
+			name: "include only limits devices of its subsystem",
+			spec: DeviceDiscoveryPolicySpec{
+				Include: []DeviceDiscoveryRule{{Subsystem: SubsystemPCI, ClassID: "03"}, {Subsystem: SubsystemPCI, ClassID: "02"}},
+			},
+			allowed: []DiscoveredDevice{gpu, nic, usbStorage},
+			denied:  []DiscoveredDevice{managementEngine},
+		},
+		{
+			name: "exclude takes precedence over include",
+			spec: DeviceDiscoveryPolicySpec{
+				Include: []DeviceDiscoveryRule{{ClassID: "02"}},
+				Exclude: []DeviceDiscoveryRule{{Address: "0000:04:*"}},
+			},
+			denied: []DiscoveredDevice{nic, gpu, usbStorage},
+		},
+		{
+			name: "usb rules match the device path",
+			spec: DeviceDiscoveryPolicySpec{
+				Exclude: []DeviceDiscoveryRule{{Subsystem: SubsystemUSB, Address: "/dev/bus/usb/001/*"}},
+			},
+			allowed: []DiscoveredDevice{managementEngine, gpu, nic},
+			denied:  []DiscoveredDevice{usbStorage},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := require.New(t)
+			for _, dev := range tt.allowed {
+				assert.True(tt.spec.Allows(dev), "expected device %v to be allowed", dev)
+			}
+			for _, dev := range tt.denied {
+				assert.False(tt.spec.Allows(dev), "expected device %v to be denied", dev)
+			}
+		})
+	}
+}
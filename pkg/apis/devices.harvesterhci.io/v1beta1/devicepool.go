@@ -0,0 +1,57 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DevicePool exposes the selected PCIDevices under a single resource name, so devices with different vendor
+// and device ids, such as several GPU SKUs, are served by one device plugin. Resource names set on the PCIDeviceSpec
+// or by the vgpu and pcideviceclaim controllers take precedence over pools, and pools take precedence over ResourceNameRules
+type DevicePool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DevicePoolSpec `json:"spec,omitempty"`
+}
+
+type DevicePoolSpec struct {
+	// Selector selects PCIDevices by their labels, such as the nodename label
+	// +kubebuilder:validation:Optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Devices limits the pool to devices matching any of the filters
+	// +kubebuilder:validation:Optional
+	Devices []PCIDeviceFilter `json:"devices,omitempty"`
+	// ResourceName is the kubelet resource name used to expose all devices in the pool
+	// +kubebuilder:validation:Required
+	ResourceName string `json:"resourceName"`
+}
+
+// Matches returns true if pd is selected by the pool. Pools without a selector or device filters match no devices
+func (s DevicePoolSpec) Matches(pd *PCIDevice) bool {
+	if s.Selector == nil && len(s.Devices) == 0 {
+		return false
+	}
+
+	if s.Selector != nil {
+		// invalid selectors are rejected by the webhook, and never match
+		selector, err := metav1.LabelSelectorAsSelector(s.Selector)
+		if err != nil || !selector.Matches(labels.Set(pd.Labels)) {
+			return false
+		}
+	}
+
+	if len(s.Devices) == 0 {
+		return true
+	}
+	for _, v := range s.Devices {
+		if v.Matches(pd.Status.VendorID, pd.Status.DeviceID, pd.Status.ClassID) {
+			return true
+		}
+	}
+	return false
+}
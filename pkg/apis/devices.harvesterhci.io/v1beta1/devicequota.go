@@ -0,0 +1,99 @@
+package v1beta1
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DeviceQuota limits the number of devices the VMs in its namespace may request, by resource name or by device class.
+// Each host device and gpu in the spec of a VM, or of a VMI created without a VM, counts as one device, whether the VM
+// is running or not. The quota is best-effort: VMs created concurrently are admitted against the same usage, and may
+// together exceed it
+type DeviceQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DeviceQuotaSpec   `json:"spec,omitempty"`
+	Status DeviceQuotaStatus `json:"status,omitempty"`
+}
+
+type DeviceQuotaSpec struct {
+	// Hard is the maximum number of devices VMs in the namespace may request
+	// +kubebuilder:validation:Required
+	Hard DeviceQuotaResources `json:"hard"`
+}
+
+type DeviceQuotaStatus struct {
+	// Used is the number of devices currently requested by VMs in the namespace, for the limits set in the spec
+	// +kubebuilder:validation:Optional
+	Used DeviceQuotaResources `json:"used,omitempty"`
+}
+
+// DeviceQuotaResources counts devices by the resource name requested by VMs and by device class
+type DeviceQuotaResources struct {
+	// +kubebuilder:validation:Optional
+	ResourceNames map[string]int `json:"resourceNames,omitempty"`
+	// +kubebuilder:validation:Optional
+	Classes map[DeviceClass]int `json:"classes,omitempty"`
+}
+
+// DeviceClass groups devices of different resource names for a DeviceQuota
+type DeviceClass string
+
+const (
+	// DeviceClassGPU are pci display controllers passed through to VMs
+	DeviceClassGPU DeviceClass = "GPU"
+	// DeviceClassNICVF are virtual functions of pci network controllers passed through to VMs
+	DeviceClassNICVF DeviceClass = "NICVF"
+	DeviceClassUSB   DeviceClass = "USB"
+	DeviceClassVGPU  DeviceClass = "VGPU"
+)
+
+// QuotaClass returns the DeviceClass the PCIDevice is counted as, or an empty class if the device is only
+// counted by resource name
+func (pd *PCIDevice) QuotaClass() DeviceClass {
+	switch {
+	case strings.HasPrefix(pd.Status.ClassID, "03"):
+		return DeviceClassGPU
+	case strings.HasPrefix(pd.Status.ClassID, "02") && pd.Status.PhysFn != "":
+		return DeviceClassNICVF
+	}
+	return ""
+}
+
+// Add counts count devices for resourceName and class. An empty class is not counted
+func (r *DeviceQuotaResources) Add(resourceName string, class DeviceClass, count int) {
+	if r.ResourceNames == nil {
+		r.ResourceNames = make(map[string]int)
+	}
+	r.ResourceNames[resourceName] += count
+	if class == "" {
+		return
+	}
+	if r.Classes == nil {
+		r.Classes = make(map[DeviceClass]int)
+	}
+	r.Classes[class] += count
+}
+
+// Limited returns the counts of r for the resource names and classes limited by hard
+func (r DeviceQuotaResources) Limited(hard DeviceQuotaResources) DeviceQuotaResources {
+	result := DeviceQuotaResources{}
+	for name := range hard.ResourceNames {
+		if result.ResourceNames == nil {
+			result.ResourceNames = make(map[string]int)
+		}
+		result.ResourceNames[name] = r.ResourceNames[name]
+	}
+	for class := range hard.Classes {
+		if result.Classes == nil {
+			result.Classes = make(map[DeviceClass]int)
+		}
+		result.Classes[class] = r.Classes[class]
+	}
+	return result
+}
@@ -0,0 +1,67 @@
+package v1beta1
+
+import (
+	"fmt"
+
+	"github.com/jaypipes/ghw/pkg/pci"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// an IOMMUGroup describes the devices sharing an iommu group on a node. Devices in the same
+// group cannot be isolated from each other, and need to be passed through to a VM together
+type IOMMUGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status IOMMUGroupStatus `json:"status,omitempty"`
+}
+
+type IOMMUGroupStatus struct {
+	NodeName string             `json:"nodeName"`
+	Group    int                `json:"group"`
+	Devices  []IOMMUGroupDevice `json:"devices,omitempty"`
+	// Isolated is true when the group contains a single device apart from pci bridges,
+	// and the device can be passed through without dragging other devices along with it
+	Isolated bool `json:"isolated"`
+}
+
+// IOMMUGroupDevice is a member device of an IOMMUGroup
+type IOMMUGroupDevice struct {
+	Address string `json:"address"`
+	// PCIDeviceName is the name of the PCIDevice object for the member, and is empty for bridges
+	// and other devices which are not managed, such as management nics
+	PCIDeviceName     string `json:"pciDeviceName,omitempty"`
+	VendorID          string `json:"vendorId"`
+	DeviceID          string `json:"deviceId"`
+	ClassID           string `json:"classId"`
+	Description       string `json:"description,omitempty"`
+	KernelDriverInUse string `json:"kernelDriverInUse,omitempty"`
+	Bridge            bool   `json:"bridge"`
+}
+
+func IOMMUGroupNameForHostname(group int, hostname string) string {
+	return fmt.Sprintf("%s-iommugroup-%d", hostname, group)
+}
+
+// NewIOMMUGroupDevice describes dev as a member of an IOMMUGroup. managed indicates if a PCIDevice
+// object exists for dev
+func NewIOMMUGroupDevice(dev *pci.Device, hostname string, bridge bool, managed bool) IOMMUGroupDevice {
+	member := IOMMUGroupDevice{
+		Address:           dev.Address,
+		VendorID:          dev.Vendor.ID,
+		DeviceID:          dev.Product.ID,
+		ClassID:           fmt.Sprintf("%s%s", dev.Class.ID, dev.Subclass.ID),
+		Description:       description(dev),
+		KernelDriverInUse: dev.Driver,
+		Bridge:            bridge,
+	}
+	if managed {
+		member.PCIDeviceName = PCIDeviceNameForHostname(dev.Address, hostname)
+	}
+	return member
+}
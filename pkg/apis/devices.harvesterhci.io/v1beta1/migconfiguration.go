@@ -26,6 +26,8 @@ type MigConfigurationStatus struct {
 	ProfileStatus []MigProfileStatus     `json:"profileStatus,omitempty"`
 	Status        MIGConfigurationStatus `json:"status"`
 	Message       string                 `json:"message,omitempty"`
+	// +kubebuilder:validation:Optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 type MigProfiles struct {
@@ -1,6 +1,8 @@
 package v1beta1
 
 import (
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -13,12 +15,188 @@ type Node struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec NodeSpec `json:"spec,omitempty"`
+	Spec   NodeSpec   `json:"spec,omitempty"`
+	Status NodeStatus `json:"status,omitempty"`
+}
+
+// NodeSpec configures the agent running on the node, changes are applied without restarting the agent
+type NodeSpec struct {
+	// SkipAddresses are pci addresses of devices which are not discovered, in addition to the nics managed by harvester.
+	// Devices bound to a vfio driver or claimed remain discovered until they are released
+	// +kubebuilder:validation:Optional
+	SkipAddresses []string `json:"skipAddresses,omitempty"`
+	// SkipDevices are filters matching pci devices which are not discovered. Devices bound to a vfio driver or claimed
+	// remain discovered until they are released
+	// +kubebuilder:validation:Optional
+	SkipDevices []PCIDeviceFilter `json:"skipDevices,omitempty"`
+	// DisabledSubsystems are not scanned by the agent. Objects previously discovered for a disabled subsystem are left as is,
+	// so existing claims continue to work. MIG configurations are managed by the gpu controller and cannot be disabled
+	// +kubebuilder:validation:Optional
+	DisabledSubsystems []NodeSubsystem `json:"disabledSubsystems,omitempty"`
+	// ReconcileInterval is the interval between full rescans of all devices on the node, when pci hotplug events are not available
+	// +kubebuilder:validation:Optional
+	ReconcileInterval *metav1.Duration `json:"reconcileInterval,omitempty"`
+	// HotplugResyncInterval is the interval between full rescans of all devices on the node while pci hotplug events are applied
+	// +kubebuilder:validation:Optional
+	HotplugResyncInterval *metav1.Duration `json:"hotplugResyncInterval,omitempty"`
+}
+
+// PCIDeviceFilter matches pci devices by their ids. Fields which are not set match all devices
+type PCIDeviceFilter struct {
+	// +kubebuilder:validation:Optional
+	VendorID string `json:"vendorID,omitempty"`
+	// +kubebuilder:validation:Optional
+	DeviceID string `json:"deviceID,omitempty"`
+	// ClassID is matched as a prefix of the class and subclass of the device, so 02 matches all network controllers
+	// +kubebuilder:validation:Optional
+	ClassID string `json:"classID,omitempty"`
+}
+
+// Matches returns true if the device with the vendor, device and class ids is matched by the filter
+func (f PCIDeviceFilter) Matches(vendorID, deviceID, classID string) bool {
+	if f.VendorID != "" && !strings.EqualFold(f.VendorID, vendorID) {
+		return false
+	}
+	if f.DeviceID != "" && !strings.EqualFold(f.DeviceID, deviceID) {
+		return false
+	}
+	return strings.HasPrefix(strings.ToLower(classID), strings.ToLower(f.ClassID))
+}
+
+// SubsystemDisabled returns true if subsystem should not be scanned by the agent
+func (s NodeSpec) SubsystemDisabled(subsystem NodeSubsystem) bool {
+	for _, v := range s.DisabledSubsystems {
+		if v == subsystem {
+			return true
+		}
+	}
+	return false
 }
 
-type NodeSpec struct{}
+// NodeStatus reports the capabilities of the node agent
+type NodeStatus struct {
+	// VFIODrivers are the vfio-pci and vfio variant drivers which are loaded or can be loaded on the node
+	// +kubebuilder:validation:Optional
+	VFIODrivers []string `json:"vfioDrivers,omitempty"`
+	// Readiness reports the result of each preflight check for pci passthrough on the node
+	// +kubebuilder:validation:Optional
+	Readiness []NodeReadinessCheck `json:"readiness,omitempty"`
+	// PassthroughReady is true when no readiness check failed
+	PassthroughReady bool `json:"passthroughReady"`
+	// AgentVersion is the version of the pcidevices agent running on the node
+	// +kubebuilder:validation:Optional
+	AgentVersion string `json:"agentVersion,omitempty"`
+	// Capabilities are the optional features supported by the agent on the node
+	// +kubebuilder:validation:Optional
+	Capabilities []NodeCapability `json:"capabilities,omitempty"`
+	// Subsystems reports the outcome of device scans performed by the agent for each subsystem
+	// +kubebuilder:validation:Optional
+	Subsystems []NodeSubsystemStatus `json:"subsystems,omitempty"`
+	// LastHeartbeatTime is periodically refreshed by the agent, and is used to detect agents which are no longer running
+	// +kubebuilder:validation:Optional
+	LastHeartbeatTime *metav1.Time `json:"lastHeartbeatTime,omitempty"`
+	// +kubebuilder:validation:Optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// NodeCapability identifies an optional feature supported by the agent on a node
+type NodeCapability string
+
+const (
+	// CapabilityPCIHotplug is reported when the agent applies pci uevents as devices are added or removed
+	CapabilityPCIHotplug NodeCapability = "PCIHotplug"
+	// CapabilityVFIOVariantDrivers is reported when vfio variant drivers are available on the node
+	CapabilityVFIOVariantDrivers NodeCapability = "VFIOVariantDrivers"
+	// CapabilityDeviceReset is reported when the agent can reset devices as defined by a claim ResetPolicy
+	CapabilityDeviceReset NodeCapability = "DeviceReset"
+	// CapabilityIOMMUGroupClaims is reported when the agent can claim all devices in an iommu group
+	CapabilityIOMMUGroupClaims NodeCapability = "IOMMUGroupClaims"
+)
+
+// NodeSubsystem identifies a class of devices scanned by the agent
+type NodeSubsystem string
+
+const (
+	SubsystemPCI   NodeSubsystem = "PCI"
+	SubsystemUSB   NodeSubsystem = "USB"
+	SubsystemSRIOV NodeSubsystem = "SRIOV"
+	SubsystemVGPU  NodeSubsystem = "VGPU"
+	SubsystemMIG   NodeSubsystem = "MIG"
+)
+
+// NodeSubsystemStatus is the outcome of device scans for a subsystem
+type NodeSubsystemStatus struct {
+	Name NodeSubsystem `json:"name"`
+	// LastSuccessfulScanTime is the time the subsystem was last scanned without errors
+	// +kubebuilder:validation:Optional
+	LastSuccessfulScanTime *metav1.Time `json:"lastSuccessfulScanTime,omitempty"`
+	// ErrorCount is the number of failed scans since the agent was started
+	ErrorCount int64 `json:"errorCount"`
+	// LastError is the error returned by the last failed scan
+	// +kubebuilder:validation:Optional
+	LastError string `json:"lastError,omitempty"`
+	// Disabled is true when the subsystem is disabled in the NodeSpec
+	// +kubebuilder:validation:Optional
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// NodeReadinessCheckName identifies a preflight check for pci passthrough
+type NodeReadinessCheckName string
+
+const (
+	// ReadinessIOMMU checks the iommu is enabled, which requires intel_iommu=on or amd_iommu on the kernel cmdline
+	ReadinessIOMMU NodeReadinessCheckName = "IOMMU"
+	// ReadinessInterruptRemapping checks interrupt remapping is available, without it vfio refuses to assign
+	// devices unless unsafe interrupts are allowed
+	ReadinessInterruptRemapping NodeReadinessCheckName = "InterruptRemapping"
+	// ReadinessVFIOModules checks the vfio-pci modules are loaded or can be loaded
+	ReadinessVFIOModules NodeReadinessCheckName = "VFIOModules"
+	// ReadinessACSOverride warns when pcie_acs_override is set, as iommu groups no longer guarantee isolation
+	ReadinessACSOverride NodeReadinessCheckName = "ACSOverride"
+)
+
+// NodeReadinessResult is the outcome of a readiness check
+type NodeReadinessResult string
+
+const (
+	ReadinessPassed  NodeReadinessResult = "Passed"
+	ReadinessWarning NodeReadinessResult = "Warning"
+	ReadinessFailed  NodeReadinessResult = "Failed"
+)
+
+// NodeReadinessCheck is the result of a single preflight check
+type NodeReadinessCheck struct {
+	Name    NodeReadinessCheckName `json:"name"`
+	Result  NodeReadinessResult    `json:"result"`
+	Message string                 `json:"message,omitempty"`
+}
+
+// HasCapability returns true if the agent on the node supports capability
+func (s NodeStatus) HasCapability(capability NodeCapability) bool {
+	for _, v := range s.Capabilities {
+		if v == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsVFIODriver returns true if driver is available on the node
+func (s NodeStatus) SupportsVFIODriver(driver string) bool {
+	for _, v := range s.VFIODrivers {
+		if v == driver {
+			return true
+		}
+	}
+	return false
+}
 
 const (
 	NodeEnvVarName = "NODE_NAME"
 	NodeKeyName    = "nodename"
+
+	// PassthroughReadyLabel is set on nodes to summarise the readiness checks for pci passthrough
+	PassthroughReadyLabel = "pcidevices.harvesterhci.io/passthrough-ready"
+	// NodeReadinessLabelPrefix is combined with the lower case check name to label nodes with the result of each check
+	NodeReadinessLabelPrefix = "readiness.pcidevices.harvesterhci.io/"
 )
@@ -2,6 +2,8 @@ package v1beta1
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -19,6 +21,9 @@ const (
 	ShortenedVFSuffix   = "VF"
 )
 
+// SysBusPCIDevices is the sysfs path used to lookup additional device attributes
+var SysBusPCIDevices = "/sys/bus/pci/devices"
+
 // +genclient
 // +genclient:nonNamespaced
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -44,8 +49,80 @@ type PCIDeviceStatus struct {
 	ResourceName      string `json:"resourceName"`
 	Description       string `json:"description"`
 	KernelDriverInUse string `json:"kernelDriverInUse,omitempty"`
+	// NUMANode is the numa node the device is attached to, -1 if the platform does not report one
+	NUMANode          int    `json:"numaNode"`
+	SubsystemVendorID string `json:"subsystemVendorId,omitempty"`
+	SubsystemDeviceID string `json:"subsystemDeviceId,omitempty"`
+	Revision          string `json:"revision,omitempty"`
+	CurrentLinkSpeed  string `json:"currentLinkSpeed,omitempty"`
+	CurrentLinkWidth  string `json:"currentLinkWidth,omitempty"`
+	MaxLinkSpeed      string `json:"maxLinkSpeed,omitempty"`
+	MaxLinkWidth      string `json:"maxLinkWidth,omitempty"`
+	// SRIOVTotalVFs is the max number of VFs supported by a physical function
+	SRIOVTotalVFs int `json:"sriovTotalVFs,omitempty"`
+	// SRIOVNumVFs is the number of VFs currently configured on a physical function
+	SRIOVNumVFs int `json:"sriovNumVFs,omitempty"`
+	// PhysFn is the address of the parent physical function, and is only set for VFs
+	PhysFn string `json:"physFn,omitempty"`
+	// ResetMethods lists the reset methods supported by the device in the order the kernel will try them
+	ResetMethods []string `json:"resetMethods,omitempty"`
+	// BootVGA is set if the device is the vga device used by the host console
+	BootVGA bool `json:"bootVGA,omitempty"`
+	// Passthrough reports the observed passthrough state when passthrough is managed from the spec
+	Passthrough *PCIDevicePassthroughStatus `json:"passthrough,omitempty"`
+	// HostUsage lists the ways the host is currently using the device. Claims for devices in use by the host
+	// are rejected unless forced
+	// +kubebuilder:validation:Optional
+	HostUsage []PCIDeviceHostUsage `json:"hostUsage,omitempty"`
+	// DevicePool is the DevicePool the device is exposed through
+	// +kubebuilder:validation:Optional
+	DevicePool string `json:"devicePool,omitempty"`
+	// +kubebuilder:validation:Optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// PCIDeviceHostUsageType identifies how the host is using a device
+type PCIDeviceHostUsageType string
+
+const (
+	// HostUsageMount is reported for storage controllers with a mounted block device
+	HostUsageMount PCIDeviceHostUsageType = "Mount"
+	// HostUsageNetworkAddress is reported for network devices carrying host ip addresses
+	HostUsageNetworkAddress PCIDeviceHostUsageType = "NetworkAddress"
+	// HostUsageConsole is reported for display devices driving the host console
+	HostUsageConsole PCIDeviceHostUsageType = "Console"
+)
+
+// PCIDeviceHostUsage describes a single use of a device by the host
+type PCIDeviceHostUsage struct {
+	Type PCIDeviceHostUsageType `json:"type"`
+	// Name is the block device, network interface or framebuffer in use
+	Name    string `json:"name"`
+	Message string `json:"message,omitempty"`
 }
 
+// PCIDevicePassthroughStatus reports the observed passthrough state of a device against the desired
+// state in the PCIDeviceSpec
+type PCIDevicePassthroughStatus struct {
+	// Enabled is true once the device is bound to the passthrough driver and exposed to kubelet
+	Enabled bool `json:"enabled"`
+	// Driver is the kernel driver currently bound to the device
+	Driver string `json:"driver,omitempty"`
+	// ClaimName is the PCIDeviceClaim currently holding the device
+	ClaimName string                    `json:"claimName,omitempty"`
+	State     PCIDevicePassthroughState `json:"state"`
+	Message   string                    `json:"message,omitempty"`
+}
+
+type PCIDevicePassthroughState string
+
+const (
+	PCIDevicePassthroughSynced    PCIDevicePassthroughState = "synced"
+	PCIDevicePassthroughPending   PCIDevicePassthroughState = "pending"
+	PCIDevicePassthroughOutOfSync PCIDevicePassthroughState = "out-of-sync"
+	PCIDevicePassthroughFailed    PCIDevicePassthroughState = "failed"
+)
+
 func description(dev *pci.Device) string {
 	var vendorName string
 	if dev.Vendor.Name != util.UNKNOWN {
@@ -123,6 +200,25 @@ func resourceName(dev *pci.Device) string {
 	return fmt.Sprintf("%s/%s", vendorCleaned, dev.Product.ID)
 }
 
+// GeneratedResourceName returns the resource name generated for dev when no other resource name is assigned to it
+func GeneratedResourceName(dev *pci.Device) string {
+	return resourceName(dev)
+}
+
+// HardwareID returns the vendor and device id of the device
+func (status *PCIDeviceStatus) HardwareID() string {
+	return strings.ToLower(fmt.Sprintf("%s:%s", status.VendorID, status.DeviceID))
+}
+
+// PoolingKey identifies the devices which may be pooled in one device plugin. Devices sharing a resource name must have
+// the same PoolingKey, which is the HardwareID unless the device is exposed through a DevicePool
+func (status *PCIDeviceStatus) PoolingKey() string {
+	if status.DevicePool != "" {
+		return fmt.Sprintf("devicepool/%s", status.DevicePool)
+	}
+	return status.HardwareID()
+}
+
 func (status *PCIDeviceStatus) Update(dev *pci.Device, hostname string, iommuGroups map[string]int, overrideResourceName string) {
 	status.Address = dev.Address
 	status.VendorID = dev.Vendor.ID
@@ -142,9 +238,59 @@ func (status *PCIDeviceStatus) Update(dev *pci.Device, hostname string, iommuGro
 	}
 	status.KernelDriverInUse = dev.Driver
 	status.NodeName = hostname
+	if dev.Subsystem != nil {
+		status.SubsystemVendorID = dev.Subsystem.VendorID
+		status.SubsystemDeviceID = dev.Subsystem.ID
+	}
+	status.Revision = dev.Revision
+	status.updateFromSysfs(filepath.Join(SysBusPCIDevices, dev.Address))
+}
+
+// updateFromSysfs fills in attributes which are not exposed by ghw from the device sysfs tree.
+// Attributes which are not supported by a device are reset to their zero value
+func (status *PCIDeviceStatus) updateFromSysfs(devicePath string) {
+	status.NUMANode = -1
+	if numaNode, err := strconv.Atoi(readSysfsAttribute(devicePath, "numa_node")); err == nil {
+		status.NUMANode = numaNode
+	}
+	status.CurrentLinkSpeed = readSysfsAttribute(devicePath, "current_link_speed")
+	status.CurrentLinkWidth = readSysfsAttribute(devicePath, "current_link_width")
+	status.MaxLinkSpeed = readSysfsAttribute(devicePath, "max_link_speed")
+	status.MaxLinkWidth = readSysfsAttribute(devicePath, "max_link_width")
+	status.SRIOVTotalVFs, _ = strconv.Atoi(readSysfsAttribute(devicePath, "sriov_totalvfs"))
+	status.SRIOVNumVFs, _ = strconv.Atoi(readSysfsAttribute(devicePath, "sriov_numvfs"))
+	status.PhysFn = ""
+	if physFn, err := os.Readlink(filepath.Join(devicePath, "physfn")); err == nil {
+		status.PhysFn = filepath.Base(physFn)
+	}
+	status.ResetMethods = strings.Fields(readSysfsAttribute(devicePath, "reset_method"))
+	status.BootVGA = readSysfsAttribute(devicePath, "boot_vga") == "1"
+}
+
+// readSysfsAttribute returns the trimmed contents of a sysfs attribute, or an empty string
+// if the attribute does not exist for the device
+func readSysfsAttribute(devicePath string, attribute string) string {
+	// #nosec G304 No risk for path injection. Reading static sysfs attributes of a device
+	contents, err := os.ReadFile(filepath.Join(devicePath, attribute))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(contents))
 }
 
+// PCIDeviceSpec defines the desired passthrough state of a PCIDevice. When Passthrough is set, the node agent
+// manages a PCIDeviceClaim for the device on behalf of the user
 type PCIDeviceSpec struct {
+	// Passthrough requests the device to be bound to a vfio driver and exposed to VMs
+	// +kubebuilder:validation:Optional
+	Passthrough bool `json:"passthrough,omitempty"`
+	// DriverOverride is the vfio driver to bind the device to, either vfio-pci or a vfio variant driver
+	// such as mlx5_vfio_pci. Defaults to vfio-pci
+	// +kubebuilder:validation:Optional
+	DriverOverride string `json:"driverOverride,omitempty"`
+	// ResourceName overrides the generated kubelet resource name used to expose the device
+	// +kubebuilder:validation:Optional
+	ResourceName string `json:"resourceName,omitempty"`
 }
 
 func PCIDeviceNameForHostname(address string, hostname string) string {
@@ -2,6 +2,8 @@ package v1beta1
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -257,3 +259,43 @@ func Test_trimResourceName(t *testing.T) {
 		assert.Equal(tt.want, resourceName, fmt.Sprintf("expected resourceName did not match specified for case: %s", tt.name))
 	}
 }
+
+func Test_updateFromSysfs(t *testing.T) {
+	assert := require.New(t)
+	devicePath := t.TempDir()
+	attributes := map[string]string{
+		"numa_node":          "1\n",
+		"current_link_speed": "16.0 GT/s PCIe\n",
+		"current_link_width": "8\n",
+		"max_link_speed":     "16.0 GT/s PCIe\n",
+		"max_link_width":     "16\n",
+		"sriov_totalvfs":     "16\n",
+		"sriov_numvfs":       "4\n",
+		"reset_method":       "flr bus\n",
+		"boot_vga":           "0\n",
+	}
+	for k, v := range attributes {
+		assert.NoError(os.WriteFile(filepath.Join(devicePath, k), []byte(v), 0600))
+	}
+
+	status := &PCIDeviceStatus{PhysFn: "0000:01:00.0"}
+	status.updateFromSysfs(devicePath)
+	assert.Equal(1, status.NUMANode)
+	assert.Equal("16.0 GT/s PCIe", status.CurrentLinkSpeed)
+	assert.Equal("8", status.CurrentLinkWidth)
+	assert.Equal("16.0 GT/s PCIe", status.MaxLinkSpeed)
+	assert.Equal("16", status.MaxLinkWidth)
+	assert.Equal(16, status.SRIOVTotalVFs)
+	assert.Equal(4, status.SRIOVNumVFs)
+	assert.Empty(status.PhysFn, "expected stale physfn to be cleared")
+	assert.Equal([]string{"flr", "bus"}, status.ResetMethods)
+	assert.False(status.BootVGA)
+
+	// a VF links to its parent physical function
+	vfPath := t.TempDir()
+	assert.NoError(os.Symlink("../0000:01:00.0", filepath.Join(vfPath, "physfn")))
+	status = &PCIDeviceStatus{}
+	status.updateFromSysfs(vfPath)
+	assert.Equal(-1, status.NUMANode, "expected numa node to default to -1")
+	assert.Equal("0000:01:00.0", status.PhysFn)
+}
@@ -2,6 +2,8 @@ package v1beta1
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -26,19 +28,164 @@ type PCIDeviceClaimSpec struct {
 	UserName string `json:"userName"`
 	// +kubebuilder:validation:Optional
 	DisableResourcePooling bool `json:"disableResourcePooling,omitempty"`
+	// ClaimIOMMUGroup binds every non-bridge device sharing the iommu group of the claimed device to vfio-pci.
+	// If any member of the group fails to bind, all members are returned to their original drivers
+	// +kubebuilder:validation:Optional
+	ClaimIOMMUGroup bool `json:"claimIOMMUGroup,omitempty"`
+	// Driver is the vfio driver the device is bound to, such as a vendor variant driver like mlx5_vfio_pci.
+	// Defaults to vfio-pci
+	// +kubebuilder:validation:Optional
+	Driver string `json:"driver,omitempty"`
+	// ResetPolicy controls when the device is reset through sysfs. Defaults to Never
+	// +kubebuilder:validation:Optional
+	ResetPolicy PCIDeviceResetPolicy `json:"resetPolicy,omitempty"`
+	// ResetMethods restricts the reset methods the kernel may use, in order of preference, such as flr, bus or pm.
+	// Defaults to the methods supported by the device
+	// +kubebuilder:validation:Optional
+	ResetMethods []string `json:"resetMethods,omitempty"`
+	// ReleasePolicy controls whether the claim is removed once VMs stop using the claimed device. Defaults to Retain
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Retain;ReleaseOnVMDelete;ReleaseAfterIdle
+	ReleasePolicy PCIDeviceClaimReleasePolicy `json:"releasePolicy,omitempty"`
+	// IdleTimeout is how long the claimed device may go unused before a claim with the ReleaseAfterIdle policy
+	// is removed. Defaults to 1h
+	// +kubebuilder:validation:Optional
+	IdleTimeout *metav1.Duration `json:"idleTimeout,omitempty"`
+	// LeaseDuration limits how long the claim is held from its creation. The claim is released once the lease
+	// expires and the device is no longer allocated to any VM. The lease can be extended by raising the duration
+	// +kubebuilder:validation:Optional
+	LeaseDuration *metav1.Duration `json:"leaseDuration,omitempty"`
 }
 
 func (s PCIDeviceClaimSpec) NodeAddr() string {
 	return fmt.Sprintf("%s-%s", s.NodeName, s.Address)
 }
 
+// VFIODriver returns the driver the claimed device is bound to
+func (s PCIDeviceClaimSpec) VFIODriver() string {
+	if s.Driver == "" {
+		return VFIOPCIDriver
+	}
+	return s.Driver
+}
+
+// ResetOnPhase returns true if the reset policy requires the device to be reset during phase
+func (s PCIDeviceClaimSpec) ResetOnPhase(phase PCIDeviceResetPhase) bool {
+	switch s.ResetPolicy {
+	case ResetPolicyBeforeAndAfter:
+		return true
+	case ResetPolicyOnRelease:
+		return phase == ResetPhaseAfterRelease
+	default:
+		return false
+	}
+}
+
+// IdleTimeoutOrDefault returns the time the claimed device may go unused before the claim is released
+func (s PCIDeviceClaimSpec) IdleTimeoutOrDefault() time.Duration {
+	if s.IdleTimeout == nil {
+		return DefaultClaimIdleTimeout
+	}
+	return s.IdleTimeout.Duration
+}
+
+// IsVFIODriver returns true for vfio-pci and the vfio-pci variant drivers, which are all named <vendor>_vfio_pci
+func IsVFIODriver(driver string) bool {
+	return driver == VFIOPCIDriver || strings.HasSuffix(driver, VFIOVariantDriverSuffix)
+}
+
 type PCIDeviceClaimStatus struct {
 	KernelDriverToUnbind string `json:"kernelDriverToUnbind"`
 	PassthroughEnabled   bool   `json:"passthroughEnabled"`
+	// IOMMUGroupMembers are the other devices bound to vfio-pci for claims with ClaimIOMMUGroup set
+	// +kubebuilder:validation:Optional
+	IOMMUGroupMembers []PCIDeviceClaimGroupMember `json:"iommuGroupMembers,omitempty"`
+	// LastReset records the result of the last reset of the claimed device
+	// +kubebuilder:validation:Optional
+	LastReset *PCIDeviceClaimResetStatus `json:"lastReset,omitempty"`
+	// VirtualMachines are the VMs, as namespace/name, the claimed device is currently allocated to
+	// +kubebuilder:validation:Optional
+	VirtualMachines []string `json:"virtualMachines,omitempty"`
+	// IdleSince is the time since which no VM has been using the claimed device
+	// +kubebuilder:validation:Optional
+	IdleSince *metav1.Time `json:"idleSince,omitempty"`
+	// Lease is reported for claims with a lease duration
+	// +kubebuilder:validation:Optional
+	Lease *ClaimLeaseStatus `json:"lease,omitempty"`
+	// +kubebuilder:validation:Optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// PCIDeviceResetPolicy controls when a claimed device is reset
+type PCIDeviceResetPolicy string
+
+const (
+	// ResetPolicyNever leaves the device as is when it is claimed and released
+	ResetPolicyNever PCIDeviceResetPolicy = "Never"
+	// ResetPolicyOnRelease resets the device after it is unbound from the vfio driver, before the original driver is bound
+	ResetPolicyOnRelease PCIDeviceResetPolicy = "OnRelease"
+	// ResetPolicyBeforeAndAfter additionally resets the device before it is bound to the vfio driver
+	ResetPolicyBeforeAndAfter PCIDeviceResetPolicy = "BeforeAndAfter"
+)
+
+// PCIDeviceClaimReleasePolicy controls when a claim is removed once VMs stop using the claimed device
+type PCIDeviceClaimReleasePolicy string
+
+const (
+	// ReleasePolicyRetain keeps the claim until it is removed by the user
+	ReleasePolicyRetain PCIDeviceClaimReleasePolicy = "Retain"
+	// ReleasePolicyReleaseOnVMDelete removes the claim once the VMs using the device are deleted, or no longer
+	// request the device. Claims which have not been used by a VM yet are kept, unless they were created for a VM
+	// which no longer exists or requests the device
+	ReleasePolicyReleaseOnVMDelete PCIDeviceClaimReleasePolicy = "ReleaseOnVMDelete"
+	// ReleasePolicyReleaseAfterIdle removes the claim once no VM has used the device for the idle timeout
+	ReleasePolicyReleaseAfterIdle PCIDeviceClaimReleasePolicy = "ReleaseAfterIdle"
+
+	DefaultClaimIdleTimeout = time.Hour
+)
+
+// PCIDeviceResetPhase is the point of the claim lifecycle at which a device was reset
+type PCIDeviceResetPhase string
+
 const (
+	ResetPhaseBeforeBind   PCIDeviceResetPhase = "BeforeBind"
+	ResetPhaseAfterRelease PCIDeviceResetPhase = "AfterRelease"
+)
+
+// PCIDeviceClaimResetStatus is the result of a device reset
+type PCIDeviceClaimResetStatus struct {
+	Phase PCIDeviceResetPhase `json:"phase"`
+	// Methods are the reset methods the kernel was allowed to use, in order of preference
+	Methods   []string    `json:"methods,omitempty"`
+	Succeeded bool        `json:"succeeded"`
+	Message   string      `json:"message,omitempty"`
+	Time      metav1.Time `json:"time"`
+}
+
+// PCIDeviceClaimGroupMember records a device bound to vfio-pci as part of an iommu group claim,
+// and the driver it is returned to once the claim is removed
+type PCIDeviceClaimGroupMember struct {
+	Address        string `json:"address"`
+	OriginalDriver string `json:"originalDriver,omitempty"`
+}
+
+const (
+	VFIOPCIDriver           = "vfio-pci"
+	VFIOVariantDriverSuffix = "_vfio_pci"
+
 	SkipVFIOBindingAnnotationKey = "pcidevices.harvesterhci.io/skip-vfio-binding"
+	// ForceClaimAnnotationKey allows claiming a device which is in use by the host
+	ForceClaimAnnotationKey = "pcidevices.harvesterhci.io/force-claim"
+
+	// PCIDeviceSpecManagedClaimKey is set on PCIDeviceClaims created by the node agent to
+	// reconcile PCIDeviceSpec.Passthrough, and marks claims which are removed once passthrough is disabled
+	PCIDeviceSpecManagedClaimKey = "pcidevices.harvesterhci.io/managed-by-spec"
+	// PCIDeviceSpecClaimUserName is the user recorded on claims created from the PCIDeviceSpec
+	PCIDeviceSpecClaimUserName = "pcidevice-spec"
+
+	// PCIDeviceClaimCreatedForVMKey is set on PCIDeviceClaims created by the VM mutator for the IOMMU group members
+	// of the devices of a VM, and records the VM the claim was created for as namespace/name
+	PCIDeviceClaimCreatedForVMKey = "pcidevices.harvesterhci.io/created-for-vm"
 
 	// PCIDeviceOverrideResourceName is an annotation key shared by two flows:
 	//   1. vGPU: set on both PCIDeviceClaim and PCIDevice by the vgpu controller;
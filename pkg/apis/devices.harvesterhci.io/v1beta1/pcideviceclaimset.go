@@ -0,0 +1,65 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PCIDeviceClaimSet claims a number of free PCIDevices matching the device filters, on nodes matching the node selector.
+// The controller picks the devices, creates a PCIDeviceClaim for each of them and reports the chosen devices in the status
+type PCIDeviceClaimSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PCIDeviceClaimSetSpec   `json:"spec,omitempty"`
+	Status PCIDeviceClaimSetStatus `json:"status,omitempty"`
+}
+
+type PCIDeviceClaimSetSpec struct {
+	// Count is the number of devices to claim
+	// +kubebuilder:validation:Minimum=1
+	Count int `json:"count"`
+	// Devices limits the set to devices matching any of the filters
+	// +kubebuilder:validation:Required
+	Devices []PCIDeviceFilter `json:"devices"`
+	// NodeSelector selects the nodes devices are claimed on, devices on all nodes are claimed when it is not set
+	// +kubebuilder:validation:Optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+	// UserName is recorded on the PCIDeviceClaims created for the set
+	// +kubebuilder:validation:Optional
+	UserName string `json:"userName,omitempty"`
+}
+
+// Matches returns true if pd matches any of the device filters of the set
+func (s PCIDeviceClaimSetSpec) Matches(pd *PCIDevice) bool {
+	for _, v := range s.Devices {
+		if v.Matches(pd.Status.VendorID, pd.Status.DeviceID, pd.Status.ClassID) {
+			return true
+		}
+	}
+	return false
+}
+
+type PCIDeviceClaimSetStatus struct {
+	// Devices are the PCIDevices claimed for the set
+	// +kubebuilder:validation:Optional
+	Devices []PCIDeviceClaimSetDevice `json:"devices,omitempty"`
+	// +kubebuilder:validation:Optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// PCIDeviceClaimSetDevice is a PCIDevice claimed for a PCIDeviceClaimSet. The PCIDeviceClaim has the same name as the device
+type PCIDeviceClaimSetDevice struct {
+	Name               string `json:"name"`
+	NodeName           string `json:"nodeName"`
+	Address            string `json:"address"`
+	PassthroughEnabled bool   `json:"passthroughEnabled"`
+}
+
+const (
+	// PCIDeviceClaimSetLabel is set on PCIDeviceClaims created for a PCIDeviceClaimSet to the name of the set
+	PCIDeviceClaimSetLabel = "pcidevices.harvesterhci.io/pcideviceclaimset"
+)
@@ -0,0 +1,89 @@
+package v1beta1
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ResourceNameRule maps pci devices to a stable resource name chosen by the admin, replacing the resource name
+// generated from the pcidb vendor and product names. Resource names set on the PCIDeviceSpec or by the
+// vgpu and pcideviceclaim controllers take precedence over rules
+type ResourceNameRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ResourceNameRuleSpec `json:"spec,omitempty"`
+}
+
+type ResourceNameRuleSpec struct {
+	// +kubebuilder:validation:Required
+	VendorID string `json:"vendorId"`
+	// +kubebuilder:validation:Required
+	DeviceID string `json:"deviceId"`
+	// SubsystemVendorID and SubsystemDeviceID limit the rule to a specific board. Rules matching the subsystem
+	// take precedence over rules which only match the vendor and device
+	// +kubebuilder:validation:Optional
+	SubsystemVendorID string `json:"subsystemVendorId,omitempty"`
+	// +kubebuilder:validation:Optional
+	SubsystemDeviceID string `json:"subsystemDeviceId,omitempty"`
+	// ResourceName is the kubelet resource name used to expose matching devices, such as nvidia.com/A100
+	// +kubebuilder:validation:Required
+	ResourceName string `json:"resourceName"`
+}
+
+// Matches returns true if the rule applies to a device with the given ids
+func (s ResourceNameRuleSpec) Matches(status *PCIDeviceStatus) bool {
+	if !strings.EqualFold(s.VendorID, status.VendorID) || !strings.EqualFold(s.DeviceID, status.DeviceID) {
+		return false
+	}
+	if s.SubsystemVendorID != "" && !strings.EqualFold(s.SubsystemVendorID, status.SubsystemVendorID) {
+		return false
+	}
+	if s.SubsystemDeviceID != "" && !strings.EqualFold(s.SubsystemDeviceID, status.SubsystemDeviceID) {
+		return false
+	}
+	return true
+}
+
+// Specificity ranks rules matching the same device, the most specific rule is applied
+func (s ResourceNameRuleSpec) Specificity() int {
+	var specificity int
+	if s.SubsystemVendorID != "" {
+		specificity++
+	}
+	if s.SubsystemDeviceID != "" {
+		specificity++
+	}
+	return specificity
+}
+
+// SameDevices returns true if both rules match exactly the same devices
+func (s ResourceNameRuleSpec) SameDevices(other ResourceNameRuleSpec) bool {
+	return strings.EqualFold(s.VendorID, other.VendorID) &&
+		strings.EqualFold(s.DeviceID, other.DeviceID) &&
+		strings.EqualFold(s.SubsystemVendorID, other.SubsystemVendorID) &&
+		strings.EqualFold(s.SubsystemDeviceID, other.SubsystemDeviceID)
+}
+
+// ValidateResourceName checks name can be used as a kubelet resource name, and that the device plugin
+// socket for the name fits within the unix socket path limit
+func ValidateResourceName(name string) error {
+	if !strings.Contains(name, "/") {
+		return fmt.Errorf("resource name %s must be of the form <vendor domain>/<name>", name)
+	}
+	if errs := validation.IsQualifiedName(name); len(errs) != 0 {
+		return fmt.Errorf("resource name %s is invalid: %s", name, strings.Join(errs, ", "))
+	}
+	socketName := fmt.Sprintf("%s%s.sock", PluginNamePrefix, strings.ReplaceAll(name, "/", "-"))
+	if len(socketName) > SocketFileNameLimit {
+		return fmt.Errorf("resource name %s is too long, device plugin socket %s exceeds %d characters", name, socketName, SocketFileNameLimit)
+	}
+	return nil
+}
@@ -52,6 +52,8 @@ type VGPUDeviceStatus struct {
 	UUID                   string            `json:"uuid,omitempty"`
 	ConfiguredVGPUTypeName string            `json:"configureVGPUTypeName,omitempty"`
 	AvailableTypes         map[string]string `json:"availableTypes,omitempty"` // reconciles against mdev_supported_types and populates name: mdevType for types which are possible
+	// +kubebuilder:validation:Optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 type VGPUStatus string
@@ -27,6 +27,8 @@ type SRIOVNetworkDeviceStatus struct {
 	VFAddresses  []string `json:"vfAddresses,omitempty"`
 	VFPCIDevices []string `json:"vfPCIDevices,omitempty"`
 	Status       string   `json:"status"`
+	// +kubebuilder:validation:Optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 const (
@@ -28,4 +28,6 @@ type USBDeviceStatus struct {
 	Enabled      bool   `json:"enabled"`
 	Status       string `json:"status,omitempty"`
 	Message      string `json:"message,omitempty"`
+	// +kubebuilder:validation:Optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
@@ -17,11 +17,20 @@ type USBDeviceClaim struct {
 
 type USBDeviceClaimSpec struct {
 	UserName string `json:"userName"`
+	// LeaseDuration limits how long the claim is held from its creation. The claim is released once the lease
+	// expires and the device is no longer allocated to any VM. The lease can be extended by raising the duration
+	// +kubebuilder:validation:Optional
+	LeaseDuration *metav1.Duration `json:"leaseDuration,omitempty"`
 }
 
 type USBDeviceClaimStatus struct {
 	NodeName   string `json:"nodeName"`
 	PCIAddress string `json:"pciAddress"`
+	// Lease is reported for claims with a lease duration
+	// +kubebuilder:validation:Optional
+	Lease *ClaimLeaseStatus `json:"lease,omitempty"`
+	// +kubebuilder:validation:Optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 const (
@@ -22,6 +22,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -71,6 +72,723 @@ func (in *AllocationDetails) DeepCopy() *AllocationDetails {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoClaimPolicy) DeepCopyInto(out *AutoClaimPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoClaimPolicy.
+func (in *AutoClaimPolicy) DeepCopy() *AutoClaimPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoClaimPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AutoClaimPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoClaimPolicyAction) DeepCopyInto(out *AutoClaimPolicyAction) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoClaimPolicyAction.
+func (in *AutoClaimPolicyAction) DeepCopy() *AutoClaimPolicyAction {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoClaimPolicyAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoClaimPolicyClaim) DeepCopyInto(out *AutoClaimPolicyClaim) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoClaimPolicyClaim.
+func (in *AutoClaimPolicyClaim) DeepCopy() *AutoClaimPolicyClaim {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoClaimPolicyClaim)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoClaimPolicyList) DeepCopyInto(out *AutoClaimPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AutoClaimPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoClaimPolicyList.
+func (in *AutoClaimPolicyList) DeepCopy() *AutoClaimPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoClaimPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AutoClaimPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoClaimPolicySpec) DeepCopyInto(out *AutoClaimPolicySpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Devices != nil {
+		in, out := &in.Devices, &out.Devices
+		*out = make([]DeviceDiscoveryRule, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoClaimPolicySpec.
+func (in *AutoClaimPolicySpec) DeepCopy() *AutoClaimPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoClaimPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoClaimPolicyStatus) DeepCopyInto(out *AutoClaimPolicyStatus) {
+	*out = *in
+	if in.Claims != nil {
+		in, out := &in.Claims, &out.Claims
+		*out = make([]AutoClaimPolicyClaim, len(*in))
+		copy(*out, *in)
+	}
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]AutoClaimPolicyAction, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoClaimPolicyStatus.
+func (in *AutoClaimPolicyStatus) DeepCopy() *AutoClaimPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoClaimPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClaimLeaseStatus) DeepCopyInto(out *ClaimLeaseStatus) {
+	*out = *in
+	in.ExpiryTime.DeepCopyInto(&out.ExpiryTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClaimLeaseStatus.
+func (in *ClaimLeaseStatus) DeepCopy() *ClaimLeaseStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClaimLeaseStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceAccessPolicy) DeepCopyInto(out *DeviceAccessPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceAccessPolicy.
+func (in *DeviceAccessPolicy) DeepCopy() *DeviceAccessPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceAccessPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeviceAccessPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceAccessPolicyList) DeepCopyInto(out *DeviceAccessPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DeviceAccessPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceAccessPolicyList.
+func (in *DeviceAccessPolicyList) DeepCopy() *DeviceAccessPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceAccessPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeviceAccessPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceAccessPolicySpec) DeepCopyInto(out *DeviceAccessPolicySpec) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServiceAccounts != nil {
+		in, out := &in.ServiceAccounts, &out.ServiceAccounts
+		*out = make([]ServiceAccountReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Devices != nil {
+		in, out := &in.Devices, &out.Devices
+		*out = make([]DeviceAccessRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceAccessPolicySpec.
+func (in *DeviceAccessPolicySpec) DeepCopy() *DeviceAccessPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceAccessPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceAccessRule) DeepCopyInto(out *DeviceAccessRule) {
+	*out = *in
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceAccessRule.
+func (in *DeviceAccessRule) DeepCopy() *DeviceAccessRule {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceAccessRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceDiscoveryPolicy) DeepCopyInto(out *DeviceDiscoveryPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceDiscoveryPolicy.
+func (in *DeviceDiscoveryPolicy) DeepCopy() *DeviceDiscoveryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceDiscoveryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeviceDiscoveryPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceDiscoveryPolicyList) DeepCopyInto(out *DeviceDiscoveryPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DeviceDiscoveryPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceDiscoveryPolicyList.
+func (in *DeviceDiscoveryPolicyList) DeepCopy() *DeviceDiscoveryPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceDiscoveryPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeviceDiscoveryPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceDiscoveryPolicySpec) DeepCopyInto(out *DeviceDiscoveryPolicySpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]DeviceDiscoveryRule, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]DeviceDiscoveryRule, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceDiscoveryPolicySpec.
+func (in *DeviceDiscoveryPolicySpec) DeepCopy() *DeviceDiscoveryPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceDiscoveryPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceDiscoveryRule) DeepCopyInto(out *DeviceDiscoveryRule) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceDiscoveryRule.
+func (in *DeviceDiscoveryRule) DeepCopy() *DeviceDiscoveryRule {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceDiscoveryRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DevicePool) DeepCopyInto(out *DevicePool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevicePool.
+func (in *DevicePool) DeepCopy() *DevicePool {
+	if in == nil {
+		return nil
+	}
+	out := new(DevicePool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DevicePool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DevicePoolList) DeepCopyInto(out *DevicePoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DevicePool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevicePoolList.
+func (in *DevicePoolList) DeepCopy() *DevicePoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(DevicePoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DevicePoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DevicePoolSpec) DeepCopyInto(out *DevicePoolSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Devices != nil {
+		in, out := &in.Devices, &out.Devices
+		*out = make([]PCIDeviceFilter, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevicePoolSpec.
+func (in *DevicePoolSpec) DeepCopy() *DevicePoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DevicePoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceQuota) DeepCopyInto(out *DeviceQuota) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceQuota.
+func (in *DeviceQuota) DeepCopy() *DeviceQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeviceQuota) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceQuotaList) DeepCopyInto(out *DeviceQuotaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DeviceQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceQuotaList.
+func (in *DeviceQuotaList) DeepCopy() *DeviceQuotaList {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceQuotaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeviceQuotaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceQuotaResources) DeepCopyInto(out *DeviceQuotaResources) {
+	*out = *in
+	if in.ResourceNames != nil {
+		in, out := &in.ResourceNames, &out.ResourceNames
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Classes != nil {
+		in, out := &in.Classes, &out.Classes
+		*out = make(map[DeviceClass]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceQuotaResources.
+func (in *DeviceQuotaResources) DeepCopy() *DeviceQuotaResources {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceQuotaResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceQuotaSpec) DeepCopyInto(out *DeviceQuotaSpec) {
+	*out = *in
+	in.Hard.DeepCopyInto(&out.Hard)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceQuotaSpec.
+func (in *DeviceQuotaSpec) DeepCopy() *DeviceQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceQuotaStatus) DeepCopyInto(out *DeviceQuotaStatus) {
+	*out = *in
+	in.Used.DeepCopyInto(&out.Used)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceQuotaStatus.
+func (in *DeviceQuotaStatus) DeepCopy() *DeviceQuotaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceQuotaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiscoveredDevice) DeepCopyInto(out *DiscoveredDevice) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoveredDevice.
+func (in *DiscoveredDevice) DeepCopy() *DiscoveredDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(DiscoveredDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IOMMUGroup) DeepCopyInto(out *IOMMUGroup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IOMMUGroup.
+func (in *IOMMUGroup) DeepCopy() *IOMMUGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(IOMMUGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IOMMUGroup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IOMMUGroupDevice) DeepCopyInto(out *IOMMUGroupDevice) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IOMMUGroupDevice.
+func (in *IOMMUGroupDevice) DeepCopy() *IOMMUGroupDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(IOMMUGroupDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IOMMUGroupList) DeepCopyInto(out *IOMMUGroupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IOMMUGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IOMMUGroupList.
+func (in *IOMMUGroupList) DeepCopy() *IOMMUGroupList {
+	if in == nil {
+		return nil
+	}
+	out := new(IOMMUGroupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IOMMUGroupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IOMMUGroupStatus) DeepCopyInto(out *IOMMUGroupStatus) {
+	*out = *in
+	if in.Devices != nil {
+		in, out := &in.Devices, &out.Devices
+		*out = make([]IOMMUGroupDevice, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IOMMUGroupStatus.
+func (in *IOMMUGroupStatus) DeepCopy() *IOMMUGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IOMMUGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MigConfiguration) DeepCopyInto(out *MigConfiguration) {
 	*out = *in
@@ -81,32 +799,529 @@ func (in *MigConfiguration) DeepCopyInto(out *MigConfiguration) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigConfiguration.
-func (in *MigConfiguration) DeepCopy() *MigConfiguration {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigConfiguration.
+func (in *MigConfiguration) DeepCopy() *MigConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(MigConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MigConfiguration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigConfigurationList) DeepCopyInto(out *MigConfigurationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MigConfiguration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigConfigurationList.
+func (in *MigConfigurationList) DeepCopy() *MigConfigurationList {
+	if in == nil {
+		return nil
+	}
+	out := new(MigConfigurationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MigConfigurationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigConfigurationSpec) DeepCopyInto(out *MigConfigurationSpec) {
+	*out = *in
+	if in.ProfileSpec != nil {
+		in, out := &in.ProfileSpec, &out.ProfileSpec
+		*out = make([]MigProfileRequest, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigConfigurationSpec.
+func (in *MigConfigurationSpec) DeepCopy() *MigConfigurationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MigConfigurationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigConfigurationStatus) DeepCopyInto(out *MigConfigurationStatus) {
+	*out = *in
+	if in.ProfileStatus != nil {
+		in, out := &in.ProfileStatus, &out.ProfileStatus
+		*out = make([]MigProfileStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigConfigurationStatus.
+func (in *MigConfigurationStatus) DeepCopy() *MigConfigurationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MigConfigurationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigProfileRequest) DeepCopyInto(out *MigProfileRequest) {
+	*out = *in
+	out.MigProfiles = in.MigProfiles
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigProfileRequest.
+func (in *MigProfileRequest) DeepCopy() *MigProfileRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(MigProfileRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigProfileStatus) DeepCopyInto(out *MigProfileStatus) {
+	*out = *in
+	out.MigProfiles = in.MigProfiles
+	if in.VGPUID != nil {
+		in, out := &in.VGPUID, &out.VGPUID
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigProfileStatus.
+func (in *MigProfileStatus) DeepCopy() *MigProfileStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MigProfileStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigProfiles) DeepCopyInto(out *MigProfiles) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigProfiles.
+func (in *MigProfiles) DeepCopy() *MigProfiles {
+	if in == nil {
+		return nil
+	}
+	out := new(MigProfiles)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Node) DeepCopyInto(out *Node) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Node.
+func (in *Node) DeepCopy() *Node {
+	if in == nil {
+		return nil
+	}
+	out := new(Node)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Node) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeList) DeepCopyInto(out *NodeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Node, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeList.
+func (in *NodeList) DeepCopy() *NodeList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeReadinessCheck) DeepCopyInto(out *NodeReadinessCheck) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeReadinessCheck.
+func (in *NodeReadinessCheck) DeepCopy() *NodeReadinessCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeReadinessCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeSpec) DeepCopyInto(out *NodeSpec) {
+	*out = *in
+	if in.SkipAddresses != nil {
+		in, out := &in.SkipAddresses, &out.SkipAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SkipDevices != nil {
+		in, out := &in.SkipDevices, &out.SkipDevices
+		*out = make([]PCIDeviceFilter, len(*in))
+		copy(*out, *in)
+	}
+	if in.DisabledSubsystems != nil {
+		in, out := &in.DisabledSubsystems, &out.DisabledSubsystems
+		*out = make([]NodeSubsystem, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReconcileInterval != nil {
+		in, out := &in.ReconcileInterval, &out.ReconcileInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.HotplugResyncInterval != nil {
+		in, out := &in.HotplugResyncInterval, &out.HotplugResyncInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSpec.
+func (in *NodeSpec) DeepCopy() *NodeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeStatus) DeepCopyInto(out *NodeStatus) {
+	*out = *in
+	if in.VFIODrivers != nil {
+		in, out := &in.VFIODrivers, &out.VFIODrivers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Readiness != nil {
+		in, out := &in.Readiness, &out.Readiness
+		*out = make([]NodeReadinessCheck, len(*in))
+		copy(*out, *in)
+	}
+	if in.Capabilities != nil {
+		in, out := &in.Capabilities, &out.Capabilities
+		*out = make([]NodeCapability, len(*in))
+		copy(*out, *in)
+	}
+	if in.Subsystems != nil {
+		in, out := &in.Subsystems, &out.Subsystems
+		*out = make([]NodeSubsystemStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastHeartbeatTime != nil {
+		in, out := &in.LastHeartbeatTime, &out.LastHeartbeatTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeStatus.
+func (in *NodeStatus) DeepCopy() *NodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeSubsystemStatus) DeepCopyInto(out *NodeSubsystemStatus) {
+	*out = *in
+	if in.LastSuccessfulScanTime != nil {
+		in, out := &in.LastSuccessfulScanTime, &out.LastSuccessfulScanTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSubsystemStatus.
+func (in *NodeSubsystemStatus) DeepCopy() *NodeSubsystemStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeSubsystemStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PCIDevice) DeepCopyInto(out *PCIDevice) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDevice.
+func (in *PCIDevice) DeepCopy() *PCIDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(PCIDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PCIDevice) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PCIDeviceClaim) DeepCopyInto(out *PCIDeviceClaim) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceClaim.
+func (in *PCIDeviceClaim) DeepCopy() *PCIDeviceClaim {
+	if in == nil {
+		return nil
+	}
+	out := new(PCIDeviceClaim)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PCIDeviceClaim) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PCIDeviceClaimGroupMember) DeepCopyInto(out *PCIDeviceClaimGroupMember) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceClaimGroupMember.
+func (in *PCIDeviceClaimGroupMember) DeepCopy() *PCIDeviceClaimGroupMember {
+	if in == nil {
+		return nil
+	}
+	out := new(PCIDeviceClaimGroupMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PCIDeviceClaimList) DeepCopyInto(out *PCIDeviceClaimList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PCIDeviceClaim, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceClaimList.
+func (in *PCIDeviceClaimList) DeepCopy() *PCIDeviceClaimList {
+	if in == nil {
+		return nil
+	}
+	out := new(PCIDeviceClaimList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PCIDeviceClaimList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PCIDeviceClaimResetStatus) DeepCopyInto(out *PCIDeviceClaimResetStatus) {
+	*out = *in
+	if in.Methods != nil {
+		in, out := &in.Methods, &out.Methods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceClaimResetStatus.
+func (in *PCIDeviceClaimResetStatus) DeepCopy() *PCIDeviceClaimResetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PCIDeviceClaimResetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PCIDeviceClaimSet) DeepCopyInto(out *PCIDeviceClaimSet) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceClaimSet.
+func (in *PCIDeviceClaimSet) DeepCopy() *PCIDeviceClaimSet {
+	if in == nil {
+		return nil
+	}
+	out := new(PCIDeviceClaimSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PCIDeviceClaimSet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PCIDeviceClaimSetDevice) DeepCopyInto(out *PCIDeviceClaimSetDevice) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceClaimSetDevice.
+func (in *PCIDeviceClaimSetDevice) DeepCopy() *PCIDeviceClaimSetDevice {
 	if in == nil {
 		return nil
 	}
-	out := new(MigConfiguration)
+	out := new(PCIDeviceClaimSetDevice)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MigConfiguration) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigConfigurationList) DeepCopyInto(out *MigConfigurationList) {
+func (in *PCIDeviceClaimSetList) DeepCopyInto(out *PCIDeviceClaimSetList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]MigConfiguration, len(*in))
+		*out = make([]PCIDeviceClaimSet, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -114,18 +1329,18 @@ func (in *MigConfigurationList) DeepCopyInto(out *MigConfigurationList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigConfigurationList.
-func (in *MigConfigurationList) DeepCopy() *MigConfigurationList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceClaimSetList.
+func (in *PCIDeviceClaimSetList) DeepCopy() *PCIDeviceClaimSetList {
 	if in == nil {
 		return nil
 	}
-	out := new(MigConfigurationList)
+	out := new(PCIDeviceClaimSetList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MigConfigurationList) DeepCopyObject() runtime.Object {
+func (in *PCIDeviceClaimSetList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -133,32 +1348,42 @@ func (in *MigConfigurationList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigConfigurationSpec) DeepCopyInto(out *MigConfigurationSpec) {
+func (in *PCIDeviceClaimSetSpec) DeepCopyInto(out *PCIDeviceClaimSetSpec) {
 	*out = *in
-	if in.ProfileSpec != nil {
-		in, out := &in.ProfileSpec, &out.ProfileSpec
-		*out = make([]MigProfileRequest, len(*in))
+	if in.Devices != nil {
+		in, out := &in.Devices, &out.Devices
+		*out = make([]PCIDeviceFilter, len(*in))
 		copy(*out, *in)
 	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigConfigurationSpec.
-func (in *MigConfigurationSpec) DeepCopy() *MigConfigurationSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceClaimSetSpec.
+func (in *PCIDeviceClaimSetSpec) DeepCopy() *PCIDeviceClaimSetSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MigConfigurationSpec)
+	out := new(PCIDeviceClaimSetSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigConfigurationStatus) DeepCopyInto(out *MigConfigurationStatus) {
+func (in *PCIDeviceClaimSetStatus) DeepCopyInto(out *PCIDeviceClaimSetStatus) {
 	*out = *in
-	if in.ProfileStatus != nil {
-		in, out := &in.ProfileStatus, &out.ProfileStatus
-		*out = make([]MigProfileStatus, len(*in))
+	if in.Devices != nil {
+		in, out := &in.Devices, &out.Devices
+		*out = make([]PCIDeviceClaimSetDevice, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -166,106 +1391,134 @@ func (in *MigConfigurationStatus) DeepCopyInto(out *MigConfigurationStatus) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigConfigurationStatus.
-func (in *MigConfigurationStatus) DeepCopy() *MigConfigurationStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceClaimSetStatus.
+func (in *PCIDeviceClaimSetStatus) DeepCopy() *PCIDeviceClaimSetStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(MigConfigurationStatus)
+	out := new(PCIDeviceClaimSetStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigProfileRequest) DeepCopyInto(out *MigProfileRequest) {
+func (in *PCIDeviceClaimSpec) DeepCopyInto(out *PCIDeviceClaimSpec) {
 	*out = *in
-	out.MigProfiles = in.MigProfiles
+	if in.ResetMethods != nil {
+		in, out := &in.ResetMethods, &out.ResetMethods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IdleTimeout != nil {
+		in, out := &in.IdleTimeout, &out.IdleTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.LeaseDuration != nil {
+		in, out := &in.LeaseDuration, &out.LeaseDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigProfileRequest.
-func (in *MigProfileRequest) DeepCopy() *MigProfileRequest {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceClaimSpec.
+func (in *PCIDeviceClaimSpec) DeepCopy() *PCIDeviceClaimSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MigProfileRequest)
+	out := new(PCIDeviceClaimSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigProfileStatus) DeepCopyInto(out *MigProfileStatus) {
+func (in *PCIDeviceClaimStatus) DeepCopyInto(out *PCIDeviceClaimStatus) {
 	*out = *in
-	out.MigProfiles = in.MigProfiles
-	if in.VGPUID != nil {
-		in, out := &in.VGPUID, &out.VGPUID
+	if in.IOMMUGroupMembers != nil {
+		in, out := &in.IOMMUGroupMembers, &out.IOMMUGroupMembers
+		*out = make([]PCIDeviceClaimGroupMember, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastReset != nil {
+		in, out := &in.LastReset, &out.LastReset
+		*out = new(PCIDeviceClaimResetStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VirtualMachines != nil {
+		in, out := &in.VirtualMachines, &out.VirtualMachines
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.IdleSince != nil {
+		in, out := &in.IdleSince, &out.IdleSince
+		*out = (*in).DeepCopy()
+	}
+	if in.Lease != nil {
+		in, out := &in.Lease, &out.Lease
+		*out = new(ClaimLeaseStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigProfileStatus.
-func (in *MigProfileStatus) DeepCopy() *MigProfileStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceClaimStatus.
+func (in *PCIDeviceClaimStatus) DeepCopy() *PCIDeviceClaimStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(MigProfileStatus)
+	out := new(PCIDeviceClaimStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigProfiles) DeepCopyInto(out *MigProfiles) {
+func (in *PCIDeviceFilter) DeepCopyInto(out *PCIDeviceFilter) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigProfiles.
-func (in *MigProfiles) DeepCopy() *MigProfiles {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceFilter.
+func (in *PCIDeviceFilter) DeepCopy() *PCIDeviceFilter {
 	if in == nil {
 		return nil
 	}
-	out := new(MigProfiles)
+	out := new(PCIDeviceFilter)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Node) DeepCopyInto(out *Node) {
+func (in *PCIDeviceHostUsage) DeepCopyInto(out *PCIDeviceHostUsage) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Node.
-func (in *Node) DeepCopy() *Node {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceHostUsage.
+func (in *PCIDeviceHostUsage) DeepCopy() *PCIDeviceHostUsage {
 	if in == nil {
 		return nil
 	}
-	out := new(Node)
+	out := new(PCIDeviceHostUsage)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Node) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodeList) DeepCopyInto(out *NodeList) {
+func (in *PCIDeviceList) DeepCopyInto(out *PCIDeviceList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Node, len(*in))
+		*out = make([]PCIDevice, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -273,18 +1526,18 @@ func (in *NodeList) DeepCopyInto(out *NodeList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeList.
-func (in *NodeList) DeepCopy() *NodeList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceList.
+func (in *PCIDeviceList) DeepCopy() *PCIDeviceList {
 	if in == nil {
 		return nil
 	}
-	out := new(NodeList)
+	out := new(PCIDeviceList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NodeList) DeepCopyObject() runtime.Object {
+func (in *PCIDeviceList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -292,85 +1545,58 @@ func (in *NodeList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodeSpec) DeepCopyInto(out *NodeSpec) {
+func (in *PCIDevicePassthroughStatus) DeepCopyInto(out *PCIDevicePassthroughStatus) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSpec.
-func (in *NodeSpec) DeepCopy() *NodeSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDevicePassthroughStatus.
+func (in *PCIDevicePassthroughStatus) DeepCopy() *PCIDevicePassthroughStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(NodeSpec)
+	out := new(PCIDevicePassthroughStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PCIDevice) DeepCopyInto(out *PCIDevice) {
+func (in *PCIDeviceSpec) DeepCopyInto(out *PCIDeviceSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDevice.
-func (in *PCIDevice) DeepCopy() *PCIDevice {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceSpec.
+func (in *PCIDeviceSpec) DeepCopy() *PCIDeviceSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PCIDevice)
+	out := new(PCIDeviceSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PCIDevice) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PCIDeviceClaim) DeepCopyInto(out *PCIDeviceClaim) {
+func (in *PCIDeviceStatus) DeepCopyInto(out *PCIDeviceStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceClaim.
-func (in *PCIDeviceClaim) DeepCopy() *PCIDeviceClaim {
-	if in == nil {
-		return nil
+	if in.ResetMethods != nil {
+		in, out := &in.ResetMethods, &out.ResetMethods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	out := new(PCIDeviceClaim)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PCIDeviceClaim) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+	if in.Passthrough != nil {
+		in, out := &in.Passthrough, &out.Passthrough
+		*out = new(PCIDevicePassthroughStatus)
+		**out = **in
 	}
-	return nil
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PCIDeviceClaimList) DeepCopyInto(out *PCIDeviceClaimList) {
-	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]PCIDeviceClaim, len(*in))
+	if in.HostUsage != nil {
+		in, out := &in.HostUsage, &out.HostUsage
+		*out = make([]PCIDeviceHostUsage, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -378,64 +1604,51 @@ func (in *PCIDeviceClaimList) DeepCopyInto(out *PCIDeviceClaimList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceClaimList.
-func (in *PCIDeviceClaimList) DeepCopy() *PCIDeviceClaimList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceStatus.
+func (in *PCIDeviceStatus) DeepCopy() *PCIDeviceStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PCIDeviceClaimList)
+	out := new(PCIDeviceStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PCIDeviceClaimList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PCIDeviceClaimSpec) DeepCopyInto(out *PCIDeviceClaimSpec) {
+func (in *ResourceNameRule) DeepCopyInto(out *ResourceNameRule) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceClaimSpec.
-func (in *PCIDeviceClaimSpec) DeepCopy() *PCIDeviceClaimSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceNameRule.
+func (in *ResourceNameRule) DeepCopy() *ResourceNameRule {
 	if in == nil {
 		return nil
 	}
-	out := new(PCIDeviceClaimSpec)
+	out := new(ResourceNameRule)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PCIDeviceClaimStatus) DeepCopyInto(out *PCIDeviceClaimStatus) {
-	*out = *in
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceClaimStatus.
-func (in *PCIDeviceClaimStatus) DeepCopy() *PCIDeviceClaimStatus {
-	if in == nil {
-		return nil
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResourceNameRule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
-	out := new(PCIDeviceClaimStatus)
-	in.DeepCopyInto(out)
-	return out
+	return nil
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PCIDeviceList) DeepCopyInto(out *PCIDeviceList) {
+func (in *ResourceNameRuleList) DeepCopyInto(out *ResourceNameRuleList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]PCIDevice, len(*in))
+		*out = make([]ResourceNameRule, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -443,18 +1656,18 @@ func (in *PCIDeviceList) DeepCopyInto(out *PCIDeviceList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceList.
-func (in *PCIDeviceList) DeepCopy() *PCIDeviceList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceNameRuleList.
+func (in *ResourceNameRuleList) DeepCopy() *ResourceNameRuleList {
 	if in == nil {
 		return nil
 	}
-	out := new(PCIDeviceList)
+	out := new(ResourceNameRuleList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PCIDeviceList) DeepCopyObject() runtime.Object {
+func (in *ResourceNameRuleList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -462,33 +1675,17 @@ func (in *PCIDeviceList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PCIDeviceSpec) DeepCopyInto(out *PCIDeviceSpec) {
-	*out = *in
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceSpec.
-func (in *PCIDeviceSpec) DeepCopy() *PCIDeviceSpec {
-	if in == nil {
-		return nil
-	}
-	out := new(PCIDeviceSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PCIDeviceStatus) DeepCopyInto(out *PCIDeviceStatus) {
+func (in *ResourceNameRuleSpec) DeepCopyInto(out *ResourceNameRuleSpec) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIDeviceStatus.
-func (in *PCIDeviceStatus) DeepCopy() *PCIDeviceStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceNameRuleSpec.
+func (in *ResourceNameRuleSpec) DeepCopy() *ResourceNameRuleSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PCIDeviceStatus)
+	out := new(ResourceNameRuleSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -686,6 +1883,13 @@ func (in *SRIOVNetworkDeviceStatus) DeepCopyInto(out *SRIOVNetworkDeviceStatus)
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -699,12 +1903,28 @@ func (in *SRIOVNetworkDeviceStatus) DeepCopy() *SRIOVNetworkDeviceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceAccountReference) DeepCopyInto(out *ServiceAccountReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceAccountReference.
+func (in *ServiceAccountReference) DeepCopy() *ServiceAccountReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceAccountReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *USBDevice) DeepCopyInto(out *USBDevice) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -731,8 +1951,8 @@ func (in *USBDeviceClaim) DeepCopyInto(out *USBDeviceClaim) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -790,6 +2010,11 @@ func (in *USBDeviceClaimList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *USBDeviceClaimSpec) DeepCopyInto(out *USBDeviceClaimSpec) {
 	*out = *in
+	if in.LeaseDuration != nil {
+		in, out := &in.LeaseDuration, &out.LeaseDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	return
 }
 
@@ -806,6 +2031,18 @@ func (in *USBDeviceClaimSpec) DeepCopy() *USBDeviceClaimSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *USBDeviceClaimStatus) DeepCopyInto(out *USBDeviceClaimStatus) {
 	*out = *in
+	if in.Lease != nil {
+		in, out := &in.Lease, &out.Lease
+		*out = new(ClaimLeaseStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -855,6 +2092,13 @@ func (in *USBDeviceList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *USBDeviceStatus) DeepCopyInto(out *USBDeviceStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -955,6 +2199,13 @@ func (in *VGPUDeviceStatus) DeepCopyInto(out *VGPUDeviceStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -26,6 +26,108 @@ import (
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
+// AutoClaimPolicyList is a list of AutoClaimPolicy resources
+type AutoClaimPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []AutoClaimPolicy `json:"items"`
+}
+
+func NewAutoClaimPolicy(namespace, name string, obj AutoClaimPolicy) *AutoClaimPolicy {
+	obj.APIVersion, obj.Kind = SchemeGroupVersion.WithKind("AutoClaimPolicy").ToAPIVersionAndKind()
+	obj.Name = name
+	obj.Namespace = namespace
+	return &obj
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DeviceAccessPolicyList is a list of DeviceAccessPolicy resources
+type DeviceAccessPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []DeviceAccessPolicy `json:"items"`
+}
+
+func NewDeviceAccessPolicy(namespace, name string, obj DeviceAccessPolicy) *DeviceAccessPolicy {
+	obj.APIVersion, obj.Kind = SchemeGroupVersion.WithKind("DeviceAccessPolicy").ToAPIVersionAndKind()
+	obj.Name = name
+	obj.Namespace = namespace
+	return &obj
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DeviceDiscoveryPolicyList is a list of DeviceDiscoveryPolicy resources
+type DeviceDiscoveryPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []DeviceDiscoveryPolicy `json:"items"`
+}
+
+func NewDeviceDiscoveryPolicy(namespace, name string, obj DeviceDiscoveryPolicy) *DeviceDiscoveryPolicy {
+	obj.APIVersion, obj.Kind = SchemeGroupVersion.WithKind("DeviceDiscoveryPolicy").ToAPIVersionAndKind()
+	obj.Name = name
+	obj.Namespace = namespace
+	return &obj
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DevicePoolList is a list of DevicePool resources
+type DevicePoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []DevicePool `json:"items"`
+}
+
+func NewDevicePool(namespace, name string, obj DevicePool) *DevicePool {
+	obj.APIVersion, obj.Kind = SchemeGroupVersion.WithKind("DevicePool").ToAPIVersionAndKind()
+	obj.Name = name
+	obj.Namespace = namespace
+	return &obj
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DeviceQuotaList is a list of DeviceQuota resources
+type DeviceQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []DeviceQuota `json:"items"`
+}
+
+func NewDeviceQuota(namespace, name string, obj DeviceQuota) *DeviceQuota {
+	obj.APIVersion, obj.Kind = SchemeGroupVersion.WithKind("DeviceQuota").ToAPIVersionAndKind()
+	obj.Name = name
+	obj.Namespace = namespace
+	return &obj
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// IOMMUGroupList is a list of IOMMUGroup resources
+type IOMMUGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []IOMMUGroup `json:"items"`
+}
+
+func NewIOMMUGroup(namespace, name string, obj IOMMUGroup) *IOMMUGroup {
+	obj.APIVersion, obj.Kind = SchemeGroupVersion.WithKind("IOMMUGroup").ToAPIVersionAndKind()
+	obj.Name = name
+	obj.Namespace = namespace
+	return &obj
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
 // MigConfigurationList is a list of MigConfiguration resources
 type MigConfigurationList struct {
 	metav1.TypeMeta `json:",inline"`
@@ -94,6 +196,40 @@ func NewPCIDeviceClaim(namespace, name string, obj PCIDeviceClaim) *PCIDeviceCla
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
+// PCIDeviceClaimSetList is a list of PCIDeviceClaimSet resources
+type PCIDeviceClaimSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []PCIDeviceClaimSet `json:"items"`
+}
+
+func NewPCIDeviceClaimSet(namespace, name string, obj PCIDeviceClaimSet) *PCIDeviceClaimSet {
+	obj.APIVersion, obj.Kind = SchemeGroupVersion.WithKind("PCIDeviceClaimSet").ToAPIVersionAndKind()
+	obj.Name = name
+	obj.Namespace = namespace
+	return &obj
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ResourceNameRuleList is a list of ResourceNameRule resources
+type ResourceNameRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ResourceNameRule `json:"items"`
+}
+
+func NewResourceNameRule(namespace, name string, obj ResourceNameRule) *ResourceNameRule {
+	obj.APIVersion, obj.Kind = SchemeGroupVersion.WithKind("ResourceNameRule").ToAPIVersionAndKind()
+	obj.Name = name
+	obj.Namespace = namespace
+	return &obj
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
 // SRIOVGPUDeviceList is a list of SRIOVGPUDevice resources
 type SRIOVGPUDeviceList struct {
 	metav1.TypeMeta `json:",inline"`
@@ -28,15 +28,23 @@ import (
 )
 
 var (
-	MigConfigurationResourceName   = "migconfigurations"
-	NodeResourceName               = "nodes"
-	PCIDeviceResourceName          = "pcidevices"
-	PCIDeviceClaimResourceName     = "pcideviceclaims"
-	SRIOVGPUDeviceResourceName     = "sriovgpudevices"
-	SRIOVNetworkDeviceResourceName = "sriovnetworkdevices"
-	USBDeviceResourceName          = "usbdevices"
-	USBDeviceClaimResourceName     = "usbdeviceclaims"
-	VGPUDeviceResourceName         = "vgpudevices"
+	AutoClaimPolicyResourceName       = "autoclaimpolicies"
+	DeviceAccessPolicyResourceName    = "deviceaccesspolicies"
+	DeviceDiscoveryPolicyResourceName = "devicediscoverypolicies"
+	DevicePoolResourceName            = "devicepools"
+	DeviceQuotaResourceName           = "devicequotas"
+	IOMMUGroupResourceName            = "iommugroups"
+	MigConfigurationResourceName      = "migconfigurations"
+	NodeResourceName                  = "nodes"
+	PCIDeviceResourceName             = "pcidevices"
+	PCIDeviceClaimResourceName        = "pcideviceclaims"
+	PCIDeviceClaimSetResourceName     = "pcideviceclaimsets"
+	ResourceNameRuleResourceName      = "resourcenamerules"
+	SRIOVGPUDeviceResourceName        = "sriovgpudevices"
+	SRIOVNetworkDeviceResourceName    = "sriovnetworkdevices"
+	USBDeviceResourceName             = "usbdevices"
+	USBDeviceClaimResourceName        = "usbdeviceclaims"
+	VGPUDeviceResourceName            = "vgpudevices"
 )
 
 // SchemeGroupVersion is group version used to register these objects
@@ -60,6 +68,18 @@ var (
 // Adds the list of known types to Scheme.
 func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
+		&AutoClaimPolicy{},
+		&AutoClaimPolicyList{},
+		&DeviceAccessPolicy{},
+		&DeviceAccessPolicyList{},
+		&DeviceDiscoveryPolicy{},
+		&DeviceDiscoveryPolicyList{},
+		&DevicePool{},
+		&DevicePoolList{},
+		&DeviceQuota{},
+		&DeviceQuotaList{},
+		&IOMMUGroup{},
+		&IOMMUGroupList{},
 		&MigConfiguration{},
 		&MigConfigurationList{},
 		&Node{},
@@ -68,6 +88,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&PCIDeviceList{},
 		&PCIDeviceClaim{},
 		&PCIDeviceClaimList{},
+		&PCIDeviceClaimSet{},
+		&PCIDeviceClaimSetList{},
+		&ResourceNameRule{},
+		&ResourceNameRuleList{},
 		&SRIOVGPUDevice{},
 		&SRIOVGPUDeviceList{},
 		&SRIOVNetworkDevice{},
@@ -0,0 +1,516 @@
+package autoclaimpolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	"github.com/rancher/wrangler/v3/pkg/relatedresource"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/config"
+	"github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
+	ctlkubevirtv1 "github.com/harvester/pcidevices/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/pcidevices/pkg/util/common"
+)
+
+// maxActions is the number of actions kept in the status of a policy
+const maxActions = 50
+
+// Handler reconciles AutoClaimPolicies by claiming matching devices on the selected nodes, and releasing
+// claims created by the policy once their device no longer matches or the policy is removed
+type Handler struct {
+	policyClient   v1beta1.AutoClaimPolicyClient
+	policyCache    v1beta1.AutoClaimPolicyCache
+	pdCache        v1beta1.PCIDeviceCache
+	pdcClient      v1beta1.PCIDeviceClaimClient
+	pdcCache       v1beta1.PCIDeviceClaimCache
+	usbCache       v1beta1.USBDeviceCache
+	usbClaimClient v1beta1.USBDeviceClaimClient
+	usbClaimCache  v1beta1.USBDeviceClaimCache
+	nodeCache      ctlcorev1.NodeCache
+	vmCache        ctlkubevirtv1.VirtualMachineCache
+}
+
+// device is a PCIDevice or USBDevice which may be claimed by a policy
+type device struct {
+	subsystem  devicesv1beta1.NodeSubsystem
+	name       string
+	nodeName   string
+	discovered devicesv1beta1.DiscoveredDevice
+	uid        types.UID
+}
+
+func deviceKey(subsystem devicesv1beta1.NodeSubsystem, name string) string {
+	return fmt.Sprintf("%s/%s", subsystem, name)
+}
+
+func Register(ctx context.Context, management *config.FactoryManager) error {
+	policyClient := management.DeviceFactory.Devices().V1beta1().AutoClaimPolicy()
+	pdClient := management.DeviceFactory.Devices().V1beta1().PCIDevice()
+	pdcClient := management.DeviceFactory.Devices().V1beta1().PCIDeviceClaim()
+	usbClient := management.DeviceFactory.Devices().V1beta1().USBDevice()
+	usbClaimClient := management.DeviceFactory.Devices().V1beta1().USBDeviceClaim()
+	nodeClient := management.CoreFactory.Core().V1().Node()
+	handler := &Handler{
+		policyClient:   policyClient,
+		policyCache:    policyClient.Cache(),
+		pdCache:        pdClient.Cache(),
+		pdcClient:      pdcClient,
+		pdcCache:       pdcClient.Cache(),
+		usbCache:       usbClient.Cache(),
+		usbClaimClient: usbClaimClient,
+		usbClaimCache:  usbClaimClient.Cache(),
+		nodeCache:      nodeClient.Cache(),
+		vmCache:        management.KubevirtFactory.Kubevirt().V1().VirtualMachine().Cache(),
+	}
+	policyClient.OnChange(ctx, "autoclaimpolicy-reconcile", handler.OnChange)
+	policyClient.OnRemove(ctx, "autoclaimpolicy-remove", handler.OnRemove)
+	relatedresource.WatchClusterScoped(ctx, "PCIDeviceToAutoClaimPolicyReconcile", handler.OnDeviceChange, policyClient, pdClient)
+	relatedresource.WatchClusterScoped(ctx, "USBDeviceToAutoClaimPolicyReconcile", handler.OnDeviceChange, policyClient, usbClient)
+	relatedresource.WatchClusterScoped(ctx, "NodeToAutoClaimPolicyReconcile", handler.OnDeviceChange, policyClient, nodeClient)
+	relatedresource.WatchClusterScoped(ctx, "PCIDeviceClaimToAutoClaimPolicyReconcile", handler.OnClaimChange, policyClient, pdcClient)
+	relatedresource.WatchClusterScoped(ctx, "USBDeviceClaimToAutoClaimPolicyReconcile", handler.OnClaimChange, policyClient, usbClaimClient)
+	return nil
+}
+
+// OnChange claims the unclaimed devices matching the policy, and releases claims of the policy for devices which no longer match
+func (h *Handler) OnChange(_ string, policy *devicesv1beta1.AutoClaimPolicy) (*devicesv1beta1.AutoClaimPolicy, error) {
+	if policy == nil || policy.DeletionTimestamp != nil {
+		return policy, nil
+	}
+
+	nodeNames, err := h.selectedNodes(policy)
+	if err != nil {
+		return policy, err
+	}
+
+	devices, err := h.devices()
+	if err != nil {
+		return policy, err
+	}
+
+	owned, err := h.ownedClaims(policy.Name)
+	if err != nil {
+		return policy, err
+	}
+
+	policyCopy := policy.DeepCopy()
+	matched := make(map[string]bool)
+	var errs []error
+	var rejected []string
+	for _, dev := range devices {
+		if !nodeNames[dev.nodeName] || !policy.Spec.Matches(dev.discovered) {
+			continue
+		}
+		key := deviceKey(dev.subsystem, dev.name)
+		matched[key] = true
+		if _, ok := owned[key]; ok {
+			continue
+		}
+
+		result, err := h.claimDevice(policyCopy, dev)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		switch result {
+		case claimRejected:
+			rejected = append(rejected, key)
+		case claimCreated:
+			owned[key] = devicesv1beta1.AutoClaimPolicyClaim{Subsystem: dev.subsystem, Name: dev.name, NodeName: dev.nodeName}
+		}
+	}
+
+	for _, key := range sortedKeys(owned) {
+		if matched[key] {
+			continue
+		}
+		if err := h.releaseClaim(policyCopy, owned[key]); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delete(owned, key)
+	}
+
+	policyCopy.Status.Claims = make([]devicesv1beta1.AutoClaimPolicyClaim, 0, len(owned))
+	for _, key := range sortedKeys(owned) {
+		policyCopy.Status.Claims = append(policyCopy.Status.Claims, owned[key])
+	}
+	reconcileErr := errors.Join(errs...)
+	switch {
+	case reconcileErr != nil:
+		common.SetCondition(&policyCopy.Status.Conditions, policy.Generation, devicesv1beta1.ConditionReady, false,
+			devicesv1beta1.ReasonPending, reconcileErr.Error())
+	case len(rejected) > 0:
+		common.SetCondition(&policyCopy.Status.Conditions, policy.Generation, devicesv1beta1.ConditionReady, false,
+			devicesv1beta1.ReasonClaimFailed, fmt.Sprintf("%d devices claimed, claims for %d devices were rejected: %s",
+				len(policyCopy.Status.Claims), len(rejected), strings.Join(rejected, ", ")))
+	default:
+		common.SetCondition(&policyCopy.Status.Conditions, policy.Generation, devicesv1beta1.ConditionReady, true,
+			devicesv1beta1.ReasonReconciled, fmt.Sprintf("%d devices claimed", len(policyCopy.Status.Claims)))
+	}
+
+	updated, err := h.updateStatus(policy, policyCopy)
+	if err != nil {
+		return policy, err
+	}
+	return updated, reconcileErr
+}
+
+// OnRemove releases all claims created for the policy. Removal is retried while any claim is still in use by a VM
+func (h *Handler) OnRemove(_ string, policy *devicesv1beta1.AutoClaimPolicy) (*devicesv1beta1.AutoClaimPolicy, error) {
+	if policy == nil {
+		return policy, nil
+	}
+
+	owned, err := h.ownedClaims(policy.Name)
+	if err != nil {
+		return policy, err
+	}
+
+	policyCopy := policy.DeepCopy()
+	var errs []error
+	for _, key := range sortedKeys(owned) {
+		if err := h.releaseClaim(policyCopy, owned[key]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return policy, nil
+	}
+
+	// record why the policy cannot be removed yet
+	if _, err := h.updateStatus(policy, policyCopy); err != nil {
+		errs = append(errs, err)
+	}
+	return policy, fmt.Errorf("error removing autoclaimpolicy %s: %w", policy.Name, errors.Join(errs...))
+}
+
+// OnDeviceChange requeues all policies, as a new or changed device or node may match any of them
+func (h *Handler) OnDeviceChange(_ string, _ string, _ runtime.Object) ([]relatedresource.Key, error) {
+	return h.policyKeys()
+}
+
+// OnClaimChange requeues the policy a claim was created for. Removing any claim may free a device,
+// so all policies are requeued when a claim is removed
+func (h *Handler) OnClaimChange(_ string, _ string, obj runtime.Object) ([]relatedresource.Key, error) {
+	if obj == nil {
+		return h.policyKeys()
+	}
+
+	accessor, ok := obj.(metav1.Object)
+	if !ok {
+		return nil, nil
+	}
+	if name, ok := accessor.GetLabels()[devicesv1beta1.AutoClaimPolicyLabel]; ok {
+		return []relatedresource.Key{relatedresource.NewKey("", name)}, nil
+	}
+	return nil, nil
+}
+
+func (h *Handler) policyKeys() ([]relatedresource.Key, error) {
+	policies, err := h.policyCache.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error listing autoclaimpolicies: %w", err)
+	}
+
+	keys := make([]relatedresource.Key, 0, len(policies))
+	for _, v := range policies {
+		keys = append(keys, relatedresource.NewKey("", v.Name))
+	}
+	return keys, nil
+}
+
+func (h *Handler) selectedNodes(policy *devicesv1beta1.AutoClaimPolicy) (map[string]bool, error) {
+	nodeSelector := labels.Everything()
+	if policy.Spec.NodeSelector != nil {
+		var err error
+		nodeSelector, err = metav1.LabelSelectorAsSelector(policy.Spec.NodeSelector)
+		if err != nil {
+			return nil, fmt.Errorf("autoclaimpolicy %s has an invalid node selector: %w", policy.Name, err)
+		}
+	}
+
+	nodes, err := h.nodeCache.List(nodeSelector)
+	if err != nil {
+		return nil, fmt.Errorf("error listing nodes: %w", err)
+	}
+	nodeNames := make(map[string]bool, len(nodes))
+	for _, v := range nodes {
+		if v.DeletionTimestamp == nil {
+			nodeNames[v.Name] = true
+		}
+	}
+	return nodeNames, nil
+}
+
+// devices returns the PCIDevices and USBDevices which are not being removed, in name order
+func (h *Handler) devices() ([]device, error) {
+	pds, err := h.pdCache.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error listing pcidevices: %w", err)
+	}
+
+	usbs, err := h.usbCache.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error listing usbdevices: %w", err)
+	}
+
+	devices := make([]device, 0, len(pds)+len(usbs))
+	for _, v := range pds {
+		if v.DeletionTimestamp != nil {
+			continue
+		}
+		devices = append(devices, device{
+			subsystem: devicesv1beta1.SubsystemPCI,
+			name:      v.Name,
+			nodeName:  v.Status.NodeName,
+			uid:       v.UID,
+			discovered: devicesv1beta1.DiscoveredDevice{
+				Subsystem: devicesv1beta1.SubsystemPCI,
+				VendorID:  v.Status.VendorID,
+				DeviceID:  v.Status.DeviceID,
+				ClassID:   v.Status.ClassID,
+				Address:   v.Status.Address,
+			},
+		})
+	}
+	for _, v := range usbs {
+		if v.DeletionTimestamp != nil {
+			continue
+		}
+		devices = append(devices, device{
+			subsystem: devicesv1beta1.SubsystemUSB,
+			name:      v.Name,
+			nodeName:  v.Status.NodeName,
+			uid:       v.UID,
+			discovered: devicesv1beta1.DiscoveredDevice{
+				Subsystem: devicesv1beta1.SubsystemUSB,
+				VendorID:  v.Status.VendorID,
+				DeviceID:  v.Status.ProductID,
+				Address:   v.Status.DevicePath,
+			},
+		})
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		return deviceKey(devices[i].subsystem, devices[i].name) < deviceKey(devices[j].subsystem, devices[j].name)
+	})
+	return devices, nil
+}
+
+// ownedClaims returns the claims created for the policy, keyed by subsystem and name
+func (h *Handler) ownedClaims(name string) (map[string]devicesv1beta1.AutoClaimPolicyClaim, error) {
+	selector := labels.SelectorFromSet(labels.Set{devicesv1beta1.AutoClaimPolicyLabel: name})
+	owned := make(map[string]devicesv1beta1.AutoClaimPolicyClaim)
+
+	pdcs, err := h.pdcCache.List(selector)
+	if err != nil {
+		return nil, fmt.Errorf("error listing pcideviceclaims for autoclaimpolicy %s: %w", name, err)
+	}
+	for _, v := range pdcs {
+		owned[deviceKey(devicesv1beta1.SubsystemPCI, v.Name)] = devicesv1beta1.AutoClaimPolicyClaim{
+			Subsystem: devicesv1beta1.SubsystemPCI,
+			Name:      v.Name,
+			NodeName:  v.Spec.NodeName,
+		}
+	}
+
+	usbClaims, err := h.usbClaimCache.List(selector)
+	if err != nil {
+		return nil, fmt.Errorf("error listing usbdeviceclaims for autoclaimpolicy %s: %w", name, err)
+	}
+	for _, v := range usbClaims {
+		owned[deviceKey(devicesv1beta1.SubsystemUSB, v.Name)] = devicesv1beta1.AutoClaimPolicyClaim{
+			Subsystem: devicesv1beta1.SubsystemUSB,
+			Name:      v.Name,
+			NodeName:  v.Labels[devicesv1beta1.NodeKeyName],
+		}
+	}
+	return owned, nil
+}
+
+// claimResult is the outcome of claiming a device for a policy
+type claimResult int
+
+const (
+	// claimSkipped is returned if the device is already claimed
+	claimSkipped claimResult = iota
+	claimCreated
+	// claimRejected is returned if the claim was rejected, for instance by the claim webhook
+	claimRejected
+)
+
+// claimDevice creates a claim for dev unless the device is already claimed. Rejected claims are recorded on the policy,
+// and are retried once the device or its claims change
+func (h *Handler) claimDevice(policy *devicesv1beta1.AutoClaimPolicy, dev device) (claimResult, error) {
+	claimed, err := h.claimed(dev)
+	if err != nil || claimed {
+		return claimSkipped, err
+	}
+
+	objMeta := metav1.ObjectMeta{
+		Name: dev.name,
+		Labels: map[string]string{
+			devicesv1beta1.NodeKeyName:          dev.nodeName,
+			devicesv1beta1.AutoClaimPolicyLabel: policy.Name,
+		},
+		OwnerReferences: []metav1.OwnerReference{
+			{
+				APIVersion: devicesv1beta1.SchemeGroupVersion.String(),
+				Name:       dev.name,
+				UID:        dev.uid,
+			},
+		},
+	}
+
+	switch dev.subsystem {
+	case devicesv1beta1.SubsystemPCI:
+		objMeta.OwnerReferences[0].Kind = "PCIDevice"
+		pd, err := h.pdCache.Get(dev.name)
+		if err != nil {
+			return claimSkipped, fmt.Errorf("error looking up pcidevice %s: %w", dev.name, err)
+		}
+		_, err = h.pdcClient.Create(&devicesv1beta1.PCIDeviceClaim{
+			ObjectMeta: objMeta,
+			Spec: devicesv1beta1.PCIDeviceClaimSpec{
+				Address:  pd.Status.Address,
+				NodeName: pd.Status.NodeName,
+				UserName: policy.Spec.UserName,
+				Driver:   pd.Spec.DriverOverride,
+			},
+		})
+	case devicesv1beta1.SubsystemUSB:
+		objMeta.OwnerReferences[0].Kind = "USBDevice"
+		_, err = h.usbClaimClient.Create(&devicesv1beta1.USBDeviceClaim{
+			ObjectMeta: objMeta,
+			Spec: devicesv1beta1.USBDeviceClaimSpec{
+				UserName: policy.Spec.UserName,
+			},
+		})
+	}
+
+	switch {
+	case apierrors.IsAlreadyExists(err):
+		// the device was claimed since the cache was last synced
+		return claimSkipped, nil
+	case err != nil:
+		logrus.Warnf("autoclaimpolicy %s: error claiming %s device %s: %v", policy.Name, dev.subsystem, dev.name, err)
+		recordAction(&policy.Status, devicesv1beta1.AutoClaimActionClaimFailed, dev.subsystem, dev.name, err.Error())
+		return claimRejected, nil
+	}
+
+	logrus.Infof("autoclaimpolicy %s claimed %s device %s", policy.Name, dev.subsystem, dev.name)
+	recordAction(&policy.Status, devicesv1beta1.AutoClaimActionClaimed, dev.subsystem, dev.name,
+		fmt.Sprintf("claimed on node %s", dev.nodeName))
+	return claimCreated, nil
+}
+
+// claimed returns true if a claim exists for dev, so devices claimed by users or other controllers are left as is
+func (h *Handler) claimed(dev device) (bool, error) {
+	var err error
+	switch dev.subsystem {
+	case devicesv1beta1.SubsystemPCI:
+		_, err = h.pdcCache.Get(dev.name)
+	case devicesv1beta1.SubsystemUSB:
+		_, err = h.usbClaimCache.Get(dev.name)
+	}
+
+	if err == nil {
+		return true, nil
+	}
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	return false, fmt.Errorf("error looking up claim for %s device %s: %w", dev.subsystem, dev.name, err)
+}
+
+// releaseClaim removes a claim created by the policy. Claims in use by a VM are retained, and an error is returned
+// so the release is retried
+func (h *Handler) releaseClaim(policy *devicesv1beta1.AutoClaimPolicy, claim devicesv1beta1.AutoClaimPolicyClaim) error {
+	index := common.VMByPCIDeviceClaim
+	if claim.Subsystem == devicesv1beta1.SubsystemUSB {
+		index = common.VMByUSBDeviceClaim
+	}
+	vms, err := h.vmCache.GetByIndex(index, claim.Name)
+	if err != nil {
+		return fmt.Errorf("error looking up vms using %s device %s: %w", claim.Subsystem, claim.Name, err)
+	}
+	if len(vms) > 0 {
+		msg := fmt.Sprintf("in use by vm %s/%s", vms[0].Namespace, vms[0].Name)
+		recordAction(&policy.Status, devicesv1beta1.AutoClaimActionRetained, claim.Subsystem, claim.Name, msg)
+		return fmt.Errorf("%s device %s is %s", claim.Subsystem, claim.Name, msg)
+	}
+
+	switch claim.Subsystem {
+	case devicesv1beta1.SubsystemPCI:
+		err = h.pdcClient.Delete(claim.Name, &metav1.DeleteOptions{})
+	case devicesv1beta1.SubsystemUSB:
+		err = h.usbClaimClient.Delete(claim.Name, &metav1.DeleteOptions{})
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		recordAction(&policy.Status, devicesv1beta1.AutoClaimActionReleaseFailed, claim.Subsystem, claim.Name, err.Error())
+		return fmt.Errorf("error releasing %s device %s: %w", claim.Subsystem, claim.Name, err)
+	}
+
+	logrus.Infof("autoclaimpolicy %s released %s device %s", policy.Name, claim.Subsystem, claim.Name)
+	recordAction(&policy.Status, devicesv1beta1.AutoClaimActionReleased, claim.Subsystem, claim.Name, "")
+	return nil
+}
+
+// recordAction appends an action to the status, keeping the most recent maxActions actions. Actions repeating the
+// last action recorded for the device are skipped, so retries do not flood the status
+func recordAction(status *devicesv1beta1.AutoClaimPolicyStatus, action devicesv1beta1.AutoClaimAction, subsystem devicesv1beta1.NodeSubsystem, name, message string) {
+	for i := len(status.Actions) - 1; i >= 0; i-- {
+		last := status.Actions[i]
+		if last.Subsystem != subsystem || last.Name != name {
+			continue
+		}
+		if last.Action == action && last.Message == message {
+			return
+		}
+		break
+	}
+
+	status.Actions = append(status.Actions, devicesv1beta1.AutoClaimPolicyAction{
+		Time:      metav1.Now(),
+		Action:    action,
+		Subsystem: subsystem,
+		Name:      name,
+		Message:   message,
+	})
+	if len(status.Actions) > maxActions {
+		status.Actions = status.Actions[len(status.Actions)-maxActions:]
+	}
+}
+
+func (h *Handler) updateStatus(policy, policyCopy *devicesv1beta1.AutoClaimPolicy) (*devicesv1beta1.AutoClaimPolicy, error) {
+	if equality.Semantic.DeepEqual(policy.Status, policyCopy.Status) {
+		return policy, nil
+	}
+	updated, err := h.policyClient.UpdateStatus(policyCopy)
+	if err != nil {
+		return policy, fmt.Errorf("error updating autoclaimpolicy %s status: %w", policy.Name, err)
+	}
+	return updated, nil
+}
+
+func sortedKeys(m map[string]devicesv1beta1.AutoClaimPolicyClaim) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
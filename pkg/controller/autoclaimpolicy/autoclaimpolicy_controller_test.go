@@ -0,0 +1,240 @@
+package autoclaimpolicy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	harvesterfake "github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/pcidevices/pkg/util/fakeclients"
+)
+
+var (
+	gpuNode = &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node1",
+			Labels: map[string]string{"gpu": "true"},
+		},
+	}
+	otherNode = &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node2",
+		},
+	}
+	node1GPU = &devicesv1beta1.PCIDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1-000081000",
+		},
+		Status: devicesv1beta1.PCIDeviceStatus{
+			Address:  "0000:81:00.0",
+			NodeName: "node1",
+			VendorID: "10de",
+			DeviceID: "20b5",
+			ClassID:  "0302",
+		},
+	}
+	node1NIC = &devicesv1beta1.PCIDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1-000004000",
+		},
+		Status: devicesv1beta1.PCIDeviceStatus{
+			Address:  "0000:04:00.0",
+			NodeName: "node1",
+			VendorID: "8086",
+			DeviceID: "1521",
+			ClassID:  "0200",
+		},
+	}
+	node2GPU = &devicesv1beta1.PCIDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node2-000081000",
+		},
+		Status: devicesv1beta1.PCIDeviceStatus{
+			Address:  "0000:81:00.0",
+			NodeName: "node2",
+			VendorID: "10de",
+			DeviceID: "20b5",
+			ClassID:  "0302",
+		},
+	}
+	node1Token = &devicesv1beta1.USBDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1-0001-0003",
+		},
+		Status: devicesv1beta1.USBDeviceStatus{
+			NodeName:   "node1",
+			VendorID:   "1050",
+			ProductID:  "0407",
+			DevicePath: "/dev/bus/usb/001/003",
+		},
+	}
+	gpuPolicy = &devicesv1beta1.AutoClaimPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "gpus",
+		},
+		Spec: devicesv1beta1.AutoClaimPolicySpec{
+			NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "true"}},
+			Devices: []devicesv1beta1.DeviceDiscoveryRule{
+				{Subsystem: devicesv1beta1.SubsystemPCI, VendorID: "10de"},
+				{Subsystem: devicesv1beta1.SubsystemUSB, VendorID: "1050"},
+			},
+			UserName: "admin",
+		},
+	}
+)
+
+func Test_OnChange(t *testing.T) {
+	assert := require.New(t)
+	// the nic is already claimed by a user, and must be left as is
+	nicClaim := &devicesv1beta1.PCIDeviceClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: node1NIC.Name,
+		},
+	}
+	client := fake.NewSimpleClientset(node1GPU, node1NIC, node2GPU, node1Token, nicClaim, gpuPolicy)
+	harvesterClient := harvesterfake.NewSimpleClientset()
+	coreClient := corefake.NewSimpleClientset(gpuNode, otherNode)
+	h := &Handler{
+		policyClient:   fakeclients.AutoClaimPoliciesClient(client.DevicesV1beta1().AutoClaimPolicies),
+		policyCache:    fakeclients.AutoClaimPoliciesCache(client.DevicesV1beta1().AutoClaimPolicies),
+		pdCache:        fakeclients.PCIDevicesCache(client.DevicesV1beta1().PCIDevices),
+		pdcClient:      fakeclients.PCIDeviceClaimsClient(client.DevicesV1beta1().PCIDeviceClaims),
+		pdcCache:       fakeclients.PCIDeviceClaimsCache(client.DevicesV1beta1().PCIDeviceClaims),
+		usbCache:       fakeclients.USBDeviceCache(client.DevicesV1beta1().USBDevices),
+		usbClaimClient: fakeclients.USBDeviceClaimsClient(client.DevicesV1beta1().USBDeviceClaims),
+		usbClaimCache:  fakeclients.USBDeviceClaimsCache(client.DevicesV1beta1().USBDeviceClaims),
+		nodeCache:      fakeclients.NodeCache(coreClient.CoreV1().Nodes),
+		vmCache:        fakeclients.VirtualMachineCache(harvesterClient.KubevirtV1().VirtualMachines),
+	}
+
+	policy := gpuPolicy.DeepCopy()
+	policy.Spec.Devices = append(policy.Spec.Devices, devicesv1beta1.DeviceDiscoveryRule{VendorID: "8086"})
+	policy, err := h.OnChange(policy.Name, policy)
+	assert.NoError(err, "expected no error while reconciling policy")
+	assert.Equal([]devicesv1beta1.AutoClaimPolicyClaim{
+		{Subsystem: devicesv1beta1.SubsystemPCI, Name: node1GPU.Name, NodeName: "node1"},
+		{Subsystem: devicesv1beta1.SubsystemUSB, Name: node1Token.Name, NodeName: "node1"},
+	}, policy.Status.Claims, "expected matching devices on selected nodes to be claimed")
+	assert.Len(policy.Status.Actions, 2)
+	assert.Equal(devicesv1beta1.AutoClaimActionClaimed, policy.Status.Actions[0].Action)
+	assert.True(meta.IsStatusConditionTrue(policy.Status.Conditions, devicesv1beta1.ConditionReady))
+
+	pdc, err := client.DevicesV1beta1().PCIDeviceClaims().Get(context.TODO(), node1GPU.Name, metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal(gpuPolicy.Name, pdc.Labels[devicesv1beta1.AutoClaimPolicyLabel])
+	assert.Equal("PCIDevice", pdc.OwnerReferences[0].Kind)
+	assert.Equal(node1GPU.Status.Address, pdc.Spec.Address)
+	usbClaim, err := client.DevicesV1beta1().USBDeviceClaims().Get(context.TODO(), node1Token.Name, metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal("USBDevice", usbClaim.OwnerReferences[0].Kind)
+	assert.Equal("admin", usbClaim.Spec.UserName)
+	nic, err := client.DevicesV1beta1().PCIDeviceClaims().Get(context.TODO(), node1NIC.Name, metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Empty(nic.Labels, "expected existing claim to be left as is")
+
+	// reconciling again records no further actions
+	policy, err = h.OnChange(policy.Name, policy)
+	assert.NoError(err)
+	assert.Len(policy.Status.Actions, 2)
+
+	// the gpu is in use by a vm, so only the usb claim is released once usb devices are no longer matched
+	vm := &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vm1",
+			Namespace: "default",
+			Annotations: map[string]string{
+				devicesv1beta1.DeviceAllocationKey: `{"hostdevices":{"nvidia.com/GA100":["node1-000081000"]}}`,
+			},
+		},
+	}
+	_, err = harvesterClient.KubevirtV1().VirtualMachines(vm.Namespace).Create(context.TODO(), vm, metav1.CreateOptions{})
+	assert.NoError(err)
+	policy.Spec.Devices = []devicesv1beta1.DeviceDiscoveryRule{{Subsystem: devicesv1beta1.SubsystemPCI, VendorID: "8086"}}
+	policy, err = h.OnChange(policy.Name, policy)
+	assert.Error(err, "expected release of claim in use to be retried")
+	assert.Equal([]devicesv1beta1.AutoClaimPolicyClaim{
+		{Subsystem: devicesv1beta1.SubsystemPCI, Name: node1GPU.Name, NodeName: "node1"},
+	}, policy.Status.Claims)
+	assert.False(meta.IsStatusConditionTrue(policy.Status.Conditions, devicesv1beta1.ConditionReady))
+	actions := policy.Status.Actions[len(policy.Status.Actions)-2:]
+	assert.Equal(devicesv1beta1.AutoClaimActionRetained, actions[0].Action)
+	assert.Equal(node1GPU.Name, actions[0].Name)
+	assert.Equal(devicesv1beta1.AutoClaimActionReleased, actions[1].Action)
+	assert.Equal(node1Token.Name, actions[1].Name)
+	_, err = client.DevicesV1beta1().USBDeviceClaims().Get(context.TODO(), node1Token.Name, metav1.GetOptions{})
+	assert.Error(err, "expected usb claim to be released")
+
+	// removing the policy is retried until the vm releases the gpu
+	_, err = h.OnRemove(policy.Name, policy)
+	assert.Error(err)
+	assert.NoError(harvesterClient.KubevirtV1().VirtualMachines(vm.Namespace).Delete(context.TODO(), vm.Name, metav1.DeleteOptions{}))
+	_, err = h.OnRemove(policy.Name, policy)
+	assert.NoError(err)
+	claims, err := client.DevicesV1beta1().PCIDeviceClaims().List(context.TODO(), metav1.ListOptions{})
+	assert.NoError(err)
+	assert.Len(claims.Items, 1)
+	assert.Equal(node1NIC.Name, claims.Items[0].Name, "expected only claims created by the policy to be released")
+}
+
+func Test_OnChangeRejectedClaim(t *testing.T) {
+	assert := require.New(t)
+	client := fake.NewSimpleClientset(node1GPU, node1Token, gpuPolicy)
+	// the usb claim is rejected by the webhook
+	client.PrependReactor("create", "usbdeviceclaims", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("admission webhook denied the request: device in use")
+	})
+	harvesterClient := harvesterfake.NewSimpleClientset()
+	coreClient := corefake.NewSimpleClientset(gpuNode)
+	h := &Handler{
+		policyClient:   fakeclients.AutoClaimPoliciesClient(client.DevicesV1beta1().AutoClaimPolicies),
+		policyCache:    fakeclients.AutoClaimPoliciesCache(client.DevicesV1beta1().AutoClaimPolicies),
+		pdCache:        fakeclients.PCIDevicesCache(client.DevicesV1beta1().PCIDevices),
+		pdcClient:      fakeclients.PCIDeviceClaimsClient(client.DevicesV1beta1().PCIDeviceClaims),
+		pdcCache:       fakeclients.PCIDeviceClaimsCache(client.DevicesV1beta1().PCIDeviceClaims),
+		usbCache:       fakeclients.USBDeviceCache(client.DevicesV1beta1().USBDevices),
+		usbClaimClient: fakeclients.USBDeviceClaimsClient(client.DevicesV1beta1().USBDeviceClaims),
+		usbClaimCache:  fakeclients.USBDeviceClaimsCache(client.DevicesV1beta1().USBDeviceClaims),
+		nodeCache:      fakeclients.NodeCache(coreClient.CoreV1().Nodes),
+		vmCache:        fakeclients.VirtualMachineCache(harvesterClient.KubevirtV1().VirtualMachines),
+	}
+
+	policy, err := h.OnChange(gpuPolicy.Name, gpuPolicy.DeepCopy())
+	assert.NoError(err, "expected rejected claims to be retried once the device changes")
+	assert.Equal([]devicesv1beta1.AutoClaimPolicyClaim{
+		{Subsystem: devicesv1beta1.SubsystemPCI, Name: node1GPU.Name, NodeName: "node1"},
+	}, policy.Status.Claims)
+	ready := meta.FindStatusCondition(policy.Status.Conditions, devicesv1beta1.ConditionReady)
+	assert.NotNil(ready)
+	assert.Equal(metav1.ConditionFalse, ready.Status, "expected policy with rejected claims not to be ready")
+	assert.Equal(devicesv1beta1.ReasonClaimFailed, ready.Reason)
+	assert.Contains(ready.Message, node1Token.Name)
+	assert.Equal(devicesv1beta1.AutoClaimActionClaimFailed, policy.Status.Actions[len(policy.Status.Actions)-1].Action)
+}
+
+func Test_recordAction(t *testing.T) {
+	assert := require.New(t)
+	status := &devicesv1beta1.AutoClaimPolicyStatus{}
+	recordAction(status, devicesv1beta1.AutoClaimActionClaimFailed, devicesv1beta1.SubsystemPCI, "dev1", "in use by host")
+	recordAction(status, devicesv1beta1.AutoClaimActionClaimFailed, devicesv1beta1.SubsystemPCI, "dev1", "in use by host")
+	assert.Len(status.Actions, 1, "expected repeated action to be recorded once")
+
+	recordAction(status, devicesv1beta1.AutoClaimActionClaimed, devicesv1beta1.SubsystemPCI, "dev1", "")
+	assert.Len(status.Actions, 2)
+
+	for i := 0; i < maxActions; i++ {
+		recordAction(status, devicesv1beta1.AutoClaimActionReleased, devicesv1beta1.SubsystemUSB, fmt.Sprintf("dev%d", i), "")
+	}
+	assert.Len(status.Actions, maxActions, "expected oldest actions to be dropped")
+	assert.Equal(devicesv1beta1.SubsystemUSB, status.Actions[0].Subsystem)
+}
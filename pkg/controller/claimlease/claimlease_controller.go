@@ -0,0 +1,271 @@
+package claimlease
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/config"
+	"github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
+	ctlkubevirtv1 "github.com/harvester/pcidevices/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/pcidevices/pkg/util/common"
+)
+
+const (
+	// heldRecheckPeriod is how often a claim with an expired lease is checked while the device is in use
+	heldRecheckPeriod = time.Minute
+)
+
+// Handler expires the leases of PCIDeviceClaims and USBDeviceClaims with a lease duration. Claims are released
+// once their lease has expired, unless the device is in use by a running VMI, in which case the release is deferred.
+// The claim delete webhooks reject the removal of claims allocated to a VM, so stopped VMs allocated the device
+// block the release until the device is removed from them
+type Handler struct {
+	pdcClient       v1beta1.PCIDeviceClaimClient
+	usbClaimClient  v1beta1.USBDeviceClaimClient
+	pdCache         v1beta1.PCIDeviceCache
+	usbCache        v1beta1.USBDeviceCache
+	vmCache         ctlkubevirtv1.VirtualMachineCache
+	vmiCache        ctlkubevirtv1.VirtualMachineInstanceCache
+	enqueuePCIAfter func(name string, duration time.Duration)
+	enqueueUSBAfter func(name string, duration time.Duration)
+	now             func() time.Time
+}
+
+func Register(ctx context.Context, management *config.FactoryManager) error {
+	pdcClient := management.DeviceFactory.Devices().V1beta1().PCIDeviceClaim()
+	usbClaimClient := management.DeviceFactory.Devices().V1beta1().USBDeviceClaim()
+	handler := &Handler{
+		pdcClient:       pdcClient,
+		usbClaimClient:  usbClaimClient,
+		pdCache:         management.DeviceFactory.Devices().V1beta1().PCIDevice().Cache(),
+		usbCache:        management.DeviceFactory.Devices().V1beta1().USBDevice().Cache(),
+		vmCache:         management.KubevirtFactory.Kubevirt().V1().VirtualMachine().Cache(),
+		vmiCache:        management.KubevirtFactory.Kubevirt().V1().VirtualMachineInstance().Cache(),
+		enqueuePCIAfter: pdcClient.EnqueueAfter,
+		enqueueUSBAfter: usbClaimClient.EnqueueAfter,
+		now:             time.Now,
+	}
+	pdcClient.OnChange(ctx, "pcideviceclaim-lease", handler.OnPCIDeviceClaimChange)
+	usbClaimClient.OnChange(ctx, "usbdeviceclaim-lease", handler.OnUSBDeviceClaimChange)
+	return nil
+}
+
+// OnPCIDeviceClaimChange reports the lease of the claim, and releases the claim once the lease has expired
+func (h *Handler) OnPCIDeviceClaimChange(name string, pdc *devicesv1beta1.PCIDeviceClaim) (*devicesv1beta1.PCIDeviceClaim, error) {
+	if pdc == nil || pdc.DeletionTimestamp != nil {
+		return pdc, nil
+	}
+
+	var lease *devicesv1beta1.ClaimLeaseStatus
+	if pdc.Spec.LeaseDuration != nil {
+		var requeue time.Duration
+		var device *claimedDevice
+		pd, err := h.pdCache.Get(name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return pdc, fmt.Errorf("error looking up pcidevice %s: %w", name, err)
+		}
+		if err == nil {
+			device = &claimedDevice{nodeName: pd.Status.NodeName, resourceName: pd.Status.ResourceName}
+		}
+		lease, requeue, err = h.evaluate("pcideviceclaim", name, common.VMByPCIDeviceClaim, device, pdc.CreationTimestamp, pdc.Spec.LeaseDuration.Duration, pdc.Status.Lease)
+		if err != nil {
+			return pdc, err
+		}
+		if requeue > 0 {
+			h.enqueuePCIAfter(name, requeue)
+		}
+	}
+
+	pdcCopy := pdc.DeepCopy()
+	pdcCopy.Status.Lease = lease
+	if !equality.Semantic.DeepEqual(pdc.Status.Lease, lease) {
+		updated, err := h.pdcClient.UpdateStatus(pdcCopy)
+		if err != nil {
+			return pdc, err
+		}
+		pdcCopy = updated
+	}
+
+	if lease == nil || lease.State != devicesv1beta1.ClaimLeaseExpired {
+		return pdcCopy, nil
+	}
+	if err := h.pdcClient.Delete(name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return pdcCopy, fmt.Errorf("error releasing pcideviceclaim %s with expired lease: %w", name, err)
+	}
+	return pdcCopy, nil
+}
+
+// OnUSBDeviceClaimChange reports the lease of the claim, and releases the claim once the lease has expired
+func (h *Handler) OnUSBDeviceClaimChange(name string, claim *devicesv1beta1.USBDeviceClaim) (*devicesv1beta1.USBDeviceClaim, error) {
+	if claim == nil || claim.DeletionTimestamp != nil {
+		return claim, nil
+	}
+
+	var lease *devicesv1beta1.ClaimLeaseStatus
+	if claim.Spec.LeaseDuration != nil {
+		var requeue time.Duration
+		var device *claimedDevice
+		usb, err := h.usbCache.Get(name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return claim, fmt.Errorf("error looking up usbdevice %s: %w", name, err)
+		}
+		if err == nil {
+			device = &claimedDevice{nodeName: usb.Status.NodeName, resourceName: usb.Status.ResourceName}
+		}
+		lease, requeue, err = h.evaluate("usbdeviceclaim", name, common.VMByUSBDeviceClaim, device, claim.CreationTimestamp, claim.Spec.LeaseDuration.Duration, claim.Status.Lease)
+		if err != nil {
+			return claim, err
+		}
+		if requeue > 0 {
+			h.enqueueUSBAfter(name, requeue)
+		}
+	}
+
+	claimCopy := claim.DeepCopy()
+	claimCopy.Status.Lease = lease
+	if !equality.Semantic.DeepEqual(claim.Status.Lease, lease) {
+		updated, err := h.usbClaimClient.UpdateStatus(claimCopy)
+		if err != nil {
+			return claim, err
+		}
+		claimCopy = updated
+	}
+
+	if lease == nil || lease.State != devicesv1beta1.ClaimLeaseExpired {
+		return claimCopy, nil
+	}
+	if err := h.usbClaimClient.Delete(name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return claimCopy, fmt.Errorf("error releasing usbdeviceclaim %s with expired lease: %w", name, err)
+	}
+	return claimCopy, nil
+}
+
+// claimedDevice identifies the device of a claim for standalone VMIs, which request devices by resource name only
+type claimedDevice struct {
+	nodeName     string
+	resourceName string
+}
+
+// evaluate returns the lease of the claim kind/name created at created, and the time after which the claim must be
+// checked again. VMs using the device are looked up through the index of the VM cache for the kind of claim, and
+// standalone VMIs through the claimed device, if it still exists. Changes of state from the previous lease are logged
+func (h *Handler) evaluate(kind, name, vmIndex string, device *claimedDevice, created metav1.Time, duration time.Duration,
+	previous *devicesv1beta1.ClaimLeaseStatus) (*devicesv1beta1.ClaimLeaseStatus, time.Duration, error) {
+	expiry := created.Add(duration)
+	lease := &devicesv1beta1.ClaimLeaseStatus{
+		ExpiryTime: metav1.NewTime(expiry),
+	}
+
+	remaining := expiry.Sub(h.now())
+	switch {
+	case remaining > devicesv1beta1.ClaimLeaseWarningPeriod:
+		lease.State = devicesv1beta1.ClaimLeaseActive
+		return lease, remaining - devicesv1beta1.ClaimLeaseWarningPeriod, nil
+	case remaining > 0:
+		lease.State = devicesv1beta1.ClaimLeaseExpiring
+		lease.Message = fmt.Sprintf("lease expires at %s, the claim will be released unless the lease duration is raised", expiry.Format(time.RFC3339))
+		if previous == nil || previous.State != lease.State {
+			logrus.Warnf("lease of %s %s expires at %s", kind, name, expiry.Format(time.RFC3339))
+		}
+		return lease, remaining, nil
+	}
+
+	running, stopped, err := h.usage(vmIndex, name, device)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch {
+	case len(running) > 0:
+		lease.State = devicesv1beta1.ClaimLeaseHeld
+		lease.Message = fmt.Sprintf("lease expired at %s, release is deferred while vmis %s are running", expiry.Format(time.RFC3339), strings.Join(running, ", "))
+		if previous == nil || previous.State != lease.State {
+			logrus.Warnf("lease of %s %s expired, but the device is in use by running vmis %s", kind, name, strings.Join(running, ", "))
+		}
+		return lease, heldRecheckPeriod, nil
+	case len(stopped) > 0:
+		lease.State = devicesv1beta1.ClaimLeaseBlocked
+		lease.Message = fmt.Sprintf("lease expired at %s, release is blocked until the device is removed from stopped vms %s", expiry.Format(time.RFC3339), strings.Join(stopped, ", "))
+		if previous == nil || previous.State != lease.State {
+			logrus.Warnf("lease of %s %s expired, but the device is allocated to stopped vms %s", kind, name, strings.Join(stopped, ", "))
+		}
+		return lease, heldRecheckPeriod, nil
+	}
+
+	lease.State = devicesv1beta1.ClaimLeaseExpired
+	lease.Message = fmt.Sprintf("lease expired at %s", expiry.Format(time.RFC3339))
+	logrus.Infof("releasing %s %s as its lease expired at %s", kind, name, expiry.Format(time.RFC3339))
+	return lease, 0, nil
+}
+
+// usage returns the running VMIs using the claimed device, and the stopped VMs it is still allocated to, as
+// namespace/name. VMs are found under claimName in vmIndex. Standalone VMIs carry no allocation details, so any running
+// standalone VMI requesting the resource name of the device on its node is considered to use the device
+func (h *Handler) usage(vmIndex, claimName string, device *claimedDevice) (running, stopped []string, err error) {
+	vms, err := h.vmCache.GetByIndex(vmIndex, claimName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error looking up vms using claim %s: %w", claimName, err)
+	}
+	for _, vm := range vms {
+		vmi, err := h.vmiCache.Get(vm.Namespace, vm.Name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, nil, fmt.Errorf("error looking up vmi %s/%s: %w", vm.Namespace, vm.Name, err)
+		}
+		if err == nil && !vmi.IsFinal() {
+			running = append(running, fmt.Sprintf("%s/%s", vm.Namespace, vm.Name))
+		} else {
+			stopped = append(stopped, fmt.Sprintf("%s/%s", vm.Namespace, vm.Name))
+		}
+	}
+
+	if device == nil {
+		return running, stopped, nil
+	}
+	vmis, err := h.vmiCache.List(metav1.NamespaceAll, labels.Everything())
+	if err != nil {
+		return nil, nil, fmt.Errorf("error listing vmis: %w", err)
+	}
+	for _, vmi := range vmis {
+		if vmi.IsFinal() || vmi.Status.NodeName != device.nodeName || !requestsResource(vmi, device.resourceName) {
+			continue
+		}
+		standalone, err := h.isStandalone(vmi)
+		if err != nil {
+			return nil, nil, err
+		}
+		if standalone {
+			running = append(running, fmt.Sprintf("%s/%s", vmi.Namespace, vmi.Name))
+		}
+	}
+	return running, stopped, nil
+}
+
+// isStandalone returns true if the VMI was not created by kubevirt for an existing VM
+func (h *Handler) isStandalone(vmi *kubevirtv1.VirtualMachineInstance) (bool, error) {
+	vm, err := h.vmCache.Get(vmi.Namespace, vmi.Name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("error looking up vm %s/%s: %w", vmi.Namespace, vmi.Name, err)
+	}
+	return !common.IsControlledBy(vmi, vm), nil
+}
+
+func requestsResource(vmi *kubevirtv1.VirtualMachineInstance, resourceName string) bool {
+	for _, v := range vmi.Spec.Domain.Devices.HostDevices {
+		if v.DeviceName == resourceName {
+			return true
+		}
+	}
+	return false
+}
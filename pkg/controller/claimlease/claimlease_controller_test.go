@@ -0,0 +1,208 @@
+package claimlease
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	harvesterfake "github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/pcidevices/pkg/util/fakeclients"
+)
+
+var (
+	created = time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+
+	gpuClaim = &devicesv1beta1.PCIDeviceClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "node1-000081000",
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: devicesv1beta1.PCIDeviceClaimSpec{
+			Address:       "0000:81:00.0",
+			NodeName:      "node1",
+			UserName:      "ci",
+			LeaseDuration: &metav1.Duration{Duration: 4 * time.Hour},
+		},
+	}
+
+	tokenClaim = &devicesv1beta1.USBDeviceClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "node1-0001-0003",
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: devicesv1beta1.USBDeviceClaimSpec{
+			UserName:      "ci",
+			LeaseDuration: &metav1.Duration{Duration: time.Hour},
+		},
+	}
+
+	gpuVM = &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vm1",
+			Namespace: "default",
+			UID:       "vm1-uid",
+			Annotations: map[string]string{
+				devicesv1beta1.DeviceAllocationKey: `{"hostdevices":{"nvidia.com/GA100":["node1-000081000"]}}`,
+			},
+		},
+	}
+
+	gpuDevice = &devicesv1beta1.PCIDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1-000081000",
+		},
+		Status: devicesv1beta1.PCIDeviceStatus{
+			Address:      "0000:81:00.0",
+			NodeName:     "node1",
+			ResourceName: "nvidia.com/GA100",
+		},
+	}
+)
+
+func newVMI(name string, owner *kubevirtv1.VirtualMachine, phase kubevirtv1.VirtualMachineInstancePhase) *kubevirtv1.VirtualMachineInstance {
+	vmi := &kubevirtv1.VirtualMachineInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: kubevirtv1.VirtualMachineInstanceSpec{
+			Domain: kubevirtv1.DomainSpec{
+				Devices: kubevirtv1.Devices{
+					HostDevices: []kubevirtv1.HostDevice{{Name: "gpu", DeviceName: "nvidia.com/GA100"}},
+				},
+			},
+		},
+		Status: kubevirtv1.VirtualMachineInstanceStatus{
+			NodeName: "node1",
+			Phase:    phase,
+		},
+	}
+	if owner != nil {
+		vmi.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(owner, kubevirtv1.VirtualMachineGroupVersionKind)}
+	}
+	return vmi
+}
+
+type requeue struct {
+	name  string
+	after time.Duration
+}
+
+func Test_PCIDeviceClaimLease(t *testing.T) {
+	assert := require.New(t)
+	client := fake.NewSimpleClientset(gpuClaim, gpuDevice)
+	harvesterClient := harvesterfake.NewSimpleClientset(gpuVM, newVMI(gpuVM.Name, gpuVM, kubevirtv1.Running))
+	var requeued []requeue
+	now := created.Add(time.Hour)
+	enqueueAfter := func(name string, duration time.Duration) {
+		requeued = append(requeued, requeue{name: name, after: duration})
+	}
+	h := &Handler{
+		pdcClient:       fakeclients.PCIDeviceClaimsClient(client.DevicesV1beta1().PCIDeviceClaims),
+		usbClaimClient:  fakeclients.USBDeviceClaimsClient(client.DevicesV1beta1().USBDeviceClaims),
+		pdCache:         fakeclients.PCIDevicesCache(client.DevicesV1beta1().PCIDevices),
+		usbCache:        fakeclients.USBDeviceCache(client.DevicesV1beta1().USBDevices),
+		vmCache:         fakeclients.VirtualMachineCache(harvesterClient.KubevirtV1().VirtualMachines),
+		vmiCache:        fakeclients.VirtualMachineInstanceCache(harvesterClient.KubevirtV1().VirtualMachineInstances),
+		enqueuePCIAfter: enqueueAfter,
+		enqueueUSBAfter: enqueueAfter,
+		now:             func() time.Time { return now },
+	}
+
+	// the lease is active until the warning period before expiry
+	claim, err := h.OnPCIDeviceClaimChange(gpuClaim.Name, gpuClaim)
+	assert.NoError(err)
+	assert.Equal(devicesv1beta1.ClaimLeaseActive, claim.Status.Lease.State)
+	assert.Equal(created.Add(4*time.Hour), claim.Status.Lease.ExpiryTime.Time)
+	assert.Equal([]requeue{{name: gpuClaim.Name, after: 3*time.Hour - devicesv1beta1.ClaimLeaseWarningPeriod}}, requeued)
+
+	now = created.Add(4*time.Hour - 10*time.Minute)
+	claim, err = h.OnPCIDeviceClaimChange(claim.Name, claim)
+	assert.NoError(err)
+	assert.Equal(devicesv1beta1.ClaimLeaseExpiring, claim.Status.Lease.State, "expected expiry to be announced")
+
+	// the running vmi of the vm the device is allocated to defers the release
+	now = created.Add(5 * time.Hour)
+	claim, err = h.OnPCIDeviceClaimChange(claim.Name, claim)
+	assert.NoError(err)
+	assert.Equal(devicesv1beta1.ClaimLeaseHeld, claim.Status.Lease.State)
+	assert.Contains(claim.Status.Lease.Message, "default/vm1")
+	assert.Equal(heldRecheckPeriod, requeued[len(requeued)-1].after)
+	_, err = client.DevicesV1beta1().PCIDeviceClaims().Get(context.TODO(), claim.Name, metav1.GetOptions{})
+	assert.NoError(err, "expected claim in use by a running vmi to be kept")
+
+	// a stopped vm the device is allocated to blocks the release
+	assert.NoError(harvesterClient.KubevirtV1().VirtualMachineInstances(gpuVM.Namespace).Delete(context.TODO(), gpuVM.Name, metav1.DeleteOptions{}))
+	claim, err = h.OnPCIDeviceClaimChange(claim.Name, claim)
+	assert.NoError(err)
+	assert.Equal(devicesv1beta1.ClaimLeaseBlocked, claim.Status.Lease.State)
+	assert.Contains(claim.Status.Lease.Message, "default/vm1")
+	assert.Equal(heldRecheckPeriod, requeued[len(requeued)-1].after)
+	_, err = client.DevicesV1beta1().PCIDeviceClaims().Get(context.TODO(), claim.Name, metav1.GetOptions{})
+	assert.NoError(err, "expected claim allocated to a stopped vm to be kept")
+
+	// a running standalone vmi requesting the device on its node defers the release
+	assert.NoError(harvesterClient.KubevirtV1().VirtualMachines(gpuVM.Namespace).Delete(context.TODO(), gpuVM.Name, metav1.DeleteOptions{}))
+	forged := newVMI("vmi2", &kubevirtv1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Name: "vmi2", UID: "forged-uid"}}, kubevirtv1.Running)
+	_, err = harvesterClient.KubevirtV1().VirtualMachineInstances(forged.Namespace).Create(context.TODO(), forged, metav1.CreateOptions{})
+	assert.NoError(err)
+	otherNode := newVMI("vmi3", nil, kubevirtv1.Running)
+	otherNode.Status.NodeName = "node2"
+	_, err = harvesterClient.KubevirtV1().VirtualMachineInstances(otherNode.Namespace).Create(context.TODO(), otherNode, metav1.CreateOptions{})
+	assert.NoError(err)
+	claim, err = h.OnPCIDeviceClaimChange(claim.Name, claim)
+	assert.NoError(err)
+	assert.Equal(devicesv1beta1.ClaimLeaseHeld, claim.Status.Lease.State)
+	assert.Contains(claim.Status.Lease.Message, "default/vmi2")
+	assert.NotContains(claim.Status.Lease.Message, "default/vmi3", "expected vmis on other nodes to be ignored")
+
+	// the claim is released once the device is no longer in use or allocated to a vm
+	assert.NoError(harvesterClient.KubevirtV1().VirtualMachineInstances(forged.Namespace).Delete(context.TODO(), forged.Name, metav1.DeleteOptions{}))
+	claim, err = h.OnPCIDeviceClaimChange(claim.Name, claim)
+	assert.NoError(err)
+	assert.Equal(devicesv1beta1.ClaimLeaseExpired, claim.Status.Lease.State)
+	_, err = client.DevicesV1beta1().PCIDeviceClaims().Get(context.TODO(), claim.Name, metav1.GetOptions{})
+	assert.Error(err, "expected claim with expired lease to be released")
+}
+
+func Test_USBDeviceClaimLease(t *testing.T) {
+	assert := require.New(t)
+	withoutLease := tokenClaim.DeepCopy()
+	withoutLease.Name = "node1-0001-0004"
+	withoutLease.Spec.LeaseDuration = nil
+	client := fake.NewSimpleClientset(tokenClaim, withoutLease)
+	harvesterClient := harvesterfake.NewSimpleClientset()
+	var requeued []requeue
+	now := created.Add(2 * time.Hour)
+	enqueueAfter := func(name string, duration time.Duration) {
+		requeued = append(requeued, requeue{name: name, after: duration})
+	}
+	h := &Handler{
+		pdcClient:       fakeclients.PCIDeviceClaimsClient(client.DevicesV1beta1().PCIDeviceClaims),
+		usbClaimClient:  fakeclients.USBDeviceClaimsClient(client.DevicesV1beta1().USBDeviceClaims),
+		pdCache:         fakeclients.PCIDevicesCache(client.DevicesV1beta1().PCIDevices),
+		usbCache:        fakeclients.USBDeviceCache(client.DevicesV1beta1().USBDevices),
+		vmCache:         fakeclients.VirtualMachineCache(harvesterClient.KubevirtV1().VirtualMachines),
+		vmiCache:        fakeclients.VirtualMachineInstanceCache(harvesterClient.KubevirtV1().VirtualMachineInstances),
+		enqueuePCIAfter: enqueueAfter,
+		enqueueUSBAfter: enqueueAfter,
+		now:             func() time.Time { return now },
+	}
+
+	claim, err := h.OnUSBDeviceClaimChange(withoutLease.Name, withoutLease)
+	assert.NoError(err)
+	assert.Nil(claim.Status.Lease, "expected no lease for claims without a lease duration")
+
+	claim, err = h.OnUSBDeviceClaimChange(tokenClaim.Name, tokenClaim)
+	assert.NoError(err)
+	assert.Equal(devicesv1beta1.ClaimLeaseExpired, claim.Status.Lease.State)
+	_, err = client.DevicesV1beta1().USBDeviceClaims().Get(context.TODO(), tokenClaim.Name, metav1.GetOptions{})
+	assert.Error(err, "expected unused claim with expired lease to be released")
+	assert.Empty(requeued)
+}
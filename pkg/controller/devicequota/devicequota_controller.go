@@ -0,0 +1,86 @@
+package devicequota
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rancher/wrangler/v3/pkg/relatedresource"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/config"
+	"github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
+	ctlkubevirtv1 "github.com/harvester/pcidevices/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/pcidevices/pkg/util/devicequota"
+)
+
+// Handler reports the devices requested by the VMs and standalone VMIs of a namespace in the status of its DeviceQuotas.
+// Quotas are enforced by the VM and VMI webhooks, the status only reflects the current usage
+type Handler struct {
+	quotaClient v1beta1.DeviceQuotaClient
+	quotaCache  v1beta1.DeviceQuotaCache
+	vmCache     ctlkubevirtv1.VirtualMachineCache
+	vmiCache    ctlkubevirtv1.VirtualMachineInstanceCache
+	counter     *devicequota.Counter
+}
+
+func Register(ctx context.Context, management *config.FactoryManager) error {
+	quotaClient := management.DeviceFactory.Devices().V1beta1().DeviceQuota()
+	vmClient := management.KubevirtFactory.Kubevirt().V1().VirtualMachine()
+	vmiClient := management.KubevirtFactory.Kubevirt().V1().VirtualMachineInstance()
+	handler := &Handler{
+		quotaClient: quotaClient,
+		quotaCache:  quotaClient.Cache(),
+		vmCache:     vmClient.Cache(),
+		vmiCache:    vmiClient.Cache(),
+		counter: devicequota.NewCounter(management.DeviceFactory.Devices().V1beta1().PCIDevice().Cache(),
+			management.DeviceFactory.Devices().V1beta1().USBDevice().Cache()),
+	}
+	quotaClient.OnChange(ctx, "devicequota-usage", handler.OnChange)
+	relatedresource.Watch(ctx, "VirtualMachineToDeviceQuotaUsage", handler.OnVMChange, quotaClient, vmClient, vmiClient)
+	return nil
+}
+
+// OnChange updates the usage reported for the limits of the quota
+func (h *Handler) OnChange(_ string, quota *devicesv1beta1.DeviceQuota) (*devicesv1beta1.DeviceQuota, error) {
+	if quota == nil || quota.DeletionTimestamp != nil {
+		return quota, nil
+	}
+
+	vms, err := h.vmCache.List(quota.Namespace, labels.Everything())
+	if err != nil {
+		return quota, fmt.Errorf("error listing vms in namespace %s: %w", quota.Namespace, err)
+	}
+	vmis, err := h.vmiCache.List(quota.Namespace, labels.Everything())
+	if err != nil {
+		return quota, fmt.Errorf("error listing vmis in namespace %s: %w", quota.Namespace, err)
+	}
+	usage, err := h.counter.Usage(devicequota.Workloads(vms, vmis)...)
+	if err != nil {
+		return quota, err
+	}
+
+	used := usage.Limited(quota.Spec.Hard)
+	if equality.Semantic.DeepEqual(quota.Status.Used, used) {
+		return quota, nil
+	}
+	quotaCopy := quota.DeepCopy()
+	quotaCopy.Status.Used = used
+	return h.quotaClient.UpdateStatus(quotaCopy)
+}
+
+// OnVMChange requeues the quotas in the namespace of the VM or VMI, as the devices it requests may have changed
+func (h *Handler) OnVMChange(namespace string, _ string, _ runtime.Object) ([]relatedresource.Key, error) {
+	quotas, err := h.quotaCache.List(namespace, labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error listing devicequotas in namespace %s: %w", namespace, err)
+	}
+
+	keys := make([]relatedresource.Key, 0, len(quotas))
+	for _, quota := range quotas {
+		keys = append(keys, relatedresource.NewKey(quota.Namespace, quota.Name))
+	}
+	return keys, nil
+}
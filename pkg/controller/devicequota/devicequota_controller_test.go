@@ -0,0 +1,154 @@
+package devicequota
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	harvesterfake "github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/pcidevices/pkg/util/devicequota"
+	"github.com/harvester/pcidevices/pkg/util/fakeclients"
+)
+
+var (
+	gpu = &devicesv1beta1.PCIDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1-000081000",
+		},
+		Status: devicesv1beta1.PCIDeviceStatus{
+			Address:      "0000:81:00.0",
+			NodeName:     "node1",
+			ClassID:      "0302",
+			ResourceName: "nvidia.com/GA100",
+		},
+	}
+	vf = &devicesv1beta1.PCIDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1-000004010",
+		},
+		Status: devicesv1beta1.PCIDeviceStatus{
+			Address:      "0000:04:01.0",
+			NodeName:     "node1",
+			ClassID:      "0200",
+			PhysFn:       "0000:04:00.0",
+			ResourceName: "intel.com/I350_VF",
+		},
+	}
+	token = &devicesv1beta1.USBDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1-0001-0003",
+		},
+		Status: devicesv1beta1.USBDeviceStatus{
+			NodeName:     "node1",
+			ResourceName: "yubico.com/YUBIKEY",
+		},
+	}
+	quota = &devicesv1beta1.DeviceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "devices",
+			Namespace: "tenant-a",
+		},
+		Spec: devicesv1beta1.DeviceQuotaSpec{
+			Hard: devicesv1beta1.DeviceQuotaResources{
+				ResourceNames: map[string]int{"nvidia.com/GA100": 2},
+				Classes: map[devicesv1beta1.DeviceClass]int{
+					devicesv1beta1.DeviceClassNICVF: 4,
+					devicesv1beta1.DeviceClassUSB:   1,
+				},
+			},
+		},
+	}
+)
+
+func newVM(namespace, name string, hostDevices ...string) *kubevirtv1.VirtualMachine {
+	vm := &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: kubevirtv1.VirtualMachineSpec{
+			Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{},
+		},
+	}
+	for i, v := range hostDevices {
+		vm.Spec.Template.Spec.Domain.Devices.HostDevices = append(vm.Spec.Template.Spec.Domain.Devices.HostDevices,
+			kubevirtv1.HostDevice{Name: fmt.Sprintf("hostdevice%d", i), DeviceName: v})
+	}
+	return vm
+}
+
+func newVMI(namespace, name string, owners []metav1.OwnerReference, hostDevices ...string) *kubevirtv1.VirtualMachineInstance {
+	vm := newVM(namespace, name, hostDevices...)
+	return &kubevirtv1.VirtualMachineInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       namespace,
+			OwnerReferences: owners,
+		},
+		Spec: vm.Spec.Template.Spec,
+	}
+}
+
+func Test_OnChange(t *testing.T) {
+	assert := require.New(t)
+	controller := true
+	client := fake.NewSimpleClientset(gpu, vf, token, quota)
+	vm1 := newVM("tenant-a", "vm1", gpu.Status.ResourceName, vf.Status.ResourceName, vf.Status.ResourceName)
+	vm1.UID = "vm1-uid"
+	ownedBy := func(name string, uid types.UID) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{
+			APIVersion: kubevirtv1.VirtualMachineGroupVersionKind.GroupVersion().String(),
+			Kind:       kubevirtv1.VirtualMachineGroupVersionKind.Kind,
+			Name:       name,
+			UID:        uid,
+			Controller: &controller,
+		}}
+	}
+	harvesterClient := harvesterfake.NewSimpleClientset(
+		vm1,
+		newVM("tenant-a", "vm2", gpu.Status.ResourceName, token.Status.ResourceName),
+		newVM("tenant-b", "vm3", gpu.Status.ResourceName),
+		newVMI("tenant-a", "vm1", ownedBy("vm1", "vm1-uid"), gpu.Status.ResourceName, vf.Status.ResourceName, vf.Status.ResourceName),
+		newVMI("tenant-a", "vmi1", nil, vf.Status.ResourceName),
+		// the owner reference of a vmi is only trusted if it matches an existing vm
+		newVMI("tenant-a", "vmi2", ownedBy("vm1", "vm1-uid"), vf.Status.ResourceName),
+	)
+	h := &Handler{
+		quotaClient: fakeclients.DeviceQuotasClient(client.DevicesV1beta1().DeviceQuotas),
+		quotaCache:  fakeclients.DeviceQuotasCache(client.DevicesV1beta1().DeviceQuotas),
+		vmCache:     fakeclients.VirtualMachineCache(harvesterClient.KubevirtV1().VirtualMachines),
+		vmiCache:    fakeclients.VirtualMachineInstanceCache(harvesterClient.KubevirtV1().VirtualMachineInstances),
+		counter: devicequota.NewCounter(fakeclients.PCIDevicesCache(client.DevicesV1beta1().PCIDevices),
+			fakeclients.USBDeviceCache(client.DevicesV1beta1().USBDevices)),
+	}
+
+	updated, err := h.OnChange(quota.Name, quota)
+	assert.NoError(err, "expected no error while counting quota usage")
+	assert.Equal(devicesv1beta1.DeviceQuotaResources{
+		ResourceNames: map[string]int{"nvidia.com/GA100": 2},
+		Classes: map[devicesv1beta1.DeviceClass]int{
+			devicesv1beta1.DeviceClassNICVF: 4,
+			devicesv1beta1.DeviceClassUSB:   1,
+		},
+	}, updated.Status.Used, "expected usage of vms and standalone vmis in the quota namespace for the limited resources only")
+
+	assert.NoError(harvesterClient.KubevirtV1().VirtualMachines("tenant-a").Delete(context.TODO(), "vm2", metav1.DeleteOptions{}))
+	keys, err := h.OnVMChange("tenant-a", "vm2", nil)
+	assert.NoError(err)
+	assert.Len(keys, 1, "expected quota in the namespace of the removed vm to be requeued")
+	keys, err = h.OnVMChange("tenant-b", "vm3", nil)
+	assert.NoError(err)
+	assert.Empty(keys)
+
+	updated, err = h.OnChange(updated.Name, updated)
+	assert.NoError(err)
+	assert.Equal(1, updated.Status.Used.ResourceNames["nvidia.com/GA100"])
+	assert.Equal(0, updated.Status.Used.Classes[devicesv1beta1.DeviceClassUSB])
+}
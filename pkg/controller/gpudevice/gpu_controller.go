@@ -22,6 +22,7 @@ import (
 	"github.com/harvester/pcidevices/pkg/config"
 	"github.com/harvester/pcidevices/pkg/deviceplugins"
 	ctl "github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/util/agentstatus"
 	"github.com/harvester/pcidevices/pkg/util/executor"
 	"github.com/harvester/pcidevices/pkg/util/gpuhelper"
 )
@@ -350,7 +351,11 @@ func (h *Handler) setupMigConfiguration(gpu *v1beta1.SRIOVGPUDevice) error {
 
 func (h *Handler) reconcileMIGConfiguration(_ string, gpu *v1beta1.SRIOVGPUDevice) (*v1beta1.SRIOVGPUDevice, error) {
 	if gpu.Spec.Enabled {
-		return gpu, h.setupMigConfiguration(gpu)
+		err := h.setupMigConfiguration(gpu)
+		if gpu.Spec.NodeName == h.nodeName {
+			agentstatus.Default.Record(v1beta1.SubsystemMIG, err)
+		}
+		return gpu, err
 	}
 
 	// trigger deletion of MIGConfiguration object
@@ -8,6 +8,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/util/common"
 	"github.com/harvester/pcidevices/pkg/util/gpuhelper"
 )
 
@@ -23,7 +24,7 @@ func (h *Handler) OnMIGChange(name string, mig *v1beta1.MigConfiguration) (*v1be
 
 	status, err := gpuhelper.GenerateMIGConfigurationStatus(h.executor, mig.Spec.GPUAddress)
 	if err != nil {
-		return mig, fmt.Errorf("error generating MIG configuration status for device %s: %w", mig.Name, err)
+		return h.markMIGDegraded(mig, fmt.Errorf("error generating MIG configuration status for device %s: %w", mig.Name, err))
 	}
 
 	migCopy := mig.DeepCopy()
@@ -35,18 +36,19 @@ func (h *Handler) OnMIGChange(name string, mig *v1beta1.MigConfiguration) (*v1be
 		err = gpuhelper.EnableMIGProfiles(h.executor, mig)
 		if err != nil {
 			migCopy.Status.Message = err.Error()
-			if _, updateErr := h.migConfigurationController.UpdateStatus(migCopy); updateErr != nil {
-				logrus.Errorf("error updating MIG configuration status for device %s: %v", mig.Name, updateErr)
-			}
-			return mig, fmt.Errorf("error setting up MIG instances for device %s: %w", mig.Name, err)
+			return h.markMIGDegraded(migCopy, fmt.Errorf("error setting up MIG instances for device %s: %w", mig.Name, err))
 		}
 		// fetch MIG instance status
 		status, err = gpuhelper.GenerateMIGConfigurationStatus(h.executor, mig.Spec.GPUAddress)
 		if err != nil {
-			return mig, fmt.Errorf("error generating MIG configuration status for device %s: %w", mig.Name, err)
+			return h.markMIGDegraded(migCopy, fmt.Errorf("error generating MIG configuration status for device %s: %w", mig.Name, err))
 		}
+		// keep the conditions of the copy, to avoid modifying the conditions of the cached object
+		conditions := migCopy.Status.Conditions
 		migCopy.Status = *status
 		migCopy.Status.Message = ""
+		migCopy.Status.Conditions = conditions
+		setMIGConditions(migCopy)
 		return h.migConfigurationController.UpdateStatus(migCopy)
 	}
 
@@ -58,8 +60,9 @@ func (h *Handler) OnMIGChange(name string, mig *v1beta1.MigConfiguration) (*v1be
 		} else {
 			migCopy.Status.Status = v1beta1.MIGConfigurationOutOfSync
 		}
+		setMIGConditions(migCopy)
 
-		if mig.Status.Status != migCopy.Status.Status {
+		if !reflect.DeepEqual(mig.Status, migCopy.Status) {
 			return h.migConfigurationController.UpdateStatus(migCopy)
 		}
 	}
@@ -71,16 +74,19 @@ func (h *Handler) OnMIGChange(name string, mig *v1beta1.MigConfiguration) (*v1be
 		logrus.Debugf("disabling MIG instances for device %s", mig.Name)
 		err = gpuhelper.DisableMIGProfiles(h.executor, mig)
 		if err != nil {
-			return mig, err
+			return h.markMIGDegraded(migCopy, err)
 		}
 
 		// update status of object
 		status, err = gpuhelper.GenerateMIGConfigurationStatus(h.executor, mig.Spec.GPUAddress)
 		if err != nil {
-			return mig, fmt.Errorf("error generating MIG configuration status for device %s: %w", mig.Name, err)
+			return h.markMIGDegraded(migCopy, fmt.Errorf("error generating MIG configuration status for device %s: %w", mig.Name, err))
 		}
+		conditions := migCopy.Status.Conditions
 		migCopy.Status = *status
 		migCopy.Status.Status = v1beta1.MIGConfigurationDisabled
+		migCopy.Status.Conditions = conditions
+		setMIGConditions(migCopy)
 		return h.migConfigurationController.UpdateStatus(migCopy)
 	}
 
@@ -89,6 +95,25 @@ func (h *Handler) OnMIGChange(name string, mig *v1beta1.MigConfiguration) (*v1be
 	return mig, nil
 }
 
+// setMIGConditions derives the Ready condition from the sync state, and clears any earlier failure
+func setMIGConditions(mig *v1beta1.MigConfiguration) {
+	switch mig.Status.Status {
+	case v1beta1.MIGConfigurationSynced:
+		common.SetCondition(&mig.Status.Conditions, mig.Generation, v1beta1.ConditionReady, true, v1beta1.ReasonReconciled, "")
+	case v1beta1.MIGConfigurationDisabled:
+		common.SetCondition(&mig.Status.Conditions, mig.Generation, v1beta1.ConditionReady, false, v1beta1.ReasonDisabled, "MIG configuration is disabled")
+	default:
+		common.SetCondition(&mig.Status.Conditions, mig.Generation, v1beta1.ConditionReady, false, v1beta1.ReasonPending, "MIG instances do not match the requested profiles")
+	}
+	common.SetDegraded(&mig.Status.Conditions, mig.Generation, v1beta1.ReasonReconciled, nil)
+}
+
+func (h *Handler) markMIGDegraded(mig *v1beta1.MigConfiguration, reconcileErr error) (*v1beta1.MigConfiguration, error) {
+	migCopy := mig.DeepCopy()
+	common.MarkDegraded(&migCopy.Status.Conditions, migCopy.Generation, v1beta1.ReasonConfigurationError, reconcileErr)
+	return common.UpdateDegradedStatus("migconfiguration", migCopy, reconcileErr, h.migConfigurationController.UpdateStatus)
+}
+
 // instanceCount returns number of instances configured based on discover status
 func instanceCount(discoveredStatus *v1beta1.MigConfigurationStatus) int {
 	var discoveredInstances int
@@ -14,6 +14,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/util/common"
 	"github.com/harvester/pcidevices/pkg/util/gpuhelper"
 )
 
@@ -49,6 +50,7 @@ func (h *Handler) OnVGPUChange(_ string, vgpu *v1beta1.VGPUDevice) (*v1beta1.VGP
 	vgpuCopy.Status.UUID = discoveredVGPUStatus.UUID
 	vgpuCopy.Status.VGPUStatus = discoveredVGPUStatus.VGPUStatus
 	vgpuCopy.Status.AvailableTypes = discoveredVGPUStatus.AvailableTypes
+	setVGPUConditions(vgpuCopy)
 	if !reflect.DeepEqual(vgpuCopy.Status, vgpu.Status) {
 		return h.vGPUClient.UpdateStatus(vgpuCopy)
 	}
@@ -153,26 +155,27 @@ func containsVGPU(vgpu *v1beta1.VGPUDevice, vgpuList []*v1beta1.VGPUDevice) *v1b
 func (h *Handler) enableVGPU(vgpu *v1beta1.VGPUDevice) (*v1beta1.VGPUDevice, error) {
 	vgpuID, ok := vgpu.Status.AvailableTypes[vgpu.Spec.VGPUTypeName]
 	if !ok {
-		return vgpu, fmt.Errorf("VGPUType specified %s is not available for vGPU %s", vgpu.Spec.VGPUTypeName, vgpu.Spec.Address)
+		return h.markVGPUDegraded(vgpu, fmt.Errorf("VGPUType specified %s is not available for vGPU %s", vgpu.Spec.VGPUTypeName, vgpu.Spec.Address))
 	}
 
 	vgpuUUID := vgpuID
 
 	// setup pcidevice claims / pcidevice objects
 	if err := h.submitPCIDeviceClaim(vgpu); err != nil && !apierrors.IsAlreadyExists(err) {
-		return vgpu, fmt.Errorf("error creating pcideviceclaim for associated vgpu device: %w", err)
+		return h.markVGPUDegraded(vgpu, fmt.Errorf("error creating pcideviceclaim for associated vgpu device: %w", err))
 	}
 
 	// setup vgpu profile
 	createFilePath := filepath.Join(v1beta1.SysDevRoot, vgpu.Spec.Address, "nvidia", v1beta1.CurrentVGPUType)
 
 	if err := os.WriteFile(createFilePath, []byte(vgpuID), 0600); err != nil {
-		return vgpu, fmt.Errorf("error writing to create file for vgpu %s: %v", vgpu.Name, err)
+		return h.markVGPUDegraded(vgpu, fmt.Errorf("error writing to create file for vgpu %s: %v", vgpu.Name, err))
 	}
 
 	vgpu.Status.VGPUStatus = v1beta1.VGPUEnabled
 	vgpu.Status.UUID = vgpuUUID
 	vgpu.Status.ConfiguredVGPUTypeName = vgpu.Spec.VGPUTypeName
+	setVGPUConditions(vgpu)
 	vgpuObj, err := h.vGPUClient.UpdateStatus(vgpu)
 	if err != nil {
 		return vgpuObj, err
@@ -185,18 +188,19 @@ func (h *Handler) enableVGPU(vgpu *v1beta1.VGPUDevice) (*v1beta1.VGPUDevice, err
 func (h *Handler) disableVGPU(vgpu *v1beta1.VGPUDevice) (*v1beta1.VGPUDevice, error) {
 	// cleanup pcidevice claim
 	if err := h.cleanupRelatedPCIDeviceObjects(vgpu); err != nil {
-		return vgpu, err
+		return h.markVGPUDegraded(vgpu, err)
 	}
 
 	createFilePath := filepath.Join(v1beta1.SysDevRoot, vgpu.Spec.Address, "nvidia", v1beta1.CurrentVGPUType)
 
 	if err := os.WriteFile(createFilePath, []byte("0"), 0600); err != nil {
-		return vgpu, fmt.Errorf("error writing to create file for vgpu %s: %v", vgpu.Name, err)
+		return h.markVGPUDegraded(vgpu, fmt.Errorf("error writing to create file for vgpu %s: %v", vgpu.Name, err))
 	}
 
 	vgpu.Status.VGPUStatus = v1beta1.VGPUDisabled
 	vgpu.Status.UUID = ""
 	vgpu.Status.ConfiguredVGPUTypeName = ""
+	setVGPUConditions(vgpu)
 	vgpuObj, err := h.vGPUClient.UpdateStatus(vgpu)
 	if err != nil {
 		return vgpuObj, err
@@ -205,6 +209,22 @@ func (h *Handler) disableVGPU(vgpu *v1beta1.VGPUDevice) (*v1beta1.VGPUDevice, er
 	return h.reconcileDisabledVGPUStatus(vgpuObj)
 }
 
+// setVGPUConditions marks the vgpu as ready when it is enabled, and clears any earlier failure
+func setVGPUConditions(vgpu *v1beta1.VGPUDevice) {
+	if vgpu.Status.VGPUStatus == v1beta1.VGPUEnabled {
+		common.SetCondition(&vgpu.Status.Conditions, vgpu.Generation, v1beta1.ConditionReady, true, v1beta1.ReasonReconciled, "")
+	} else {
+		common.SetCondition(&vgpu.Status.Conditions, vgpu.Generation, v1beta1.ConditionReady, false, v1beta1.ReasonDisabled, "vgpu is not enabled")
+	}
+	common.SetDegraded(&vgpu.Status.Conditions, vgpu.Generation, v1beta1.ReasonReconciled, nil)
+}
+
+func (h *Handler) markVGPUDegraded(vgpu *v1beta1.VGPUDevice, reconcileErr error) (*v1beta1.VGPUDevice, error) {
+	vgpuCopy := vgpu.DeepCopy()
+	common.MarkDegraded(&vgpuCopy.Status.Conditions, vgpuCopy.Generation, v1beta1.ReasonConfigurationError, reconcileErr)
+	return common.UpdateDegradedStatus("vgpudevice", vgpuCopy, reconcileErr, h.vGPUClient.UpdateStatus)
+}
+
 // reconcileDisabledVGPUStatus is needed as when a vgpu is enabled, based on type of vGPU the available types for other vgpu's may change. This ensures that the state of other vGPU's from the same parent GPU is reconciled immediately to avoid users from attempting to enable unsupported vGPU Types
 func (h *Handler) reconcileDisabledVGPUStatus(vgpu *v1beta1.VGPUDevice) (*v1beta1.VGPUDevice, error) {
 	if vgpu == nil || vgpu.DeletionTimestamp != nil || vgpu.Spec.NodeName != h.nodeName {
@@ -13,6 +13,7 @@ import (
 
 	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
 	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/pcidevices/pkg/util/common"
 	"github.com/harvester/pcidevices/pkg/util/fakeclients"
 	"github.com/harvester/pcidevices/pkg/util/gpuhelper"
 )
@@ -389,3 +390,24 @@ func Test_cleanupPCIDeviceClaim(t *testing.T) {
 	_, ok = pciDeviceObj.Labels[v1beta1.ParentSRIOVGPUDeviceLabel]
 	assert.False(ok, "expected to not find label for v1beta1.ParentSRIOVGPUDeviceLabel")
 }
+
+// test validates failures while enabling a vgpu are recorded as conditions on the vgpu
+func Test_enableVGPUDegraded(t *testing.T) {
+	assert := require.New(t)
+	vgpu := missingVGPU.DeepCopy()
+	vgpu.Spec.Enabled = true
+	vgpu.Spec.VGPUTypeName = "NVIDIA A2-4Q"
+	client := fake.NewSimpleClientset(vgpu)
+	h := &Handler{
+		nodeName:   nodeName,
+		vGPUClient: fakeclients.VGPUDeviceClient(client.DevicesV1beta1().VGPUDevices),
+	}
+
+	_, err := h.enableVGPU(vgpu)
+	assert.Error(err, "expected error as vgpu type is not available")
+	obj, err := client.DevicesV1beta1().VGPUDevices().Get(context.TODO(), vgpu.Name, metav1.GetOptions{})
+	assert.NoError(err)
+	assert.True(common.IsConditionTrue(obj.Status.Conditions, v1beta1.ConditionDegraded), "expected vgpu to be marked degraded")
+	assert.False(common.IsConditionTrue(obj.Status.Conditions, v1beta1.ConditionReady), "expected vgpu to not be ready")
+	assert.Empty(vgpu.Status.Conditions, "expected cached object to not be modified")
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
 	"github.com/harvester/pcidevices/pkg/config"
+	"github.com/harvester/pcidevices/pkg/util/common"
 )
 
 func RegisterIndexers(management *config.FactoryManager) {
@@ -11,6 +12,13 @@ func RegisterIndexers(management *config.FactoryManager) {
 
 	usbDevClaimCache := management.DeviceFactory.Devices().V1beta1().USBDeviceClaim().Cache()
 	usbDevClaimCache.AddIndexer(v1beta1.USBDevicePCIAddress, getUSBDeviceClaimFromPCIAddress)
+
+	pdCache := management.DeviceFactory.Devices().V1beta1().PCIDevice().Cache()
+	pdCache.AddIndexer(common.PCIDeviceByResourceName, common.PCIDeviceResourceName)
+
+	vmCache := management.KubevirtFactory.Kubevirt().V1().VirtualMachine().Cache()
+	vmCache.AddIndexer(common.VMByPCIDeviceClaim, common.VMByHostDeviceName)
+	vmCache.AddIndexer(common.VMByUSBDeviceClaim, common.VMBySpecHostDeviceName)
 }
 
 func getSriovDeviceFromVF(obj *v1beta1.SRIOVNetworkDevice) ([]string, error) {
@@ -0,0 +1,151 @@
+package iommugroup
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jaypipes/ghw"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/controller/pcidevice"
+	ctl "github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/iommu"
+)
+
+// Handler publishes the iommu group topology of a node as IOMMUGroup objects
+type Handler struct {
+	client        ctl.IOMMUGroupClient
+	cache         ctl.IOMMUGroupCache
+	pci           *ghw.PCIInfo
+	skipAddresses []string
+}
+
+func NewHandler(client ctl.IOMMUGroupClient, cache ctl.IOMMUGroupCache, pci *ghw.PCIInfo, skipAddresses []string) *Handler {
+	return &Handler{
+		client:        client,
+		cache:         cache,
+		pci:           pci,
+		skipAddresses: skipAddresses,
+	}
+}
+
+// ReconcileIOMMUGroups creates or updates an IOMMUGroup for each iommu group on the node, and
+// removes IOMMUGroups for groups which no longer exist
+func (h *Handler) ReconcileIOMMUGroups(nodename string) error {
+	iommuGroupPaths, err := iommu.GroupPaths()
+	if err != nil {
+		return err
+	}
+
+	return h.reconcileGroups(nodename, iommu.GroupMapForPCIDevices(iommuGroupPaths))
+}
+
+func (h *Handler) reconcileGroups(nodename string, iommuGroupMap map[string]int) error {
+	desired := h.generateGroupStatus(nodename, iommuGroupMap)
+	for _, status := range desired {
+		if err := h.reconcileGroup(status); err != nil {
+			return err
+		}
+	}
+
+	existing, err := h.cache.List(labels.SelectorFromSet(map[string]string{v1beta1.NodeKeyName: nodename}))
+	if err != nil {
+		return fmt.Errorf("error listing iommugroups for node %s: %w", nodename, err)
+	}
+
+	for _, v := range existing {
+		if _, ok := desired[v.Status.Group]; ok {
+			continue
+		}
+		logrus.Debugf("removing iommugroup %s as group no longer exists", v.Name)
+		if err := h.client.Delete(v.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("error removing iommugroup %s: %w", v.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (h *Handler) reconcileGroup(status v1beta1.IOMMUGroupStatus) error {
+	name := v1beta1.IOMMUGroupNameForHostname(status.Group, status.NodeName)
+	group, err := h.cache.Get(name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("error looking up iommugroup %s: %w", name, err)
+		}
+
+		group, err = h.client.Create(&v1beta1.IOMMUGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Labels: map[string]string{
+					v1beta1.NodeKeyName: status.NodeName,
+				},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("error creating iommugroup %s: %w", name, err)
+		}
+	}
+
+	if equality.Semantic.DeepEqual(group.Status, status) {
+		return nil
+	}
+
+	groupCopy := group.DeepCopy()
+	groupCopy.Status = status
+	if _, err := h.client.UpdateStatus(groupCopy); err != nil {
+		return fmt.Errorf("error updating iommugroup %s: %w", name, err)
+	}
+	return nil
+}
+
+// generateGroupStatus groups the devices on the node by their iommu group
+func (h *Handler) generateGroupStatus(nodename string, iommuGroupMap map[string]int) map[int]v1beta1.IOMMUGroupStatus {
+	bridges := pcidevice.IdentifyPCIBridgeDevices(h.pci)
+	groups := make(map[int]v1beta1.IOMMUGroupStatus)
+	for _, dev := range h.pci.Devices {
+		group, ok := iommuGroupMap[dev.Address]
+		if !ok {
+			continue
+		}
+
+		bridge := containsString(bridges, dev.Address)
+		managed := !bridge && !containsString(h.skipAddresses, dev.Address)
+		status := groups[group]
+		status.NodeName = nodename
+		status.Group = group
+		status.Devices = append(status.Devices, v1beta1.NewIOMMUGroupDevice(dev, nodename, bridge, managed))
+		groups[group] = status
+	}
+
+	for group, status := range groups {
+		sort.Slice(status.Devices, func(i, j int) bool {
+			return status.Devices[i].Address < status.Devices[j].Address
+		})
+
+		var endpoints int
+		for _, v := range status.Devices {
+			if !v.Bridge {
+				endpoints++
+			}
+		}
+		status.Isolated = endpoints == 1
+		groups[group] = status
+	}
+
+	return groups
+}
+
+func containsString(elements []string, element string) bool {
+	for _, v := range elements {
+		if v == element {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package iommugroup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jaypipes/ghw"
+	"github.com/jaypipes/ghw/pkg/pci"
+	"github.com/jaypipes/pcidb"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/pcidevices/pkg/util/fakeclients"
+)
+
+const testNode = "node1"
+
+func newTestDevice(address, classID, subclassID, driver string) *pci.Device {
+	return &pci.Device{
+		Address:  address,
+		Vendor:   &pcidb.Vendor{ID: "10de", Name: "NVIDIA Corporation"},
+		Product:  &pcidb.Product{ID: "2236", Name: "GA102GL [A10]"},
+		Class:    &pcidb.Class{ID: classID, Name: "Display controller"},
+		Subclass: &pcidb.Subclass{ID: subclassID, Name: "3D controller"},
+		Driver:   driver,
+	}
+}
+
+func Test_reconcileGroups(t *testing.T) {
+	assert := require.New(t)
+	staleGroup := &v1beta1.IOMMUGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: v1beta1.IOMMUGroupNameForHostname(99, testNode),
+			Labels: map[string]string{
+				v1beta1.NodeKeyName: testNode,
+			},
+		},
+		Status: v1beta1.IOMMUGroupStatus{
+			NodeName: testNode,
+			Group:    99,
+		},
+	}
+	client := fake.NewSimpleClientset(staleGroup)
+	pciInfo := &ghw.PCIInfo{
+		Devices: []*pci.Device{
+			newTestDevice("0000:00:01.0", "06", "04", "pcieport"),
+			newTestDevice("0000:08:00.0", "03", "02", "nvidia"),
+			newTestDevice("0000:08:00.1", "04", "03", "snd_hda_intel"),
+			newTestDevice("0000:09:00.0", "02", "00", "ixgbe"),
+		},
+	}
+	groupMap := map[string]int{
+		"0000:00:01.0": 1,
+		"0000:08:00.0": 1,
+		"0000:08:00.1": 1,
+		"0000:09:00.0": 2,
+	}
+	h := NewHandler(fakeclients.IOMMUGroupsClient(client.DevicesV1beta1().IOMMUGroups),
+		fakeclients.IOMMUGroupsCache(client.DevicesV1beta1().IOMMUGroups), pciInfo, []string{"0000:09:00.0"})
+
+	assert.NoError(h.reconcileGroups(testNode, groupMap), "expected no error reconciling iommu groups")
+
+	gpuGroup, err := client.DevicesV1beta1().IOMMUGroups().Get(context.TODO(), v1beta1.IOMMUGroupNameForHostname(1, testNode), metav1.GetOptions{})
+	assert.NoError(err, "expected group 1 to be created")
+	assert.Equal(testNode, gpuGroup.Labels[v1beta1.NodeKeyName])
+	assert.Len(gpuGroup.Status.Devices, 3)
+	assert.False(gpuGroup.Status.Isolated, "expected gpu sharing group with audio function to not be isolated")
+	assert.True(gpuGroup.Status.Devices[0].Bridge, "expected root port to be reported as a bridge")
+	assert.Empty(gpuGroup.Status.Devices[0].PCIDeviceName, "expected no pcidevice name for bridges")
+	assert.Equal(v1beta1.PCIDeviceNameForHostname("0000:08:00.1", testNode), gpuGroup.Status.Devices[2].PCIDeviceName)
+	assert.Equal("snd_hda_intel", gpuGroup.Status.Devices[2].KernelDriverInUse)
+
+	nicGroup, err := client.DevicesV1beta1().IOMMUGroups().Get(context.TODO(), v1beta1.IOMMUGroupNameForHostname(2, testNode), metav1.GetOptions{})
+	assert.NoError(err, "expected group 2 to be created")
+	assert.True(nicGroup.Status.Isolated, "expected group with a single device to be isolated")
+	assert.Empty(nicGroup.Status.Devices[0].PCIDeviceName, "expected no pcidevice name for skipped devices")
+
+	_, err = client.DevicesV1beta1().IOMMUGroups().Get(context.TODO(), staleGroup.Name, metav1.GetOptions{})
+	assert.True(apierrors.IsNotFound(err), "expected stale group to be removed")
+}
@@ -0,0 +1,183 @@
+package nodeagent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/config"
+	"github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/util/common"
+)
+
+const (
+	// staleTimeout is the time after which an agent which has not refreshed its heartbeat is considered stale.
+	// agents refresh their heartbeat every minute
+	staleTimeout = 3 * time.Minute
+)
+
+// Handler monitors the heartbeat reported by the agent on each node, and flags the devices and claims
+// on nodes with a stale agent as unknown, as their status is no longer being maintained
+type Handler struct {
+	nodeClient     v1beta1.NodeClient
+	enqueueAfter   func(name string, duration time.Duration)
+	pdClient       v1beta1.PCIDeviceClient
+	pdCache        v1beta1.PCIDeviceCache
+	pdcClient      v1beta1.PCIDeviceClaimClient
+	pdcCache       v1beta1.PCIDeviceClaimCache
+	usbClient      v1beta1.USBDeviceClient
+	usbCache       v1beta1.USBDeviceCache
+	usbClaimClient v1beta1.USBDeviceClaimClient
+	usbClaimCache  v1beta1.USBDeviceClaimCache
+	now            func() time.Time
+}
+
+func Register(ctx context.Context, management *config.FactoryManager) error {
+	nodeClient := management.DeviceFactory.Devices().V1beta1().Node()
+	pdClient := management.DeviceFactory.Devices().V1beta1().PCIDevice()
+	pdcClient := management.DeviceFactory.Devices().V1beta1().PCIDeviceClaim()
+	usbClient := management.DeviceFactory.Devices().V1beta1().USBDevice()
+	usbClaimClient := management.DeviceFactory.Devices().V1beta1().USBDeviceClaim()
+
+	handler := &Handler{
+		nodeClient:     nodeClient,
+		enqueueAfter:   nodeClient.EnqueueAfter,
+		pdClient:       pdClient,
+		pdCache:        pdClient.Cache(),
+		pdcClient:      pdcClient,
+		pdcCache:       pdcClient.Cache(),
+		usbClient:      usbClient,
+		usbCache:       usbClient.Cache(),
+		usbClaimClient: usbClaimClient,
+		usbClaimCache:  usbClaimClient.Cache(),
+		now:            time.Now,
+	}
+	nodeClient.OnChange(ctx, "node-agent-monitor", handler.OnNodeChange)
+	return nil
+}
+
+func (h *Handler) OnNodeChange(name string, node *devicesv1beta1.Node) (*devicesv1beta1.Node, error) {
+	if node == nil || node.DeletionTimestamp != nil {
+		return node, nil
+	}
+
+	remaining := h.heartbeatRemaining(node)
+	ready := remaining > 0
+	if ready {
+		// the heartbeat is not refreshed when the agent stops, so the node is checked again once it would become stale
+		h.enqueueAfter(name, remaining)
+	}
+
+	if err := h.flagDevices(node.Name, ready); err != nil {
+		return node, err
+	}
+
+	nodeCopy := node.DeepCopy()
+	if !setNodeAgentReady(&nodeCopy.Status.Conditions, node.Generation, ready) {
+		return node, nil
+	}
+
+	if ready {
+		logrus.Infof("agent on node %s is reporting again", node.Name)
+	} else {
+		logrus.Warnf("agent on node %s has not reported since %s, flagging devices as unknown", node.Name, lastSeen(node).Format(time.RFC3339))
+	}
+	return h.nodeClient.UpdateStatus(nodeCopy)
+}
+
+// heartbeatRemaining returns the time left until the agent on node is considered stale. Nodes which have
+// never reported a heartbeat are measured from the creation of the Node object
+func (h *Handler) heartbeatRemaining(node *devicesv1beta1.Node) time.Duration {
+	return lastSeen(node).Add(staleTimeout).Sub(h.now())
+}
+
+func lastSeen(node *devicesv1beta1.Node) time.Time {
+	if node.Status.LastHeartbeatTime != nil {
+		return node.Status.LastHeartbeatTime.Time
+	}
+	return node.CreationTimestamp.Time
+}
+
+// setNodeAgentReady sets the NodeAgentReady condition, which is Unknown while the agent is stale
+func setNodeAgentReady(conditions *[]metav1.Condition, generation int64, ready bool) bool {
+	if ready {
+		return common.SetConditionStatus(conditions, generation, devicesv1beta1.ConditionNodeAgentReady, metav1.ConditionTrue,
+			devicesv1beta1.ReasonHeartbeatReceived, "")
+	}
+	return common.SetConditionStatus(conditions, generation, devicesv1beta1.ConditionNodeAgentReady, metav1.ConditionUnknown,
+		devicesv1beta1.ReasonNodeAgentStale, "agent on the node has stopped reporting, status may be out of date")
+}
+
+// flagDevices sets the NodeAgentReady condition on all devices and claims on nodeName
+func (h *Handler) flagDevices(nodeName string, ready bool) error {
+	selector := labels.SelectorFromSet(map[string]string{devicesv1beta1.NodeKeyName: nodeName})
+
+	pds, err := h.pdCache.List(selector)
+	if err != nil {
+		return fmt.Errorf("error listing pcidevices for node %s: %w", nodeName, err)
+	}
+	for _, pd := range pds {
+		pdCopy := pd.DeepCopy()
+		if !setNodeAgentReady(&pdCopy.Status.Conditions, pd.Generation, ready) {
+			continue
+		}
+		if _, err := h.pdClient.UpdateStatus(pdCopy); err != nil {
+			return fmt.Errorf("error updating pcidevice %s: %w", pd.Name, err)
+		}
+	}
+
+	pdcs, err := h.pdcCache.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error listing pcideviceclaims: %w", err)
+	}
+	for _, pdc := range pdcs {
+		if pdc.Spec.NodeName != nodeName {
+			continue
+		}
+		pdcCopy := pdc.DeepCopy()
+		if !setNodeAgentReady(&pdcCopy.Status.Conditions, pdc.Generation, ready) {
+			continue
+		}
+		if _, err := h.pdcClient.UpdateStatus(pdcCopy); err != nil {
+			return fmt.Errorf("error updating pcideviceclaim %s: %w", pdc.Name, err)
+		}
+	}
+
+	usbs, err := h.usbCache.List(selector)
+	if err != nil {
+		return fmt.Errorf("error listing usbdevices for node %s: %w", nodeName, err)
+	}
+	for _, usb := range usbs {
+		usbCopy := usb.DeepCopy()
+		if !setNodeAgentReady(&usbCopy.Status.Conditions, usb.Generation, ready) {
+			continue
+		}
+		if _, err := h.usbClient.UpdateStatus(usbCopy); err != nil {
+			return fmt.Errorf("error updating usbdevice %s: %w", usb.Name, err)
+		}
+	}
+
+	usbClaims, err := h.usbClaimCache.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error listing usbdeviceclaims: %w", err)
+	}
+	for _, usbClaim := range usbClaims {
+		if usbClaim.Status.NodeName != nodeName {
+			continue
+		}
+		usbClaimCopy := usbClaim.DeepCopy()
+		if !setNodeAgentReady(&usbClaimCopy.Status.Conditions, usbClaim.Generation, ready) {
+			continue
+		}
+		if _, err := h.usbClaimClient.UpdateStatus(usbClaimCopy); err != nil {
+			return fmt.Errorf("error updating usbdeviceclaim %s: %w", usbClaim.Name, err)
+		}
+	}
+
+	return nil
+}
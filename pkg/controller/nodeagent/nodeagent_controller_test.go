@@ -0,0 +1,128 @@
+package nodeagent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/pcidevices/pkg/util/fakeclients"
+)
+
+var (
+	heartbeatTime = metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	node1         = &v1beta1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1",
+		},
+		Status: v1beta1.NodeStatus{
+			LastHeartbeatTime: &heartbeatTime,
+		},
+	}
+	pcidevice1 = &v1beta1.PCIDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1-000008000",
+			Labels: map[string]string{
+				v1beta1.NodeKeyName: node1.Name,
+			},
+		},
+	}
+	pcidevice2 = &v1beta1.PCIDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node2-000008000",
+			Labels: map[string]string{
+				v1beta1.NodeKeyName: "node2",
+			},
+		},
+	}
+	pcideviceclaim1 = &v1beta1.PCIDeviceClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1-000008000",
+		},
+		Spec: v1beta1.PCIDeviceClaimSpec{
+			NodeName: node1.Name,
+		},
+	}
+	usbDevice1 = &v1beta1.USBDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1-001002",
+			Labels: map[string]string{
+				v1beta1.NodeKeyName: node1.Name,
+			},
+		},
+	}
+	usbDeviceClaim1 = &v1beta1.USBDeviceClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1-001002",
+		},
+		Status: v1beta1.USBDeviceClaimStatus{
+			NodeName: node1.Name,
+		},
+	}
+)
+
+func Test_OnNodeChange(t *testing.T) {
+	assert := require.New(t)
+	client := fake.NewSimpleClientset(node1, pcidevice1, pcidevice2, pcideviceclaim1, usbDevice1, usbDeviceClaim1)
+
+	now := heartbeatTime.Add(time.Minute)
+	var requeue time.Duration
+	h := &Handler{
+		nodeClient:     fakeclients.NodeDevicesClient(client.DevicesV1beta1().Nodes),
+		enqueueAfter:   func(_ string, duration time.Duration) { requeue = duration },
+		pdClient:       fakeclients.PCIDevicesClient(client.DevicesV1beta1().PCIDevices),
+		pdCache:        fakeclients.PCIDevicesCache(client.DevicesV1beta1().PCIDevices),
+		pdcClient:      fakeclients.PCIDeviceClaimsClient(client.DevicesV1beta1().PCIDeviceClaims),
+		pdcCache:       fakeclients.PCIDeviceClaimsCache(client.DevicesV1beta1().PCIDeviceClaims),
+		usbClient:      fakeclients.USBDevicesClient(client.DevicesV1beta1().USBDevices),
+		usbCache:       fakeclients.USBDeviceCache(client.DevicesV1beta1().USBDevices),
+		usbClaimClient: fakeclients.USBDeviceClaimsClient(client.DevicesV1beta1().USBDeviceClaims),
+		usbClaimCache:  fakeclients.USBDeviceClaimsCache(client.DevicesV1beta1().USBDeviceClaims),
+		now:            func() time.Time { return now },
+	}
+
+	// agent is reporting, devices are flagged as ready and the node is checked again once the heartbeat would become stale
+	node, err := h.OnNodeChange(node1.Name, node1)
+	assert.NoError(err, "expected no error while reconciling node")
+	assert.Equal(staleTimeout-time.Minute, requeue)
+	assert.True(meta.IsStatusConditionTrue(node.Status.Conditions, v1beta1.ConditionNodeAgentReady))
+
+	// agent has stopped reporting, devices and claims on the node are flagged as unknown
+	now = heartbeatTime.Add(staleTimeout + time.Second)
+	node, err = h.OnNodeChange(node.Name, node)
+	assert.NoError(err, "expected no error while reconciling node")
+	assert.Equal(metav1.ConditionUnknown, meta.FindStatusCondition(node.Status.Conditions, v1beta1.ConditionNodeAgentReady).Status)
+
+	pd, err := client.DevicesV1beta1().PCIDevices().Get(context.TODO(), pcidevice1.Name, metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal(metav1.ConditionUnknown, meta.FindStatusCondition(pd.Status.Conditions, v1beta1.ConditionNodeAgentReady).Status)
+	pdc, err := client.DevicesV1beta1().PCIDeviceClaims().Get(context.TODO(), pcideviceclaim1.Name, metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal(metav1.ConditionUnknown, meta.FindStatusCondition(pdc.Status.Conditions, v1beta1.ConditionNodeAgentReady).Status)
+	usb, err := client.DevicesV1beta1().USBDevices().Get(context.TODO(), usbDevice1.Name, metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal(metav1.ConditionUnknown, meta.FindStatusCondition(usb.Status.Conditions, v1beta1.ConditionNodeAgentReady).Status)
+	usbClaim, err := client.DevicesV1beta1().USBDeviceClaims().Get(context.TODO(), usbDeviceClaim1.Name, metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal(metav1.ConditionUnknown, meta.FindStatusCondition(usbClaim.Status.Conditions, v1beta1.ConditionNodeAgentReady).Status)
+
+	other, err := client.DevicesV1beta1().PCIDevices().Get(context.TODO(), pcidevice2.Name, metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Nil(meta.FindStatusCondition(other.Status.Conditions, v1beta1.ConditionNodeAgentReady), "expected devices on other nodes to be left as is")
+
+	// agent resumes reporting
+	lastHeartbeat := metav1.NewTime(heartbeatTime.Add(staleTimeout + time.Minute))
+	node.Status.LastHeartbeatTime = &lastHeartbeat
+	now = lastHeartbeat.Time
+	node, err = h.OnNodeChange(node.Name, node)
+	assert.NoError(err, "expected no error while reconciling node")
+	assert.True(meta.IsStatusConditionTrue(node.Status.Conditions, v1beta1.ConditionNodeAgentReady))
+	pd, err = client.DevicesV1beta1().PCIDevices().Get(context.TODO(), pcidevice1.Name, metav1.GetOptions{})
+	assert.NoError(err)
+	assert.True(meta.IsStatusConditionTrue(pd.Status.Conditions, v1beta1.ConditionNodeAgentReady))
+}
@@ -27,6 +27,7 @@ type Handler struct {
 	usbDeviceClaimClient      v1beta1.USBDeviceClaimClient
 	usbDevicesClient          v1beta1.USBDeviceClient
 	nodeDevicesClient         v1beta1.NodeClient
+	iommuGroupsClient         v1beta1.IOMMUGroupClient
 
 	nodeClient corecontrollers.NodeController
 }
@@ -45,6 +46,7 @@ func (h *Handler) OnRemove(_ string, node *v1.Node) (*v1.Node, error) {
 		h.removeUSBDevicesOnNode,
 		h.removeSRIOVGPUDevicesOnNode,
 		h.removeVGPUDevicesOnNode,
+		h.removeIOMMUGroupsOnNode,
 	}
 
 	for _, fn := range cleanupFuncs {
@@ -66,6 +68,7 @@ func Register(ctx context.Context, management *config.FactoryManager) error {
 	usbDeviceClaimClient := management.DeviceFactory.Devices().V1beta1().USBDeviceClaim()
 	usbDevicesClient := management.DeviceFactory.Devices().V1beta1().USBDevice()
 	nodeDevicesClient := management.DeviceFactory.Devices().V1beta1().Node()
+	iommuGroupsClient := management.DeviceFactory.Devices().V1beta1().IOMMUGroup()
 
 	handler := &Handler{
 		pdcClient:                 pdcClient,
@@ -77,6 +80,7 @@ func Register(ctx context.Context, management *config.FactoryManager) error {
 		usbDeviceClaimClient:      usbDeviceClaimClient,
 		usbDevicesClient:          usbDevicesClient,
 		nodeDevicesClient:         nodeDevicesClient,
+		iommuGroupsClient:         iommuGroupsClient,
 	}
 	nodeClient.OnRemove(ctx, "node-remove", handler.OnRemove)
 	return nil
@@ -255,6 +259,25 @@ func (h *Handler) removeVGPUDevicesOnNode(node *v1.Node) error {
 	return nil
 }
 
+func (h *Handler) removeIOMMUGroupsOnNode(node *v1.Node) error {
+	selector := fmt.Sprintf("nodename=%s", node.Name)
+	iommuGroups, err := h.iommuGroupsClient.List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		logrus.Errorf("error listing iommuGroups for node %s: %v", node.Name, err)
+		return err
+	}
+
+	for _, iommuGroup := range iommuGroups.Items {
+		err = h.iommuGroupsClient.Delete(iommuGroup.Name, &metav1.DeleteOptions{})
+		if err != nil {
+			logrus.Errorf("error deleting iommuGroup %s: %v", iommuGroup.Name, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (h *Handler) removeNodeObject(node *v1.Node) error {
 	// delete the node.devices object used to reconcile /sys fs objects
 	err := h.nodeDevicesClient.Delete(node.Name, &metav1.DeleteOptions{})
@@ -80,7 +80,16 @@ var (
 		},
 	}
 
-	fakeClient = fake.NewSimpleClientset(vgpuDevice1, usbDevice1, usbDeviceClaim1, pcidevice1, pcideviceclaim1, sriovNetworkDevice1)
+	iommuGroup1 = &v1beta1.IOMMUGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: v1beta1.IOMMUGroupNameForHostname(1, node1.Name),
+			Labels: map[string]string{
+				"nodename": node1.Name,
+			},
+		},
+	}
+
+	fakeClient = fake.NewSimpleClientset(vgpuDevice1, usbDevice1, usbDeviceClaim1, pcidevice1, pcideviceclaim1, sriovNetworkDevice1, iommuGroup1)
 )
 
 // check deletion is not blocked if there are no device resources for specific node
@@ -94,6 +103,7 @@ func Test_ValidateRemove(t *testing.T) {
 	usbDeviceClaimClient := fakeclients.USBDeviceClaimsClient(fakeClient.DevicesV1beta1().USBDeviceClaims)
 	usbDeviceClient := fakeclients.USBDevicesClient(fakeClient.DevicesV1beta1().USBDevices)
 	nodeDevicesClient := fakeclients.NodeDevicesClient(fakeClient.DevicesV1beta1().Nodes)
+	iommuGroupsClient := fakeclients.IOMMUGroupsClient(fakeClient.DevicesV1beta1().IOMMUGroups)
 
 	h := &Handler{
 		pdcClient:                 pdcClient,
@@ -104,6 +114,7 @@ func Test_ValidateRemove(t *testing.T) {
 		usbDeviceClaimClient:      usbDeviceClaimClient,
 		usbDevicesClient:          usbDeviceClient,
 		nodeDevicesClient:         nodeDevicesClient,
+		iommuGroupsClient:         iommuGroupsClient,
 	}
 
 	// emulate deletion of node1
@@ -118,6 +129,9 @@ func Test_ValidateRemove(t *testing.T) {
 	usbDeviceClaimList, err := usbDeviceClaimClient.List(metav1.ListOptions{})
 	assert.NoError(err, "expected no error while listing usbdeviceclaims")
 	assert.Len(usbDeviceClaimList.Items, 0, "expected to find no usbdeviceclaims")
+	iommuGroupList, err := iommuGroupsClient.List(metav1.ListOptions{})
+	assert.NoError(err, "expected no error while listing iommugroups")
+	assert.Len(iommuGroupList.Items, 0, "expected to find no iommugroups")
 
 	// emulate deletion of node2
 	// no objects on node2 should be cleaned up
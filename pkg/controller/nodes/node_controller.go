@@ -5,13 +5,19 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"slices"
+	"sync/atomic"
 	"time"
 
 	"github.com/jaypipes/ghw"
 	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	"github.com/rancher/wrangler/v3/pkg/relatedresource"
+	"github.com/sirupsen/logrus"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"kubevirt.io/client-go/kubecli"
 
 	ctlnetworkv1beta1 "github.com/harvester/harvester-network-controller/pkg/generated/controllers/network.harvesterhci.io/v1beta1"
@@ -19,15 +25,29 @@ import (
 	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
 	"github.com/harvester/pcidevices/pkg/config"
 	"github.com/harvester/pcidevices/pkg/controller/gpudevice"
+	"github.com/harvester/pcidevices/pkg/controller/iommugroup"
 	"github.com/harvester/pcidevices/pkg/controller/pcidevice"
 	"github.com/harvester/pcidevices/pkg/controller/sriovdevice"
 	"github.com/harvester/pcidevices/pkg/controller/usbdevice"
 	ctl "github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/util/agentstatus"
+	"github.com/harvester/pcidevices/pkg/util/discoverypolicy"
 	"github.com/harvester/pcidevices/pkg/util/nichelper"
+	"github.com/harvester/pcidevices/pkg/util/resourcenamerule"
+	"github.com/harvester/pcidevices/pkg/util/uevent"
+	"github.com/harvester/pcidevices/pkg/util/vfiohelper"
+	"github.com/harvester/pcidevices/pkg/version"
 )
 
 const (
 	defaultRequeuePeriod = 30 * time.Second
+	// hotplugResyncPeriod is used for periodic full rescans once pci uevents are being watched.
+	// the rescan is only a safety net to catch dropped events
+	hotplugResyncPeriod = 5 * time.Minute
+	// minRequeuePeriod is the shortest interval between full rescans which can be configured in the NodeSpec
+	minRequeuePeriod = 10 * time.Second
+	// heartbeatPeriod is the interval at which the agent refreshes the heartbeat on the Node status
+	heartbeatPeriod = time.Minute
 )
 
 type handler struct {
@@ -49,6 +69,18 @@ type handler struct {
 	usbClaimCtl                ctl.USBDeviceClaimController
 	virtClient                 kubecli.KubevirtClient
 	migConfigurationController ctl.MigConfigurationController
+	iommuGroupCtl              ctl.IOMMUGroupController
+	pciInfo                    *ghw.PCIInfo
+	watchingPCIEvents          atomic.Bool
+	scans                      *agentstatus.Recorder
+	discovery                  *discoverypolicy.Evaluator
+	resourceNames              *resourcenamerule.Resolver
+	// forceRescan is set when the DeviceDiscoveryPolicies, ResourceNameRules or DevicePools change, to rescan devices without
+	// waiting for the requeue period
+	forceRescan atomic.Bool
+	// lastScan and observedGeneration are used to skip rescans when only the Node status has changed
+	lastScan           time.Time
+	observedGeneration int64
 }
 
 const (
@@ -69,6 +101,10 @@ func Register(ctx context.Context, management *config.FactoryManager) error {
 	virtClient := management.KubevirtClient
 	nodeName := os.Getenv(v1beta1.NodeEnvVarName)
 	migConfigurationController := management.DeviceFactory.Devices().V1beta1().MigConfiguration()
+	iommuGroupCtl := management.DeviceFactory.Devices().V1beta1().IOMMUGroup()
+	policyCtl := management.DeviceFactory.Devices().V1beta1().DeviceDiscoveryPolicy()
+	ruleCtl := management.DeviceFactory.Devices().V1beta1().ResourceNameRule()
+	poolCtl := management.DeviceFactory.Devices().V1beta1().DevicePool()
 
 	h := &handler{
 		ctx:                        ctx,
@@ -89,66 +125,341 @@ func Register(ctx context.Context, management *config.FactoryManager) error {
 		usbClaimCtl:                usbClaimCtl,
 		virtClient:                 virtClient,
 		migConfigurationController: migConfigurationController,
+		iommuGroupCtl:              iommuGroupCtl,
+		scans:                      agentstatus.Default,
+		discovery:                  discoverypolicy.NewEvaluator(nodeName, coreNodeCtl.Cache(), policyCtl.Cache()),
+		resourceNames:              resourcenamerule.NewResolver(ruleCtl.Cache(), poolCtl.Cache()),
 	}
 
 	nodeCtl.OnChange(ctx, reconcilePCIDevices, h.reconcileNodeDevices)
+	relatedresource.WatchClusterScoped(ctx, "DeviceDiscoveryPolicyToNode", h.OnDiscoveryConfigChange, nodeCtl, policyCtl)
+	relatedresource.WatchClusterScoped(ctx, "ResourceNameRuleToNode", h.OnDiscoveryConfigChange, nodeCtl, ruleCtl)
+	relatedresource.WatchClusterScoped(ctx, "DevicePoolToNode", h.OnDiscoveryConfigChange, nodeCtl, poolCtl)
+	if err := h.watchPCIEvents(ctx); err != nil {
+		logrus.Warnf("unable to watch pci uevents, falling back to periodic rescans: %v", err)
+	}
+	go wait.UntilWithContext(ctx, func(_ context.Context) {
+		if err := h.heartbeat(); err != nil {
+			logrus.Warnf("error updating heartbeat for node %s: %v", h.nodeName, err)
+		}
+	}, heartbeatPeriod)
 	return nil
 }
 
+// OnDiscoveryConfigChange rescans the devices on the node when a DeviceDiscoveryPolicy, ResourceNameRule or DevicePool
+// is changed or removed. Policies may select the node by its labels, so all changes trigger a rescan
+func (h *handler) OnDiscoveryConfigChange(_ string, _ string, _ runtime.Object) ([]relatedresource.Key, error) {
+	h.forceRescan.Store(true)
+	return []relatedresource.Key{relatedresource.NewKey("", h.nodeName)}, nil
+}
+
+// heartbeat refreshes the heartbeat on the Node status, which is used by the cluster to identify agents which are no longer running
+func (h *handler) heartbeat() error {
+	node, err := h.nodeCtl.Cache().Get(h.nodeName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	nodeCopy := node.DeepCopy()
+	now := metav1.Now()
+	nodeCopy.Status.LastHeartbeatTime = &now
+	nodeCopy.Status.AgentVersion = version.Version
+	_, err = h.nodeCtl.UpdateStatus(nodeCopy)
+	return err
+}
+
+// watchPCIEvents listens for kernel uevents from the pci subsystem and reconciles the affected
+// PCIDevice objects as devices are hot added, removed or change drivers
+func (h *handler) watchPCIEvents(ctx context.Context) error {
+	events, err := uevent.Listen(ctx, uevent.SubsystemPCI)
+	if err != nil {
+		return err
+	}
+
+	h.watchingPCIEvents.Store(true)
+	go func() {
+		defer h.watchingPCIEvents.Store(false)
+		for e := range events {
+			if err := h.handlePCIEvent(e); err != nil {
+				logrus.Errorf("error handling pci uevent %s for %s: %v", e.Action, e.PCIAddress(), err)
+			}
+		}
+	}()
+	return nil
+}
+
+func (h *handler) handlePCIEvent(e *uevent.Event) error {
+	switch e.Action {
+	case uevent.ActionAdd, uevent.ActionRemove, uevent.ActionChange, uevent.ActionBind, uevent.ActionUnbind:
+	default:
+		return nil
+	}
+
+	address := e.PCIAddress()
+	if address == "" {
+		return nil
+	}
+
+	logrus.Debugf("received pci uevent %s for device %s", e.Action, address)
+	// the pci info is only used to lookup individual devices so the pcidb does not need to be reloaded
+	// for each event. GetDevice serves cached devices first, so the device list is dropped to ensure
+	// devices are always read fresh from sysfs
+	if h.pciInfo == nil {
+		pci, err := ghw.PCI()
+		if err != nil {
+			return fmt.Errorf("error listing pcidevices: %v", err)
+		}
+		pci.Devices = nil
+		h.pciInfo = pci
+	}
+
+	node, err := h.nodeCtl.Cache().Get(h.nodeName)
+	if err != nil {
+		return fmt.Errorf("error looking up node %s: %v", h.nodeName, err)
+	}
+	if node.Spec.SubsystemDisabled(v1beta1.SubsystemPCI) {
+		return nil
+	}
+
+	var skippedDevices []string
+	if dev := h.pciInfo.GetDevice(address); dev != nil && pcidevice.IsSkippedDevice(dev, node.Spec.SkipDevices) {
+		skippedDevices = append(skippedDevices, address)
+	}
+	skipAddresses, err := h.skipAddresses(h.pciInfo, node, skippedDevices)
+	if err != nil {
+		return err
+	}
+
+	pciHandler := pcidevice.NewHandler(h.pciDeviceCtl, h.pciDeviceCache, h.pciInfo, h.coreNodeCache, h.vlanConfigCache, h.sriovNetworkDeviceCache, skipAddresses, h.discovery, h.resourceNames)
+	return pciHandler.ReconcilePCIDevice(h.nodeName, address)
+}
+
+// requeuePeriod returns the interval between full rescans of all devices on the node
+func (h *handler) requeuePeriod(node *v1beta1.Node) time.Duration {
+	if h.watchingPCIEvents.Load() {
+		return intervalOrDefault(node.Spec.HotplugResyncInterval, hotplugResyncPeriod)
+	}
+	return intervalOrDefault(node.Spec.ReconcileInterval, defaultRequeuePeriod)
+}
+
+// intervalOrDefault returns interval if it is set, intervals are limited to minRequeuePeriod to avoid constant rescans
+func intervalOrDefault(interval *metav1.Duration, defaultInterval time.Duration) time.Duration {
+	if interval == nil || interval.Duration <= 0 {
+		return defaultInterval
+	}
+	if interval.Duration < minRequeuePeriod {
+		return minRequeuePeriod
+	}
+	return interval.Duration
+}
+
 func (h *handler) reconcileNodeDevices(name string, node *v1beta1.Node) (*v1beta1.Node, error) {
 	if node == nil || node.DeletionTimestamp != nil || node.Name != h.nodeName {
 		return node, nil
 	}
 
-	pci, err := ghw.PCI()
+	// status updates from the agent retrigger the handler, devices are only rescanned once the requeue period
+	// has elapsed or the spec has changed. A rescan is already queued for the end of the period
+	if !h.forceRescan.Swap(false) && node.Generation == h.observedGeneration && time.Since(h.lastScan) < h.requeuePeriod(node) {
+		return node, nil
+	}
+
+	scans := []struct {
+		subsystem v1beta1.NodeSubsystem
+		scan      func(*v1beta1.Node) error
+	}{
+		{subsystem: v1beta1.SubsystemPCI, scan: h.scanPCIDevices},
+		{subsystem: v1beta1.SubsystemUSB, scan: h.scanUSBDevices},
+		{subsystem: v1beta1.SubsystemSRIOV, scan: h.scanSRIOVDevices},
+		{subsystem: v1beta1.SubsystemVGPU, scan: h.scanVGPUDevices},
+	}
+
+	for _, v := range scans {
+		if node.Spec.SubsystemDisabled(v.subsystem) {
+			logrus.Debugf("skipping %s scan as it is disabled on node %s", v.subsystem, h.nodeName)
+			continue
+		}
+		if err := v.scan(node); err != nil {
+			return nil, h.scanFailed(node, v.subsystem, err)
+		}
+		h.scans.Record(v.subsystem, nil)
+	}
+
+	err := checkAndUpdateNodeLabels(h.nodeName, h.coreNodeCtl.Cache(), h.coreNodeCtl, h.sriovGPUController.Cache())
+	if err != nil {
+		return nil, fmt.Errorf("error updating node labels for node %s: %v", h.nodeName, err)
+	}
+
+	node, err = h.updateNodeStatus(node)
 	if err != nil {
-		return node, fmt.Errorf("error listing pcidevices: %v", err)
+		return nil, fmt.Errorf("error updating status for node %s: %v", h.nodeName, err)
 	}
 
+	h.lastScan = time.Now()
+	h.observedGeneration = node.Generation
+	h.nodeCtl.EnqueueAfter(name, h.requeuePeriod(node))
+	return node, err
+}
+
+// skipAddresses returns the pci addresses which are not discovered, this includes the nics managed by harvester
+// and devices skipped in the NodeSpec, either by address or by skippedDevices matching the skip filters
+func (h *handler) skipAddresses(pci *ghw.PCIInfo, node *v1beta1.Node, skippedDevices []string) ([]string, error) {
 	skipAddresses, err := nichelper.IdentifyHarvesterManagedNIC(h.nodeName, h.coreNodeCache, h.vlanConfigCache)
 	if err != nil {
-		return node, fmt.Errorf("error identifying management nics: %v", err)
+		return nil, fmt.Errorf("error identifying management nics: %v", err)
+	}
+
+	specSkipped, err := unusedDevices(pci, h.nodeName, append(slices.Clone(node.Spec.SkipAddresses), skippedDevices...), h.pciDeviceClaimController.Cache())
+	if err != nil {
+		return nil, err
+	}
+	return append(skipAddresses, specSkipped...), nil
+}
+
+// unusedDevices returns the addresses which are neither bound to a vfio driver nor claimed. Devices in use for
+// passthrough are never skipped, as removing them would orphan the claim and the device plugin serving the device
+func unusedDevices(pci *ghw.PCIInfo, nodeName string, addresses []string, pdcCache ctl.PCIDeviceClaimCache) ([]string, error) {
+	var result []string
+	for _, address := range addresses {
+		if dev := pci.GetDevice(address); dev != nil && v1beta1.IsVFIODriver(dev.Driver) {
+			logrus.Debugf("not skipping device %s as it is bound to %s", address, dev.Driver)
+			continue
+		}
+		_, err := pdcCache.Get(v1beta1.PCIDeviceNameForHostname(address, nodeName))
+		if err == nil {
+			logrus.Debugf("not skipping device %s as it is claimed", address)
+			continue
+		}
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("error looking up pcideviceclaim for device %s: %w", address, err)
+		}
+		result = append(result, address)
+	}
+	return result, nil
+}
+
+func (h *handler) scanPCIDevices(node *v1beta1.Node) error {
+	pci, err := ghw.PCI()
+	if err != nil {
+		return fmt.Errorf("error listing pcidevices: %v", err)
+	}
+
+	skipAddresses, err := h.skipAddresses(pci, node, pcidevice.IdentifySkippedDevices(pci, node.Spec.SkipDevices))
+	if err != nil {
+		return err
 	}
 
 	pciBridgeAddresses := pcidevice.IdentifyPCIBridgeDevices(pci)
 	skipAddresses = append(skipAddresses, pciBridgeAddresses...)
 
-	pciHandler := pcidevice.NewHandler(h.pciDeviceCtl, pci, h.coreNodeCache, h.vlanConfigCache, h.sriovNetworkDeviceCache, skipAddresses)
+	pciHandler := pcidevice.NewHandler(h.pciDeviceCtl, h.pciDeviceCache, pci, h.coreNodeCache, h.vlanConfigCache, h.sriovNetworkDeviceCache, skipAddresses, h.discovery, h.resourceNames)
 	err = pciHandler.ReconcilePCIDevices(h.nodeName)
 	if err != nil {
-		return nil, fmt.Errorf("error reconciling pcidevices for node %s: %v", h.nodeName, err)
+		return fmt.Errorf("error reconciling pcidevices for node %s: %v", h.nodeName, err)
 	}
 
-	usbHandler := usbdevice.NewHandler(h.usbCtl, h.usbClaimCtl, h.usbCtl.Cache(), h.usbClaimCtl.Cache())
-	err = usbHandler.Reconcile()
+	iommuGroupHandler := iommugroup.NewHandler(h.iommuGroupCtl, h.iommuGroupCtl.Cache(), pci, skipAddresses)
+	err = iommuGroupHandler.ReconcileIOMMUGroups(h.nodeName)
 	if err != nil {
-		return nil, fmt.Errorf("error reconciling usbdevices for node %s: %v", h.nodeName, err)
+		return fmt.Errorf("error reconciling iommugroups for node %s: %v", h.nodeName, err)
 	}
+	return nil
+}
+
+func (h *handler) scanUSBDevices(_ *v1beta1.Node) error {
+	usbHandler := usbdevice.NewHandler(h.usbCtl, h.usbClaimCtl, h.usbCtl.Cache(), h.usbClaimCtl.Cache(), h.nodeCtl.Cache(), h.discovery)
+	if err := usbHandler.Reconcile(); err != nil {
+		return fmt.Errorf("error reconciling usbdevices for node %s: %v", h.nodeName, err)
+	}
+	return nil
+}
 
-	// additional steps for sriov reconcile
+func (h *handler) scanSRIOVDevices(_ *v1beta1.Node) error {
 	sriovHelper := sriovdevice.NewHandler(h.ctx, h.sriovCache, h.sriovClient, h.nodeName, h.coreNodeCache, h.vlanConfigCache)
-	err = sriovHelper.SetupSriovDevices()
-	if err != nil {
-		return nil, fmt.Errorf("error setting up sriov devices for node %s: %v", h.nodeName, err)
+	if err := sriovHelper.SetupSriovDevices(); err != nil {
+		return fmt.Errorf("error setting up sriov devices for node %s: %v", h.nodeName, err)
+	}
+
+	if err := h.gpuHandler().SetupSRIOVGPUDevices(); err != nil {
+		return fmt.Errorf("error setting up SRIOV GPU devices for node %s: %v", h.nodeName, err)
+	}
+	return nil
+}
+
+func (h *handler) scanVGPUDevices(_ *v1beta1.Node) error {
+	if err := h.gpuHandler().SetupVGPUDevices(); err != nil {
+		return fmt.Errorf("error setting VGPU devices for node %s: %v", h.nodeName, err)
 	}
+	return nil
+}
+
+func (h *handler) gpuHandler() *gpudevice.Handler {
 	gpuhelper, _ := gpudevice.NewHandler(h.ctx, h.sriovGPUController, h.vGPUController, h.pciDeviceClaimController, h.pciDeviceCtl, h.migConfigurationController, nil, nil, nil)
-	err = gpuhelper.SetupSRIOVGPUDevices()
-	if err != nil {
-		return nil, fmt.Errorf("error setting up SRIOV GPU devices for node %s: %v", h.nodeName, err)
+	return gpuhelper
+}
+
+// scanFailed records the failed scan of subsystem, and publishes it on the Node status before err is returned
+func (h *handler) scanFailed(node *v1beta1.Node, subsystem v1beta1.NodeSubsystem, err error) error {
+	h.scans.Record(subsystem, err)
+	nodeCopy := node.DeepCopy()
+	nodeCopy.Status.Subsystems = h.subsystemStatus(node)
+	if _, statusErr := h.nodeCtl.UpdateStatus(nodeCopy); statusErr != nil {
+		logrus.Warnf("error reporting failed %s scan for node %s: %v", subsystem, h.nodeName, statusErr)
 	}
+	return err
+}
 
-	err = gpuhelper.SetupVGPUDevices()
-	if err != nil {
-		return nil, fmt.Errorf("error setting VGPU devices for node %s: %v", h.nodeName, err)
+// subsystemStatus returns the outcome of the scans of each subsystem, flagging subsystems disabled in the NodeSpec
+func (h *handler) subsystemStatus(node *v1beta1.Node) []v1beta1.NodeSubsystemStatus {
+	subsystems := h.scans.Status(node.Status.Subsystems)
+	for i := range subsystems {
+		subsystems[i].Disabled = node.Spec.SubsystemDisabled(subsystems[i].Name)
+	}
+	return subsystems
+}
+
+// capabilities returns the optional features supported by the agent
+func (h *handler) capabilities(vfioDrivers []string) []v1beta1.NodeCapability {
+	capabilities := []v1beta1.NodeCapability{v1beta1.CapabilityDeviceReset, v1beta1.CapabilityIOMMUGroupClaims}
+	if h.watchingPCIEvents.Load() {
+		capabilities = append(capabilities, v1beta1.CapabilityPCIHotplug)
+	}
+	for _, v := range vfioDrivers {
+		if v != v1beta1.VFIOPCIDriver {
+			capabilities = append(capabilities, v1beta1.CapabilityVFIOVariantDrivers)
+			break
+		}
 	}
+	return capabilities
+}
 
-	err = checkAndUpdateNodeLabels(h.nodeName, h.coreNodeCtl.Cache(), h.coreNodeCtl, h.sriovGPUController.Cache())
+// updateNodeStatus publishes the vfio drivers available on the node, which are used to validate claims for variant drivers,
+// along with the readiness of the node for pci passthrough and the status of the agent
+func (h *handler) updateNodeStatus(node *v1beta1.Node) (*v1beta1.Node, error) {
+	drivers, err := vfiohelper.AvailableDrivers()
 	if err != nil {
-		return nil, fmt.Errorf("error updating node labels for node %s: %v", h.nodeName, err)
+		return node, err
 	}
 
-	h.nodeCtl.EnqueueAfter(name, defaultRequeuePeriod)
-	return node, err
+	checks := generateReadinessChecks(defaultReadinessPaths, drivers)
+	if err := updateReadinessLabels(h.nodeName, h.coreNodeCache, h.coreNodeCtl, checks); err != nil {
+		return node, fmt.Errorf("error updating readiness labels: %w", err)
+	}
+
+	nodeCopy := node.DeepCopy()
+	nodeCopy.Status.VFIODrivers = drivers
+	nodeCopy.Status.Readiness = checks
+	nodeCopy.Status.PassthroughReady = passthroughReady(checks)
+	nodeCopy.Status.AgentVersion = version.Version
+	nodeCopy.Status.Capabilities = h.capabilities(drivers)
+	nodeCopy.Status.Subsystems = h.subsystemStatus(node)
+	if reflect.DeepEqual(node.Status, nodeCopy.Status) {
+		return node, nil
+	}
+	return h.nodeCtl.UpdateStatus(nodeCopy)
 }
 
 func SetupNodeObjects(nodeCtl ctl.NodeController) error {
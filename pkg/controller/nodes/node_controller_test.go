@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/jaypipes/ghw"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -85,3 +87,30 @@ func Test_checkAndUpdateNodeLabels(t *testing.T) {
 	}
 
 }
+
+func Test_intervalOrDefault(t *testing.T) {
+	assert := require.New(t)
+	assert.Equal(defaultRequeuePeriod, intervalOrDefault(nil, defaultRequeuePeriod))
+	assert.Equal(time.Minute, intervalOrDefault(&metav1.Duration{Duration: time.Minute}, defaultRequeuePeriod))
+	assert.Equal(minRequeuePeriod, intervalOrDefault(&metav1.Duration{Duration: time.Second}, defaultRequeuePeriod), "expected short intervals to be limited")
+}
+
+func Test_unusedDevices(t *testing.T) {
+	assert := require.New(t)
+	pci, err := ghw.PCI(ghw.WithSnapshot(ghw.SnapshotOptions{
+		Path: "../../../tests/snapshots/linux-amd64-e147d239df014921c6cbb49fbc3d6c41.tar.gz",
+	}))
+	assert.NoError(err, "expected no error during snapshot loading")
+	pci.GetDevice("0000:08:00.0").Driver = "vfio-pci"
+
+	claim := &v1beta1.PCIDeviceClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1-000004000",
+		},
+	}
+	client := fakev1beta1.NewSimpleClientset(claim)
+	addresses, err := unusedDevices(pci, "node1", []string{"0000:08:00.0", "0000:04:00.0", "0000:04:00.1"},
+		fakeclients.PCIDeviceClaimsCache(client.DevicesV1beta1().PCIDeviceClaims))
+	assert.NoError(err)
+	assert.Equal([]string{"0000:04:00.1"}, addresses, "expected vfio bound and claimed devices to not be skipped")
+}
@@ -0,0 +1,166 @@
+package nodes
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+)
+
+// readinessPaths are the host files inspected by the readiness checks
+type readinessPaths struct {
+	cmdline               string
+	iommuGroups           string
+	interrupts            string
+	allowUnsafeInterrupts string
+}
+
+var defaultReadinessPaths = readinessPaths{
+	cmdline:               "/proc/cmdline",
+	iommuGroups:           "/sys/kernel/iommu_groups",
+	interrupts:            "/proc/interrupts",
+	allowUnsafeInterrupts: "/sys/module/vfio_iommu_type1/parameters/allow_unsafe_interrupts",
+}
+
+// generateReadinessChecks runs the preflight checks for pci passthrough on the node. vfioDrivers are the
+// vfio drivers available on the node
+func generateReadinessChecks(paths readinessPaths, vfioDrivers []string) []v1beta1.NodeReadinessCheck {
+	// #nosec G304 No risk for path injection. Reading the kernel cmdline
+	contents, _ := os.ReadFile(paths.cmdline)
+	cmdline := strings.Fields(string(contents))
+
+	return []v1beta1.NodeReadinessCheck{
+		checkIOMMU(paths.iommuGroups, cmdline),
+		checkInterruptRemapping(paths.interrupts, paths.allowUnsafeInterrupts),
+		checkVFIOModules(vfioDrivers),
+		checkACSOverride(cmdline),
+	}
+}
+
+func checkIOMMU(iommuGroupsPath string, cmdline []string) v1beta1.NodeReadinessCheck {
+	check := v1beta1.NodeReadinessCheck{
+		Name: v1beta1.ReadinessIOMMU,
+	}
+
+	groups, err := os.ReadDir(iommuGroupsPath)
+	if err != nil || len(groups) == 0 {
+		check.Result = v1beta1.ReadinessFailed
+		check.Message = "no iommu groups found, enable VT-d/AMD-Vi in the firmware and set intel_iommu=on or amd_iommu=on on the kernel cmdline"
+		return check
+	}
+
+	check.Result = v1beta1.ReadinessPassed
+	check.Message = fmt.Sprintf("%d iommu groups found", len(groups))
+	if param := cmdlineParam(cmdline, "iommu", "intel_iommu", "amd_iommu"); param != "" {
+		check.Message = fmt.Sprintf("%s, kernel cmdline has %s", check.Message, param)
+	}
+	return check
+}
+
+func checkInterruptRemapping(interruptsPath, allowUnsafeInterruptsPath string) v1beta1.NodeReadinessCheck {
+	check := v1beta1.NodeReadinessCheck{
+		Name: v1beta1.ReadinessInterruptRemapping,
+	}
+
+	// #nosec G304 No risk for path injection. Reading the interrupts of the host
+	interrupts, _ := os.ReadFile(interruptsPath)
+	if strings.Contains(string(interrupts), "IR-") {
+		check.Result = v1beta1.ReadinessPassed
+		check.Message = "interrupt remapping is enabled"
+		return check
+	}
+
+	// #nosec G304 No risk for path injection. Reading a static module parameter
+	allowUnsafe, _ := os.ReadFile(allowUnsafeInterruptsPath)
+	if strings.TrimSpace(string(allowUnsafe)) == "Y" {
+		check.Result = v1beta1.ReadinessWarning
+		check.Message = "interrupt remapping is not enabled, devices are assigned with allow_unsafe_interrupts"
+		return check
+	}
+
+	check.Result = v1beta1.ReadinessFailed
+	check.Message = "interrupt remapping is not enabled, vfio will refuse to assign devices"
+	return check
+}
+
+func checkVFIOModules(vfioDrivers []string) v1beta1.NodeReadinessCheck {
+	check := v1beta1.NodeReadinessCheck{
+		Name: v1beta1.ReadinessVFIOModules,
+	}
+
+	if !slices.Contains(vfioDrivers, v1beta1.VFIOPCIDriver) {
+		check.Result = v1beta1.ReadinessFailed
+		check.Message = "vfio-pci is not loaded and no module was found for the running kernel"
+		return check
+	}
+
+	check.Result = v1beta1.ReadinessPassed
+	check.Message = fmt.Sprintf("available drivers: %s", strings.Join(vfioDrivers, ", "))
+	return check
+}
+
+func checkACSOverride(cmdline []string) v1beta1.NodeReadinessCheck {
+	check := v1beta1.NodeReadinessCheck{
+		Name:   v1beta1.ReadinessACSOverride,
+		Result: v1beta1.ReadinessPassed,
+	}
+
+	if param := cmdlineParam(cmdline, "pcie_acs_override"); param != "" {
+		check.Result = v1beta1.ReadinessWarning
+		check.Message = fmt.Sprintf("kernel cmdline has %s, devices sharing a group may not be isolated from each other", param)
+	}
+	return check
+}
+
+// cmdlineParam returns the first parameter on the kernel cmdline matching one of keys
+func cmdlineParam(cmdline []string, keys ...string) string {
+	for _, param := range cmdline {
+		key, _, _ := strings.Cut(param, "=")
+		if slices.Contains(keys, key) {
+			return param
+		}
+	}
+	return ""
+}
+
+// passthroughReady is true when no readiness check failed
+func passthroughReady(checks []v1beta1.NodeReadinessCheck) bool {
+	for _, v := range checks {
+		if v.Result == v1beta1.ReadinessFailed {
+			return false
+		}
+	}
+	return true
+}
+
+// updateReadinessLabels summarises the readiness checks as labels on the node
+func updateReadinessLabels(nodeName string, nodeCache ctlcorev1.NodeCache, nodeClient ctlcorev1.NodeClient, checks []v1beta1.NodeReadinessCheck) error {
+	nodeObj, err := nodeCache.Get(nodeName)
+	if err != nil {
+		return err
+	}
+
+	nodeCopy := nodeObj.DeepCopy()
+	if nodeCopy.Labels == nil {
+		nodeCopy.Labels = make(map[string]string)
+	}
+	nodeCopy.Labels[v1beta1.PassthroughReadyLabel] = fmt.Sprintf("%t", passthroughReady(checks))
+	for _, v := range checks {
+		nodeCopy.Labels[readinessLabel(v.Name)] = string(v.Result)
+	}
+
+	if reflect.DeepEqual(nodeObj.Labels, nodeCopy.Labels) {
+		return nil
+	}
+	_, err = nodeClient.Update(nodeCopy)
+	return err
+}
+
+func readinessLabel(name v1beta1.NodeReadinessCheckName) string {
+	return v1beta1.NodeReadinessLabelPrefix + strings.ToLower(string(name))
+}
@@ -0,0 +1,97 @@
+package nodes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/util/fakeclients"
+)
+
+func newReadinessPaths(t *testing.T, cmdline, interrupts string, groups int) readinessPaths {
+	t.Helper()
+	root := t.TempDir()
+	paths := readinessPaths{
+		cmdline:               filepath.Join(root, "cmdline"),
+		iommuGroups:           filepath.Join(root, "iommu_groups"),
+		interrupts:            filepath.Join(root, "interrupts"),
+		allowUnsafeInterrupts: filepath.Join(root, "allow_unsafe_interrupts"),
+	}
+	if err := os.WriteFile(paths.cmdline, []byte(cmdline), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(paths.interrupts, []byte(interrupts), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < groups; i++ {
+		if err := os.MkdirAll(filepath.Join(paths.iommuGroups, string(rune('0'+i))), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths
+}
+
+func readinessResults(checks []v1beta1.NodeReadinessCheck) map[v1beta1.NodeReadinessCheckName]v1beta1.NodeReadinessResult {
+	results := make(map[v1beta1.NodeReadinessCheckName]v1beta1.NodeReadinessResult)
+	for _, v := range checks {
+		results[v.Name] = v.Result
+	}
+	return results
+}
+
+func Test_generateReadinessChecks(t *testing.T) {
+	assert := require.New(t)
+
+	paths := newReadinessPaths(t, "BOOT_IMAGE=/vmlinuz intel_iommu=on iommu=pt\n", " 24:  0  IR-PCI-MSI 327680-edge  xhci_hcd\n", 3)
+	checks := generateReadinessChecks(paths, []string{v1beta1.VFIOPCIDriver})
+	assert.Equal(map[v1beta1.NodeReadinessCheckName]v1beta1.NodeReadinessResult{
+		v1beta1.ReadinessIOMMU:              v1beta1.ReadinessPassed,
+		v1beta1.ReadinessInterruptRemapping: v1beta1.ReadinessPassed,
+		v1beta1.ReadinessVFIOModules:        v1beta1.ReadinessPassed,
+		v1beta1.ReadinessACSOverride:        v1beta1.ReadinessPassed,
+	}, readinessResults(checks))
+	assert.True(passthroughReady(checks))
+
+	paths = newReadinessPaths(t, "BOOT_IMAGE=/vmlinuz pcie_acs_override=downstream,multifunction\n", " 24:  0  PCI-MSI 327680-edge  xhci_hcd\n", 0)
+	checks = generateReadinessChecks(paths, nil)
+	assert.Equal(map[v1beta1.NodeReadinessCheckName]v1beta1.NodeReadinessResult{
+		v1beta1.ReadinessIOMMU:              v1beta1.ReadinessFailed,
+		v1beta1.ReadinessInterruptRemapping: v1beta1.ReadinessFailed,
+		v1beta1.ReadinessVFIOModules:        v1beta1.ReadinessFailed,
+		v1beta1.ReadinessACSOverride:        v1beta1.ReadinessWarning,
+	}, readinessResults(checks))
+	assert.False(passthroughReady(checks))
+
+	assert.NoError(os.WriteFile(paths.allowUnsafeInterrupts, []byte("Y\n"), 0600))
+	checks = generateReadinessChecks(paths, nil)
+	assert.Equal(v1beta1.ReadinessWarning, readinessResults(checks)[v1beta1.ReadinessInterruptRemapping], "expected unsafe interrupts to be reported as a warning")
+}
+
+func Test_updateReadinessLabels(t *testing.T) {
+	assert := require.New(t)
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1",
+		},
+	}
+	c := fake.NewSimpleClientset(node)
+	checks := []v1beta1.NodeReadinessCheck{
+		{Name: v1beta1.ReadinessIOMMU, Result: v1beta1.ReadinessPassed},
+		{Name: v1beta1.ReadinessInterruptRemapping, Result: v1beta1.ReadinessFailed},
+	}
+
+	err := updateReadinessLabels(node.Name, fakeclients.NodeCache(c.CoreV1().Nodes), fakeclients.NodeClient(c.CoreV1().Nodes), checks)
+	assert.NoError(err, "expected no error updating readiness labels")
+	nodeObj, err := c.CoreV1().Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal("false", nodeObj.Labels[v1beta1.PassthroughReadyLabel])
+	assert.Equal("Passed", nodeObj.Labels[v1beta1.NodeReadinessLabelPrefix+"iommu"])
+	assert.Equal("Failed", nodeObj.Labels[v1beta1.NodeReadinessLabelPrefix+"interruptremapping"])
+}
@@ -5,8 +5,10 @@ import (
 	"time"
 
 	"github.com/jaypipes/ghw"
+	"github.com/jaypipes/ghw/pkg/pci"
 	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -16,6 +18,10 @@ import (
 	v1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
 	ctl "github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
 	"github.com/harvester/pcidevices/pkg/iommu"
+	"github.com/harvester/pcidevices/pkg/metrics"
+	"github.com/harvester/pcidevices/pkg/util/discoverypolicy"
+	"github.com/harvester/pcidevices/pkg/util/hostusage"
+	"github.com/harvester/pcidevices/pkg/util/resourcenamerule"
 )
 
 const (
@@ -25,25 +31,64 @@ const (
 
 type Handler struct {
 	client                  ctl.PCIDeviceClient
+	cache                   ctl.PCIDeviceCache
 	pci                     *ghw.PCIInfo
 	nodeCache               ctlcorev1.NodeCache
 	vlanConfigCache         ctlnetworkv1beta1.VlanConfigCache
 	sriovNetworkDeviceCache ctl.SRIOVNetworkDeviceCache
 	skipAddresses           []string
+	discovery               *discoverypolicy.Evaluator
+	resourceNames           *resourcenamerule.Resolver
+	rules                   resourcenamerule.Rules
+	pools                   resourcenamerule.Pools
+	// detectHostUsage identifies devices in use by the host, host usage is not reported when it is nil
+	detectHostUsage func() (map[string][]v1beta1.PCIDeviceHostUsage, error)
+	hostUsage       map[string][]v1beta1.PCIDeviceHostUsage
 }
 
-func NewHandler(client ctl.PCIDeviceClient, pci *ghw.PCIInfo, nodeCache ctlcorev1.NodeCache,
-	vlanConfigCache ctlnetworkv1beta1.VlanConfigCache, sriovNetworkDeviceCache ctl.SRIOVNetworkDeviceCache, skipAddresses []string) *Handler {
+func NewHandler(client ctl.PCIDeviceClient, cache ctl.PCIDeviceCache, pci *ghw.PCIInfo, nodeCache ctlcorev1.NodeCache,
+	vlanConfigCache ctlnetworkv1beta1.VlanConfigCache, sriovNetworkDeviceCache ctl.SRIOVNetworkDeviceCache, skipAddresses []string, discovery *discoverypolicy.Evaluator,
+	resourceNames *resourcenamerule.Resolver) *Handler {
 	return &Handler{
 		client:                  client,
+		cache:                   cache,
 		pci:                     pci,
 		nodeCache:               nodeCache,
 		vlanConfigCache:         vlanConfigCache,
 		sriovNetworkDeviceCache: sriovNetworkDeviceCache,
 		skipAddresses:           skipAddresses,
+		discovery:               discovery,
+		resourceNames:           resourceNames,
+		detectHostUsage:         hostusage.Detect,
 	}
 }
 
+// refreshResourceNames lists the ResourceNameRules and DevicePools applied to the devices
+func (h *Handler) refreshResourceNames() error {
+	var err error
+	h.rules, err = h.resourceNames.Rules()
+	if err != nil {
+		return err
+	}
+	h.pools, err = h.resourceNames.Pools()
+	return err
+}
+
+// refreshHostUsage detects the current host usage of devices. If detection fails, host usage is left as is on all devices
+func (h *Handler) refreshHostUsage() {
+	h.hostUsage = nil
+	if h.detectHostUsage == nil {
+		return
+	}
+
+	usage, err := h.detectHostUsage()
+	if err != nil {
+		logrus.Warnf("[PCIDeviceController] unable to detect host usage of devices: %v", err)
+		return
+	}
+	h.hostUsage = usage
+}
+
 func (h *Handler) ReconcilePCIDevices(nodename string) error {
 	// Build up the IOMMU group map
 	iommuGroupPaths, err := iommu.GroupPaths()
@@ -51,61 +96,25 @@ func (h *Handler) ReconcilePCIDevices(nodename string) error {
 		return err
 	}
 	iommuGroupMap := iommu.GroupMapForPCIDevices(iommuGroupPaths)
+	h.refreshHostUsage()
+
+	filter, err := h.discovery.Filter()
+	if err != nil {
+		return err
+	}
+
+	if err := h.refreshResourceNames(); err != nil {
+		return err
+	}
 
 	commonLabels := map[string]string{"nodename": nodename} // label
 	var setOfRealPCIAddrs = make(map[string]bool)
 	for _, dev := range h.pci.Devices {
-		if !containsString(h.skipAddresses, dev.Address) {
+		// devices which are no longer discovered are removed along with non-existent devices
+		if !containsString(h.skipAddresses, dev.Address) && discovered(dev, filter) {
 			setOfRealPCIAddrs[dev.Address] = true
-			name := v1beta1.PCIDeviceNameForHostname(dev.Address, nodename)
-			// Check if device is stored
-			devCR, err := h.client.Get(name, metav1.GetOptions{})
-
+			commonLabels, err = h.reconcileDevice(dev, nodename, iommuGroupMap, commonLabels)
 			if err != nil {
-				if apierrors.IsNotFound(err) {
-					logrus.Infof("[PCIDeviceController] Device %s does not exist", name)
-
-					// Create the PCIDevice CR if it doesn't exist
-					pdToCreate := v1beta1.NewPCIDeviceForHostname(dev, nodename)
-					logrus.Infof("Creating PCI Device: %s\n", pdToCreate.Name)
-
-					logrus.Debugf("querying sriov network device ownership for pcidevice: %s", pdToCreate.Name)
-					commonLabels, err = h.QuerySRIOVNetworkDeviceOwnership(pdToCreate, commonLabels)
-					if err != nil {
-						return err
-					}
-					pdToCreate.Labels = commonLabels
-					devCR, err = h.client.Create(&pdToCreate)
-					if err != nil {
-						logrus.Errorf("[PCIDeviceController] Failed to create PCI Device: %v", err)
-						return err
-					}
-				} else {
-					logrus.Errorf("[PCIDeviceController] error fetching device %s: %v", name, err)
-					return err
-				}
-
-			}
-
-			devCopy := devCR.DeepCopy()
-			// PCIDeviceOverrideResourceName is used by two flows:
-			//   - vGPU: the vgpu controller sets/removes this annotation on PCIDevice
-			//     to override the resource name with the vGPU profile name.
-			//   - Individual PCIDevice: the pcideviceclaim controller sets/removes this
-			//     annotation with a stable name when DisableResourcePooling is enabled.
-			// When present, the value takes precedence over the auto-generated resource name.
-			overrideResourceName := devCopy.Annotations[v1beta1.PCIDeviceOverrideResourceName]
-			// during reboot if the device driver has changed back from vfio, then update the CRD
-			// to correct driver in use. This will ensure that the original driver is correctly updated on device
-			// the PCIDeviceClaim checks for driver to identify if a rebind is needed on reboot
-			if devCopy.Status.KernelDriverInUse != dev.Driver {
-				devCopy.Status.KernelDriverInUse = dev.Driver
-			}
-			// Update only modifies the status, no need to update the main object
-			devCopy.Status.Update(dev, nodename, iommuGroupMap, overrideResourceName) // update the in-memory CR with the current PCI info
-			_, err = h.client.UpdateStatus(devCopy)
-			if err != nil {
-				logrus.Errorf("[PCIDeviceController] Failed to update status sub-resource: %v", err)
 				return err
 			}
 		}
@@ -115,15 +124,15 @@ func (h *Handler) ReconcilePCIDevices(nodename string) error {
 	// remove non-existent devices
 	selector := labels.SelectorFromValidatedSet(commonLabels)
 
-	pdList, err := h.client.List(metav1.ListOptions{LabelSelector: selector.String()})
+	pdList, err := h.cache.List(selector)
 	if err != nil {
 		logrus.Errorf("[PCIDeviceController] error listing devices for node %s: %v", nodename, err)
 		return err
 	}
 
-	var deleteList []v1beta1.PCIDevice
+	var deleteList []*v1beta1.PCIDevice
 
-	for _, v := range pdList.Items {
+	for _, v := range pdList {
 		if ok := setOfRealPCIAddrs[v.Status.Address]; !ok {
 			deleteList = append(deleteList, v)
 		}
@@ -139,6 +148,157 @@ func (h *Handler) ReconcilePCIDevices(nodename string) error {
 	return nil
 }
 
+// ReconcilePCIDevice reconciles the PCIDevice object for a single address, and is used to
+// incrementally apply hotplug events without rescanning all devices on the node.
+// If the device no longer exists in sysfs, the corresponding PCIDevice is removed
+func (h *Handler) ReconcilePCIDevice(nodename string, address string) error {
+	filter, err := h.discovery.Filter()
+	if err != nil {
+		return err
+	}
+
+	name := v1beta1.PCIDeviceNameForHostname(address, nodename)
+	dev := h.pci.GetDevice(address)
+	if dev == nil || containsString(h.skipAddresses, address) || isPCIBridge(dev) || !discovered(dev, filter) {
+		if _, err := h.cache.Get(name); apierrors.IsNotFound(err) {
+			return nil
+		}
+		err := h.client.Delete(name, &metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("error removing pcidevice %s: %w", name, err)
+		}
+		return nil
+	}
+
+	iommuGroupMap := make(map[string]int)
+	group, err := iommu.GroupForPCIDevice(address)
+	if err != nil {
+		logrus.Warnf("[PCIDeviceController] unable to find iommu group for device %s: %v", address, err)
+	} else {
+		iommuGroupMap[address] = group
+	}
+	h.refreshHostUsage()
+
+	if err := h.refreshResourceNames(); err != nil {
+		return err
+	}
+
+	_, err = h.reconcileDevice(dev, nodename, iommuGroupMap, map[string]string{v1beta1.NodeKeyName: nodename})
+	return err
+}
+
+// reconcileDevice creates the PCIDevice object for dev if needed and syncs its status
+func (h *Handler) reconcileDevice(dev *pci.Device, nodename string, iommuGroupMap map[string]int, commonLabels map[string]string) (map[string]string, error) {
+	name := v1beta1.PCIDeviceNameForHostname(dev.Address, nodename)
+	// Check if device is stored
+	devCR, err := h.cache.Get(name)
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			logrus.Infof("[PCIDeviceController] Device %s does not exist", name)
+
+			// Create the PCIDevice CR if it doesn't exist
+			pdToCreate := v1beta1.NewPCIDeviceForHostname(dev, nodename)
+			logrus.Infof("Creating PCI Device: %s\n", pdToCreate.Name)
+
+			logrus.Debugf("querying sriov network device ownership for pcidevice: %s", pdToCreate.Name)
+			commonLabels, err = h.QuerySRIOVNetworkDeviceOwnership(pdToCreate, commonLabels)
+			if err != nil {
+				return commonLabels, err
+			}
+			pdToCreate.Labels = commonLabels
+			devCR, err = h.client.Create(&pdToCreate)
+			if err != nil {
+				logrus.Errorf("[PCIDeviceController] Failed to create PCI Device: %v", err)
+				return commonLabels, err
+			}
+		} else {
+			logrus.Errorf("[PCIDeviceController] error fetching device %s: %v", name, err)
+			return commonLabels, err
+		}
+
+	}
+
+	devCopy := devCR.DeepCopy()
+	// PCIDeviceOverrideResourceName is used by two flows:
+	//   - vGPU: the vgpu controller sets/removes this annotation on PCIDevice
+	//     to override the resource name with the vGPU profile name.
+	//   - Individual PCIDevice: the pcideviceclaim controller sets/removes this
+	//     annotation with a stable name when DisableResourcePooling is enabled.
+	// When present, the value takes precedence over the auto-generated resource name.
+	// Otherwise a resource name requested on the PCIDeviceSpec, or assigned by a DevicePool or ResourceNameRule is used.
+	overrideResourceName := devCopy.Annotations[v1beta1.PCIDeviceOverrideResourceName]
+	var devicePool string
+	switch {
+	case overrideResourceName != "":
+	case devCopy.Spec.ResourceName != "" && v1beta1.IsVFIODriver(dev.Driver) && devCR.Status.ResourceName != "":
+		// the device plugin serving a device bound to vfio is registered with the current resource name, the spec
+		// handler reports the device as out of sync until passthrough is disabled
+		overrideResourceName, devicePool = devCR.Status.ResourceName, devCR.Status.DevicePool
+	case devCopy.Spec.ResourceName != "":
+		overrideResourceName = devCopy.Spec.ResourceName
+	default:
+		overrideResourceName, devicePool = h.assignedResourceName(devCR, dev)
+	}
+	// during reboot if the device driver has changed back from vfio, then update the CRD
+	// to correct driver in use. This will ensure that the original driver is correctly updated on device
+	// the PCIDeviceClaim checks for driver to identify if a rebind is needed on reboot
+	if devCopy.Status.KernelDriverInUse != dev.Driver {
+		devCopy.Status.KernelDriverInUse = dev.Driver
+	}
+	// Update only modifies the status, no need to update the main object
+	devCopy.Status.Update(dev, nodename, iommuGroupMap, overrideResourceName) // update the in-memory CR with the current PCI info
+	devCopy.Status.DevicePool = devicePool
+	if h.hostUsage != nil {
+		devCopy.Status.HostUsage = h.hostUsage[dev.Address]
+	}
+	// skip the write if nothing changed, to avoid a constant stream of no-op updates to the api server
+	if equality.Semantic.DeepEqual(devCR.Status, devCopy.Status) {
+		metrics.StatusUpdatesSkipped.WithLabelValues(metrics.ResourcePCIDevice).Inc()
+		return commonLabels, nil
+	}
+
+	_, err = h.client.UpdateStatus(devCopy)
+	if err != nil {
+		logrus.Errorf("[PCIDeviceController] Failed to update status sub-resource: %v", err)
+		return commonLabels, err
+	}
+	metrics.StatusUpdates.WithLabelValues(metrics.ResourcePCIDevice).Inc()
+	return commonLabels, nil
+}
+
+// assignedResourceName returns the resource name assigned to dev by a DevicePool or the ResourceNameRules, along with
+// the pool the device is exposed through. Devices bound to a vfio driver keep their current resource name, as the
+// device plugin serving the device is registered with it
+func (h *Handler) assignedResourceName(devCR *v1beta1.PCIDevice, dev *pci.Device) (string, string) {
+	status := &v1beta1.PCIDeviceStatus{
+		VendorID: dev.Vendor.ID,
+		DeviceID: dev.Product.ID,
+	}
+	if dev.Subsystem != nil {
+		status.SubsystemVendorID = dev.Subsystem.VendorID
+		status.SubsystemDeviceID = dev.Subsystem.ID
+	}
+
+	resourceName, devicePool := h.rules.ResourceName(status), ""
+	if pool := h.pools.Pool(devCR); pool != nil {
+		resourceName, devicePool = pool.Spec.ResourceName, pool.Name
+	}
+	// without a rule or pool the generated resource name is used
+	target := resourceName
+	if target == "" {
+		target = v1beta1.GeneratedResourceName(dev)
+	}
+	if target == devCR.Status.ResourceName {
+		return resourceName, devicePool
+	}
+	if v1beta1.IsVFIODriver(dev.Driver) && devCR.Status.ResourceName != "" {
+		logrus.Infof("[PCIDeviceController] deferring resource name %s for device %s until passthrough is disabled", target, devCR.Name)
+		return devCR.Status.ResourceName, devCR.Status.DevicePool
+	}
+	return resourceName, devicePool
+}
+
 func containsString(elements []string, element string) bool {
 	for _, v := range elements {
 		if v == element {
@@ -155,13 +315,53 @@ func containsString(elements []string, element string) bool {
 func IdentifyPCIBridgeDevices(pci *ghw.PCIInfo) []string {
 	var pciBridgeAddresses []string
 	for _, v := range pci.Devices {
-		if fmt.Sprintf("%s%s", v.Class.ID, v.Subclass.ID) == pciBridgeClassID {
+		if isPCIBridge(v) {
 			pciBridgeAddresses = append(pciBridgeAddresses, v.Address)
 		}
 	}
 	return pciBridgeAddresses
 }
 
+// IdentifySkippedDevices will identify devices matching any of the filters, which are skipped during discovery
+func IdentifySkippedDevices(pci *ghw.PCIInfo, filters []v1beta1.PCIDeviceFilter) []string {
+	var skippedAddresses []string
+	for _, v := range pci.Devices {
+		if IsSkippedDevice(v, filters) {
+			skippedAddresses = append(skippedAddresses, v.Address)
+		}
+	}
+	return skippedAddresses
+}
+
+// IsSkippedDevice returns true if dev is matched by any of the filters
+func IsSkippedDevice(dev *pci.Device, filters []v1beta1.PCIDeviceFilter) bool {
+	for _, f := range filters {
+		if f.Matches(dev.Vendor.ID, dev.Product.ID, fmt.Sprintf("%s%s", dev.Class.ID, dev.Subclass.ID)) {
+			return true
+		}
+	}
+	return false
+}
+
+// discovered returns true if dev is allowed by the DeviceDiscoveryPolicies in filter. Devices bound to a vfio driver
+// are always discovered, so devices in use for passthrough are not removed when a policy changes
+func discovered(dev *pci.Device, filter discoverypolicy.Filter) bool {
+	if v1beta1.IsVFIODriver(dev.Driver) {
+		return true
+	}
+	return filter.Allows(v1beta1.DiscoveredDevice{
+		Subsystem: v1beta1.SubsystemPCI,
+		VendorID:  dev.Vendor.ID,
+		DeviceID:  dev.Product.ID,
+		ClassID:   fmt.Sprintf("%s%s", dev.Class.ID, dev.Subclass.ID),
+		Address:   dev.Address,
+	})
+}
+
+func isPCIBridge(dev *pci.Device) bool {
+	return fmt.Sprintf("%s%s", dev.Class.ID, dev.Subclass.ID) == pciBridgeClassID
+}
+
 func (h *Handler) QuerySRIOVNetworkDeviceOwnership(device v1beta1.PCIDevice, labels map[string]string) (map[string]string, error) {
 	sriovDev, err := h.sriovNetworkDeviceCache.GetByIndex(v1beta1.SRIOVFromVF, device.Name)
 	if err != nil {
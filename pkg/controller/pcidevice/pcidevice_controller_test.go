@@ -2,15 +2,21 @@ package pcidevice
 
 import (
 	"context"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/jaypipes/ghw"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
 	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/pcidevices/pkg/metrics"
 	"github.com/harvester/pcidevices/pkg/util/fakeclients"
+	"github.com/harvester/pcidevices/pkg/util/resourcenamerule"
 )
 
 const (
@@ -28,6 +34,7 @@ func Test_reconcilePCIDevices(t *testing.T) {
 
 	h := Handler{
 		client:                  fakeclients.PCIDevicesClient(client.DevicesV1beta1().PCIDevices),
+		cache:                   fakeclients.PCIDevicesCache(client.DevicesV1beta1().PCIDevices),
 		sriovNetworkDeviceCache: fakeclients.SriovDevicesCache(client.DevicesV1beta1().SRIOVNetworkDevices),
 		pci:                     pci,
 		skipAddresses:           []string{"0000:04:00.1"}, //address of eno5 interface in the snapshot
@@ -42,6 +49,14 @@ func Test_reconcilePCIDevices(t *testing.T) {
 	_, err = client.DevicesV1beta1().PCIDevices().Get(context.TODO(), "TEST_NODE-000004001", metav1.GetOptions{})
 	assert.True(apierrors.IsNotFound(err), "expected to not find the pci address for 000004001")
 	t.Log(gpuDevice.Status)
+
+	// a second pass with no hardware changes should not write any status
+	updates := testutil.ToFloat64(metrics.StatusUpdates.WithLabelValues(metrics.ResourcePCIDevice))
+	skipped := testutil.ToFloat64(metrics.StatusUpdatesSkipped.WithLabelValues(metrics.ResourcePCIDevice))
+	err = h.ReconcilePCIDevices("TEST_NODE")
+	assert.NoError(err, "expected no error during second pcidevice reconcile")
+	assert.Equal(updates, testutil.ToFloat64(metrics.StatusUpdates.WithLabelValues(metrics.ResourcePCIDevice)), "expected no status updates")
+	assert.Equal(skipped+float64(len(pci.Devices)-1), testutil.ToFloat64(metrics.StatusUpdatesSkipped.WithLabelValues(metrics.ResourcePCIDevice)), "expected all status updates to be skipped")
 }
 
 func Test_identifyPCIBridgeAddresses(t *testing.T) {
@@ -54,3 +69,245 @@ func Test_identifyPCIBridgeAddresses(t *testing.T) {
 	devs := IdentifyPCIBridgeDevices(pci)
 	assert.Len(devs, 26, "expected to find 26 devices from the snapshot")
 }
+
+func Test_identifySkippedDevices(t *testing.T) {
+	assert := require.New(t)
+	pci, err := ghw.PCI(ghw.WithSnapshot(ghw.SnapshotOptions{
+		Path: defaultPCIDeviceSnapshot,
+	}))
+	assert.NoError(err, "expected no error during snapshot loading")
+
+	assert.Len(IdentifySkippedDevices(pci, []v1beta1.PCIDeviceFilter{{ClassID: "0604"}}), 26, "expected all pci bridges to be skipped")
+	assert.Equal(IdentifySkippedDevices(pci, []v1beta1.PCIDeviceFilter{{ClassID: "06"}}),
+		IdentifySkippedDevices(pci, []v1beta1.PCIDeviceFilter{{ClassID: "06"}, {ClassID: "0604"}}), "expected devices matching multiple filters to be skipped once")
+	assert.Contains(IdentifySkippedDevices(pci, []v1beta1.PCIDeviceFilter{{VendorID: "10DE"}}), "0000:08:00.0", "expected vendor ids to be case insensitive")
+	assert.Empty(IdentifySkippedDevices(pci, nil))
+}
+
+func Test_reconcilePCIDevice(t *testing.T) {
+	assert := require.New(t)
+	client := fake.NewSimpleClientset()
+
+	// unpack into a test owned directory as devices are looked up after the snapshot has been loaded
+	snapshotRoot := t.TempDir()
+	pci, err := ghw.PCI(ghw.WithSnapshot(ghw.SnapshotOptions{
+		Path: defaultPCIDeviceSnapshot,
+		Root: &snapshotRoot,
+	}))
+	assert.NoError(err, "expected no error during snapshot loading")
+	pci.Devices = nil
+
+	h := Handler{
+		client:                  fakeclients.PCIDevicesClient(client.DevicesV1beta1().PCIDevices),
+		cache:                   fakeclients.PCIDevicesCache(client.DevicesV1beta1().PCIDevices),
+		sriovNetworkDeviceCache: fakeclients.SriovDevicesCache(client.DevicesV1beta1().SRIOVNetworkDevices),
+		pci:                     pci,
+		skipAddresses:           []string{"0000:04:00.1"},
+	}
+
+	// hot added device is created
+	err = h.ReconcilePCIDevice("TEST_NODE", "0000:08:00.0")
+	assert.NoError(err, "expected no error during reconcile of added device")
+	gpuDevice, err := client.DevicesV1beta1().PCIDevices().Get(context.TODO(), "TEST_NODE-000008000", metav1.GetOptions{})
+	assert.NoError(err, "expected to find GPU device")
+	assert.Equal("0000:08:00.0", gpuDevice.Status.Address)
+	assert.Equal("TEST_NODE", gpuDevice.Labels["nodename"])
+
+	// skipped devices are not created
+	err = h.ReconcilePCIDevice("TEST_NODE", "0000:04:00.1")
+	assert.NoError(err, "expected no error during reconcile of skipped device")
+	_, err = client.DevicesV1beta1().PCIDevices().Get(context.TODO(), "TEST_NODE-000004001", metav1.GetOptions{})
+	assert.True(apierrors.IsNotFound(err), "expected to not find the pci address for 000004001")
+
+	// surprise removed device is cleaned up
+	removed := &v1beta1.PCIDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "TEST_NODE-0000ff000",
+		},
+	}
+	_, err = client.DevicesV1beta1().PCIDevices().Create(context.TODO(), removed, metav1.CreateOptions{})
+	assert.NoError(err)
+	err = h.ReconcilePCIDevice("TEST_NODE", "0000:ff:00.0")
+	assert.NoError(err, "expected no error during reconcile of removed device")
+	_, err = client.DevicesV1beta1().PCIDevices().Get(context.TODO(), removed.Name, metav1.GetOptions{})
+	assert.True(apierrors.IsNotFound(err), "expected removed device to be deleted")
+}
+
+func Test_reconcilePCIDevicesHostUsage(t *testing.T) {
+	assert := require.New(t)
+	client := fake.NewSimpleClientset()
+
+	pci, err := ghw.PCI(ghw.WithSnapshot(ghw.SnapshotOptions{
+		Path: defaultPCIDeviceSnapshot,
+	}))
+	assert.NoError(err, "expected no error during snapshot loading")
+
+	consoleUsage := []v1beta1.PCIDeviceHostUsage{{Type: v1beta1.HostUsageConsole, Name: "fb0"}}
+	h := Handler{
+		client:                  fakeclients.PCIDevicesClient(client.DevicesV1beta1().PCIDevices),
+		cache:                   fakeclients.PCIDevicesCache(client.DevicesV1beta1().PCIDevices),
+		sriovNetworkDeviceCache: fakeclients.SriovDevicesCache(client.DevicesV1beta1().SRIOVNetworkDevices),
+		pci:                     pci,
+		detectHostUsage: func() (map[string][]v1beta1.PCIDeviceHostUsage, error) {
+			return map[string][]v1beta1.PCIDeviceHostUsage{"0000:08:00.0": consoleUsage}, nil
+		},
+	}
+
+	assert.NoError(h.ReconcilePCIDevices("TEST_NODE"), "expected no error during pcidevice reconcile")
+	gpuDevice, err := client.DevicesV1beta1().PCIDevices().Get(context.TODO(), "TEST_NODE-000008000", metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal(consoleUsage, gpuDevice.Status.HostUsage)
+
+	// host usage is retained when detection fails
+	h.detectHostUsage = func() (map[string][]v1beta1.PCIDeviceHostUsage, error) {
+		return nil, os.ErrNotExist
+	}
+	assert.NoError(h.ReconcilePCIDevices("TEST_NODE"), "expected no error during pcidevice reconcile")
+	gpuDevice, err = client.DevicesV1beta1().PCIDevices().Get(context.TODO(), "TEST_NODE-000008000", metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal(consoleUsage, gpuDevice.Status.HostUsage)
+}
+
+func Test_reconcilePCIDevicesResourceNameRule(t *testing.T) {
+	assert := require.New(t)
+	client := fake.NewSimpleClientset()
+
+	pci, err := ghw.PCI(ghw.WithSnapshot(ghw.SnapshotOptions{
+		Path: defaultPCIDeviceSnapshot,
+	}))
+	assert.NoError(err, "expected no error during snapshot loading")
+
+	h := Handler{
+		client:                  fakeclients.PCIDevicesClient(client.DevicesV1beta1().PCIDevices),
+		cache:                   fakeclients.PCIDevicesCache(client.DevicesV1beta1().PCIDevices),
+		sriovNetworkDeviceCache: fakeclients.SriovDevicesCache(client.DevicesV1beta1().SRIOVNetworkDevices),
+		pci:                     pci,
+		resourceNames: resourcenamerule.NewResolver(fakeclients.ResourceNameRulesCache(client.DevicesV1beta1().ResourceNameRules),
+			fakeclients.DevicePoolsCache(client.DevicesV1beta1().DevicePools)),
+	}
+
+	assert.NoError(h.ReconcilePCIDevices("TEST_NODE"), "expected no error during pcidevice reconcile")
+	gpuDevice, err := client.DevicesV1beta1().PCIDevices().Get(context.TODO(), "TEST_NODE-000008000", metav1.GetOptions{})
+	assert.NoError(err)
+	generatedName := gpuDevice.Status.ResourceName
+
+	rule := &v1beta1.ResourceNameRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "gpu",
+		},
+		Spec: v1beta1.ResourceNameRuleSpec{
+			VendorID:     gpuDevice.Status.VendorID,
+			DeviceID:     gpuDevice.Status.DeviceID,
+			ResourceName: "nvidia.com/GPU",
+		},
+	}
+	_, err = client.DevicesV1beta1().ResourceNameRules().Create(context.TODO(), rule, metav1.CreateOptions{})
+	assert.NoError(err)
+
+	assert.NoError(h.ReconcilePCIDevices("TEST_NODE"), "expected no error during pcidevice reconcile")
+	gpuDevice, err = client.DevicesV1beta1().PCIDevices().Get(context.TODO(), "TEST_NODE-000008000", metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal("nvidia.com/GPU", gpuDevice.Status.ResourceName, "expected resource name from rule")
+
+	// removing the rule is deferred while the device is bound to vfio
+	pci.GetDevice("0000:08:00.0").Driver = "vfio-pci"
+	assert.NoError(client.DevicesV1beta1().ResourceNameRules().Delete(context.TODO(), rule.Name, metav1.DeleteOptions{}))
+	assert.NoError(h.ReconcilePCIDevices("TEST_NODE"), "expected no error during pcidevice reconcile")
+	gpuDevice, err = client.DevicesV1beta1().PCIDevices().Get(context.TODO(), "TEST_NODE-000008000", metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal("nvidia.com/GPU", gpuDevice.Status.ResourceName, "expected resource name of vfio bound device to be retained")
+
+	// the resource name reverts once passthrough is disabled
+	pci.GetDevice("0000:08:00.0").Driver = "nvidia"
+	assert.NoError(h.ReconcilePCIDevices("TEST_NODE"), "expected no error during pcidevice reconcile")
+	gpuDevice, err = client.DevicesV1beta1().PCIDevices().Get(context.TODO(), "TEST_NODE-000008000", metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal(generatedName, gpuDevice.Status.ResourceName)
+}
+
+func Test_reconcilePCIDevicesDevicePool(t *testing.T) {
+	assert := require.New(t)
+	client := fake.NewSimpleClientset()
+
+	pci, err := ghw.PCI(ghw.WithSnapshot(ghw.SnapshotOptions{
+		Path: defaultPCIDeviceSnapshot,
+	}))
+	assert.NoError(err, "expected no error during snapshot loading")
+
+	h := Handler{
+		client:                  fakeclients.PCIDevicesClient(client.DevicesV1beta1().PCIDevices),
+		cache:                   fakeclients.PCIDevicesCache(client.DevicesV1beta1().PCIDevices),
+		sriovNetworkDeviceCache: fakeclients.SriovDevicesCache(client.DevicesV1beta1().SRIOVNetworkDevices),
+		pci:                     pci,
+		resourceNames: resourcenamerule.NewResolver(fakeclients.ResourceNameRulesCache(client.DevicesV1beta1().ResourceNameRules),
+			fakeclients.DevicePoolsCache(client.DevicesV1beta1().DevicePools)),
+	}
+
+	pool := &v1beta1.DevicePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "display",
+		},
+		Spec: v1beta1.DevicePoolSpec{
+			Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{v1beta1.NodeKeyName: "TEST_NODE"}},
+			Devices:      []v1beta1.PCIDeviceFilter{{ClassID: "03"}},
+			ResourceName: "example.com/DISPLAY",
+		},
+	}
+	_, err = client.DevicesV1beta1().DevicePools().Create(context.TODO(), pool, metav1.CreateOptions{})
+	assert.NoError(err)
+
+	assert.NoError(h.ReconcilePCIDevices("TEST_NODE"), "expected no error during pcidevice reconcile")
+	pds, err := client.DevicesV1beta1().PCIDevices().List(context.TODO(), metav1.ListOptions{})
+	assert.NoError(err)
+	var pooled int
+	for _, pd := range pds.Items {
+		if strings.HasPrefix(pd.Status.ClassID, "03") {
+			pooled++
+			assert.Equal(pool.Spec.ResourceName, pd.Status.ResourceName, "expected display controllers to be pooled")
+			assert.Equal(pool.Name, pd.Status.DevicePool)
+		} else {
+			assert.Empty(pd.Status.DevicePool, "expected other devices to not be pooled")
+		}
+	}
+	assert.NotZero(pooled, "expected to find display controllers in the snapshot")
+}
+
+func Test_reconcilePCIDevicesSpecResourceName(t *testing.T) {
+	assert := require.New(t)
+	client := fake.NewSimpleClientset()
+
+	pci, err := ghw.PCI(ghw.WithSnapshot(ghw.SnapshotOptions{
+		Path: defaultPCIDeviceSnapshot,
+	}))
+	assert.NoError(err, "expected no error during snapshot loading")
+
+	h := Handler{
+		client:                  fakeclients.PCIDevicesClient(client.DevicesV1beta1().PCIDevices),
+		cache:                   fakeclients.PCIDevicesCache(client.DevicesV1beta1().PCIDevices),
+		sriovNetworkDeviceCache: fakeclients.SriovDevicesCache(client.DevicesV1beta1().SRIOVNetworkDevices),
+		pci:                     pci,
+		resourceNames: resourcenamerule.NewResolver(fakeclients.ResourceNameRulesCache(client.DevicesV1beta1().ResourceNameRules),
+			fakeclients.DevicePoolsCache(client.DevicesV1beta1().DevicePools)),
+	}
+
+	assert.NoError(h.ReconcilePCIDevices("TEST_NODE"), "expected no error during pcidevice reconcile")
+	gpuDevice, err := client.DevicesV1beta1().PCIDevices().Get(context.TODO(), "TEST_NODE-000008000", metav1.GetOptions{})
+	assert.NoError(err)
+	generatedName := gpuDevice.Status.ResourceName
+
+	// the resource name requested on the spec is not applied while the device is bound to vfio
+	pci.GetDevice("0000:08:00.0").Driver = "vfio-pci"
+	gpuDevice.Spec.ResourceName = "nvidia.com/GPU"
+	_, err = client.DevicesV1beta1().PCIDevices().Update(context.TODO(), gpuDevice, metav1.UpdateOptions{})
+	assert.NoError(err)
+	assert.NoError(h.ReconcilePCIDevices("TEST_NODE"), "expected no error during pcidevice reconcile")
+	gpuDevice, err = client.DevicesV1beta1().PCIDevices().Get(context.TODO(), "TEST_NODE-000008000", metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal(generatedName, gpuDevice.Status.ResourceName, "expected resource name of vfio bound device to be retained")
+
+	pci.GetDevice("0000:08:00.0").Driver = "nvidia"
+	assert.NoError(h.ReconcilePCIDevices("TEST_NODE"), "expected no error during pcidevice reconcile")
+	gpuDevice, err = client.DevicesV1beta1().PCIDevices().Get(context.TODO(), "TEST_NODE-000008000", metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal("nvidia.com/GPU", gpuDevice.Status.ResourceName, "expected resource name from spec once passthrough is disabled")
+}
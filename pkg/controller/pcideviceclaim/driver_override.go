@@ -0,0 +1,105 @@
+package pcideviceclaim
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+)
+
+const (
+	pciDriversProbePath = "/sys/bus/pci/drivers_probe"
+	vfioPCIRemoveIDPath = "/sys/bus/pci/drivers/vfio-pci/remove_id"
+)
+
+// setDriverOverride restricts the drivers which can bind to the device at address to driver.
+// An empty driver clears the override, allowing any matching driver to bind again
+func setDriverOverride(pciDevicesPath, address, driver string) error {
+	path := filepath.Join(pciDevicesPath, address, "driver_override")
+	file, err := os.OpenFile(path, os.O_WRONLY, 0200)
+	if err != nil {
+		return fmt.Errorf("error opening driver_override for device %s: %w", address, err)
+	}
+	defer file.Close()
+
+	// the kernel clears the override when a lone newline is written
+	if _, err := file.WriteString(driver + "\n"); err != nil {
+		return fmt.Errorf("error writing driver_override for device %s: %w", address, err)
+	}
+	return nil
+}
+
+// probeDevice asks the kernel to bind the device at address to a matching driver, honouring driver_override
+func probeDevice(address string) error {
+	file, err := os.OpenFile(pciDriversProbePath, os.O_WRONLY, 0200)
+	if err != nil {
+		return fmt.Errorf("error opening drivers_probe: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(address); err != nil {
+		return fmt.Errorf("error probing device %s: %w", address, err)
+	}
+	return nil
+}
+
+// clearVFIODriverOverride removes the vfio-pci override from a device once it has been released,
+// so that its original driver can bind to it again
+func clearVFIODriverOverride(address string) error {
+	return setDriverOverride(sysBusPCIDevices, address, "")
+}
+
+// removeVFIODynamicID removes an id previously written to vfio-pci new_id. Older versions of the controller
+// bound devices using new_id, which makes vfio-pci claim every device with the same vendor and device id
+func removeVFIODynamicID(vendorID, deviceID string) error {
+	file, err := os.OpenFile(vfioPCIRemoveIDPath, os.O_WRONLY, 0200)
+	if err != nil {
+		return fmt.Errorf("error opening remove_id file: %w", err)
+	}
+	defer file.Close()
+
+	// the kernel returns ENODEV when the id was never added
+	if _, err := file.WriteString(fmt.Sprintf("%s %s", vendorID, deviceID)); err != nil && !errors.Is(err, syscall.ENODEV) {
+		return fmt.Errorf("error writing to remove_id file: %w", err)
+	}
+	return nil
+}
+
+// migrateVFIODynamicIDs removes stale vfio-pci new_id entries for all devices on the node, and pins claimed
+// devices already bound to vfio-pci with a driver_override so they stay bound once the ids are removed
+func migrateVFIODynamicIDs(pds []v1beta1.PCIDevice, claimed func(pd *v1beta1.PCIDevice) bool) error {
+	if _, err := os.Stat(vfioPCIRemoveIDPath); err != nil {
+		// vfio-pci is not loaded, so there are no dynamic ids to remove
+		return nil
+	}
+
+	ids := make(map[string]struct{})
+	for i := range pds {
+		pd := &pds[i]
+		if pd.Status.VendorID == "" || pd.Status.DeviceID == "" {
+			continue
+		}
+
+		if claimed(pd) && deviceBoundToDriver(vfioPCIDriverPath, pd.Status.Address) {
+			if err := setDriverOverride(sysBusPCIDevices, pd.Status.Address, vfioPCIDriver); err != nil {
+				return err
+			}
+		}
+
+		id := fmt.Sprintf("%s %s", pd.Status.VendorID, pd.Status.DeviceID)
+		if _, ok := ids[id]; ok {
+			continue
+		}
+		ids[id] = struct{}{}
+		logrus.Debugf("removing vfio-pci dynamic id %s", id)
+		if err := removeVFIODynamicID(pd.Status.VendorID, pd.Status.DeviceID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
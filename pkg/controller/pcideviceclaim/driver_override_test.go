@@ -0,0 +1,31 @@
+package pcideviceclaim
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_setDriverOverride(t *testing.T) {
+	assert := require.New(t)
+	root := t.TempDir()
+	address := "0000:04:00.0"
+	assert.NoError(os.MkdirAll(filepath.Join(root, address), 0755))
+	overridePath := filepath.Join(root, address, "driver_override")
+	assert.NoError(os.WriteFile(overridePath, []byte("(null)\n"), 0600))
+
+	assert.NoError(setDriverOverride(root, address, vfioPCIDriver), "expected no error setting driver_override")
+	contents, err := os.ReadFile(overridePath)
+	assert.NoError(err)
+	assert.Equal("vfio-pci\n", string(contents))
+
+	assert.NoError(os.Truncate(overridePath, 0))
+	assert.NoError(setDriverOverride(root, address, ""), "expected no error clearing driver_override")
+	contents, err = os.ReadFile(overridePath)
+	assert.NoError(err)
+	assert.Equal("\n", string(contents))
+
+	assert.Error(setDriverOverride(root, "0000:05:00.0", vfioPCIDriver), "expected error for missing device")
+}
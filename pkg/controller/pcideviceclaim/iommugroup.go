@@ -0,0 +1,151 @@
+package pcideviceclaim
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/iommu"
+)
+
+const sysBusPCIDevices = "/sys/bus/pci/devices"
+
+// groupMemberDriver manages the driver of iommu group members, which may not have a PCIDevice object
+type groupMemberDriver interface {
+	currentDriver(address string) string
+	bindVFIO(address string) error
+	release(member v1beta1.PCIDeviceClaimGroupMember) error
+}
+
+type sysfsGroupMemberDriver struct{}
+
+func (sysfsGroupMemberDriver) currentDriver(address string) string {
+	return boundDriver(address)
+}
+
+func (d sysfsGroupMemberDriver) bindVFIO(address string) error {
+	if driver := d.currentDriver(address); driver != "" {
+		if err := unbindDeviceFromDriver(address, driver); err != nil {
+			return err
+		}
+	}
+
+	return bindToVFIODriver(address, address, vfioPCIDriver)
+}
+
+// release returns a group member from vfio-pci to the driver it was using before it was claimed
+func (d sysfsGroupMemberDriver) release(member v1beta1.PCIDeviceClaimGroupMember) error {
+	if err := unbindDeviceFromDriver(member.Address, vfioPCIDriver); err != nil {
+		return fmt.Errorf("error unbinding %s from vfio-pci: %w", member.Address, err)
+	}
+	if err := clearVFIODriverOverride(member.Address); err != nil {
+		return err
+	}
+
+	if member.OriginalDriver == "" || member.OriginalDriver == vfioPCIDriver {
+		return nil
+	}
+
+	driverPath := filepath.Join("/sys/bus/pci/drivers", member.OriginalDriver)
+	if deviceBoundToDriver(driverPath, member.Address) {
+		return nil
+	}
+	logrus.Debugf("Binding device %s to %s", member.Address, member.OriginalDriver)
+	file, err := os.OpenFile(filepath.Join(driverPath, "bind"), os.O_WRONLY, 0200)
+	if err != nil {
+		return fmt.Errorf("error opening bind file for driver %s: %w", member.OriginalDriver, err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(member.Address); err != nil {
+		return fmt.Errorf("error binding %s to %s: %w", member.Address, member.OriginalDriver, err)
+	}
+	return nil
+}
+
+// boundDriver returns the driver currently bound to the device at address, or an empty string if it is unbound
+func boundDriver(address string) string {
+	link, err := os.Readlink(filepath.Join(sysBusPCIDevices, address, "driver"))
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(link)
+}
+
+// iommuGroupEndpoints returns the members of the iommu group of address which need to be bound to vfio-pci,
+// skipping address itself and pci bridges
+func iommuGroupEndpoints(address string) ([]string, error) {
+	members, err := iommu.GroupMembers(address)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := make([]string, 0, len(members))
+	for _, member := range members {
+		if member == address {
+			continue
+		}
+		bridge, err := iommu.IsPCIBridge(member)
+		if err != nil {
+			return nil, err
+		}
+		if !bridge {
+			endpoints = append(endpoints, member)
+		}
+	}
+	return endpoints, nil
+}
+
+// bindIOMMUGroupMembers binds all addresses to vfio-pci. Original drivers are taken from recorded members
+// when a claim is reconciled again, so they are not lost once members are already bound to vfio-pci.
+// If any member fails to bind, members rebound by this call are returned to their original drivers
+func bindIOMMUGroupMembers(driver groupMemberDriver, addresses []string, recorded []v1beta1.PCIDeviceClaimGroupMember) ([]v1beta1.PCIDeviceClaimGroupMember, error) {
+	originalDrivers := make(map[string]string, len(recorded))
+	for _, v := range recorded {
+		originalDrivers[v.Address] = v.OriginalDriver
+	}
+
+	members := make([]v1beta1.PCIDeviceClaimGroupMember, 0, len(addresses))
+	var rebound []v1beta1.PCIDeviceClaimGroupMember
+	for _, address := range addresses {
+		current := driver.currentDriver(address)
+		originalDriver, ok := originalDrivers[address]
+		if !ok {
+			originalDriver = current
+		}
+		member := v1beta1.PCIDeviceClaimGroupMember{
+			Address:        address,
+			OriginalDriver: originalDriver,
+		}
+		members = append(members, member)
+		if current == vfioPCIDriver {
+			continue
+		}
+
+		if err := driver.bindVFIO(address); err != nil {
+			err = fmt.Errorf("error binding iommu group member %s to vfio-pci: %w", address, err)
+			if rollbackErr := releaseIOMMUGroupMembers(driver, rebound); rollbackErr != nil {
+				return nil, errors.Join(err, fmt.Errorf("error rolling back iommu group: %w", rollbackErr))
+			}
+			return nil, err
+		}
+		rebound = append(rebound, member)
+	}
+
+	return members, nil
+}
+
+// releaseIOMMUGroupMembers returns all members to their original drivers, and reports all members
+// which could not be released
+func releaseIOMMUGroupMembers(driver groupMemberDriver, members []v1beta1.PCIDeviceClaimGroupMember) error {
+	var errs []error
+	for _, member := range members {
+		if err := driver.release(member); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,75 @@
+package pcideviceclaim
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+)
+
+type fakeGroupMemberDriver struct {
+	drivers  map[string]string
+	failBind string
+}
+
+func (f *fakeGroupMemberDriver) currentDriver(address string) string {
+	return f.drivers[address]
+}
+
+func (f *fakeGroupMemberDriver) bindVFIO(address string) error {
+	if address == f.failBind {
+		return errors.New("bind failed")
+	}
+	f.drivers[address] = vfioPCIDriver
+	return nil
+}
+
+func (f *fakeGroupMemberDriver) release(member v1beta1.PCIDeviceClaimGroupMember) error {
+	f.drivers[member.Address] = member.OriginalDriver
+	return nil
+}
+
+func Test_bindIOMMUGroupMembers(t *testing.T) {
+	assert := require.New(t)
+	driver := &fakeGroupMemberDriver{
+		drivers: map[string]string{
+			"0000:08:00.1": "snd_hda_intel",
+			"0000:08:00.2": "xhci_hcd",
+		},
+	}
+
+	members, err := bindIOMMUGroupMembers(driver, []string{"0000:08:00.1", "0000:08:00.2"}, nil)
+	assert.NoError(err, "expected no error binding group members")
+	assert.Equal([]v1beta1.PCIDeviceClaimGroupMember{
+		{Address: "0000:08:00.1", OriginalDriver: "snd_hda_intel"},
+		{Address: "0000:08:00.2", OriginalDriver: "xhci_hcd"},
+	}, members)
+	assert.Equal(vfioPCIDriver, driver.drivers["0000:08:00.1"])
+
+	// reconciling again must keep the original drivers recorded on the claim
+	members, err = bindIOMMUGroupMembers(driver, []string{"0000:08:00.1", "0000:08:00.2"}, members)
+	assert.NoError(err, "expected no error binding already bound group members")
+	assert.Equal("snd_hda_intel", members[0].OriginalDriver)
+
+	assert.NoError(releaseIOMMUGroupMembers(driver, members), "expected no error releasing group members")
+	assert.Equal("snd_hda_intel", driver.drivers["0000:08:00.1"])
+	assert.Equal("xhci_hcd", driver.drivers["0000:08:00.2"])
+}
+
+func Test_bindIOMMUGroupMembersRollback(t *testing.T) {
+	assert := require.New(t)
+	driver := &fakeGroupMemberDriver{
+		drivers: map[string]string{
+			"0000:08:00.1": "snd_hda_intel",
+			"0000:08:00.2": "xhci_hcd",
+		},
+		failBind: "0000:08:00.2",
+	}
+
+	_, err := bindIOMMUGroupMembers(driver, []string{"0000:08:00.1", "0000:08:00.2"}, nil)
+	assert.Error(err, "expected error binding group members")
+	assert.Equal("snd_hda_intel", driver.drivers["0000:08:00.1"], "expected rebound member to be rolled back")
+	assert.Equal("xhci_hcd", driver.drivers["0000:08:00.2"])
+}
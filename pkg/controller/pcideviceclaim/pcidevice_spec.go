@@ -0,0 +1,192 @@
+package pcideviceclaim
+
+import (
+	"fmt"
+
+	"github.com/rancher/wrangler/v3/pkg/relatedresource"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	v1beta1gen "github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
+)
+
+// SpecHandler reconciles the desired passthrough state declared on a PCIDeviceSpec by managing a
+// PCIDeviceClaim for the device. Binding and device plugin management is left to the claim controller
+type SpecHandler struct {
+	nodeName  string
+	pdClient  v1beta1gen.PCIDeviceClient
+	pdcClient v1beta1gen.PCIDeviceClaimClient
+	pdcCache  v1beta1gen.PCIDeviceClaimCache
+}
+
+func NewSpecHandler(nodeName string, pdClient v1beta1gen.PCIDeviceClient, pdcClient v1beta1gen.PCIDeviceClaimClient,
+	pdcCache v1beta1gen.PCIDeviceClaimCache) *SpecHandler {
+	return &SpecHandler{
+		nodeName:  nodeName,
+		pdClient:  pdClient,
+		pdcClient: pdcClient,
+		pdcCache:  pdcCache,
+	}
+}
+
+func (h *SpecHandler) reconcilePCIDeviceSpec(_ string, pd *v1beta1.PCIDevice) (*v1beta1.PCIDevice, error) {
+	if pd == nil || pd.DeletionTimestamp != nil || pd.Status.NodeName != h.nodeName {
+		return pd, nil
+	}
+
+	// devices which have never been managed from the spec are left to manually created claims
+	if !pd.Spec.Passthrough && pd.Status.Passthrough == nil {
+		return pd, nil
+	}
+
+	// pd may be updated while reconciling the claim, so work on a copy of the cached object
+	pd = pd.DeepCopy()
+	pdc, err := h.pdcCache.Get(pd.Name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return pd, fmt.Errorf("error looking up pcideviceclaim %s: %w", pd.Name, err)
+		}
+		pdc = nil
+	}
+
+	status, err := h.reconcileClaim(pd, pdc)
+	if err != nil {
+		return pd, err
+	}
+
+	if equality.Semantic.DeepEqual(pd.Status.Passthrough, status) {
+		return pd, nil
+	}
+
+	pd.Status.Passthrough = status
+	return h.pdClient.UpdateStatus(pd)
+}
+
+// reconcileClaim creates or removes the managed claim to match the desired state, and returns the observed passthrough status
+func (h *SpecHandler) reconcileClaim(pd *v1beta1.PCIDevice, pdc *v1beta1.PCIDeviceClaim) (*v1beta1.PCIDevicePassthroughStatus, error) {
+	status := &v1beta1.PCIDevicePassthroughStatus{
+		Driver: pd.Status.KernelDriverInUse,
+	}
+	if pdc != nil {
+		status.ClaimName = pdc.Name
+		status.Enabled = pdc.Status.PassthroughEnabled
+	}
+
+	if pd.Spec.DriverOverride != "" && !v1beta1.IsVFIODriver(pd.Spec.DriverOverride) {
+		status.State = v1beta1.PCIDevicePassthroughFailed
+		status.Message = fmt.Sprintf("unsupported driverOverride %s", pd.Spec.DriverOverride)
+		return status, nil
+	}
+
+	switch {
+	case pd.Spec.Passthrough && pdc == nil:
+		if err := h.applySpecResourceName(pd); err != nil {
+			return nil, err
+		}
+		logrus.Infof("creating pcideviceclaim for pcidevice %s requesting passthrough", pd.Name)
+		if _, err := h.pdcClient.Create(generateSpecManagedClaim(pd)); err != nil {
+			return nil, fmt.Errorf("error creating pcideviceclaim for pcidevice %s: %w", pd.Name, err)
+		}
+		status.ClaimName = pd.Name
+		status.State = v1beta1.PCIDevicePassthroughPending
+	case pd.Spec.Passthrough && !pdc.Status.PassthroughEnabled:
+		status.State = v1beta1.PCIDevicePassthroughPending
+	case pd.Spec.Passthrough && pd.Spec.ResourceName != "" && pd.Spec.ResourceName != pd.Status.ResourceName:
+		status.State = v1beta1.PCIDevicePassthroughOutOfSync
+		status.Message = "resourceName can only be changed while passthrough is disabled"
+	case pd.Spec.Passthrough && specDriver(pd) != pdc.Spec.VFIODriver():
+		status.State = v1beta1.PCIDevicePassthroughOutOfSync
+		status.Message = "driverOverride can only be changed while passthrough is disabled"
+	case pd.Spec.Passthrough:
+		status.State = v1beta1.PCIDevicePassthroughSynced
+	case pdc == nil:
+		status.State = v1beta1.PCIDevicePassthroughSynced
+	case isSpecManagedClaim(pdc):
+		if pdc.DeletionTimestamp == nil {
+			logrus.Infof("removing pcideviceclaim %s as passthrough is no longer requested", pdc.Name)
+			if err := h.pdcClient.Delete(pdc.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("error removing pcideviceclaim %s: %w", pdc.Name, err)
+			}
+		}
+		status.State = v1beta1.PCIDevicePassthroughPending
+	default:
+		status.State = v1beta1.PCIDevicePassthroughOutOfSync
+		status.Message = fmt.Sprintf("device is held by pcideviceclaim %s for user %s", pdc.Name, pdc.Spec.UserName)
+	}
+
+	return status, nil
+}
+
+// applySpecResourceName propagates the resource name from the spec to the status before the claim is created,
+// as the claim controller registers the device plugin using the status resource name
+func (h *SpecHandler) applySpecResourceName(pd *v1beta1.PCIDevice) error {
+	if pd.Spec.ResourceName == "" || pd.Spec.ResourceName == pd.Status.ResourceName {
+		return nil
+	}
+
+	if _, ok := pd.Annotations[v1beta1.PCIDeviceOverrideResourceName]; ok {
+		return nil
+	}
+
+	pdCopy := pd.DeepCopy()
+	pdCopy.Status.ResourceName = pd.Spec.ResourceName
+	updated, err := h.pdClient.UpdateStatus(pdCopy)
+	if err != nil {
+		return fmt.Errorf("error updating resource name on pcidevice %s: %w", pd.Name, err)
+	}
+	pd.ResourceVersion = updated.ResourceVersion
+	pd.Status.ResourceName = updated.Status.ResourceName
+	return nil
+}
+
+// OnClaimChange requeues the PCIDevice backing a claim, to ensure the observed passthrough state is refreshed
+func (h *SpecHandler) OnClaimChange(_ string, _ string, obj runtime.Object) ([]relatedresource.Key, error) {
+	if pdc, ok := obj.(*v1beta1.PCIDeviceClaim); ok && pdc.Spec.NodeName == h.nodeName {
+		return []relatedresource.Key{relatedresource.NewKey("", pdc.Name)}, nil
+	}
+	return nil, nil
+}
+
+func generateSpecManagedClaim(pd *v1beta1.PCIDevice) *v1beta1.PCIDeviceClaim {
+	return &v1beta1.PCIDeviceClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: pd.Name,
+			Labels: map[string]string{
+				v1beta1.NodeKeyName: pd.Status.NodeName,
+			},
+			Annotations: map[string]string{
+				v1beta1.PCIDeviceSpecManagedClaimKey: "true",
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: v1beta1.SchemeGroupVersion.String(),
+					Kind:       "PCIDevice",
+					Name:       pd.Name,
+					UID:        pd.UID,
+				},
+			},
+		},
+		Spec: v1beta1.PCIDeviceClaimSpec{
+			Address:  pd.Status.Address,
+			NodeName: pd.Status.NodeName,
+			UserName: v1beta1.PCIDeviceSpecClaimUserName,
+			Driver:   pd.Spec.DriverOverride,
+		},
+	}
+}
+
+// specDriver returns the vfio driver requested on the PCIDeviceSpec
+func specDriver(pd *v1beta1.PCIDevice) string {
+	if pd.Spec.DriverOverride == "" {
+		return vfioPCIDriver
+	}
+	return pd.Spec.DriverOverride
+}
+
+func isSpecManagedClaim(pdc *v1beta1.PCIDeviceClaim) bool {
+	return pdc.Annotations[v1beta1.PCIDeviceSpecManagedClaimKey] == "true"
+}
@@ -0,0 +1,161 @@
+package pcideviceclaim
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/pcidevices/pkg/util/fakeclients"
+)
+
+const specTestNode = "node1"
+
+func newSpecTestDevice(passthrough bool) *v1beta1.PCIDevice {
+	return &v1beta1.PCIDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1-000008000",
+		},
+		Spec: v1beta1.PCIDeviceSpec{
+			Passthrough: passthrough,
+		},
+		Status: v1beta1.PCIDeviceStatus{
+			Address:           "0000:08:00.0",
+			NodeName:          specTestNode,
+			ResourceName:      "nvidia.com/GA102GL_A10",
+			KernelDriverInUse: "nvidia",
+		},
+	}
+}
+
+func newSpecTestHandler(client *fake.Clientset) *SpecHandler {
+	return NewSpecHandler(specTestNode,
+		fakeclients.PCIDevicesClient(client.DevicesV1beta1().PCIDevices),
+		fakeclients.PCIDeviceClaimsClient(client.DevicesV1beta1().PCIDeviceClaims),
+		fakeclients.PCIDeviceClaimsCache(client.DevicesV1beta1().PCIDeviceClaims),
+	)
+}
+
+func Test_reconcilePCIDeviceSpecCreatesClaim(t *testing.T) {
+	assert := require.New(t)
+	pd := newSpecTestDevice(true)
+	pd.Spec.ResourceName = "example.com/gpu"
+	client := fake.NewSimpleClientset(pd)
+	h := newSpecTestHandler(client)
+
+	updated, err := h.reconcilePCIDeviceSpec(pd.Name, pd)
+	assert.NoError(err, "expected no error during spec reconcile")
+	assert.NotNil(updated.Status.Passthrough)
+	assert.Equal(v1beta1.PCIDevicePassthroughPending, updated.Status.Passthrough.State)
+	assert.Equal("example.com/gpu", updated.Status.ResourceName, "expected spec resource name to be applied before claiming")
+
+	pdc, err := client.DevicesV1beta1().PCIDeviceClaims().Get(context.TODO(), pd.Name, metav1.GetOptions{})
+	assert.NoError(err, "expected claim to be created")
+	assert.True(isSpecManagedClaim(pdc))
+	assert.Equal(pd.Status.Address, pdc.Spec.Address)
+	assert.Equal(pd.Name, pdc.OwnerReferences[0].Name)
+
+	// once the claim controller enables passthrough the device is reported as synced
+	pdc.Status.PassthroughEnabled = true
+	_, err = client.DevicesV1beta1().PCIDeviceClaims().Update(context.TODO(), pdc, metav1.UpdateOptions{})
+	assert.NoError(err)
+	updated, err = h.reconcilePCIDeviceSpec(pd.Name, updated)
+	assert.NoError(err)
+	assert.True(updated.Status.Passthrough.Enabled)
+	assert.Equal(v1beta1.PCIDevicePassthroughSynced, updated.Status.Passthrough.State)
+}
+
+func Test_reconcilePCIDeviceSpecRemovesManagedClaim(t *testing.T) {
+	assert := require.New(t)
+	pd := newSpecTestDevice(false)
+	pd.Status.Passthrough = &v1beta1.PCIDevicePassthroughStatus{Enabled: true, State: v1beta1.PCIDevicePassthroughSynced}
+	client := fake.NewSimpleClientset(pd, generateSpecManagedClaim(pd))
+	h := newSpecTestHandler(client)
+
+	updated, err := h.reconcilePCIDeviceSpec(pd.Name, pd)
+	assert.NoError(err, "expected no error during spec reconcile")
+	assert.Equal(v1beta1.PCIDevicePassthroughPending, updated.Status.Passthrough.State)
+	_, err = client.DevicesV1beta1().PCIDeviceClaims().Get(context.TODO(), pd.Name, metav1.GetOptions{})
+	assert.True(apierrors.IsNotFound(err), "expected managed claim to be removed")
+
+	updated, err = h.reconcilePCIDeviceSpec(pd.Name, updated)
+	assert.NoError(err)
+	assert.Equal(v1beta1.PCIDevicePassthroughSynced, updated.Status.Passthrough.State)
+	assert.False(updated.Status.Passthrough.Enabled)
+}
+
+func Test_reconcilePCIDeviceSpecKeepsManualClaim(t *testing.T) {
+	assert := require.New(t)
+	pd := newSpecTestDevice(false)
+	pd.Status.Passthrough = &v1beta1.PCIDevicePassthroughStatus{State: v1beta1.PCIDevicePassthroughSynced}
+	pdc := &v1beta1.PCIDeviceClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: pd.Name,
+		},
+		Spec: v1beta1.PCIDeviceClaimSpec{
+			Address:  pd.Status.Address,
+			NodeName: specTestNode,
+			UserName: "admin",
+		},
+	}
+	client := fake.NewSimpleClientset(pd, pdc)
+	h := newSpecTestHandler(client)
+
+	updated, err := h.reconcilePCIDeviceSpec(pd.Name, pd)
+	assert.NoError(err, "expected no error during spec reconcile")
+	assert.Equal(v1beta1.PCIDevicePassthroughOutOfSync, updated.Status.Passthrough.State)
+	_, err = client.DevicesV1beta1().PCIDeviceClaims().Get(context.TODO(), pd.Name, metav1.GetOptions{})
+	assert.NoError(err, "expected manual claim to be left in place")
+}
+
+func Test_reconcilePCIDeviceSpecUnsupportedDriver(t *testing.T) {
+	assert := require.New(t)
+	pd := newSpecTestDevice(true)
+	pd.Spec.DriverOverride = "nouveau"
+	client := fake.NewSimpleClientset(pd)
+	h := newSpecTestHandler(client)
+
+	updated, err := h.reconcilePCIDeviceSpec(pd.Name, pd)
+	assert.NoError(err, "expected no error during spec reconcile")
+	assert.Equal(v1beta1.PCIDevicePassthroughFailed, updated.Status.Passthrough.State)
+	_, err = client.DevicesV1beta1().PCIDeviceClaims().Get(context.TODO(), pd.Name, metav1.GetOptions{})
+	assert.True(apierrors.IsNotFound(err), "expected no claim to be created")
+}
+
+func Test_reconcilePCIDeviceSpecIgnoresUnmanagedDevices(t *testing.T) {
+	assert := require.New(t)
+	pd := newSpecTestDevice(false)
+	client := fake.NewSimpleClientset(pd)
+	h := newSpecTestHandler(client)
+
+	updated, err := h.reconcilePCIDeviceSpec(pd.Name, pd)
+	assert.NoError(err)
+	assert.Nil(updated.Status.Passthrough, "expected no passthrough status on devices not managed from the spec")
+}
+
+func Test_reconcilePCIDeviceSpecVariantDriver(t *testing.T) {
+	assert := require.New(t)
+	pd := newSpecTestDevice(true)
+	pd.Spec.DriverOverride = "nvgrace_gpu_vfio_pci"
+	client := fake.NewSimpleClientset(pd)
+	h := newSpecTestHandler(client)
+
+	updated, err := h.reconcilePCIDeviceSpec(pd.Name, pd)
+	assert.NoError(err, "expected no error during spec reconcile")
+	assert.Equal(v1beta1.PCIDevicePassthroughPending, updated.Status.Passthrough.State)
+	pdc, err := client.DevicesV1beta1().PCIDeviceClaims().Get(context.TODO(), pd.Name, metav1.GetOptions{})
+	assert.NoError(err, "expected claim to be created")
+	assert.Equal("nvgrace_gpu_vfio_pci", pdc.Spec.VFIODriver())
+
+	pdc.Status.PassthroughEnabled = true
+	_, err = client.DevicesV1beta1().PCIDeviceClaims().Update(context.TODO(), pdc, metav1.UpdateOptions{})
+	assert.NoError(err)
+	updated.Spec.DriverOverride = ""
+	updated, err = h.reconcilePCIDeviceSpec(pd.Name, updated)
+	assert.NoError(err)
+	assert.Equal(v1beta1.PCIDevicePassthroughOutOfSync, updated.Status.Passthrough.State, "expected driver change on a bound device to be reported")
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"sync"
@@ -26,6 +27,7 @@ import (
 	"github.com/harvester/pcidevices/pkg/deviceplugins"
 	v1beta1gen "github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
 	"github.com/harvester/pcidevices/pkg/iommu"
+	"github.com/harvester/pcidevices/pkg/util/common"
 )
 
 var (
@@ -43,7 +45,7 @@ var (
 )
 
 const (
-	vfioPCIDriver     = "vfio-pci"
+	vfioPCIDriver     = v1beta1.VFIOPCIDriver
 	DefaultNS         = "harvester-system"
 	KubevirtCR        = "kubevirt"
 	vfioPCIDriverPath = "/sys/bus/pci/drivers/vfio-pci"
@@ -89,6 +91,11 @@ func Register(ctx context.Context, management *config.FactoryManager) error {
 	// Watch to check for updates to pcidevices. This can happen on reboot as devices are set to reflect the correct
 	// driver in use by said device. This helps ensure that associated claim is reconciled to trigger rebind if needed
 	relatedresource.WatchClusterScoped(ctx, "PCIDeviceToClaimReconcile", handler.OnDeviceChange, pdcClient, pdClient)
+
+	// declarative passthrough is reconciled by managing claims for devices on this node
+	specHandler := NewSpecHandler(nodeName, pdClient, pdcClient, pdcClient.Cache())
+	pdClient.OnChange(ctx, "PCIDeviceSpecReconcile", specHandler.reconcilePCIDeviceSpec)
+	relatedresource.WatchClusterScoped(ctx, "PCIDeviceClaimToDeviceReconcile", specHandler.OnClaimChange, pdClient, pdcClient)
 	err = handler.unbindOrphanedPCIDevices()
 	if err != nil {
 		return err
@@ -127,10 +134,23 @@ func (h *Handler) OnRemove(_ string, pdc *v1beta1.PCIDeviceClaim) (*v1beta1.PCID
 
 	// Disable PCI Passthrough by unbinding from the vfio-pci device driver
 	if !skipDeviceBindingOp(pdc) {
-		err = h.disablePassthrough(pd)
+		// the reset is recorded even if the release fails, to avoid resetting the device again on retries
+		reset, err := h.disablePassthrough(pd, pdc)
+		if reset != nil {
+			pdcCopy := pdc.DeepCopy()
+			pdcCopy.Status.LastReset = reset
+			if updated, err := h.pdcClient.UpdateStatus(pdcCopy); err != nil {
+				logrus.Errorf("error recording reset on pcideviceclaim %s: %v", pdc.Name, err)
+			} else {
+				pdc = updated
+			}
+		}
 		if err != nil {
 			return pdc, err
 		}
+		if err := releaseIOMMUGroupMembers(sysfsGroupMemberDriver{}, pdc.Status.IOMMUGroupMembers); err != nil {
+			return pdc, fmt.Errorf("error releasing iommu group members: %w", err)
+		}
 	}
 	// Find the DevicePlugin
 	resourceName := pd.Status.ResourceName
@@ -216,66 +236,90 @@ func checkModulesExists(filename string, modules []string) (bool, error) {
 	return false, nil
 }
 
-func bindDeviceToVFIOPCIDriver(pd *v1beta1.PCIDevice) error {
-	if deviceBoundToDriver(vfioPCIDriverPath, pd.Status.Address) {
+func bindDeviceToVFIODriver(pd *v1beta1.PCIDevice, driver string) error {
+	if deviceBoundToDriver(vfioDriverPath(driver), pd.Status.Address) {
 		return nil
 	}
 
-	vendorID := pd.Status.VendorID
-	deviceID := pd.Status.DeviceID
-	id := fmt.Sprintf("%s %s", vendorID, deviceID)
-	logrus.Infof("Binding device %s [%s] to vfio-pci", pd.Name, id)
+	return bindToVFIODriver(pd.Name, pd.Status.Address, driver)
+}
 
-	newIDFile, err := os.OpenFile("/sys/bus/pci/drivers/vfio-pci/new_id", os.O_WRONLY, 0200)
-	if err != nil {
-		return fmt.Errorf("error opening new_id file: %v", err)
+// bindToVFIODriver binds a single device to a vfio driver using driver_override, so other devices
+// sharing the same vendor and device id are left with their current drivers
+func bindToVFIODriver(name, address, driver string) error {
+	logrus.Infof("Binding device %s [%s] to %s", name, address, driver)
+	if err := loadVFIODriver(driver); err != nil {
+		return err
 	}
-	defer newIDFile.Close()
-	_, err = newIDFile.WriteString(id)
-	if err != nil && !os.IsExist(err) {
-		return fmt.Errorf("error writing to new_id file: %v", err)
+
+	if err := setDriverOverride(sysBusPCIDevices, address, driver); err != nil {
+		return err
 	}
 
-	logrus.Infof("Binding device %s vfio-pci", pd.Status.Address)
-	file, err := os.OpenFile("/sys/bus/pci/drivers/vfio-pci/bind", os.O_WRONLY, 0200)
-	if err != nil {
-		return fmt.Errorf("error opening bind file: %s", err)
+	if err := probeDevice(address); err != nil {
+		return err
 	}
-	_, err = file.WriteString(pd.Status.Address)
-	if err != nil {
-		file.Close()
-		return fmt.Errorf("error writing to bind file: %s", err)
+
+	if !deviceBoundToDriver(vfioDriverPath(driver), address) {
+		return fmt.Errorf("no device %s found at %s", address, vfioDriverPath(driver))
 	}
-	file.Close()
+	return nil
+}
 
-	if !deviceBoundToDriver(vfioPCIDriverPath, pd.Status.Address) {
-		return fmt.Errorf("no device %s found at /sys/bus/pci/drivers/vfio-pci", pd.Status.Address)
+// loadVFIODriver loads the module for a vfio variant driver, if it is not already loaded.
+// vfio-pci itself is loaded when the controller starts
+func loadVFIODriver(driver string) error {
+	if _, err := os.Stat(vfioDriverPath(driver)); err == nil {
+		return nil
+	}
+
+	logrus.Infof("Loading driver %s", driver)
+	if err := kmodule.Probe(driver, ""); err != nil {
+		return fmt.Errorf("error loading driver %s: %w", driver, err)
 	}
 	return nil
 }
 
+func vfioDriverPath(driver string) string {
+	return filepath.Join("/sys/bus/pci/drivers", driver)
+}
+
 // Enabling passthrough for a PCI Device requires two steps:
 // 1. Bind the device to the vfio-pci driver in the host
 // 2. Add device to DevicePlugin so KubeVirt will recognize it
-func (h Handler) enablePassthrough(pd *v1beta1.PCIDevice) error {
-	err := bindDeviceToVFIOPCIDriver(pd)
+func (h Handler) enablePassthrough(pd *v1beta1.PCIDevice, driver string) error {
+	err := bindDeviceToVFIODriver(pd, driver)
 	if err != nil {
 		return err
 	}
 	pdCopy := pd.DeepCopy()
-	pdCopy.Status.KernelDriverInUse = vfioPCIDriver
+	pdCopy.Status.KernelDriverInUse = driver
 	_, err = h.pdClient.UpdateStatus(pdCopy)
 	return err
 }
 
-// disablePassthrough will unbind and bind device to the original driver
-func (h *Handler) disablePassthrough(pd *v1beta1.PCIDevice) error {
-	err := unbindDeviceFromDriver(pd.Status.Address, vfioPCIDriver)
-	if err != nil {
-		return fmt.Errorf("failed unbinding driver: (%s)", err)
+// disablePassthrough will unbind the device from the vfio driver it is bound to, and bind it to the original driver.
+// The device is reset in between if required by the claim, and the result of the reset is returned
+func (h *Handler) disablePassthrough(pd *v1beta1.PCIDevice, pdc *v1beta1.PCIDeviceClaim) (*v1beta1.PCIDeviceClaimResetStatus, error) {
+	if driver := boundDriver(pd.Status.Address); v1beta1.IsVFIODriver(driver) {
+		if err := unbindDeviceFromDriver(pd.Status.Address, driver); err != nil {
+			return nil, fmt.Errorf("failed unbinding driver: (%s)", err)
+		}
+	}
+
+	var reset *v1beta1.PCIDeviceClaimResetStatus
+	if !releaseResetDone(pdc) {
+		reset = resetClaimedDevice(sysBusPCIDevices, pd, pdc, v1beta1.ResetPhaseAfterRelease)
+	}
+	if err := clearVFIODriverOverride(pd.Status.Address); err != nil {
+		return reset, err
 	}
 
-	return h.bindDeviceToOriginalDriver(pd)
+	if err := h.bindDeviceToOriginalDriver(pd); err != nil {
+		return reset, err
+	}
+
+	return reset, h.updateDeviceResetCondition(pd.Name, reset)
 }
 
 // This function unbinds the device with PCI Address addr from the given driver
@@ -305,15 +349,18 @@ func unbindDeviceFromDriver(addr string, driver string) error {
 
 func pciDeviceIsClaimed(pd *v1beta1.PCIDevice, pdcs *v1beta1.PCIDeviceClaimList, nodeName string) bool {
 	for _, pdc := range pdcs.Items {
-		if pd.Status.NodeName != nodeName {
+		if pdc.Spec.NodeName != nodeName {
 			continue
 		}
-		if pdc.OwnerReferences == nil {
-			return false
-		}
-		if pdc.OwnerReferences[0].Name == pd.Name {
+		if len(pdc.OwnerReferences) != 0 && pdc.OwnerReferences[0].Name == pd.Name {
 			return true
 		}
+		// devices bound as part of an iommu group claim are owned by the claim of the group
+		for _, member := range pdc.Status.IOMMUGroupMembers {
+			if member.Address == pd.Status.Address {
+				return true
+			}
+		}
 	}
 	return false
 }
@@ -327,7 +374,7 @@ func getOrphanedPCIDevices(
 	pdsOrphaned := v1beta1.PCIDeviceList{}
 	for i := range pds.Items {
 		pd := pds.Items[i] // fix G601: Implicit memory aliasing in for loop. (gosec)
-		isVfioPci := pd.Status.KernelDriverInUse == "vfio-pci"
+		isVfioPci := v1beta1.IsVFIODriver(pd.Status.KernelDriverInUse)
 		isOnThisNode := nodeName == pd.Status.NodeName
 		if isVfioPci && isOnThisNode && !pciDeviceIsClaimed(&pd, pdcs, nodeName) {
 			pdsOrphaned.Items = append(pdsOrphaned.Items, *pd.DeepCopy())
@@ -444,12 +491,23 @@ func (h *Handler) reconcilePCIDeviceClaims(_ string, pdc *v1beta1.PCIDeviceClaim
 	// Enable PCI Passthrough on the device by binding it to vfio-pci driver
 	// for certain devices like nvidia vGPUs on kernel 6.8+ the vGPU is passed
 	// through as a normal PCIdevice but we need to skip binding to vfio driver
+	boundReason := v1beta1.ReasonDriverBound
+	groupMembers := pdcCopy.Status.IOMMUGroupMembers
 	if !skipDeviceBindingOp(pdc) {
-		err = h.attemptToEnablePassthrough(pd, pdc)
+		var reset *v1beta1.PCIDeviceClaimResetStatus
+		if pdc.Spec.ClaimIOMMUGroup {
+			groupMembers, reset, err = h.attemptToEnableGroupPassthrough(pd, pdc)
+		} else {
+			reset, err = h.attemptToEnablePassthrough(pd, pdc)
+		}
+		if reset != nil {
+			pdcCopy.Status.LastReset = reset
+		}
 		if err != nil {
-			return pdc, err
+			return h.markClaimDegraded(pdcCopy, v1beta1.ReasonDriverBindFailed, err)
 		}
 	} else {
+		boundReason = v1beta1.ReasonBindingSkipped
 		// because device is not going to be bound to vfio
 		// this is likely a vgpu device
 		// so we are going to check resourceName generated by vgpu controller from pdc annotation
@@ -469,26 +527,67 @@ func (h *Handler) reconcilePCIDeviceClaims(_ string, pdc *v1beta1.PCIDeviceClaim
 		pds := []*v1beta1.PCIDevice{pd}
 		dp, err = h.createDevicePlugin(pds, pdc, resourceName)
 		if err != nil {
-			return pdc, err
+			return h.markClaimDegraded(pdcCopy, v1beta1.ReasonPluginFailed, err)
 		}
 		// new plugin created. Need to store state.
 		h.devicePlugins[resourceName] = dp
 	} else {
 		// Add the Device to the DevicePlugin
 		if err := dp.AddDevice(pd, pdc); err != nil {
-			return pdc, err
+			return h.markClaimDegraded(pdcCopy, v1beta1.ReasonPluginFailed, err)
 		}
 	}
 
+	changed := setClaimReadyConditions(pdcCopy, boundReason, dp.Started())
+	if !reflect.DeepEqual(pdcCopy.Status.LastReset, pdc.Status.LastReset) {
+		changed = true
+	}
+	if !reflect.DeepEqual(pdcCopy.Status.IOMMUGroupMembers, groupMembers) {
+		pdcCopy.Status.IOMMUGroupMembers = groupMembers
+		changed = true
+	}
 	if !pdcCopy.Status.PassthroughEnabled {
 		pdcCopy.Status.PassthroughEnabled = true
 		pdcCopy.Status.KernelDriverToUnbind = pd.Status.KernelDriverInUse
+		changed = true
+	}
+
+	if changed {
 		return h.pdcClient.UpdateStatus(pdcCopy)
 	}
 
 	return pdc, nil
 }
 
+// setClaimReadyConditions records a successfully bound claim, which is ready once the device plugin is serving it.
+// It returns true if any of the conditions changed
+func setClaimReadyConditions(pdc *v1beta1.PCIDeviceClaim, boundReason string, pluginStarted bool) bool {
+	conditions := &pdc.Status.Conditions
+	changed := common.SetCondition(conditions, pdc.Generation, v1beta1.ConditionBound, true, boundReason, "")
+	reason, message := v1beta1.ReasonPending, "device plugin is not started"
+	if pluginStarted {
+		reason, message = v1beta1.ReasonPluginRegistered, ""
+	}
+	changed = common.SetCondition(conditions, pdc.Generation, v1beta1.ConditionPluginRegistered, pluginStarted, reason, message) || changed
+	if pluginStarted {
+		reason = v1beta1.ReasonReconciled
+	}
+	changed = common.SetCondition(conditions, pdc.Generation, v1beta1.ConditionReady, pluginStarted, reason, message) || changed
+	return common.SetDegraded(conditions, pdc.Generation, v1beta1.ReasonReconciled, nil) || changed
+}
+
+// markClaimDegraded also records whether binding the driver or registering the device plugin failed
+func (h *Handler) markClaimDegraded(pdc *v1beta1.PCIDeviceClaim, reason string, reconcileErr error) (*v1beta1.PCIDeviceClaim, error) {
+	conditions := &pdc.Status.Conditions
+	if reason == v1beta1.ReasonDriverBindFailed {
+		common.SetCondition(conditions, pdc.Generation, v1beta1.ConditionBound, false, reason, reconcileErr.Error())
+	} else {
+		common.SetCondition(conditions, pdc.Generation, v1beta1.ConditionPluginRegistered, false, reason, reconcileErr.Error())
+	}
+	common.MarkDegraded(conditions, pdc.Generation, reason, reconcileErr)
+	return common.UpdateDegradedStatus("pcideviceclaim", pdc, reconcileErr, h.pdcClient.UpdateStatus)
+}
+
 func (h *Handler) createDevicePlugin(
 	pds []*v1beta1.PCIDevice,
 	pdc *v1beta1.PCIDeviceClaim,
@@ -592,14 +691,18 @@ func (h *Handler) startDevicePlugin(
 	return nil
 }
 
-func (h *Handler) attemptToEnablePassthrough(pd *v1beta1.PCIDevice, pdc *v1beta1.PCIDeviceClaim) error {
-	if !deviceBoundToDriver(vfioPCIDriverPath, pd.Status.Address) {
+// attemptToEnablePassthrough binds the claimed device to the vfio driver requested by the claim. The device is reset
+// before it is bound if required by the claim, and the result of the reset is returned
+func (h *Handler) attemptToEnablePassthrough(pd *v1beta1.PCIDevice, pdc *v1beta1.PCIDeviceClaim) (*v1beta1.PCIDeviceClaimResetStatus, error) {
+	driver := pdc.Spec.VFIODriver()
+	var reset *v1beta1.PCIDeviceClaimResetStatus
+	if !deviceBoundToDriver(vfioDriverPath(driver), pd.Status.Address) {
 		logrus.Infof("Enabling passthrough for PDC: %s", pdc.Name)
 		// Only unbind from driver is a driver is currently in use
 		if strings.TrimSpace(pd.Status.KernelDriverInUse) != "" {
 			err := unbindDeviceFromDriver(pd.Status.Address, pd.Status.KernelDriverInUse)
 			if err != nil {
-				return err
+				return nil, err
 			}
 		}
 
@@ -607,21 +710,52 @@ func (h *Handler) attemptToEnablePassthrough(pd *v1beta1.PCIDevice, pdc *v1beta1
 		if ok {
 			err := unbindDeviceFromDriver(pd.Status.Address, originalDriver)
 			if err != nil {
-				return err
+				return nil, err
 			}
 		}
-		// Enable PCI Passthrough by binding the device to the vfio-pci driver
-		err := h.enablePassthrough(pd)
+		reset = resetClaimedDevice(sysBusPCIDevices, pd, pdc, v1beta1.ResetPhaseBeforeBind)
+
+		// Enable PCI Passthrough by binding the device to the vfio driver requested by the claim
+		err := h.enablePassthrough(pd, driver)
 		if err != nil {
-			return err
+			return reset, err
+		}
+
+		if err := h.updateDeviceResetCondition(pd.Name, reset); err != nil {
+			return reset, err
 		}
 	}
 
 	pdc.Status.PassthroughEnabled = true
-	return nil
+	return reset, nil
 
 }
 
+// attemptToEnableGroupPassthrough binds the claimed device along with every other member of its iommu group.
+// If the claimed device fails to bind, the rest of the group is returned to its original drivers.
+// The result of resetting the claimed device is returned along with the members
+func (h *Handler) attemptToEnableGroupPassthrough(pd *v1beta1.PCIDevice, pdc *v1beta1.PCIDeviceClaim) ([]v1beta1.PCIDeviceClaimGroupMember, *v1beta1.PCIDeviceClaimResetStatus, error) {
+	addresses, err := iommuGroupEndpoints(pd.Status.Address)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error looking up iommu group members for %s: %w", pd.Name, err)
+	}
+
+	driver := sysfsGroupMemberDriver{}
+	members, err := bindIOMMUGroupMembers(driver, addresses, pdc.Status.IOMMUGroupMembers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reset, err := h.attemptToEnablePassthrough(pd, pdc)
+	if err != nil {
+		if rollbackErr := releaseIOMMUGroupMembers(driver, members); rollbackErr != nil {
+			return nil, reset, errors.Join(err, fmt.Errorf("error rolling back iommu group: %w", rollbackErr))
+		}
+		return nil, reset, err
+	}
+	return members, reset, nil
+}
+
 func (h *Handler) unbindOrphanedPCIDevices() error {
 	pdcs, err := h.pdcClient.List(metav1.ListOptions{})
 	if err != nil {
@@ -636,18 +770,30 @@ func (h *Handler) unbindOrphanedPCIDevices() error {
 		return err
 	}
 	for _, pd := range orphanedPCIDevices.Items {
-		if err := unbindDeviceFromDriver(pd.Status.Address, vfioPCIDriver); err != nil {
+		if err := unbindDeviceFromDriver(pd.Status.Address, pd.Status.KernelDriverInUse); err != nil {
+			return err
+		}
+		if err := clearVFIODriverOverride(pd.Status.Address); err != nil {
 			return err
 		}
 	}
-	return nil
+
+	var nodeDevices []v1beta1.PCIDevice
+	for _, pd := range pds.Items {
+		if pd.Status.NodeName == h.nodeName {
+			nodeDevices = append(nodeDevices, pd)
+		}
+	}
+	return migrateVFIODynamicIDs(nodeDevices, func(pd *v1beta1.PCIDevice) bool {
+		return pciDeviceIsClaimed(pd, pdcs, h.nodeName)
+	})
 }
 
 // OnDeviceChange will watch the PCIDevice objects and trigger a reconcile of related PCIDeviceClaims if the underlying PCIDevice objects has a change.
 // this can happen at reboot when device driver is updated to reflect in use device driver
 func (h *Handler) OnDeviceChange(_ string, _ string, obj runtime.Object) ([]relatedresource.Key, error) {
 	if pd, ok := obj.(*v1beta1.PCIDevice); ok {
-		if pd.Status.NodeName == h.nodeName && pd.Status.KernelDriverInUse != vfioPCIDriver {
+		if pd.Status.NodeName == h.nodeName && !v1beta1.IsVFIODriver(pd.Status.KernelDriverInUse) {
 			pdcList, err := h.pdcClient.List(metav1.ListOptions{LabelSelector: fmt.Sprintf("nodename=%s", h.nodeName)})
 			if err != nil {
 				return nil, fmt.Errorf("error listing PCIDeviceClaims during device watch: %v", err)
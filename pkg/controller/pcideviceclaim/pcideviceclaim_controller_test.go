@@ -9,6 +9,7 @@ import (
 	kubevirtv1 "kubevirt.io/api/core/v1"
 
 	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/util/common"
 )
 
 func TestHandler_getOrphanedPCIDevices(t *testing.T) {
@@ -49,6 +50,35 @@ func TestHandler_getOrphanedPCIDevices(t *testing.T) {
 				},
 			},
 		},
+		Spec: v1beta1.PCIDeviceClaimSpec{
+			NodeName: "testnode1",
+		},
+	}
+	// a claim without owner references, such as one created before owner references were set
+	unownedPDC := v1beta1.PCIDeviceClaim{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "testnode1-00003f064",
+		},
+		Spec: v1beta1.PCIDeviceClaimSpec{
+			NodeName: "testnode1",
+		},
+	}
+	groupPDC := v1beta1.PCIDeviceClaim{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "testnode1-00003f065",
+			OwnerReferences: []v1.OwnerReference{
+				{
+					Kind: "PCIDevice",
+					Name: "testnode1-00003f065",
+				},
+			},
+		},
+		Spec: v1beta1.PCIDeviceClaimSpec{
+			NodeName: "testnode1",
+		},
+		Status: v1beta1.PCIDeviceClaimStatus{
+			IOMMUGroupMembers: []v1beta1.PCIDeviceClaimGroupMember{{Address: orphanpd.Status.Address}},
+		},
 	}
 
 	tests := []struct {
@@ -91,6 +121,25 @@ func TestHandler_getOrphanedPCIDevices(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "PCIDevice bound as member of an iommu group claim listed after a claim without owner references",
+			args: args{
+				nodename: "testnode1",
+				pdcs: &v1beta1.PCIDeviceClaimList{
+					Items: []v1beta1.PCIDeviceClaim{
+						unownedPDC,
+						groupPDC,
+					},
+				},
+				pds: &v1beta1.PCIDeviceList{
+					Items: []v1beta1.PCIDevice{
+						orphanpd, // this should not be returned, since it's a member of the group claim
+					},
+				},
+			},
+			want:    &v1beta1.PCIDeviceList{},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -178,3 +227,27 @@ func Test_checkModulesExists(t *testing.T) {
 	assert.NoError(err)
 	assert.False(found)
 }
+
+func Test_setClaimReadyConditions(t *testing.T) {
+	assert := require.New(t)
+	pdc := &v1beta1.PCIDeviceClaim{
+		ObjectMeta: v1.ObjectMeta{
+			Name:       "node1-000008000",
+			Generation: 2,
+		},
+	}
+
+	assert.True(setClaimReadyConditions(pdc, v1beta1.ReasonDriverBound, false), "expected conditions to be added")
+	assert.True(common.IsConditionTrue(pdc.Status.Conditions, v1beta1.ConditionBound))
+	assert.False(common.IsConditionTrue(pdc.Status.Conditions, v1beta1.ConditionPluginRegistered))
+	assert.False(common.IsConditionTrue(pdc.Status.Conditions, v1beta1.ConditionReady), "expected claim to not be ready until device plugin is started")
+
+	assert.True(setClaimReadyConditions(pdc, v1beta1.ReasonDriverBound, true), "expected conditions to change once plugin is started")
+	assert.True(common.IsConditionTrue(pdc.Status.Conditions, v1beta1.ConditionReady))
+	assert.False(common.IsConditionTrue(pdc.Status.Conditions, v1beta1.ConditionDegraded))
+	for _, c := range pdc.Status.Conditions {
+		assert.Equal(pdc.Generation, c.ObservedGeneration, "expected observedGeneration to be recorded on %s", c.Type)
+	}
+
+	assert.False(setClaimReadyConditions(pdc, v1beta1.ReasonDriverBound, true), "expected no change when reconciling the same state")
+}
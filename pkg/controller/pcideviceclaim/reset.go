@@ -0,0 +1,122 @@
+package pcideviceclaim
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/util/common"
+)
+
+// resetDevice resets the device at address through sysfs. When methods are set the kernel is restricted to them
+// for this reset only. It returns the reset methods the kernel was allowed to use
+func resetDevice(pciDevicesPath, address string, methods []string) ([]string, error) {
+	devicePath := filepath.Join(pciDevicesPath, address)
+	if _, err := os.Stat(filepath.Join(devicePath, "reset")); err != nil {
+		return nil, fmt.Errorf("device %s does not support reset", address)
+	}
+
+	if len(methods) > 0 {
+		if err := writeSysfsAttribute(devicePath, "reset_method", strings.Join(methods, " ")); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err := writeSysfsAttribute(devicePath, "reset_method", "default"); err != nil {
+				logrus.Warnf("error restoring default reset methods for device %s: %v", address, err)
+			}
+		}()
+	}
+
+	// #nosec G304 No risk for path injection. Reading static sysfs attributes of a device
+	contents, _ := os.ReadFile(filepath.Join(devicePath, "reset_method"))
+	used := strings.Fields(string(contents))
+
+	logrus.Infof("Resetting device %s", address)
+	if err := writeSysfsAttribute(devicePath, "reset", "1"); err != nil {
+		return used, err
+	}
+	return used, nil
+}
+
+func writeSysfsAttribute(devicePath, attribute, value string) error {
+	file, err := os.OpenFile(filepath.Join(devicePath, attribute), os.O_WRONLY, 0200)
+	if err != nil {
+		return fmt.Errorf("error opening %s: %w", attribute, err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(value); err != nil {
+		return fmt.Errorf("error writing %s to %s: %w", value, attribute, err)
+	}
+	return nil
+}
+
+// resetClaimedDevice resets the device when the reset policy of the claim requires it during phase.
+// The device must not be bound to any driver. A nil result is returned when no reset was attempted
+func resetClaimedDevice(pciDevicesPath string, pd *v1beta1.PCIDevice, pdc *v1beta1.PCIDeviceClaim, phase v1beta1.PCIDeviceResetPhase) *v1beta1.PCIDeviceClaimResetStatus {
+	if !pdc.Spec.ResetOnPhase(phase) {
+		return nil
+	}
+
+	methods, err := resetDevice(pciDevicesPath, pd.Status.Address, pdc.Spec.ResetMethods)
+	result := &v1beta1.PCIDeviceClaimResetStatus{
+		Phase:     phase,
+		Methods:   methods,
+		Succeeded: err == nil,
+		Time:      metav1.Now(),
+	}
+	if err != nil {
+		logrus.Errorf("error resetting pcidevice %s for pcideviceclaim %s: %v", pd.Name, pdc.Name, err)
+		result.Message = err.Error()
+	}
+	return result
+}
+
+// releaseResetDone returns true if the device was already reset after it was released from a claim being removed,
+// so retries of a failed removal do not reset the device again
+func releaseResetDone(pdc *v1beta1.PCIDeviceClaim) bool {
+	return pdc.DeletionTimestamp != nil && pdc.Status.LastReset != nil && pdc.Status.LastReset.Phase == v1beta1.ResetPhaseAfterRelease &&
+		!pdc.Status.LastReset.Time.Before(pdc.DeletionTimestamp)
+}
+
+// updateDeviceResetCondition marks the device degraded when a reset failed, and clears a previous reset failure
+// once a reset succeeds. The device is looked up again, as its status is updated while it is bound and released
+func (h *Handler) updateDeviceResetCondition(name string, result *v1beta1.PCIDeviceClaimResetStatus) error {
+	if result == nil {
+		return nil
+	}
+
+	pd, err := h.pdClient.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error looking up pcidevice %s: %w", name, err)
+	}
+
+	pdCopy := pd.DeepCopy()
+	conditions := &pdCopy.Status.Conditions
+	var changed bool
+	if !result.Succeeded {
+		changed = common.SetCondition(conditions, pd.Generation, v1beta1.ConditionDegraded, true, v1beta1.ReasonResetFailed, result.Message)
+	} else if isResetFailure(pd.Status.Conditions) {
+		changed = common.SetDegraded(conditions, pd.Generation, v1beta1.ReasonReconciled, nil)
+	}
+
+	if !changed {
+		return nil
+	}
+	_, err = h.pdClient.UpdateStatus(pdCopy)
+	return err
+}
+
+func isResetFailure(conditions []metav1.Condition) bool {
+	for _, v := range conditions {
+		if v.Type == v1beta1.ConditionDegraded && v.Status == metav1.ConditionTrue && v.Reason == v1beta1.ReasonResetFailed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package pcideviceclaim
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/pcidevices/pkg/util/common"
+	"github.com/harvester/pcidevices/pkg/util/fakeclients"
+)
+
+func Test_resetDevice(t *testing.T) {
+	assert := require.New(t)
+	root := t.TempDir()
+	address := "0000:08:00.0"
+	devicePath := filepath.Join(root, address)
+	assert.NoError(os.MkdirAll(devicePath, 0755))
+	assert.NoError(os.WriteFile(filepath.Join(devicePath, "reset"), nil, 0600))
+	assert.NoError(os.WriteFile(filepath.Join(devicePath, "reset_method"), []byte("flr bus\n"), 0600))
+
+	methods, err := resetDevice(root, address, nil)
+	assert.NoError(err, "expected no error resetting device")
+	assert.Equal([]string{"flr", "bus"}, methods)
+	contents, err := os.ReadFile(filepath.Join(devicePath, "reset"))
+	assert.NoError(err)
+	assert.Equal("1", string(contents))
+
+	assert.NoError(os.Truncate(filepath.Join(devicePath, "reset_method"), 0))
+	methods, err = resetDevice(root, address, []string{"bus"})
+	assert.NoError(err, "expected no error resetting device with restricted methods")
+	assert.Equal([]string{"bus"}, methods)
+	contents, err = os.ReadFile(filepath.Join(devicePath, "reset_method"))
+	assert.NoError(err)
+	assert.Equal("default", string(contents), "expected reset methods to be restored")
+
+	_, err = resetDevice(root, "0000:09:00.0", nil)
+	assert.Error(err, "expected error for device without reset support")
+}
+
+func Test_resetClaimedDevicePolicy(t *testing.T) {
+	assert := require.New(t)
+	pd := &v1beta1.PCIDevice{Status: v1beta1.PCIDeviceStatus{Address: "0000:09:00.0"}}
+	pdc := &v1beta1.PCIDeviceClaim{}
+	assert.Nil(resetClaimedDevice(t.TempDir(), pd, pdc, v1beta1.ResetPhaseAfterRelease), "expected no reset by default")
+
+	pdc.Spec.ResetPolicy = v1beta1.ResetPolicyOnRelease
+	assert.Nil(resetClaimedDevice(t.TempDir(), pd, pdc, v1beta1.ResetPhaseBeforeBind), "expected no reset before bind")
+	result := resetClaimedDevice(t.TempDir(), pd, pdc, v1beta1.ResetPhaseAfterRelease)
+	assert.NotNil(result)
+	assert.False(result.Succeeded, "expected reset of a device without reset support to fail")
+	assert.Equal(v1beta1.ResetPhaseAfterRelease, result.Phase)
+}
+
+func Test_updateDeviceResetCondition(t *testing.T) {
+	assert := require.New(t)
+	pd := &v1beta1.PCIDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1-000008000",
+		},
+	}
+	client := fake.NewSimpleClientset(pd)
+	h := &Handler{
+		pdClient: fakeclients.PCIDevicesClient(client.DevicesV1beta1().PCIDevices),
+	}
+
+	assert.NoError(h.updateDeviceResetCondition(pd.Name, &v1beta1.PCIDeviceClaimResetStatus{Message: "reset failed"}))
+	updated, err := client.DevicesV1beta1().PCIDevices().Get(context.TODO(), pd.Name, metav1.GetOptions{})
+	assert.NoError(err)
+	assert.True(common.IsConditionTrue(updated.Status.Conditions, v1beta1.ConditionDegraded), "expected failed reset to mark device degraded")
+
+	assert.NoError(h.updateDeviceResetCondition(pd.Name, &v1beta1.PCIDeviceClaimResetStatus{Succeeded: true}))
+	updated, err = client.DevicesV1beta1().PCIDevices().Get(context.TODO(), pd.Name, metav1.GetOptions{})
+	assert.NoError(err)
+	assert.False(common.IsConditionTrue(updated.Status.Conditions, v1beta1.ConditionDegraded), "expected successful reset to clear degraded")
+}
+
+func Test_releaseResetDone(t *testing.T) {
+	assert := require.New(t)
+	deleted := metav1.NewTime(time.Now())
+	pdc := &v1beta1.PCIDeviceClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			DeletionTimestamp: &deleted,
+		},
+		Status: v1beta1.PCIDeviceClaimStatus{
+			LastReset: &v1beta1.PCIDeviceClaimResetStatus{
+				Phase: v1beta1.ResetPhaseBeforeBind,
+				Time:  metav1.NewTime(deleted.Add(-time.Hour)),
+			},
+		},
+	}
+	assert.False(releaseResetDone(pdc), "expected reset before bind to be ignored")
+
+	pdc.Status.LastReset.Phase = v1beta1.ResetPhaseAfterRelease
+	assert.False(releaseResetDone(pdc), "expected reset before the claim was removed to be ignored")
+
+	pdc.Status.LastReset.Time = metav1.NewTime(deleted.Add(time.Second))
+	assert.True(releaseResetDone(pdc), "expected reset after the claim was removed to be skipped on retry")
+}
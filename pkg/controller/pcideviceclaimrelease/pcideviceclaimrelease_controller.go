@@ -0,0 +1,194 @@
+package pcideviceclaimrelease
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rancher/wrangler/v3/pkg/relatedresource"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/config"
+	"github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
+	ctlkubevirtv1 "github.com/harvester/pcidevices/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/pcidevices/pkg/util/common"
+)
+
+// creatorGracePeriod is how long a claim created for a VM by the VM mutator is kept before the VM is looked up, as the
+// claim is created while the VM is being admitted
+const creatorGracePeriod = time.Minute
+
+// Handler tracks the VMs each PCIDeviceClaim is allocated to, and removes claims once their release policy allows it.
+// The reason for the release is recorded in the Released condition while the claim is being removed
+type Handler struct {
+	claimClient  v1beta1.PCIDeviceClaimClient
+	claimCache   v1beta1.PCIDeviceClaimCache
+	vmCache      ctlkubevirtv1.VirtualMachineCache
+	enqueueAfter func(name string, duration time.Duration)
+	now          func() time.Time
+}
+
+func Register(ctx context.Context, management *config.FactoryManager) error {
+	claimClient := management.DeviceFactory.Devices().V1beta1().PCIDeviceClaim()
+	vmClient := management.KubevirtFactory.Kubevirt().V1().VirtualMachine()
+	handler := &Handler{
+		claimClient:  claimClient,
+		claimCache:   claimClient.Cache(),
+		vmCache:      vmClient.Cache(),
+		enqueueAfter: claimClient.EnqueueAfter,
+		now:          time.Now,
+	}
+	claimClient.OnChange(ctx, "pcideviceclaim-release", handler.OnChange)
+	relatedresource.WatchClusterScoped(ctx, "VirtualMachineToPCIDeviceClaimRelease", handler.OnVMChange, claimClient, vmClient)
+	return nil
+}
+
+// OnChange records the VMs using the claimed device, and releases the claim according to its release policy
+func (h *Handler) OnChange(name string, pdc *devicesv1beta1.PCIDeviceClaim) (*devicesv1beta1.PCIDeviceClaim, error) {
+	if pdc == nil || pdc.DeletionTimestamp != nil {
+		return pdc, nil
+	}
+
+	vms, err := h.allocatedVMs(pdc.Name)
+	if err != nil {
+		return pdc, err
+	}
+
+	pdcCopy := pdc.DeepCopy()
+	if creator, ok := pdc.Annotations[devicesv1beta1.PCIDeviceClaimCreatedForVMKey]; ok && len(vms) == 0 &&
+		pdc.Spec.ReleasePolicy == devicesv1beta1.ReleasePolicyReleaseOnVMDelete {
+		message, err := h.unusedByCreator(pdc, creator)
+		if err != nil {
+			return pdc, err
+		}
+		if message != "" {
+			return h.release(pdcCopy, devicesv1beta1.ReasonVMDeleted, message)
+		}
+	}
+
+	switch {
+	case len(vms) > 0:
+		pdcCopy.Status.VirtualMachines = vms
+		pdcCopy.Status.IdleSince = nil
+	case len(pdc.Status.VirtualMachines) > 0 && pdc.Spec.ReleasePolicy == devicesv1beta1.ReleasePolicyReleaseOnVMDelete:
+		return h.release(pdcCopy, devicesv1beta1.ReasonVMDeleted,
+			fmt.Sprintf("vms %s no longer use the device", strings.Join(pdc.Status.VirtualMachines, ", ")))
+	default:
+		pdcCopy.Status.VirtualMachines = nil
+		if pdcCopy.Status.IdleSince == nil {
+			pdcCopy.Status.IdleSince = &metav1.Time{Time: h.now()}
+		}
+	}
+
+	if pdc.Spec.ReleasePolicy == devicesv1beta1.ReleasePolicyReleaseAfterIdle && pdcCopy.Status.IdleSince != nil {
+		timeout := pdc.Spec.IdleTimeoutOrDefault()
+		remaining := pdcCopy.Status.IdleSince.Add(timeout).Sub(h.now())
+		if remaining <= 0 {
+			return h.release(pdcCopy, devicesv1beta1.ReasonIdleTimeout,
+				fmt.Sprintf("device has not been used by a vm since %s", pdcCopy.Status.IdleSince.Format(time.RFC3339)))
+		}
+		// no event is triggered once the timeout passes, so the claim is checked again then
+		h.enqueueAfter(name, remaining)
+	}
+
+	if slices.Equal(pdc.Status.VirtualMachines, pdcCopy.Status.VirtualMachines) && pdc.Status.IdleSince.Equal(pdcCopy.Status.IdleSince) {
+		return pdc, nil
+	}
+	return h.claimClient.UpdateStatus(pdcCopy)
+}
+
+// OnVMChange requeues the claims allocated to the VM, and the claims which recorded the VM as using them,
+// as the VM may have been deleted or no longer use the device
+func (h *Handler) OnVMChange(namespace string, name string, obj runtime.Object) ([]relatedresource.Key, error) {
+	var keys []relatedresource.Key
+	if vm, ok := obj.(*kubevirtv1.VirtualMachine); ok {
+		devices, err := common.VMByHostDeviceName(vm)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range devices {
+			keys = append(keys, relatedresource.NewKey("", v))
+		}
+	}
+
+	claims, err := h.claimCache.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error listing pcideviceclaims: %w", err)
+	}
+	vmName := fmt.Sprintf("%s/%s", namespace, name)
+	for _, pdc := range claims {
+		if slices.Contains(pdc.Status.VirtualMachines, vmName) || pdc.Annotations[devicesv1beta1.PCIDeviceClaimCreatedForVMKey] == vmName {
+			keys = append(keys, relatedresource.NewKey("", pdc.Name))
+		}
+	}
+	return keys, nil
+}
+
+// allocatedVMs returns the VMs, as sorted namespace/name, the device claimed by claimName is allocated to
+func (h *Handler) allocatedVMs(claimName string) ([]string, error) {
+	vms, err := h.vmCache.GetByIndex(common.VMByPCIDeviceClaim, claimName)
+	if err != nil {
+		return nil, fmt.Errorf("error looking up vms using pcideviceclaim %s: %w", claimName, err)
+	}
+
+	names := make([]string, 0, len(vms))
+	for _, vm := range vms {
+		names = append(names, fmt.Sprintf("%s/%s", vm.Namespace, vm.Name))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// unusedByCreator returns why the claim can be released if the VM it was created for, as namespace/name, no longer
+// exists or requests the device. The VM is only looked up once the grace period has passed, as the VM may be rejected
+// by a validating webhook after the claim was created, or deleted before it ever started
+func (h *Handler) unusedByCreator(pdc *devicesv1beta1.PCIDeviceClaim, creator string) (string, error) {
+	remaining := pdc.CreationTimestamp.Add(creatorGracePeriod).Sub(h.now())
+	if remaining > 0 {
+		h.enqueueAfter(pdc.Name, remaining)
+		return "", nil
+	}
+
+	namespace, name, ok := strings.Cut(creator, "/")
+	if !ok {
+		return "", nil
+	}
+	vm, err := h.vmCache.Get(namespace, name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Sprintf("vm %s the claim was created for no longer exists", creator), nil
+		}
+		return "", fmt.Errorf("error looking up vm %s: %w", creator, err)
+	}
+	if vm.Spec.Template != nil && slices.ContainsFunc(vm.Spec.Template.Spec.Domain.Devices.HostDevices, func(v kubevirtv1.HostDevice) bool {
+		return v.Name == pdc.Name
+	}) {
+		return "", nil
+	}
+	return fmt.Sprintf("vm %s the claim was created for no longer requests the device", creator), nil
+}
+
+// release records why the claim is released in the Released condition, and removes the claim
+func (h *Handler) release(pdc *devicesv1beta1.PCIDeviceClaim, reason, message string) (*devicesv1beta1.PCIDeviceClaim, error) {
+	pdc.Status.VirtualMachines = nil
+	common.SetCondition(&pdc.Status.Conditions, pdc.Generation, devicesv1beta1.ConditionReleased, true, reason, message)
+	updated, err := h.claimClient.UpdateStatus(pdc)
+	if err != nil {
+		return pdc, fmt.Errorf("error recording release of pcideviceclaim %s: %w", pdc.Name, err)
+	}
+
+	logrus.Infof("releasing pcideviceclaim %s with release policy %s: %s", pdc.Name, pdc.Spec.ReleasePolicy, message)
+	if err := h.claimClient.Delete(pdc.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return updated, fmt.Errorf("error releasing pcideviceclaim %s: %w", pdc.Name, err)
+	}
+	return updated, nil
+}
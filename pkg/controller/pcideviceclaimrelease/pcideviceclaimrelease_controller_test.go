@@ -0,0 +1,252 @@
+package pcideviceclaimrelease
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	harvesterfake "github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/rancher/wrangler/v3/pkg/relatedresource"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/pcidevices/pkg/util/fakeclients"
+)
+
+var (
+	now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	gpuClaim = &devicesv1beta1.PCIDeviceClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1-000081000",
+		},
+		Spec: devicesv1beta1.PCIDeviceClaimSpec{
+			Address:  "0000:81:00.0",
+			NodeName: "node1",
+			UserName: "admin",
+		},
+	}
+
+	vm = &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vm1",
+			Namespace: "default",
+			Annotations: map[string]string{
+				devicesv1beta1.DeviceAllocationKey: `{"hostdevices":{"nvidia.com/GA100":["node1-000081000"]}}`,
+			},
+		},
+	}
+)
+
+type requeue struct {
+	name  string
+	after time.Duration
+}
+
+func Test_ReleaseOnVMDelete(t *testing.T) {
+	assert := require.New(t)
+	claim := gpuClaim.DeepCopy()
+	claim.Spec.ReleasePolicy = devicesv1beta1.ReleasePolicyReleaseOnVMDelete
+	client := fake.NewSimpleClientset(claim)
+	harvesterClient := harvesterfake.NewSimpleClientset()
+	var requeued []requeue
+	h := &Handler{
+		claimClient: fakeclients.PCIDeviceClaimsClient(client.DevicesV1beta1().PCIDeviceClaims),
+		claimCache:  fakeclients.PCIDeviceClaimsCache(client.DevicesV1beta1().PCIDeviceClaims),
+		vmCache:     fakeclients.VirtualMachineCache(harvesterClient.KubevirtV1().VirtualMachines),
+		enqueueAfter: func(name string, duration time.Duration) {
+			requeued = append(requeued, requeue{name: name, after: duration})
+		},
+		now: func() time.Time { return now },
+	}
+
+	// the claim is kept until a vm has used it
+	claim, err := h.OnChange(claim.Name, claim)
+	assert.NoError(err)
+	assert.Empty(claim.Status.VirtualMachines)
+	assert.Equal(now, claim.Status.IdleSince.Time)
+
+	_, err = harvesterClient.KubevirtV1().VirtualMachines(vm.Namespace).Create(context.TODO(), vm, metav1.CreateOptions{})
+	assert.NoError(err)
+	keys, err := h.OnVMChange(vm.Namespace, vm.Name, vm)
+	assert.NoError(err)
+	assert.Len(keys, 1)
+	assert.Equal(claim.Name, keys[0].Name, "expected claim allocated to the vm to be requeued")
+
+	claim, err = h.OnChange(claim.Name, claim)
+	assert.NoError(err)
+	assert.Equal([]string{"default/vm1"}, claim.Status.VirtualMachines)
+	assert.Nil(claim.Status.IdleSince)
+
+	assert.NoError(harvesterClient.KubevirtV1().VirtualMachines(vm.Namespace).Delete(context.TODO(), vm.Name, metav1.DeleteOptions{}))
+	keys, err = h.OnVMChange(vm.Namespace, vm.Name, nil)
+	assert.NoError(err)
+	assert.Len(keys, 1, "expected claim used by the deleted vm to be requeued")
+
+	claim, err = h.OnChange(claim.Name, claim)
+	assert.NoError(err)
+	released := meta.FindStatusCondition(claim.Status.Conditions, devicesv1beta1.ConditionReleased)
+	assert.NotNil(released)
+	assert.Equal(devicesv1beta1.ReasonVMDeleted, released.Reason)
+	assert.Contains(released.Message, "default/vm1")
+	_, err = client.DevicesV1beta1().PCIDeviceClaims().Get(context.TODO(), claim.Name, metav1.GetOptions{})
+	assert.Error(err, "expected claim to be removed")
+	assert.Empty(requeued)
+}
+
+func Test_ReleaseOnCreatorVMDelete(t *testing.T) {
+	assert := require.New(t)
+	sibling := gpuClaim.DeepCopy()
+	sibling.Name = "node1-000081010"
+	sibling.CreationTimestamp = metav1.NewTime(now)
+	sibling.Annotations = map[string]string{devicesv1beta1.PCIDeviceClaimCreatedForVMKey: "default/vm1"}
+	sibling.Spec.ReleasePolicy = devicesv1beta1.ReleasePolicyReleaseOnVMDelete
+	stopped := vm.DeepCopy()
+	stopped.Annotations = nil
+	stopped.Spec.Template = &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+		Spec: kubevirtv1.VirtualMachineInstanceSpec{
+			Domain: kubevirtv1.DomainSpec{
+				Devices: kubevirtv1.Devices{
+					HostDevices: []kubevirtv1.HostDevice{
+						{Name: "node1-000081000", DeviceName: "nvidia.com/GA100"},
+						{Name: sibling.Name, DeviceName: "nvidia.com/GA100_AUDIO"},
+					},
+				},
+			},
+		},
+	}
+	client := fake.NewSimpleClientset(sibling)
+	harvesterClient := harvesterfake.NewSimpleClientset()
+	var requeued []requeue
+	h := &Handler{
+		claimClient: fakeclients.PCIDeviceClaimsClient(client.DevicesV1beta1().PCIDeviceClaims),
+		claimCache:  fakeclients.PCIDeviceClaimsCache(client.DevicesV1beta1().PCIDeviceClaims),
+		vmCache:     fakeclients.VirtualMachineCache(harvesterClient.KubevirtV1().VirtualMachines),
+		enqueueAfter: func(name string, duration time.Duration) {
+			requeued = append(requeued, requeue{name: name, after: duration})
+		},
+		now: func() time.Time { return now },
+	}
+
+	// the vm is being admitted while the claim is created
+	claim, err := h.OnChange(sibling.Name, sibling)
+	assert.NoError(err)
+	assert.Empty(claim.Status.Conditions)
+	assert.Equal([]requeue{{name: sibling.Name, after: creatorGracePeriod}}, requeued, "expected claim to be checked once the vm is admitted")
+
+	// the stopped vm the claim was created for still requests the device
+	_, err = harvesterClient.KubevirtV1().VirtualMachines(stopped.Namespace).Create(context.TODO(), stopped, metav1.CreateOptions{})
+	assert.NoError(err)
+	keys, err := h.OnVMChange(stopped.Namespace, stopped.Name, stopped)
+	assert.NoError(err)
+	assert.Contains(keys, relatedresource.NewKey("", sibling.Name), "expected claim created for the vm to be requeued")
+	h.now = func() time.Time { return now.Add(creatorGracePeriod) }
+	claim, err = h.OnChange(claim.Name, claim)
+	assert.NoError(err)
+	assert.Empty(claim.Status.Conditions)
+	_, err = client.DevicesV1beta1().PCIDeviceClaims().Get(context.TODO(), claim.Name, metav1.GetOptions{})
+	assert.NoError(err, "expected claim requested by the vm to be kept")
+
+	// the vm is deleted before it ever started
+	assert.NoError(harvesterClient.KubevirtV1().VirtualMachines(stopped.Namespace).Delete(context.TODO(), stopped.Name, metav1.DeleteOptions{}))
+	claim, err = h.OnChange(claim.Name, claim)
+	assert.NoError(err)
+	released := meta.FindStatusCondition(claim.Status.Conditions, devicesv1beta1.ConditionReleased)
+	assert.NotNil(released)
+	assert.Equal(devicesv1beta1.ReasonVMDeleted, released.Reason)
+	assert.Contains(released.Message, "default/vm1")
+	_, err = client.DevicesV1beta1().PCIDeviceClaims().Get(context.TODO(), claim.Name, metav1.GetOptions{})
+	assert.Error(err, "expected claim created for the deleted vm to be removed")
+}
+
+func Test_ReleaseOnCreatorVMRejected(t *testing.T) {
+	assert := require.New(t)
+	sibling := gpuClaim.DeepCopy()
+	sibling.CreationTimestamp = metav1.NewTime(now.Add(-creatorGracePeriod))
+	sibling.Annotations = map[string]string{devicesv1beta1.PCIDeviceClaimCreatedForVMKey: "default/vm1"}
+	sibling.Spec.ReleasePolicy = devicesv1beta1.ReleasePolicyReleaseOnVMDelete
+	// the update of the running vm adding the device was rejected after the claim was created
+	client := fake.NewSimpleClientset(sibling)
+	harvesterClient := harvesterfake.NewSimpleClientset(&kubevirtv1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Name: vm.Name, Namespace: vm.Namespace}})
+	h := &Handler{
+		claimClient:  fakeclients.PCIDeviceClaimsClient(client.DevicesV1beta1().PCIDeviceClaims),
+		claimCache:   fakeclients.PCIDeviceClaimsCache(client.DevicesV1beta1().PCIDeviceClaims),
+		vmCache:      fakeclients.VirtualMachineCache(harvesterClient.KubevirtV1().VirtualMachines),
+		enqueueAfter: func(string, time.Duration) {},
+		now:          func() time.Time { return now },
+	}
+
+	claim, err := h.OnChange(sibling.Name, sibling)
+	assert.NoError(err)
+	released := meta.FindStatusCondition(claim.Status.Conditions, devicesv1beta1.ConditionReleased)
+	assert.NotNil(released)
+	assert.Contains(released.Message, "no longer requests the device")
+	_, err = client.DevicesV1beta1().PCIDeviceClaims().Get(context.TODO(), claim.Name, metav1.GetOptions{})
+	assert.Error(err, "expected claim not requested by the vm to be removed")
+}
+
+func Test_ReleaseAfterIdle(t *testing.T) {
+	assert := require.New(t)
+	claim := gpuClaim.DeepCopy()
+	claim.Spec.ReleasePolicy = devicesv1beta1.ReleasePolicyReleaseAfterIdle
+	claim.Spec.IdleTimeout = &metav1.Duration{Duration: 30 * time.Minute}
+	claim.Status.IdleSince = &metav1.Time{Time: now.Add(-10 * time.Minute)}
+	client := fake.NewSimpleClientset(claim)
+	var requeued []requeue
+	harvesterClient := harvesterfake.NewSimpleClientset()
+	h := &Handler{
+		claimClient: fakeclients.PCIDeviceClaimsClient(client.DevicesV1beta1().PCIDeviceClaims),
+		claimCache:  fakeclients.PCIDeviceClaimsCache(client.DevicesV1beta1().PCIDeviceClaims),
+		vmCache:     fakeclients.VirtualMachineCache(harvesterClient.KubevirtV1().VirtualMachines),
+		enqueueAfter: func(name string, duration time.Duration) {
+			requeued = append(requeued, requeue{name: name, after: duration})
+		},
+		now: func() time.Time { return now },
+	}
+
+	claim, err := h.OnChange(claim.Name, claim)
+	assert.NoError(err)
+	assert.Equal([]requeue{{name: claim.Name, after: 20 * time.Minute}}, requeued, "expected claim to be checked once the timeout passes")
+	_, err = client.DevicesV1beta1().PCIDeviceClaims().Get(context.TODO(), claim.Name, metav1.GetOptions{})
+	assert.NoError(err)
+
+	h.now = func() time.Time { return now.Add(20 * time.Minute) }
+	claim, err = h.OnChange(claim.Name, claim)
+	assert.NoError(err)
+	assert.True(meta.IsStatusConditionTrue(claim.Status.Conditions, devicesv1beta1.ConditionReleased))
+	assert.Equal(devicesv1beta1.ReasonIdleTimeout, meta.FindStatusCondition(claim.Status.Conditions, devicesv1beta1.ConditionReleased).Reason)
+	_, err = client.DevicesV1beta1().PCIDeviceClaims().Get(context.TODO(), claim.Name, metav1.GetOptions{})
+	assert.Error(err, "expected idle claim to be removed")
+}
+
+func Test_Retain(t *testing.T) {
+	assert := require.New(t)
+	claim := gpuClaim.DeepCopy()
+	claim.Status.VirtualMachines = []string{"default/vm1"}
+	client := fake.NewSimpleClientset(claim)
+	var requeued []requeue
+	harvesterClient := harvesterfake.NewSimpleClientset()
+	h := &Handler{
+		claimClient: fakeclients.PCIDeviceClaimsClient(client.DevicesV1beta1().PCIDeviceClaims),
+		claimCache:  fakeclients.PCIDeviceClaimsCache(client.DevicesV1beta1().PCIDeviceClaims),
+		vmCache:     fakeclients.VirtualMachineCache(harvesterClient.KubevirtV1().VirtualMachines),
+		enqueueAfter: func(name string, duration time.Duration) {
+			requeued = append(requeued, requeue{name: name, after: duration})
+		},
+		now: func() time.Time { return now },
+	}
+
+	h.now = func() time.Time { return now.Add(24 * time.Hour) }
+	claim, err := h.OnChange(claim.Name, claim)
+	assert.NoError(err)
+	assert.Empty(claim.Status.VirtualMachines, "expected deleted vm to be removed from the status")
+	assert.NotNil(claim.Status.IdleSince)
+	assert.Empty(claim.Status.Conditions)
+	_, err = client.DevicesV1beta1().PCIDeviceClaims().Get(context.TODO(), claim.Name, metav1.GetOptions{})
+	assert.NoError(err, "expected claim without release policy to be kept")
+	assert.Empty(requeued)
+}
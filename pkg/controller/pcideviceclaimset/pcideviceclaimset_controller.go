@@ -0,0 +1,316 @@
+package pcideviceclaimset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	"github.com/rancher/wrangler/v3/pkg/relatedresource"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/config"
+	"github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/util/common"
+)
+
+// Handler reconciles PCIDeviceClaimSets by creating PCIDeviceClaims for free devices until the requested number of
+// devices is claimed. Free devices are chosen in name order, and claims are released in reverse name order when the
+// count is reduced. Claims in use by a VM cannot be released, so they are kept until the VM no longer uses them
+type Handler struct {
+	setClient   v1beta1.PCIDeviceClaimSetClient
+	setCache    v1beta1.PCIDeviceClaimSetCache
+	claimClient v1beta1.PCIDeviceClaimClient
+	claimCache  v1beta1.PCIDeviceClaimCache
+	pdCache     v1beta1.PCIDeviceCache
+	nodeCache   ctlcorev1.NodeCache
+}
+
+func Register(ctx context.Context, management *config.FactoryManager) error {
+	setClient := management.DeviceFactory.Devices().V1beta1().PCIDeviceClaimSet()
+	claimClient := management.DeviceFactory.Devices().V1beta1().PCIDeviceClaim()
+	pdClient := management.DeviceFactory.Devices().V1beta1().PCIDevice()
+	handler := &Handler{
+		setClient:   setClient,
+		setCache:    setClient.Cache(),
+		claimClient: claimClient,
+		claimCache:  claimClient.Cache(),
+		pdCache:     pdClient.Cache(),
+		nodeCache:   management.CoreFactory.Core().V1().Node().Cache(),
+	}
+	setClient.OnChange(ctx, "pcideviceclaimset-reconcile", handler.OnChange)
+	setClient.OnRemove(ctx, "pcideviceclaimset-remove", handler.OnRemove)
+	relatedresource.WatchClusterScoped(ctx, "PCIDeviceClaimToClaimSetReconcile", handler.OnClaimChange, setClient, claimClient)
+	relatedresource.WatchClusterScoped(ctx, "PCIDeviceToClaimSetReconcile", handler.OnDeviceChange, setClient, pdClient)
+	return nil
+}
+
+// OnChange claims or releases devices until the set holds the requested number of claims, and reports the claimed devices
+func (h *Handler) OnChange(_ string, set *devicesv1beta1.PCIDeviceClaimSet) (*devicesv1beta1.PCIDeviceClaimSet, error) {
+	if set == nil || set.DeletionTimestamp != nil {
+		return set, nil
+	}
+
+	claims, err := h.claimsForSet(set.Name)
+	if err != nil {
+		return set, err
+	}
+
+	claims, releaseErr := h.releaseClaims(claims, set.Spec.Count)
+	claims, claimErr := h.createClaims(set, claims)
+	if claimErr != nil {
+		return set, claimErr
+	}
+
+	updated, err := h.updateStatus(set, claims, releaseErr)
+	if err != nil {
+		return set, err
+	}
+	return updated, releaseErr
+}
+
+// OnRemove releases all claims created for the set. Removal is retried while any claim is still in use by a VM
+func (h *Handler) OnRemove(_ string, set *devicesv1beta1.PCIDeviceClaimSet) (*devicesv1beta1.PCIDeviceClaimSet, error) {
+	if set == nil {
+		return set, nil
+	}
+
+	claims, err := h.claimsForSet(set.Name)
+	if err != nil {
+		return set, err
+	}
+	if _, err := h.releaseClaims(claims, 0); err != nil {
+		return set, fmt.Errorf("error removing pcideviceclaimset %s: %w", set.Name, err)
+	}
+	return set, nil
+}
+
+// OnClaimChange requeues the set a claim was created for. Any other claim may free or take a device,
+// so sets which are still short of devices are requeued as well
+func (h *Handler) OnClaimChange(_ string, _ string, obj runtime.Object) ([]relatedresource.Key, error) {
+	if obj == nil {
+		// the removed claim may belong to any set
+		return h.setKeys(func(*devicesv1beta1.PCIDeviceClaimSet) bool { return true })
+	}
+
+	if pdc, ok := obj.(*devicesv1beta1.PCIDeviceClaim); ok {
+		if name, ok := pdc.Labels[devicesv1beta1.PCIDeviceClaimSetLabel]; ok {
+			return []relatedresource.Key{relatedresource.NewKey("", name)}, nil
+		}
+	}
+	return h.setKeys(pending)
+}
+
+// OnDeviceChange requeues sets which are still short of devices, as the device may now be free
+func (h *Handler) OnDeviceChange(_ string, _ string, _ runtime.Object) ([]relatedresource.Key, error) {
+	return h.setKeys(pending)
+}
+
+func (h *Handler) setKeys(include func(*devicesv1beta1.PCIDeviceClaimSet) bool) ([]relatedresource.Key, error) {
+	sets, err := h.setCache.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error listing pcideviceclaimsets: %w", err)
+	}
+
+	var keys []relatedresource.Key
+	for _, v := range sets {
+		if include(v) {
+			keys = append(keys, relatedresource.NewKey("", v.Name))
+		}
+	}
+	return keys, nil
+}
+
+func pending(set *devicesv1beta1.PCIDeviceClaimSet) bool {
+	return len(set.Status.Devices) < set.Spec.Count
+}
+
+// claimsForSet lists the claims created for the set from the api server rather than the cache,
+// so claims created by the previous reconcile are never missed and devices are not claimed twice
+func (h *Handler) claimsForSet(name string) ([]devicesv1beta1.PCIDeviceClaim, error) {
+	list, err := h.claimClient.List(metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{devicesv1beta1.PCIDeviceClaimSetLabel: name}).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing pcideviceclaims for pcideviceclaimset %s: %w", name, err)
+	}
+
+	claims := list.Items
+	sort.Slice(claims, func(i, j int) bool {
+		return claims[i].Name < claims[j].Name
+	})
+	return claims, nil
+}
+
+// releaseClaims deletes claims in reverse name order until at most count claims are left, and returns the remaining claims.
+// Claims which cannot be deleted, such as claims in use by a VM, are skipped in favour of the next claim
+func (h *Handler) releaseClaims(claims []devicesv1beta1.PCIDeviceClaim, count int) ([]devicesv1beta1.PCIDeviceClaim, error) {
+	var errs []error
+	excess := len(claims) - count
+	for i := len(claims) - 1; i >= 0 && excess > 0; i-- {
+		if claims[i].DeletionTimestamp == nil {
+			err := h.claimClient.Delete(claims[i].Name, &metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("error releasing pcideviceclaim %s: %w", claims[i].Name, err))
+				continue
+			}
+		}
+		claims = append(claims[:i], claims[i+1:]...)
+		excess--
+	}
+	return claims, errors.Join(errs...)
+}
+
+// createClaims claims free devices until the set holds the requested number of claims. Claims rejected for a device,
+// such as devices in use by the host, are logged and the next free device is tried instead
+func (h *Handler) createClaims(set *devicesv1beta1.PCIDeviceClaimSet, claims []devicesv1beta1.PCIDeviceClaim) ([]devicesv1beta1.PCIDeviceClaim, error) {
+	if len(claims) >= set.Spec.Count {
+		return claims, nil
+	}
+
+	pds, err := h.freeDevices(set)
+	if err != nil {
+		return claims, err
+	}
+
+	for _, pd := range pds {
+		if len(claims) >= set.Spec.Count {
+			break
+		}
+		claim, err := h.claimClient.Create(newClaim(set, pd))
+		if err != nil {
+			logrus.Warnf("pcideviceclaimset %s: error claiming pcidevice %s: %v", set.Name, pd.Name, err)
+			continue
+		}
+		logrus.Infof("pcideviceclaimset %s claimed pcidevice %s", set.Name, pd.Name)
+		claims = append(claims, *claim)
+	}
+	return claims, nil
+}
+
+// freeDevices returns the unclaimed devices matching the set on schedulable nodes selected by the set, in name order.
+// Devices on nodes with a stale agent are skipped, as the agent would not bind them
+func (h *Handler) freeDevices(set *devicesv1beta1.PCIDeviceClaimSet) ([]*devicesv1beta1.PCIDevice, error) {
+	nodeSelector := labels.Everything()
+	if set.Spec.NodeSelector != nil {
+		var err error
+		nodeSelector, err = metav1.LabelSelectorAsSelector(set.Spec.NodeSelector)
+		if err != nil {
+			return nil, fmt.Errorf("pcideviceclaimset %s has an invalid node selector: %w", set.Name, err)
+		}
+	}
+
+	nodes, err := h.nodeCache.List(nodeSelector)
+	if err != nil {
+		return nil, fmt.Errorf("error listing nodes: %w", err)
+	}
+	nodeNames := make(map[string]bool, len(nodes))
+	for _, v := range nodes {
+		if !v.Spec.Unschedulable {
+			nodeNames[v.Name] = true
+		}
+	}
+
+	pds, err := h.pdCache.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error listing pcidevices: %w", err)
+	}
+	sort.Slice(pds, func(i, j int) bool {
+		return pds[i].Name < pds[j].Name
+	})
+
+	var free []*devicesv1beta1.PCIDevice
+	for _, pd := range pds {
+		if pd.DeletionTimestamp != nil || !nodeNames[pd.Status.NodeName] || !set.Spec.Matches(pd) {
+			continue
+		}
+		if meta.IsStatusConditionPresentAndEqual(pd.Status.Conditions, devicesv1beta1.ConditionNodeAgentReady, metav1.ConditionUnknown) {
+			continue
+		}
+		_, err := h.claimCache.Get(pd.Name)
+		if err == nil {
+			continue
+		}
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("error looking up pcideviceclaim %s: %w", pd.Name, err)
+		}
+		free = append(free, pd)
+	}
+	return free, nil
+}
+
+// newClaim generates the claim for pd, which like any PCIDeviceClaim is named after and owned by the device
+func newClaim(set *devicesv1beta1.PCIDeviceClaimSet, pd *devicesv1beta1.PCIDevice) *devicesv1beta1.PCIDeviceClaim {
+	return &devicesv1beta1.PCIDeviceClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: pd.Name,
+			Labels: map[string]string{
+				devicesv1beta1.NodeKeyName:            pd.Status.NodeName,
+				devicesv1beta1.PCIDeviceClaimSetLabel: set.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: devicesv1beta1.SchemeGroupVersion.String(),
+					Kind:       "PCIDevice",
+					Name:       pd.Name,
+					UID:        pd.UID,
+				},
+			},
+		},
+		Spec: devicesv1beta1.PCIDeviceClaimSpec{
+			Address:  pd.Status.Address,
+			NodeName: pd.Status.NodeName,
+			UserName: set.Spec.UserName,
+			Driver:   pd.Spec.DriverOverride,
+		},
+	}
+}
+
+func (h *Handler) updateStatus(set *devicesv1beta1.PCIDeviceClaimSet, claims []devicesv1beta1.PCIDeviceClaim, releaseErr error) (*devicesv1beta1.PCIDeviceClaimSet, error) {
+	setCopy := set.DeepCopy()
+	setCopy.Status.Devices = nil
+	var waiting []string
+	for _, v := range claims {
+		setCopy.Status.Devices = append(setCopy.Status.Devices, devicesv1beta1.PCIDeviceClaimSetDevice{
+			Name:               v.Name,
+			NodeName:           v.Spec.NodeName,
+			Address:            v.Spec.Address,
+			PassthroughEnabled: v.Status.PassthroughEnabled,
+		})
+		if !v.Status.PassthroughEnabled {
+			waiting = append(waiting, v.Name)
+		}
+	}
+
+	switch {
+	case len(claims) < set.Spec.Count:
+		common.SetCondition(&setCopy.Status.Conditions, set.Generation, devicesv1beta1.ConditionReady, false, devicesv1beta1.ReasonInsufficientDevices,
+			fmt.Sprintf("claimed %d of %d devices, no more free devices match the set", len(claims), set.Spec.Count))
+	case len(claims) > set.Spec.Count:
+		common.SetCondition(&setCopy.Status.Conditions, set.Generation, devicesv1beta1.ConditionReady, false, devicesv1beta1.ReasonPending,
+			fmt.Sprintf("releasing %d devices: %v", len(claims)-set.Spec.Count, releaseErr))
+	case len(waiting) > 0:
+		common.SetCondition(&setCopy.Status.Conditions, set.Generation, devicesv1beta1.ConditionReady, false, devicesv1beta1.ReasonPending,
+			fmt.Sprintf("waiting for passthrough on %s", strings.Join(waiting, ", ")))
+	default:
+		common.SetCondition(&setCopy.Status.Conditions, set.Generation, devicesv1beta1.ConditionReady, true, devicesv1beta1.ReasonReconciled, "")
+	}
+
+	if equality.Semantic.DeepEqual(set.Status, setCopy.Status) {
+		return set, nil
+	}
+	updated, err := h.setClient.UpdateStatus(setCopy)
+	if err != nil {
+		return set, fmt.Errorf("error updating pcideviceclaimset %s status: %w", set.Name, err)
+	}
+	return updated, nil
+}
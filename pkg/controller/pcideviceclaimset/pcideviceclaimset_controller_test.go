@@ -0,0 +1,171 @@
+package pcideviceclaimset
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corefake "k8s.io/client-go/kubernetes/fake"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/pcidevices/pkg/util/fakeclients"
+)
+
+func gpu(name, nodeName, address, deviceID string) *devicesv1beta1.PCIDevice {
+	return &devicesv1beta1.PCIDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: devicesv1beta1.PCIDeviceStatus{
+			Address:  address,
+			NodeName: nodeName,
+			VendorID: "10de",
+			DeviceID: deviceID,
+			ClassID:  "0302",
+		},
+	}
+}
+
+var (
+	node1 = &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node1",
+			Labels: map[string]string{"gpu": "a100"},
+		},
+	}
+	node2 = &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node2",
+		},
+	}
+	node1GPU1     = gpu("node1-000081000", "node1", "0000:81:00.0", "20b5")
+	node1GPU2     = gpu("node1-000082000", "node1", "0000:82:00.0", "20b5")
+	node1GPU3     = gpu("node1-000083000", "node1", "0000:83:00.0", "20b5")
+	node1OtherGPU = gpu("node1-000084000", "node1", "0000:84:00.0", "2236")
+	node2GPU      = gpu("node2-000081000", "node2", "0000:81:00.0", "20b5")
+
+	// node1GPU1 is already claimed outside the set
+	node1GPU1Claim = &devicesv1beta1.PCIDeviceClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: node1GPU1.Name,
+		},
+		Spec: devicesv1beta1.PCIDeviceClaimSpec{
+			Address:  node1GPU1.Status.Address,
+			NodeName: node1GPU1.Status.NodeName,
+			UserName: "admin",
+		},
+	}
+
+	a100Set = &devicesv1beta1.PCIDeviceClaimSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a100",
+		},
+		Spec: devicesv1beta1.PCIDeviceClaimSetSpec{
+			Count:        2,
+			Devices:      []devicesv1beta1.PCIDeviceFilter{{VendorID: "10de", DeviceID: "20b5"}},
+			NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "a100"}},
+			UserName:     "admin",
+		},
+	}
+)
+
+func Test_OnChange(t *testing.T) {
+	assert := require.New(t)
+	client := fake.NewSimpleClientset(node1GPU1, node1GPU2, node1GPU3, node1OtherGPU, node2GPU, node1GPU1Claim, a100Set)
+	coreClient := corefake.NewSimpleClientset(node1, node2)
+	h := &Handler{
+		setClient:   fakeclients.PCIDeviceClaimSetsClient(client.DevicesV1beta1().PCIDeviceClaimSets),
+		setCache:    fakeclients.PCIDeviceClaimSetsCache(client.DevicesV1beta1().PCIDeviceClaimSets),
+		claimClient: fakeclients.PCIDeviceClaimsClient(client.DevicesV1beta1().PCIDeviceClaims),
+		claimCache:  fakeclients.PCIDeviceClaimsCache(client.DevicesV1beta1().PCIDeviceClaims),
+		pdCache:     fakeclients.PCIDevicesCache(client.DevicesV1beta1().PCIDevices),
+		nodeCache:   fakeclients.NodeCache(coreClient.CoreV1().Nodes),
+	}
+
+	set, err := h.OnChange(a100Set.Name, a100Set)
+	assert.NoError(err, "expected no error while reconciling claim set")
+	assert.Equal([]devicesv1beta1.PCIDeviceClaimSetDevice{
+		{Name: node1GPU2.Name, NodeName: "node1", Address: node1GPU2.Status.Address},
+		{Name: node1GPU3.Name, NodeName: "node1", Address: node1GPU3.Status.Address},
+	}, set.Status.Devices, "expected free matching devices on selected nodes to be claimed")
+	ready := meta.FindStatusCondition(set.Status.Conditions, devicesv1beta1.ConditionReady)
+	assert.Equal(metav1.ConditionFalse, ready.Status)
+	assert.Equal(devicesv1beta1.ReasonPending, ready.Reason, "expected set to wait for passthrough")
+
+	claim, err := client.DevicesV1beta1().PCIDeviceClaims().Get(context.TODO(), node1GPU2.Name, metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal(a100Set.Name, claim.Labels[devicesv1beta1.PCIDeviceClaimSetLabel])
+	assert.Equal("PCIDevice", claim.OwnerReferences[0].Kind)
+	assert.Equal("admin", claim.Spec.UserName)
+
+	// reconciling again must not claim further devices
+	set, err = h.OnChange(set.Name, set)
+	assert.NoError(err)
+	assert.Len(set.Status.Devices, 2)
+
+	// requesting more devices than are free reports the shortfall
+	set.Spec.Count = 3
+	set, err = h.OnChange(set.Name, set)
+	assert.NoError(err)
+	assert.Len(set.Status.Devices, 2)
+	ready = meta.FindStatusCondition(set.Status.Conditions, devicesv1beta1.ConditionReady)
+	assert.Equal(devicesv1beta1.ReasonInsufficientDevices, ready.Reason)
+
+	// reducing the count releases claims in reverse name order
+	set.Spec.Count = 1
+	set, err = h.OnChange(set.Name, set)
+	assert.NoError(err)
+	assert.Len(set.Status.Devices, 1)
+	assert.Equal(node1GPU2.Name, set.Status.Devices[0].Name)
+	_, err = client.DevicesV1beta1().PCIDeviceClaims().Get(context.TODO(), node1GPU3.Name, metav1.GetOptions{})
+	assert.Error(err, "expected released claim to be deleted")
+
+	// the set is ready once passthrough is enabled on all claims
+	claim, err = client.DevicesV1beta1().PCIDeviceClaims().Get(context.TODO(), node1GPU2.Name, metav1.GetOptions{})
+	assert.NoError(err)
+	claim.Status.PassthroughEnabled = true
+	_, err = client.DevicesV1beta1().PCIDeviceClaims().Update(context.TODO(), claim, metav1.UpdateOptions{})
+	assert.NoError(err)
+	set, err = h.OnChange(set.Name, set)
+	assert.NoError(err)
+	assert.True(meta.IsStatusConditionTrue(set.Status.Conditions, devicesv1beta1.ConditionReady))
+
+	// removing the set releases its claims, and leaves other claims in place
+	_, err = h.OnRemove(set.Name, set)
+	assert.NoError(err)
+	claims, err := client.DevicesV1beta1().PCIDeviceClaims().List(context.TODO(), metav1.ListOptions{})
+	assert.NoError(err)
+	assert.Len(claims.Items, 1)
+	assert.Equal(node1GPU1Claim.Name, claims.Items[0].Name)
+}
+
+func Test_OnClaimChange(t *testing.T) {
+	assert := require.New(t)
+	satisfied := a100Set.DeepCopy()
+	satisfied.Name = "satisfied"
+	satisfied.Status.Devices = []devicesv1beta1.PCIDeviceClaimSetDevice{{Name: node1GPU2.Name}, {Name: node1GPU3.Name}}
+	client := fake.NewSimpleClientset(a100Set, satisfied)
+	h := &Handler{
+		setCache: fakeclients.PCIDeviceClaimSetsCache(client.DevicesV1beta1().PCIDeviceClaimSets),
+	}
+
+	owned := node1GPU1Claim.DeepCopy()
+	owned.Labels = map[string]string{devicesv1beta1.PCIDeviceClaimSetLabel: satisfied.Name}
+	keys, err := h.OnClaimChange("", owned.Name, owned)
+	assert.NoError(err)
+	assert.Len(keys, 1)
+	assert.Equal(satisfied.Name, keys[0].Name, "expected set owning the claim to be requeued")
+
+	keys, err = h.OnClaimChange("", node1GPU1Claim.Name, node1GPU1Claim)
+	assert.NoError(err)
+	assert.Len(keys, 1)
+	assert.Equal(a100Set.Name, keys[0].Name, "expected only pending sets to be requeued")
+
+	keys, err = h.OnClaimChange("", node1GPU1Claim.Name, nil)
+	assert.NoError(err)
+	assert.Len(keys, 2, "expected all sets to be requeued when a claim is removed")
+}
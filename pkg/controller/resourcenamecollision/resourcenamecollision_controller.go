@@ -0,0 +1,134 @@
+package resourcenamecollision
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/config"
+	"github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/util/common"
+)
+
+// Handler detects PCIDevices with different vendor and device ids which share a resource name across the cluster.
+// A device plugin serving such a resource name would pool unrelated hardware under a single kubelet resource.
+// Devices exposed through the same DevicePool are pooled deliberately, and are not flagged
+type Handler struct {
+	pdClient v1beta1.PCIDeviceClient
+	pdCache  v1beta1.PCIDeviceCache
+
+	lock sync.Mutex
+	// resourceNames tracks the last resource name evaluated for each device, so the devices left behind
+	// are re-evaluated when a device changes its resource name or is removed
+	resourceNames map[string]string
+}
+
+func Register(ctx context.Context, management *config.FactoryManager) error {
+	pdClient := management.DeviceFactory.Devices().V1beta1().PCIDevice()
+	handler := &Handler{
+		pdClient:      pdClient,
+		pdCache:       pdClient.Cache(),
+		resourceNames: make(map[string]string),
+	}
+	pdClient.OnChange(ctx, "pcidevice-resource-name-collision", handler.OnDeviceChange)
+	return nil
+}
+
+// OnDeviceChange re-evaluates collisions for the devices sharing the current and the previous resource name
+// of the device, as a device changing or dropping its resource name may resolve the collision flagged on other devices
+func (h *Handler) OnDeviceChange(name string, pd *devicesv1beta1.PCIDevice) (*devicesv1beta1.PCIDevice, error) {
+	h.lock.Lock()
+	previous, ok := h.resourceNames[name]
+	h.lock.Unlock()
+
+	var current string
+	if pd != nil && pd.DeletionTimestamp == nil {
+		current = pd.Status.ResourceName
+	}
+
+	if ok && previous != "" && previous != current {
+		if err := h.flagResourceName(previous); err != nil {
+			return pd, err
+		}
+	}
+	if current != "" {
+		if err := h.flagResourceName(current); err != nil {
+			return pd, err
+		}
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if pd == nil {
+		delete(h.resourceNames, name)
+	} else {
+		h.resourceNames[name] = current
+	}
+	return pd, nil
+}
+
+// flagResourceName evaluates the collision condition of all devices using resourceName
+func (h *Handler) flagResourceName(resourceName string) error {
+	pds, err := h.pdCache.GetByIndex(common.PCIDeviceByResourceName, resourceName)
+	if err != nil {
+		return fmt.Errorf("error listing pcidevices with resource name %s: %w", resourceName, err)
+	}
+
+	poolingKeys := make(map[string]bool)
+	for _, v := range pds {
+		if v.DeletionTimestamp == nil {
+			poolingKeys[v.Status.PoolingKey()] = true
+		}
+	}
+
+	for _, v := range pds {
+		if v.DeletionTimestamp != nil {
+			continue
+		}
+		if err := h.flagDevice(v, poolingKeys); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flagDevice sets the ResourceNameCollision condition on pd if its resource name is shared by devices with
+// different hardware ids. The condition is only cleared on devices which have previously been flagged
+func (h *Handler) flagDevice(pd *devicesv1beta1.PCIDevice, poolingKeys map[string]bool) error {
+	pdCopy := pd.DeepCopy()
+	var changed bool
+	if len(poolingKeys) > 1 {
+		changed = common.SetCondition(&pdCopy.Status.Conditions, pd.Generation, devicesv1beta1.ConditionResourceNameCollision, true,
+			devicesv1beta1.ReasonResourceNameShared, fmt.Sprintf("resource name %s is shared by devices with different ids: %s",
+				pd.Status.ResourceName, strings.Join(sortedKeys(poolingKeys), ", ")))
+	} else if meta.FindStatusCondition(pd.Status.Conditions, devicesv1beta1.ConditionResourceNameCollision) != nil {
+		changed = common.SetCondition(&pdCopy.Status.Conditions, pd.Generation, devicesv1beta1.ConditionResourceNameCollision, false,
+			devicesv1beta1.ReasonResourceNameUnique, "")
+	}
+	if !changed {
+		return nil
+	}
+
+	if len(poolingKeys) > 1 {
+		logrus.Warnf("pcidevice %s shares resource name %s with devices of different ids", pd.Name, pd.Status.ResourceName)
+	}
+	if _, err := h.pdClient.UpdateStatus(pdCopy); err != nil {
+		return fmt.Errorf("error updating pcidevice %s: %w", pd.Name, err)
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,99 @@
+package resourcenamecollision
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/pcidevices/pkg/util/fakeclients"
+)
+
+var (
+	node1NIC = &devicesv1beta1.PCIDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1-000004000",
+		},
+		Status: devicesv1beta1.PCIDeviceStatus{
+			VendorID:     "8086",
+			DeviceID:     "1521",
+			ResourceName: "intel.com/NIC",
+		},
+	}
+	node2NIC = &devicesv1beta1.PCIDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node2-000004000",
+		},
+		Status: devicesv1beta1.PCIDeviceStatus{
+			VendorID:     "8086",
+			DeviceID:     "1521",
+			ResourceName: "intel.com/NIC",
+		},
+	}
+	node2OtherNIC = &devicesv1beta1.PCIDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node2-000005000",
+		},
+		Status: devicesv1beta1.PCIDeviceStatus{
+			VendorID:     "8086",
+			DeviceID:     "1572",
+			ResourceName: "intel.com/NIC",
+		},
+	}
+)
+
+func Test_OnDeviceChange(t *testing.T) {
+	assert := require.New(t)
+	client := fake.NewSimpleClientset(node1NIC, node2NIC, node2OtherNIC)
+	h := &Handler{
+		pdClient:      fakeclients.PCIDevicesClient(client.DevicesV1beta1().PCIDevices),
+		pdCache:       fakeclients.PCIDevicesCache(client.DevicesV1beta1().PCIDevices),
+		resourceNames: make(map[string]string),
+	}
+
+	collision := func(name string) *metav1.Condition {
+		pd, err := client.DevicesV1beta1().PCIDevices().Get(context.TODO(), name, metav1.GetOptions{})
+		assert.NoError(err)
+		return meta.FindStatusCondition(pd.Status.Conditions, devicesv1beta1.ConditionResourceNameCollision)
+	}
+
+	_, err := h.OnDeviceChange(node1NIC.Name, node1NIC)
+	assert.NoError(err, "expected no error while detecting collisions")
+	for _, name := range []string{node1NIC.Name, node2NIC.Name, node2OtherNIC.Name} {
+		assert.Equal(metav1.ConditionTrue, collision(name).Status, "expected all devices sharing the resource name to be flagged")
+	}
+	assert.Contains(collision(node1NIC.Name).Message, "8086:1521, 8086:1572")
+	_, err = h.OnDeviceChange(node2OtherNIC.Name, node2OtherNIC)
+	assert.NoError(err, "expected no error while detecting collisions")
+
+	// renaming the other device resolves the collision
+	renamed, err := client.DevicesV1beta1().PCIDevices().Get(context.TODO(), node2OtherNIC.Name, metav1.GetOptions{})
+	assert.NoError(err)
+	renamed.Status.ResourceName = "intel.com/XL710"
+	renamed, err = client.DevicesV1beta1().PCIDevices().Update(context.TODO(), renamed, metav1.UpdateOptions{})
+	assert.NoError(err)
+	_, err = h.OnDeviceChange(renamed.Name, renamed)
+	assert.NoError(err, "expected no error while detecting collisions")
+	for _, name := range []string{node1NIC.Name, node2NIC.Name, node2OtherNIC.Name} {
+		assert.Equal(metav1.ConditionFalse, collision(name).Status, "expected collision to be cleared")
+	}
+
+	// removing a colliding device resolves the collision
+	renamed.Status.ResourceName = "intel.com/NIC"
+	renamed, err = client.DevicesV1beta1().PCIDevices().Update(context.TODO(), renamed, metav1.UpdateOptions{})
+	assert.NoError(err)
+	_, err = h.OnDeviceChange(renamed.Name, renamed)
+	assert.NoError(err, "expected no error while detecting collisions")
+	assert.Equal(metav1.ConditionTrue, collision(node1NIC.Name).Status, "expected collision to be flagged again")
+	assert.NoError(client.DevicesV1beta1().PCIDevices().Delete(context.TODO(), renamed.Name, metav1.DeleteOptions{}))
+	_, err = h.OnDeviceChange(renamed.Name, nil)
+	assert.NoError(err, "expected no error while detecting collisions")
+	for _, name := range []string{node1NIC.Name, node2NIC.Name} {
+		assert.Equal(metav1.ConditionFalse, collision(name).Status, "expected collision to be cleared once the device is removed")
+	}
+	assert.NotContains(h.resourceNames, renamed.Name, "expected removed device to no longer be tracked")
+}
@@ -23,16 +23,24 @@ import (
 	ctlnetwork "github.com/harvester/harvester-network-controller/pkg/generated/controllers/network.harvesterhci.io"
 
 	"github.com/harvester/pcidevices/pkg/config"
+	"github.com/harvester/pcidevices/pkg/controller/autoclaimpolicy"
+	"github.com/harvester/pcidevices/pkg/controller/claimlease"
+	"github.com/harvester/pcidevices/pkg/controller/devicequota"
 	"github.com/harvester/pcidevices/pkg/controller/gpudevice"
+	"github.com/harvester/pcidevices/pkg/controller/nodeagent"
 	"github.com/harvester/pcidevices/pkg/controller/nodecleanup"
 	"github.com/harvester/pcidevices/pkg/controller/nodes"
 	"github.com/harvester/pcidevices/pkg/controller/pcideviceclaim"
+	"github.com/harvester/pcidevices/pkg/controller/pcideviceclaimrelease"
+	"github.com/harvester/pcidevices/pkg/controller/pcideviceclaimset"
+	"github.com/harvester/pcidevices/pkg/controller/resourcenamecollision"
 	"github.com/harvester/pcidevices/pkg/controller/sriovdevice"
 	"github.com/harvester/pcidevices/pkg/controller/usbdevice"
 	"github.com/harvester/pcidevices/pkg/controller/virtualmachine"
 	"github.com/harvester/pcidevices/pkg/crd"
 	ctldevices "github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io"
 	ctlkubevirt "github.com/harvester/pcidevices/pkg/generated/controllers/kubevirt.io"
+	"github.com/harvester/pcidevices/pkg/metrics"
 	"github.com/harvester/pcidevices/pkg/webhook"
 )
 
@@ -135,6 +143,69 @@ func Setup(ctx context.Context, cfg *rest.Config, _ *runtime.Scheme) error {
 		<-ctx.Done()
 	})
 
+	// the node agent monitor is cluster wide, so only the leader flags devices on nodes with stale agents
+	go leader.RunOrDie(ctx, "harvester-system", "pcidevices-node-agent-monitor", k8sclient, func(ctx context.Context) {
+		logrus.Info("starting leader election for nodeagent controller")
+		if err := nodeagent.Register(ctx, management); err != nil {
+			panic(err)
+		}
+		<-ctx.Done()
+	})
+
+	// resource name collisions are detected across all nodes, so only the leader flags devices
+	go leader.RunOrDie(ctx, "harvester-system", "pcidevices-resource-name-collision", k8sclient, func(ctx context.Context) {
+		logrus.Info("starting leader election for resourcenamecollision controller")
+		if err := resourcenamecollision.Register(ctx, management); err != nil {
+			panic(err)
+		}
+		<-ctx.Done()
+	})
+
+	// claim sets choose devices across all nodes, so only the leader creates claims to avoid claiming devices twice
+	go leader.RunOrDie(ctx, "harvester-system", "pcidevices-claim-set", k8sclient, func(ctx context.Context) {
+		logrus.Info("starting leader election for pcideviceclaimset controller")
+		if err := pcideviceclaimset.Register(ctx, management); err != nil {
+			panic(err)
+		}
+		<-ctx.Done()
+	})
+
+	// auto claim policies claim devices across all nodes, so only the leader creates claims
+	go leader.RunOrDie(ctx, "harvester-system", "pcidevices-auto-claim-policy", k8sclient, func(ctx context.Context) {
+		logrus.Info("starting leader election for autoclaimpolicy controller")
+		if err := autoclaimpolicy.Register(ctx, management); err != nil {
+			panic(err)
+		}
+		<-ctx.Done()
+	})
+
+	// quota usage is counted across all VMs of a namespace, so only the leader updates the status
+	go leader.RunOrDie(ctx, "harvester-system", "pcidevices-device-quota", k8sclient, func(ctx context.Context) {
+		logrus.Info("starting leader election for devicequota controller")
+		if err := devicequota.Register(ctx, management); err != nil {
+			panic(err)
+		}
+		<-ctx.Done()
+	})
+
+	// claims are released based on VMs on all nodes, so only the leader removes claims
+	go leader.RunOrDie(ctx, "harvester-system", "pcidevices-claim-release", k8sclient, func(ctx context.Context) {
+		logrus.Info("starting leader election for pcideviceclaimrelease controller")
+		if err := pcideviceclaimrelease.Register(ctx, management); err != nil {
+			panic(err)
+		}
+		<-ctx.Done()
+	})
+
+	// claims with an expired lease are released based on VMIs on all nodes, so only the leader removes claims
+	go leader.RunOrDie(ctx, "harvester-system", "pcidevices-claim-lease", k8sclient, func(ctx context.Context) {
+		logrus.Info("starting leader election for claimlease controller")
+		if err := claimlease.Register(ctx, management); err != nil {
+			panic(err)
+		}
+		<-ctx.Done()
+	})
+
 	if err := start.All(ctx, 2, coreFactory, networkFactory, deviceFactory, kubevirtFactory); err != nil {
 		return fmt.Errorf("error starting controllers :%v", err)
 	}
@@ -144,6 +215,8 @@ func Setup(ctx context.Context, cfg *rest.Config, _ *runtime.Scheme) error {
 		return fmt.Errorf("error setting up node object: %v", err)
 	}
 
+	metrics.Serve(ctx)
+
 	w := webhook.New(ctx, cfg)
 	if err := w.ListenAndServe(); err != nil {
 		return fmt.Errorf("error starting webhook: %v", err)
@@ -16,6 +16,7 @@ import (
 	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
 	"github.com/harvester/pcidevices/pkg/config"
 	ctl "github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/util/common"
 	"github.com/harvester/pcidevices/pkg/util/nichelper"
 )
 
@@ -131,11 +132,13 @@ func (h *Handler) ensureDeviceIsDisabled(sriovDevice *v1beta1.SRIOVNetworkDevice
 
 	if vfs != 0 {
 		if err := nichelper.ConfigureVF(sriovDevice.Spec.Address, 0); err != nil {
-			return sriovDevice, fmt.Errorf("error setting vf count to 0 on device %s: %v", sriovDevice.Name, err)
+			return h.markDeviceDegraded(deviceCopy, fmt.Errorf("error setting vf count to 0 on device %s: %v", sriovDevice.Name, err))
 		}
 		deviceCopy.Status.Status = v1beta1.DeviceDisabled
 		deviceCopy.Status.VFAddresses = nil
 	}
+	common.SetCondition(&deviceCopy.Status.Conditions, deviceCopy.Generation, v1beta1.ConditionReady, false, v1beta1.ReasonDisabled, "no vfs requested")
+	common.SetDegraded(&deviceCopy.Status.Conditions, deviceCopy.Generation, v1beta1.ReasonReconciled, nil)
 
 	if !reflect.DeepEqual(deviceCopy.Status, sriovDevice.Status) {
 		return h.sriovClient.UpdateStatus(deviceCopy)
@@ -154,7 +157,7 @@ func (h *Handler) ensureDeviceIsConfigured(sriovDevice *v1beta1.SRIOVNetworkDevi
 
 	if vfs != deviceCopy.Spec.NumVFs {
 		if err := nichelper.ConfigureVF(sriovDevice.Spec.Address, sriovDevice.Spec.NumVFs); err != nil {
-			return sriovDevice, fmt.Errorf("error setting vf count to %d on device %s: %v", sriovDevice.Spec.NumVFs, sriovDevice.Name, err)
+			return h.markDeviceDegraded(deviceCopy, fmt.Errorf("error setting vf count to %d on device %s: %v", sriovDevice.Spec.NumVFs, sriovDevice.Name, err))
 		}
 	}
 
@@ -170,6 +173,8 @@ func (h *Handler) ensureDeviceIsConfigured(sriovDevice *v1beta1.SRIOVNetworkDevi
 	deviceCopy.Status.Status = v1beta1.DeviceEnabled
 	deviceCopy.Status.VFPCIDevices = vfPCIDevices
 	deviceCopy.Status.VFAddresses = vfAddresses
+	common.SetCondition(&deviceCopy.Status.Conditions, deviceCopy.Generation, v1beta1.ConditionReady, true, v1beta1.ReasonReconciled, "")
+	common.SetDegraded(&deviceCopy.Status.Conditions, deviceCopy.Generation, v1beta1.ReasonReconciled, nil)
 
 	if !reflect.DeepEqual(deviceCopy.Status, sriovDevice.Status) {
 		return h.sriovClient.UpdateStatus(deviceCopy)
@@ -177,3 +182,8 @@ func (h *Handler) ensureDeviceIsConfigured(sriovDevice *v1beta1.SRIOVNetworkDevi
 
 	return sriovDevice, nil
 }
+
+func (h *Handler) markDeviceDegraded(sriovDevice *v1beta1.SRIOVNetworkDevice, reconcileErr error) (*v1beta1.SRIOVNetworkDevice, error) {
+	common.MarkDegraded(&sriovDevice.Status.Conditions, sriovDevice.Generation, v1beta1.ReasonConfigurationError, reconcileErr)
+	return common.UpdateDegradedStatus("sriovnetworkdevice", sriovDevice, reconcileErr, h.sriovClient.UpdateStatus)
+}
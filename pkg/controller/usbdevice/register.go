@@ -7,6 +7,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/harvester/pcidevices/pkg/config"
+	"github.com/harvester/pcidevices/pkg/util/discoverypolicy"
 )
 
 const (
@@ -19,8 +20,11 @@ func Register(ctx context.Context, management *config.FactoryManager) error {
 	usbDeviceCtrl := management.DeviceFactory.Devices().V1beta1().USBDevice()
 	usbDeviceClaimCtrl := management.DeviceFactory.Devices().V1beta1().USBDeviceClaim()
 	virtClient := management.KubevirtFactory.Kubevirt().V1().KubeVirt()
+	nodeCtrl := management.DeviceFactory.Devices().V1beta1().Node()
+	discovery := discoverypolicy.NewEvaluator(cl.nodeName, management.CoreFactory.Core().V1().Node().Cache(),
+		management.DeviceFactory.Devices().V1beta1().DeviceDiscoveryPolicy().Cache())
 
-	handler := NewHandler(usbDeviceCtrl, usbDeviceClaimCtrl, usbDeviceCtrl.Cache(), usbDeviceClaimCtrl.Cache())
+	handler := NewHandler(usbDeviceCtrl, usbDeviceClaimCtrl, usbDeviceCtrl.Cache(), usbDeviceClaimCtrl.Cache(), nodeCtrl.Cache(), discovery)
 	usbDeviceClaimController := NewClaimHandler(usbDeviceCtrl.Cache(), usbDeviceClaimCtrl, usbDeviceCtrl, virtClient, handler.reconcileSignal)
 
 	// Initial reconcile
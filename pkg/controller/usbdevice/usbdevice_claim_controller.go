@@ -14,6 +14,7 @@ import (
 	"github.com/harvester/pcidevices/pkg/deviceplugins"
 	ctldevicerv1beta1 "github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
 	ctlkubevirtv1 "github.com/harvester/pcidevices/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/pcidevices/pkg/util/common"
 )
 
 type DevClaimHandler struct {
@@ -81,7 +82,7 @@ func (h *DevClaimHandler) OnUSBDeviceClaimChanged(_ string, usbDeviceClaim *v1be
 	_, err = h.updateKubeVirt(virt, usbDevice)
 	if err != nil {
 		logrus.Errorf("failed to update kubevirt: %v", err)
-		return usbDeviceClaim, err
+		return h.markClaimDegraded(usbDeviceClaim, v1beta1.ReasonConfigurationError, err)
 	}
 
 	devicePlugin, ok := h.managedDevicePlugins[usbDeviceClaim.Name]
@@ -91,7 +92,7 @@ func (h *DevClaimHandler) OnUSBDeviceClaimChanged(_ string, usbDeviceClaim *v1be
 
 		if err != nil {
 			logrus.Errorf("failed to create usb device plugin: %v", err)
-			return usbDeviceClaim, err
+			return h.markClaimDegraded(usbDeviceClaim, v1beta1.ReasonPluginFailed, err)
 		}
 
 		h.managedDevicePlugins[usbDeviceClaim.Name] = usbDevicePlugin
@@ -111,6 +112,7 @@ func (h *DevClaimHandler) OnUSBDeviceClaimChanged(_ string, usbDeviceClaim *v1be
 	if !usbDevice.Status.Enabled {
 		usbDeviceCp.Status.Enabled = true
 	}
+	setUSBReadyConditions(&usbDeviceCp.Status.Conditions, usbDeviceCp.Generation)
 
 	if !reflect.DeepEqual(usbDevice.Status, usbDeviceCp.Status) {
 		if usbDevice, err = h.usbClient.UpdateStatus(usbDeviceCp); err != nil {
@@ -128,10 +130,25 @@ func (h *DevClaimHandler) OnUSBDeviceClaimChanged(_ string, usbDeviceClaim *v1be
 	usbDeviceClaimCp := usbDeviceClaim.DeepCopy()
 	usbDeviceClaimCp.Status.PCIAddress = usbDevice.Status.PCIAddress
 	usbDeviceClaimCp.Status.NodeName = usbDevice.Status.NodeName
+	common.SetCondition(&usbDeviceClaimCp.Status.Conditions, usbDeviceClaimCp.Generation, v1beta1.ConditionBound, true, v1beta1.ReasonReconciled, "")
+	setUSBReadyConditions(&usbDeviceClaimCp.Status.Conditions, usbDeviceClaimCp.Generation)
 
 	return h.usbClaimClient.UpdateStatus(usbDeviceClaimCp)
 }
 
+// setUSBReadyConditions records a usb device or claim which is served by a running device plugin
+func setUSBReadyConditions(conditions *[]metav1.Condition, generation int64) {
+	common.SetCondition(conditions, generation, v1beta1.ConditionPluginRegistered, true, v1beta1.ReasonPluginRegistered, "")
+	common.SetCondition(conditions, generation, v1beta1.ConditionReady, true, v1beta1.ReasonReconciled, "")
+	common.SetDegraded(conditions, generation, v1beta1.ReasonReconciled, nil)
+}
+
+func (h *DevClaimHandler) markClaimDegraded(claim *v1beta1.USBDeviceClaim, reason string, reconcileErr error) (*v1beta1.USBDeviceClaim, error) {
+	claimCp := claim.DeepCopy()
+	common.MarkDegraded(&claimCp.Status.Conditions, claimCp.Generation, reason, reconcileErr)
+	return common.UpdateDegradedStatus("usbdeviceclaim", claimCp, reconcileErr, h.usbClaimClient.UpdateStatus)
+}
+
 func (h *DevClaimHandler) OnRemove(_ string, claim *v1beta1.USBDeviceClaim) (*v1beta1.USBDeviceClaim, error) {
 	if claim == nil || claim.DeletionTimestamp == nil {
 		return claim, nil
@@ -196,6 +213,8 @@ func (h *DevClaimHandler) OnRemove(_ string, claim *v1beta1.USBDeviceClaim) (*v1
 
 	usbDeviceCp := usbDevice.DeepCopy()
 	usbDeviceCp.Status.Enabled = false
+	common.SetCondition(&usbDeviceCp.Status.Conditions, usbDeviceCp.Generation, v1beta1.ConditionPluginRegistered, false, v1beta1.ReasonDisabled, "")
+	common.SetCondition(&usbDeviceCp.Status.Conditions, usbDeviceCp.Generation, v1beta1.ConditionReady, false, v1beta1.ReasonDisabled, "usb device is not claimed")
 	if _, err = h.usbClient.UpdateStatus(usbDeviceCp); err != nil {
 		logrus.Errorf("failed to disable usb device %s status: %v", usbDeviceCp.Name, err)
 		return claim, err
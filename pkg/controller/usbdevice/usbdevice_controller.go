@@ -2,6 +2,7 @@ package usbdevice
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"github.com/fsnotify/fsnotify"
 	"github.com/rancher/wrangler/v3/pkg/relatedresource"
 	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -18,6 +20,8 @@ import (
 	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
 	"github.com/harvester/pcidevices/pkg/deviceplugins"
 	ctldevicerv1vbeta1 "github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/util/common"
+	"github.com/harvester/pcidevices/pkg/util/discoverypolicy"
 	"github.com/harvester/pcidevices/pkg/util/gousb"
 	"github.com/harvester/pcidevices/pkg/util/gousb/usbid"
 )
@@ -27,6 +31,10 @@ type DevHandler struct {
 	usbClaimClient ctldevicerv1vbeta1.USBDeviceClaimClient
 	usbCache       ctldevicerv1vbeta1.USBDeviceCache
 	usbClaimCache  ctldevicerv1vbeta1.USBDeviceClaimCache
+	// nodeCache is used to check if usb discovery is disabled on the node
+	nodeCache ctldevicerv1vbeta1.NodeCache
+	// discovery limits the devices which are discovered by the DeviceDiscoveryPolicies which apply to the node
+	discovery *discoverypolicy.Evaluator
 
 	reconcileSignal chan struct{}
 }
@@ -45,12 +53,16 @@ func NewHandler(
 	usbClaimClient ctldevicerv1vbeta1.USBDeviceClaimClient,
 	usbCache ctldevicerv1vbeta1.USBDeviceCache,
 	usbClaimCache ctldevicerv1vbeta1.USBDeviceClaimCache,
+	nodeCache ctldevicerv1vbeta1.NodeCache,
+	discovery *discoverypolicy.Evaluator,
 ) *DevHandler {
 	return &DevHandler{
 		usbClient:       usbClient,
 		usbClaimClient:  usbClaimClient,
 		usbCache:        usbCache,
 		usbClaimCache:   usbClaimCache,
+		nodeCache:       nodeCache,
+		discovery:       discovery,
 		reconcileSignal: make(chan struct{}, 1),
 	}
 }
@@ -151,6 +163,15 @@ func (h *DevHandler) WatchReconcile(ctx context.Context) {
 func (h *DevHandler) Reconcile() error {
 	nodeName := cl.nodeName
 
+	disabled, err := h.discoveryDisabled()
+	if err != nil {
+		return err
+	}
+	if disabled {
+		logrus.Debugf("skipping usb device reconcile as usb discovery is disabled on node %s", nodeName)
+		return nil
+	}
+
 	localUSBDevices, err := walkUSBDevices()
 	if err != nil {
 		logrus.Errorf("failed to walk USB devices: %v\n", err)
@@ -169,6 +190,13 @@ func (h *DevHandler) Reconcile() error {
 		mapStoredUSBDevices[storedUSBDevice.Status.DevicePath] = &storedUSBDevice
 	}
 
+	filter, err := h.discovery.Filter()
+	if err != nil {
+		return err
+	}
+	// devices which are no longer discovered are removed along with unplugged devices
+	localUSBDevices = discoveredDevices(localUSBDevices, mapStoredUSBDevices, filter)
+
 	err = h.handleList(h.getList(localUSBDevices, mapStoredUSBDevices, nodeName))
 	if err != nil {
 		return err
@@ -216,6 +244,8 @@ func (h *DevHandler) handleList(usageList UsageList) error {
 		logrus.Errorf("failed to delete orphaned device claim: %v\n", err)
 		usbDevice.Status.Status = v1beta1.USBDeviceStatusOrphaned
 		usbDevice.Status.Message = "The USB device is orphaned, please remove it from virtual machine and disable it."
+		common.SetCondition(&usbDevice.Status.Conditions, usbDevice.Generation, v1beta1.ConditionReady, false, v1beta1.ReasonOrphaned, usbDevice.Status.Message)
+		common.SetDegraded(&usbDevice.Status.Conditions, usbDevice.Generation, v1beta1.ReasonOrphaned, errors.New(usbDevice.Status.Message))
 
 		_, err = h.usbClient.UpdateStatus(usbDevice)
 		if err != nil {
@@ -234,6 +264,50 @@ func (h *DevHandler) handleList(usageList UsageList) error {
 	return nil
 }
 
+// discoveryDisabled returns true if usb discovery is disabled in the NodeSpec
+func (h *DevHandler) discoveryDisabled() (bool, error) {
+	if h.nodeCache == nil {
+		return false, nil
+	}
+
+	node, err := h.nodeCache.Get(cl.nodeName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error looking up node %s: %w", cl.nodeName, err)
+	}
+	return node.Spec.SubsystemDisabled(v1beta1.SubsystemUSB), nil
+}
+
+// discoveredDevices returns the local devices which are allowed by the DeviceDiscoveryPolicies in filter.
+// Enabled devices are always discovered, so devices in use by a claim are not removed when a policy changes
+func discoveredDevices(localUSBDevices map[int][]*deviceplugins.USBDevice, mapStoredUSBDevices map[string]*v1beta1.USBDevice, filter discoverypolicy.Filter) map[int][]*deviceplugins.USBDevice {
+	if len(filter) == 0 {
+		return localUSBDevices
+	}
+
+	discovered := make(map[int][]*deviceplugins.USBDevice, len(localUSBDevices))
+	for key, localDevices := range localUSBDevices {
+		for _, localUSBDevice := range localDevices {
+			if stored, ok := mapStoredUSBDevices[localUSBDevice.DevicePath]; ok && stored.Status.Enabled {
+				discovered[key] = append(discovered[key], localUSBDevice)
+				continue
+			}
+			if filter.Allows(v1beta1.DiscoveredDevice{
+				Subsystem: v1beta1.SubsystemUSB,
+				VendorID:  fmt.Sprintf("%04x", localUSBDevice.Vendor),
+				DeviceID:  fmt.Sprintf("%04x", localUSBDevice.Product),
+				ClassID:   localUSBDevice.ClassCode,
+				Address:   localUSBDevice.DevicePath,
+			}) {
+				discovered[key] = append(discovered[key], localUSBDevice)
+			}
+		}
+	}
+	return discovered
+}
+
 func (h *DevHandler) getList(localUSBDevices map[int][]*deviceplugins.USBDevice, mapStoredUSBDevices map[string]*v1beta1.USBDevice, nodeName string) UsageList {
 	var (
 		createList   []*v1beta1.USBDevice
@@ -275,6 +349,8 @@ func (h *DevHandler) getList(localUSBDevices map[int][]*deviceplugins.USBDevice,
 						Description:  usbDescription(localUSBDevice),
 						PCIAddress:   localUSBDevice.PCIAddress,
 						ClassType:    localUSBDevice.ClassType,
+						// conditions are owned by the claim controller and are preserved across rediscovery
+						Conditions: existed.Status.Conditions,
 					}
 					updateList = append(updateList, existedCp)
 				}
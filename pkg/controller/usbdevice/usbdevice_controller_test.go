@@ -5,10 +5,14 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corefake "k8s.io/client-go/kubernetes/fake"
 
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
 	"github.com/harvester/pcidevices/pkg/deviceplugins"
 	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/pcidevices/pkg/util/discoverypolicy"
 	"github.com/harvester/pcidevices/pkg/util/fakeclients"
 )
 
@@ -42,6 +46,8 @@ func Test_ReconcileUSBDevices(t *testing.T) {
 		fakeclients.USBDeviceClaimsClient(client.DevicesV1beta1().USBDeviceClaims),
 		fakeclients.USBDeviceCache(client.DevicesV1beta1().USBDevices),
 		fakeclients.USBDeviceClaimsCache(client.DevicesV1beta1().USBDeviceClaims),
+		fakeclients.NodeDevicesCache(client.DevicesV1beta1().Nodes),
+		nil,
 	)
 
 	err := usbHandler.Reconcile()
@@ -69,3 +75,74 @@ func Test_ReconcileUSBDevices(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 0, len(list.Items))
 }
+
+func Test_ReconcileUSBDevicesDisabled(t *testing.T) {
+	walkUSBDevices = mockWalkUSBDevices
+	cl = mockCommonLabel
+	node := &v1beta1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: cl.nodeName,
+		},
+		Spec: v1beta1.NodeSpec{
+			DisabledSubsystems: []v1beta1.NodeSubsystem{v1beta1.SubsystemUSB},
+		},
+	}
+	client := fake.NewSimpleClientset(node)
+
+	usbHandler := NewHandler(
+		fakeclients.USBDevicesClient(client.DevicesV1beta1().USBDevices),
+		fakeclients.USBDeviceClaimsClient(client.DevicesV1beta1().USBDeviceClaims),
+		fakeclients.USBDeviceCache(client.DevicesV1beta1().USBDevices),
+		fakeclients.USBDeviceClaimsCache(client.DevicesV1beta1().USBDeviceClaims),
+		fakeclients.NodeDevicesCache(client.DevicesV1beta1().Nodes),
+		nil,
+	)
+
+	err := usbHandler.Reconcile()
+	assert.NoError(t, err)
+
+	list, err := client.DevicesV1beta1().USBDevices().List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, list.Items, "expected no usb devices to be discovered while usb discovery is disabled")
+}
+
+func Test_ReconcileUSBDevicesDiscoveryPolicy(t *testing.T) {
+	walkUSBDevices = mockWalkUSBDevices
+	cl = mockCommonLabel
+	client := fake.NewSimpleClientset()
+	coreClient := corefake.NewSimpleClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: cl.nodeName}})
+
+	usbHandler := NewHandler(
+		fakeclients.USBDevicesClient(client.DevicesV1beta1().USBDevices),
+		fakeclients.USBDeviceClaimsClient(client.DevicesV1beta1().USBDeviceClaims),
+		fakeclients.USBDeviceCache(client.DevicesV1beta1().USBDevices),
+		fakeclients.USBDeviceClaimsCache(client.DevicesV1beta1().USBDeviceClaims),
+		fakeclients.NodeDevicesCache(client.DevicesV1beta1().Nodes),
+		discoverypolicy.NewEvaluator(cl.nodeName, fakeclients.NodeCache(coreClient.CoreV1().Nodes),
+			fakeclients.DeviceDiscoveryPoliciesCache(client.DevicesV1beta1().DeviceDiscoveryPolicies)),
+	)
+
+	err := usbHandler.Reconcile()
+	assert.NoError(t, err)
+	list, err := client.DevicesV1beta1().USBDevices().List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, list.Items, 1)
+
+	// excluding the vendor removes the existing USBDevice
+	policy := &v1beta1.DeviceDiscoveryPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "no-kingston",
+		},
+		Spec: v1beta1.DeviceDiscoveryPolicySpec{
+			Exclude: []v1beta1.DeviceDiscoveryRule{{Subsystem: v1beta1.SubsystemUSB, VendorID: "0951"}},
+		},
+	}
+	_, err = client.DevicesV1beta1().DeviceDiscoveryPolicies().Create(context.Background(), policy, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	err = usbHandler.Reconcile()
+	assert.NoError(t, err)
+	list, err = client.DevicesV1beta1().USBDevices().List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, list.Items, "expected excluded usb device to be removed")
+}
@@ -28,6 +28,7 @@ import (
 	ctldevicesv1beta1 "github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
 	ctlkubevirtv1 "github.com/harvester/pcidevices/pkg/generated/controllers/kubevirt.io/v1"
 	"github.com/harvester/pcidevices/pkg/util/executor"
+	"github.com/harvester/pcidevices/pkg/util/podresources"
 )
 
 const (
@@ -44,6 +45,7 @@ type Handler struct {
 	pciDeviceCache ctldevicesv1beta1.PCIDeviceCache
 	config         *rest.Config
 	nodeName       string
+	podResources   podresources.Lister
 }
 
 func Register(ctx context.Context, management *config.FactoryManager) error {
@@ -64,6 +66,7 @@ func Register(ctx context.Context, management *config.FactoryManager) error {
 		pciDeviceCache: pciDeviceCache,
 		config:         management.Cfg,
 		nodeName:       nodeName,
+		podResources:   podresources.NewLister(podresources.DefaultSocketPath),
 	}
 	vmi.OnChange(ctx, "virtual-machine-instance-handler", h.OnVMIChange)
 	vmi.OnRemove(ctx, "virtual-machine-deletion", h.OnVMIDeletion)
@@ -113,17 +116,53 @@ func (h *Handler) OnVMIChange(name string, vmi *kubevirtv1.VirtualMachineInstanc
 }
 
 // trackDevices reconciles GPU and HostDevices info
+// devices allocated to the launcher pod are looked up from the kubelet pod resources api, and only if that fails
+// from the env of the launcher pod
 func (h *Handler) trackDevices(vmi *kubevirtv1.VirtualMachineInstance) error {
-	envMap, err := h.generatePodEnvMap(vmi)
+	pod, err := h.findPodForVMI(vmi)
 	if err != nil {
 		return err
 	}
-	return h.reconcileDeviceAllocationDetails(vmi, envMap)
+
+	allocatedDevices, err := h.podResources.PodDevices(h.ctx, pod.Namespace, pod.Name)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"name":      vmi.Name,
+			"namespace": vmi.Namespace,
+		}).Warnf("falling back to pod env to lookup allocated devices: %v", err)
+		envMap, err := h.generatePodEnvMap(pod)
+		if err != nil {
+			return err
+		}
+		allocatedDevices = envAllocatedDevices(vmi, envMap)
+	}
+	return h.reconcileDeviceAllocationDetails(vmi, allocatedDevices)
 }
 
-// reconcileDeviceAllocationDetails will reconcile envMap into device allocation annotation on vmi
+// envAllocatedDevices converts the device plugin env variables in envMap to the device ids allocated to the pod,
+// keyed by resource name
+func envAllocatedDevices(vmi *kubevirtv1.VirtualMachineInstance, envMap map[string]string) map[string][]string {
+	allocatedDevices := make(map[string][]string)
+	for _, device := range vmi.Spec.Domain.Devices.HostDevices {
+		if val, ok := envMap[util.ResourceNameToEnvVar(deviceplugins.PCIResourcePrefix, device.DeviceName)]; ok {
+			allocatedDevices[device.DeviceName] = strings.Split(val, ",")
+		}
+	}
+	for _, device := range vmi.Spec.Domain.Devices.GPUs {
+		// if there are multiple vGPU of same type then the environment variable
+		// will contain details of all GPUs in the envMap
+		// for example a VM with 2 vGPU of the same kind: MDEV_PCI_RESOURCE_NVIDIA_COM_NVIDIA_A2-4Q=e898f311-6b9e-46a2-b728-144d01af1a7c,95242535-e423-41a6-bb58-f28a44d68d66
+		if val, ok := envMap[util.ResourceNameToEnvVar(deviceplugins.VGPUPrefix, device.DeviceName)]; ok {
+			allocatedDevices[device.DeviceName] = strings.Split(val, ",")
+		}
+	}
+	return allocatedDevices
+}
+
+// reconcileDeviceAllocationDetails will reconcile device ids allocated to the launcher pod, keyed by resource name,
+// into device allocation annotation on vmi
 // has been split into its own method to simplify testing
-func (h *Handler) reconcileDeviceAllocationDetails(vmi *kubevirtv1.VirtualMachineInstance, envMap map[string]string) error {
+func (h *Handler) reconcileDeviceAllocationDetails(vmi *kubevirtv1.VirtualMachineInstance, allocatedDevices map[string][]string) error {
 	var pciDeviceMap, vGPUMap map[string]string
 	selector := map[string]string{
 		"nodename": vmi.Status.NodeName,
@@ -134,6 +173,7 @@ func (h *Handler) reconcileDeviceAllocationDetails(vmi *kubevirtv1.VirtualMachin
 			return fmt.Errorf("error listing pcidevices from cache: %v", err)
 		}
 		pciDeviceMap = buildPCIDeviceMap(deviceList)
+		allocatedDevices = expandIOMMUGroups(allocatedDevices, deviceList)
 	}
 	if len(vmi.Spec.Domain.Devices.GPUs) > 0 {
 		deviceList, err := h.vgpuCache.List(labels.SelectorFromSet(selector))
@@ -144,8 +184,8 @@ func (h *Handler) reconcileDeviceAllocationDetails(vmi *kubevirtv1.VirtualMachin
 	}
 	// map to hold device details
 
-	hostDeviceMap := reconcilePCIDeviceDetails(vmi, envMap, pciDeviceMap)
-	gpuMap := reconcileGPUDetails(vmi, envMap, vGPUMap)
+	hostDeviceMap := reconcilePCIDeviceDetails(vmi, allocatedDevices, pciDeviceMap)
+	gpuMap := reconcileGPUDetails(vmi, allocatedDevices, vGPUMap)
 
 	// generate allocation details
 	deviceDetails := generateAllocationDetails(hostDeviceMap, gpuMap)
@@ -158,7 +198,8 @@ func (h *Handler) reconcileDeviceAllocationDetails(vmi *kubevirtv1.VirtualMachin
 }
 
 // findPodForVMI leverages the fact that each pod associated with a VMI a label vm.kubevirt.io/name: $vmName
-// this makes it easier to find the correct pod
+// this makes it easier to find the correct pod. Launcher pods on other nodes, such as the target of a migration,
+// are ignored
 func (h *Handler) findPodForVMI(vmi *kubevirtv1.VirtualMachineInstance) (*corev1.Pod, error) {
 	podList, err := h.pod.List(vmi.Namespace, metav1.ListOptions{
 		LabelSelector: fmt.Sprintf("%s=%s", kubevirtVMLabelKey, vmi.Name),
@@ -167,21 +208,20 @@ func (h *Handler) findPodForVMI(vmi *kubevirtv1.VirtualMachineInstance) (*corev1
 		return nil, fmt.Errorf("error listing pods: %v", err)
 	}
 
-	// if more than 1 pod is returned make sure only 1 is running
-	// if more than 1 is running then error out since there may be a migration
-	// running and reconcile again
+	// if more than 1 pod is returned make sure only 1 is running on this node
+	// if more than 1 is running then error out and reconcile again
 	var runningPod corev1.Pod
 
 	var count int
 	for _, pod := range podList.Items {
-		if pod.Status.Phase == corev1.PodRunning {
+		if pod.Spec.NodeName == h.nodeName && pod.Status.Phase == corev1.PodRunning {
 			runningPod = pod // we copy pod in case there is only 1 running, avoids having to iterate again
 			count++
 		}
 	}
 
 	if count != 1 {
-		return nil, fmt.Errorf("expected to find 1 pod, but found %d associated with vmi %s", count, vmi.Name)
+		return nil, fmt.Errorf("expected to find 1 pod on node %s, but found %d associated with vmi %s", h.nodeName, count, vmi.Name)
 	}
 
 	return &runningPod, nil
@@ -258,6 +298,41 @@ func buildPCIDeviceMap(pciDevices []*v1beta1.PCIDevice) map[string]string {
 	return result
 }
 
+// expandIOMMUGroups adds the other members of the iommu group of each allocated pci device, as the pcidevice plugin
+// passes the whole group through to the launcher pod. Device ids which are not pci addresses are kept as is
+func expandIOMMUGroups(allocatedDevices map[string][]string, pciDevices []*v1beta1.PCIDevice) map[string][]string {
+	groupOf := make(map[string]string)
+	members := make(map[string][]string)
+	for _, device := range pciDevices {
+		if device.Status.IOMMUGroup == "" {
+			continue
+		}
+		groupOf[device.Status.Address] = device.Status.IOMMUGroup
+		members[device.Status.IOMMUGroup] = append(members[device.Status.IOMMUGroup], device.Status.Address)
+	}
+	for _, v := range members {
+		slices.Sort(v)
+	}
+
+	result := make(map[string][]string, len(allocatedDevices))
+	for resourceName, ids := range allocatedDevices {
+		var expanded []string
+		for _, id := range ids {
+			group := []string{id}
+			if v, ok := groupOf[id]; ok {
+				group = members[v]
+			}
+			for _, v := range group {
+				if !slices.Contains(expanded, v) {
+					expanded = append(expanded, v)
+				}
+			}
+		}
+		result[resourceName] = expanded
+	}
+	return result
+}
+
 func generateAllocationDetails(hostDeviceMap, gpuMap map[string][]string) *v1beta1.AllocationDetails {
 	resp := &v1beta1.AllocationDetails{}
 	if len(hostDeviceMap) > 0 {
@@ -282,20 +357,10 @@ func dedupDevices(deviceMap map[string][]string) map[string][]string {
 	return deviceMap
 }
 
-// generatePodEnvMap attempts to find the pod associated with vmi, exec into pod to fetch `env` output
+// generatePodEnvMap execs into the launcher pod of the vmi to fetch `env` output
 // and converts the same to the map, to allow the controller to identify device allocated to pod by kubelet
 // which can differ from the name in the vmi devices spec, since allocation is only performed by resourceName
-func (h *Handler) generatePodEnvMap(vmi *kubevirtv1.VirtualMachineInstance) (map[string]string, error) {
-	logrus.WithFields(logrus.Fields{
-		"name":      vmi.Name,
-		"namespace": vmi.Namespace,
-	}).Debug("looking up pod associated with vmi")
-
-	pod, err := h.findPodForVMI(vmi)
-	if err != nil {
-		return nil, err
-	}
-
+func (h *Handler) generatePodEnvMap(pod *corev1.Pod) (map[string]string, error) {
 	logrus.WithFields(logrus.Fields{
 		"name":      pod.Name,
 		"namespace": pod.Namespace,
@@ -312,8 +377,8 @@ func (h *Handler) generatePodEnvMap(vmi *kubevirtv1.VirtualMachineInstance) (map
 	}
 
 	logrus.WithFields(logrus.Fields{
-		"name":      vmi.Name,
-		"namespace": vmi.Namespace,
+		"name":      pod.Name,
+		"namespace": pod.Namespace,
 	}).Debugf("found envMap: %v", envMap)
 	return envMap, nil
 }
@@ -447,46 +512,54 @@ func patchGPUDevices(vm *kubevirtv1.VirtualMachine, deviceInfo map[string][]stri
 	}
 }
 
-func reconcileGPUDetails(vmi *kubevirtv1.VirtualMachineInstance, envMap map[string]string, vGPUMap map[string]string) map[string][]string {
+func reconcileGPUDetails(vmi *kubevirtv1.VirtualMachineInstance, allocatedDevices map[string][]string, vGPUMap map[string]string) map[string][]string {
 	gpuMap := make(map[string][]string)
 	for _, device := range vmi.Spec.Domain.Devices.GPUs {
-		val, ok := envMap[util.ResourceNameToEnvVar(deviceplugins.VGPUPrefix, device.DeviceName)]
-		if ok {
-			deviceInfo, deviceFound := gpuMap[device.DeviceName]
-			// if there are multiple vGPU of same type then the environment variable
-			// will contain details of all GPUs in the envMap
-			// for example a VM with 2 vGPU of the same kind: MDEV_PCI_RESOURCE_NVIDIA_COM_NVIDIA_A2-4Q=e898f311-6b9e-46a2-b728-144d01af1a7c,95242535-e423-41a6-bb58-f28a44d68d66
-			// as a result all GPUs will be added in the first pass of the key not being found
-			// and we ignore subsequent lookups of the resource name key
-			if !deviceFound {
-				devices := strings.Split(val, ",")
-				for _, v := range devices {
-					deviceInfo = append(deviceInfo, vGPUMap[v])
-				}
-				gpuMap[device.DeviceName] = deviceInfo
+		ids, ok := allocatedDevices[device.DeviceName]
+		if !ok {
+			continue
+		}
+		// if there are multiple vGPU of same type then all of them are allocated under the same resource name
+		// as a result all GPUs will be added in the first pass of the key not being found
+		// and we ignore subsequent lookups of the resource name key
+		if _, deviceFound := gpuMap[device.DeviceName]; deviceFound {
+			continue
+		}
+		var deviceInfo []string
+		for _, v := range ids {
+			if name, ok := vGPUMap[v]; ok {
+				deviceInfo = append(deviceInfo, name)
 			}
 		}
+		if len(deviceInfo) > 0 {
+			gpuMap[device.DeviceName] = deviceInfo
+		}
 	}
 	return gpuMap
 }
 
-func reconcilePCIDeviceDetails(vmi *kubevirtv1.VirtualMachineInstance, envMap map[string]string, pciDeviceMap map[string]string) map[string][]string {
+func reconcilePCIDeviceDetails(vmi *kubevirtv1.VirtualMachineInstance, allocatedDevices map[string][]string, pciDeviceMap map[string]string) map[string][]string {
 	hostDeviceMap := make(map[string][]string)
 	for _, device := range vmi.Spec.Domain.Devices.HostDevices {
-		val, ok := envMap[util.ResourceNameToEnvVar(deviceplugins.PCIResourcePrefix, device.DeviceName)]
-		if ok {
-			deviceInfo, deviceFound := hostDeviceMap[device.DeviceName]
-			if !deviceFound {
-				devices := strings.Split(val, ",")
-				// currently our pcidevice plugin duplicates pci addresses
-				// extra step needed to dedup addresses
-				devices = slices.Compact(devices)
-				for _, v := range devices {
-					deviceInfo = append(deviceInfo, pciDeviceMap[v])
-				}
-				hostDeviceMap[device.DeviceName] = deviceInfo
+		ids, ok := allocatedDevices[device.DeviceName]
+		if !ok {
+			continue
+		}
+		if _, deviceFound := hostDeviceMap[device.DeviceName]; deviceFound {
+			continue
+		}
+		// currently our pcidevice plugin duplicates pci addresses
+		// extra step needed to dedup addresses
+		ids = slices.Compact(slices.Clone(ids))
+		var deviceInfo []string
+		for _, v := range ids {
+			// ids of other device plugins, such as usb devices passed as host devices, are not pci addresses
+			if name, ok := pciDeviceMap[v]; ok {
+				deviceInfo = append(deviceInfo, name)
 			}
-
+		}
+		if len(deviceInfo) > 0 {
+			hostDeviceMap[device.DeviceName] = deviceInfo
 		}
 	}
 	return hostDeviceMap
@@ -1,11 +1,17 @@
 package virtualmachine
 
 import (
+	"context"
 	"testing"
 
+	harvesterfake "github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/pcidevices/pkg/util/fakeclients"
 )
 
 func Test_patchHostDevices(t *testing.T) {
@@ -112,7 +118,7 @@ func Test_reconcileGPUDetails(t *testing.T) {
 			},
 		},
 	}
-	gpuMap := reconcileGPUDetails(vmi, envMap, vGPUMap)
+	gpuMap := reconcileGPUDetails(vmi, envAllocatedDevices(vmi, envMap), vGPUMap)
 	assert := require.New(t)
 	assert.Len(gpuMap["nvidia.com/NVIDIA_A2-4Q"], 2, "expected to find only 2 gpus")
 }
@@ -149,7 +155,78 @@ func Test_reconcilePCIDeviceDetails(t *testing.T) {
 			},
 		},
 	}
-	gpuMap := reconcilePCIDeviceDetails(vmi, envMap, pciDeviceMap)
+	gpuMap := reconcilePCIDeviceDetails(vmi, envAllocatedDevices(vmi, envMap), pciDeviceMap)
 	assert := require.New(t)
 	assert.Len(gpuMap["mellanox.com/MT27700_FAMILY_CONNECTX4_VIRTUAL_FUNCTION"], 2, "expected to find only 2 gpus")
 }
+
+func Test_reconcileDeviceAllocationDetails(t *testing.T) {
+	assert := require.New(t)
+	pd := &devicesv1beta1.PCIDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node1-000081000",
+			Labels: map[string]string{"nodename": "node1"},
+		},
+		Status: devicesv1beta1.PCIDeviceStatus{
+			Address:    "0000:81:00.0",
+			NodeName:   "node1",
+			IOMMUGroup: "12",
+		},
+	}
+	// the audio function of the gpu shares its iommu group, and is passed through with it
+	audio := &devicesv1beta1.PCIDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node1-000081001",
+			Labels: map[string]string{"nodename": "node1"},
+		},
+		Status: devicesv1beta1.PCIDeviceStatus{
+			Address:    "0000:81:00.1",
+			NodeName:   "node1",
+			IOMMUGroup: "12",
+		},
+	}
+	vm := &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "default",
+		},
+	}
+	vmi := &kubevirtv1.VirtualMachineInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "default",
+		},
+		Spec: kubevirtv1.VirtualMachineInstanceSpec{
+			Domain: kubevirtv1.DomainSpec{
+				Devices: kubevirtv1.Devices{
+					HostDevices: []kubevirtv1.HostDevice{
+						{Name: "node1-000082000", DeviceName: "nvidia.com/GA100"},
+						{Name: "node1-001-002", DeviceName: "yubico.com/YUBIKEY"},
+					},
+				},
+			},
+		},
+		Status: kubevirtv1.VirtualMachineInstanceStatus{
+			NodeName: "node1",
+		},
+	}
+	client := fake.NewSimpleClientset(pd, audio)
+	harvesterClient := harvesterfake.NewSimpleClientset(vm)
+	h := &Handler{
+		vmCache:        fakeclients.VirtualMachineCache(harvesterClient.KubevirtV1().VirtualMachines),
+		vmClient:       fakeclients.VirtualMachineClient(harvesterClient.KubevirtV1().VirtualMachines),
+		vgpuCache:      fakeclients.VGPUDeviceCache(client.DevicesV1beta1().VGPUDevices),
+		pciDeviceCache: fakeclients.PCIDevicesCache(client.DevicesV1beta1().PCIDevices),
+	}
+
+	// device ids as reported by the kubelet pod resources api
+	allocatedDevices := map[string][]string{
+		"nvidia.com/GA100":   {"0000:81:00.0"},
+		"yubico.com/YUBIKEY": {"node1-001-002"},
+	}
+	assert.NoError(h.reconcileDeviceAllocationDetails(vmi, allocatedDevices))
+	vm, err := harvesterClient.KubevirtV1().VirtualMachines(vm.Namespace).Get(context.TODO(), vm.Name, metav1.GetOptions{})
+	assert.NoError(err)
+	assert.Equal(`{"hostdevices":{"nvidia.com/GA100":["node1-000081000","node1-000081001"]}}`, vm.Annotations[devicesv1beta1.DeviceAllocationKey],
+		"expected allocated pci device and the members of its iommu group to be tracked, and ids of other device plugins to be ignored")
+}
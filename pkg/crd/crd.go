@@ -93,7 +93,8 @@ func List() []crd.CRD {
 				WithColumn("Node Name", ".spec.nodeName").
 				WithColumn("User Name", ".spec.userName").
 				WithColumn("Kernel Driver Το Unbind", ".status.kernelDriverToUnbind").
-				WithColumn("Passthrough Enabled", ".status.passthroughEnabled")
+				WithColumn("Passthrough Enabled", ".status.passthroughEnabled").
+				WithColumn("Lease Expiry", ".status.lease.expiryTime")
 		}),
 		newCRD(&devices.SRIOVNetworkDevice{}, func(c crd.CRD) crd.CRD {
 			c.NonNamespace = true
@@ -105,7 +106,6 @@ func List() []crd.CRD {
 		}),
 		newCRD(&devices.Node{}, func(c crd.CRD) crd.CRD {
 			c.NonNamespace = true
-			c.Status = false
 			return c
 		}),
 		newCRD(&devices.SRIOVGPUDevice{}, func(c crd.CRD) crd.CRD {
@@ -142,7 +142,8 @@ func List() []crd.CRD {
 			return c.
 				WithColumn("Node Name", ".status.nodeName").
 				WithColumn("PCI Address", ".status.pciAddress").
-				WithColumn("User Name", ".spec.userName")
+				WithColumn("User Name", ".spec.userName").
+				WithColumn("Lease Expiry", ".status.lease.expiryTime")
 		}),
 		newCRD(&devices.MigConfiguration{}, func(c crd.CRD) crd.CRD {
 			c.NonNamespace = true
@@ -153,6 +154,54 @@ func List() []crd.CRD {
 				WithColumn("Status", ".status.status").
 				WithColumn("Message", ".status.message")
 		}),
+		newCRD(&devices.IOMMUGroup{}, func(c crd.CRD) crd.CRD {
+			c.NonNamespace = true
+			return c.
+				WithColumn("Node Name", ".status.nodeName").
+				WithColumn("Group", ".status.group").
+				WithColumn("Isolated", ".status.isolated")
+		}),
+		newCRD(&devices.DeviceDiscoveryPolicy{}, func(c crd.CRD) crd.CRD {
+			c.NonNamespace = true
+			c.Status = false
+			return c
+		}),
+		newCRD(&devices.ResourceNameRule{}, func(c crd.CRD) crd.CRD {
+			c.NonNamespace = true
+			c.Status = false
+			return c.
+				WithColumn("Vendor Id", ".spec.vendorId").
+				WithColumn("Device Id", ".spec.deviceId").
+				WithColumn("Resource Name", ".spec.resourceName")
+		}),
+		newCRD(&devices.DevicePool{}, func(c crd.CRD) crd.CRD {
+			c.NonNamespace = true
+			c.Status = false
+			return c.
+				WithColumn("Resource Name", ".spec.resourceName")
+		}),
+		newCRD(&devices.PCIDeviceClaimSet{}, func(c crd.CRD) crd.CRD {
+			c.NonNamespace = true
+			return c.
+				WithColumn("Count", ".spec.count").
+				WithColumn("User Name", ".spec.userName")
+		}),
+		newCRD(&devices.AutoClaimPolicy{}, func(c crd.CRD) crd.CRD {
+			c.NonNamespace = true
+			return c.
+				WithColumn("User Name", ".spec.userName")
+		}),
+		newCRD(&devices.DeviceAccessPolicy{}, func(c crd.CRD) crd.CRD {
+			c.NonNamespace = true
+			c.Status = false
+			return c.
+				WithColumn("Namespaces", ".spec.namespaces")
+		}),
+		newCRD(&devices.DeviceQuota{}, func(c crd.CRD) crd.CRD {
+			return c.
+				WithColumn("Hard", ".spec.hard").
+				WithColumn("Used", ".status.used")
+		}),
 	}
 }
 
@@ -71,6 +71,7 @@ type USBDevice struct {
 	DevicePath   string
 	PCIAddress   string
 	ClassType    string
+	ClassCode    string
 	ProductName  string
 }
 
@@ -110,10 +111,10 @@ func classTypeName(code string) string {
 	return fmt.Sprintf("Unknown (0x%s)", code)
 }
 
-// parseUSBClassType determines the USB class name for a device rooted at path.
+// parseUSBClassCode determines the USB class code for a device rooted at path.
 // When bDeviceClass is 00, the class is reported per-interface; in that case the
 // function reads bInterfaceClass from the first interface sub-directory.
-func parseUSBClassType(path string) string {
+func parseUSBClassCode(path string) string {
 	code, ok := parseClassCode(path, "bDeviceClass")
 	if !ok {
 		logrus.Debugf("Unable to read or parse bDeviceClass from %s", path)
@@ -121,7 +122,7 @@ func parseUSBClassType(path string) string {
 	}
 
 	if code != "00" {
-		return classTypeName(code)
+		return code
 	}
 
 	// class 00 means "use Interface Descriptors" – look at the first interface sub-directory
@@ -136,7 +137,7 @@ func parseUSBClassType(path string) string {
 		}
 
 		if iCode, ok := parseClassCode(filepath.Join(path, entry.Name()), "bInterfaceClass"); ok {
-			return classTypeName(iCode)
+			return iCode
 		}
 	}
 
@@ -177,7 +178,10 @@ func parseSysUeventFile(path string) *USBDevice {
 	}
 
 	u.ProductName = readSysfsString(path, "product")
-	u.ClassType = parseUSBClassType(path)
+	u.ClassCode = parseUSBClassCode(path)
+	if u.ClassCode != "" {
+		u.ClassType = classTypeName(u.ClassCode)
+	}
 
 	return &u
 }
@@ -28,10 +28,18 @@ import (
 
 type DevicesV1beta1Interface interface {
 	RESTClient() rest.Interface
+	AutoClaimPoliciesGetter
+	DeviceAccessPoliciesGetter
+	DeviceDiscoveryPoliciesGetter
+	DevicePoolsGetter
+	DeviceQuotasGetter
+	IOMMUGroupsGetter
 	MigConfigurationsGetter
 	NodesGetter
 	PCIDevicesGetter
 	PCIDeviceClaimsGetter
+	PCIDeviceClaimSetsGetter
+	ResourceNameRulesGetter
 	SRIOVGPUDevicesGetter
 	SRIOVNetworkDevicesGetter
 	USBDevicesGetter
@@ -44,6 +52,30 @@ type DevicesV1beta1Client struct {
 	restClient rest.Interface
 }
 
+func (c *DevicesV1beta1Client) AutoClaimPolicies() AutoClaimPolicyInterface {
+	return newAutoClaimPolicies(c)
+}
+
+func (c *DevicesV1beta1Client) DeviceAccessPolicies() DeviceAccessPolicyInterface {
+	return newDeviceAccessPolicies(c)
+}
+
+func (c *DevicesV1beta1Client) DeviceDiscoveryPolicies() DeviceDiscoveryPolicyInterface {
+	return newDeviceDiscoveryPolicies(c)
+}
+
+func (c *DevicesV1beta1Client) DevicePools() DevicePoolInterface {
+	return newDevicePools(c)
+}
+
+func (c *DevicesV1beta1Client) DeviceQuotas(namespace string) DeviceQuotaInterface {
+	return newDeviceQuotas(c, namespace)
+}
+
+func (c *DevicesV1beta1Client) IOMMUGroups() IOMMUGroupInterface {
+	return newIOMMUGroups(c)
+}
+
 func (c *DevicesV1beta1Client) MigConfigurations() MigConfigurationInterface {
 	return newMigConfigurations(c)
 }
@@ -60,6 +92,14 @@ func (c *DevicesV1beta1Client) PCIDeviceClaims() PCIDeviceClaimInterface {
 	return newPCIDeviceClaims(c)
 }
 
+func (c *DevicesV1beta1Client) PCIDeviceClaimSets() PCIDeviceClaimSetInterface {
+	return newPCIDeviceClaimSets(c)
+}
+
+func (c *DevicesV1beta1Client) ResourceNameRules() ResourceNameRuleInterface {
+	return newResourceNameRules(c)
+}
+
 func (c *DevicesV1beta1Client) SRIOVGPUDevices() SRIOVGPUDeviceInterface {
 	return newSRIOVGPUDevices(c)
 }
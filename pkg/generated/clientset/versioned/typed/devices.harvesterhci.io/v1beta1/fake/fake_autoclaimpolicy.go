@@ -0,0 +1,138 @@
+/*
+Copyright 2022 Rancher Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeAutoClaimPolicies implements AutoClaimPolicyInterface
+type FakeAutoClaimPolicies struct {
+	Fake *FakeDevicesV1beta1
+}
+
+var autoclaimpoliciesResource = v1beta1.SchemeGroupVersion.WithResource("autoclaimpolicies")
+
+var autoclaimpoliciesKind = v1beta1.SchemeGroupVersion.WithKind("AutoClaimPolicy")
+
+// Get takes name of the autoClaimPolicy, and returns the corresponding autoClaimPolicy object, and an error if there is any.
+func (c *FakeAutoClaimPolicies) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.AutoClaimPolicy, err error) {
+	emptyResult := &v1beta1.AutoClaimPolicy{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetActionWithOptions(autoclaimpoliciesResource, name, options), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.AutoClaimPolicy), err
+}
+
+// List takes label and field selectors, and returns the list of AutoClaimPolicies that match those selectors.
+func (c *FakeAutoClaimPolicies) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.AutoClaimPolicyList, err error) {
+	emptyResult := &v1beta1.AutoClaimPolicyList{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListActionWithOptions(autoclaimpoliciesResource, autoclaimpoliciesKind, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.AutoClaimPolicyList{ListMeta: obj.(*v1beta1.AutoClaimPolicyList).ListMeta}
+	for _, item := range obj.(*v1beta1.AutoClaimPolicyList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested autoClaimPolicies.
+func (c *FakeAutoClaimPolicies) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchActionWithOptions(autoclaimpoliciesResource, opts))
+}
+
+// Create takes the representation of a autoClaimPolicy and creates it.  Returns the server's representation of the autoClaimPolicy, and an error, if there is any.
+func (c *FakeAutoClaimPolicies) Create(ctx context.Context, autoClaimPolicy *v1beta1.AutoClaimPolicy, opts v1.CreateOptions) (result *v1beta1.AutoClaimPolicy, err error) {
+	emptyResult := &v1beta1.AutoClaimPolicy{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateActionWithOptions(autoclaimpoliciesResource, autoClaimPolicy, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.AutoClaimPolicy), err
+}
+
+// Update takes the representation of a autoClaimPolicy and updates it. Returns the server's representation of the autoClaimPolicy, and an error, if there is any.
+func (c *FakeAutoClaimPolicies) Update(ctx context.Context, autoClaimPolicy *v1beta1.AutoClaimPolicy, opts v1.UpdateOptions) (result *v1beta1.AutoClaimPolicy, err error) {
+	emptyResult := &v1beta1.AutoClaimPolicy{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateActionWithOptions(autoclaimpoliciesResource, autoClaimPolicy, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.AutoClaimPolicy), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeAutoClaimPolicies) UpdateStatus(ctx context.Context, autoClaimPolicy *v1beta1.AutoClaimPolicy, opts v1.UpdateOptions) (result *v1beta1.AutoClaimPolicy, err error) {
+	emptyResult := &v1beta1.AutoClaimPolicy{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateSubresourceActionWithOptions(autoclaimpoliciesResource, "status", autoClaimPolicy, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.AutoClaimPolicy), err
+}
+
+// Delete takes name of the autoClaimPolicy and deletes it. Returns an error if one occurs.
+func (c *FakeAutoClaimPolicies) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteActionWithOptions(autoclaimpoliciesResource, name, opts), &v1beta1.AutoClaimPolicy{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeAutoClaimPolicies) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionActionWithOptions(autoclaimpoliciesResource, opts, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.AutoClaimPolicyList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched autoClaimPolicy.
+func (c *FakeAutoClaimPolicies) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.AutoClaimPolicy, err error) {
+	emptyResult := &v1beta1.AutoClaimPolicy{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceActionWithOptions(autoclaimpoliciesResource, name, pt, data, opts, subresources...), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.AutoClaimPolicy), err
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2022 Rancher Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeDeviceAccessPolicies implements DeviceAccessPolicyInterface
+type FakeDeviceAccessPolicies struct {
+	Fake *FakeDevicesV1beta1
+}
+
+var deviceaccesspoliciesResource = v1beta1.SchemeGroupVersion.WithResource("deviceaccesspolicies")
+
+var deviceaccesspoliciesKind = v1beta1.SchemeGroupVersion.WithKind("DeviceAccessPolicy")
+
+// Get takes name of the deviceAccessPolicy, and returns the corresponding deviceAccessPolicy object, and an error if there is any.
+func (c *FakeDeviceAccessPolicies) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.DeviceAccessPolicy, err error) {
+	emptyResult := &v1beta1.DeviceAccessPolicy{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetActionWithOptions(deviceaccesspoliciesResource, name, options), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.DeviceAccessPolicy), err
+}
+
+// List takes label and field selectors, and returns the list of DeviceAccessPolicies that match those selectors.
+func (c *FakeDeviceAccessPolicies) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.DeviceAccessPolicyList, err error) {
+	emptyResult := &v1beta1.DeviceAccessPolicyList{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListActionWithOptions(deviceaccesspoliciesResource, deviceaccesspoliciesKind, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.DeviceAccessPolicyList{ListMeta: obj.(*v1beta1.DeviceAccessPolicyList).ListMeta}
+	for _, item := range obj.(*v1beta1.DeviceAccessPolicyList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested deviceAccessPolicies.
+func (c *FakeDeviceAccessPolicies) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchActionWithOptions(deviceaccesspoliciesResource, opts))
+}
+
+// Create takes the representation of a deviceAccessPolicy and creates it.  Returns the server's representation of the deviceAccessPolicy, and an error, if there is any.
+func (c *FakeDeviceAccessPolicies) Create(ctx context.Context, deviceAccessPolicy *v1beta1.DeviceAccessPolicy, opts v1.CreateOptions) (result *v1beta1.DeviceAccessPolicy, err error) {
+	emptyResult := &v1beta1.DeviceAccessPolicy{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateActionWithOptions(deviceaccesspoliciesResource, deviceAccessPolicy, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.DeviceAccessPolicy), err
+}
+
+// Update takes the representation of a deviceAccessPolicy and updates it. Returns the server's representation of the deviceAccessPolicy, and an error, if there is any.
+func (c *FakeDeviceAccessPolicies) Update(ctx context.Context, deviceAccessPolicy *v1beta1.DeviceAccessPolicy, opts v1.UpdateOptions) (result *v1beta1.DeviceAccessPolicy, err error) {
+	emptyResult := &v1beta1.DeviceAccessPolicy{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateActionWithOptions(deviceaccesspoliciesResource, deviceAccessPolicy, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.DeviceAccessPolicy), err
+}
+
+// Delete takes name of the deviceAccessPolicy and deletes it. Returns an error if one occurs.
+func (c *FakeDeviceAccessPolicies) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteActionWithOptions(deviceaccesspoliciesResource, name, opts), &v1beta1.DeviceAccessPolicy{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeDeviceAccessPolicies) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionActionWithOptions(deviceaccesspoliciesResource, opts, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.DeviceAccessPolicyList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched deviceAccessPolicy.
+func (c *FakeDeviceAccessPolicies) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.DeviceAccessPolicy, err error) {
+	emptyResult := &v1beta1.DeviceAccessPolicy{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceActionWithOptions(deviceaccesspoliciesResource, name, pt, data, opts, subresources...), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.DeviceAccessPolicy), err
+}
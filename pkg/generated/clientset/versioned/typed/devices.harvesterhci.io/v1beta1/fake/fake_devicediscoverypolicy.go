@@ -0,0 +1,126 @@
+/*
+Copyright 2022 Rancher Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeDeviceDiscoveryPolicies implements DeviceDiscoveryPolicyInterface
+type FakeDeviceDiscoveryPolicies struct {
+	Fake *FakeDevicesV1beta1
+}
+
+var devicediscoverypoliciesResource = v1beta1.SchemeGroupVersion.WithResource("devicediscoverypolicies")
+
+var devicediscoverypoliciesKind = v1beta1.SchemeGroupVersion.WithKind("DeviceDiscoveryPolicy")
+
+// Get takes name of the deviceDiscoveryPolicy, and returns the corresponding deviceDiscoveryPolicy object, and an error if there is any.
+func (c *FakeDeviceDiscoveryPolicies) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.DeviceDiscoveryPolicy, err error) {
+	emptyResult := &v1beta1.DeviceDiscoveryPolicy{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetActionWithOptions(devicediscoverypoliciesResource, name, options), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.DeviceDiscoveryPolicy), err
+}
+
+// List takes label and field selectors, and returns the list of DeviceDiscoveryPolicies that match those selectors.
+func (c *FakeDeviceDiscoveryPolicies) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.DeviceDiscoveryPolicyList, err error) {
+	emptyResult := &v1beta1.DeviceDiscoveryPolicyList{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListActionWithOptions(devicediscoverypoliciesResource, devicediscoverypoliciesKind, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.DeviceDiscoveryPolicyList{ListMeta: obj.(*v1beta1.DeviceDiscoveryPolicyList).ListMeta}
+	for _, item := range obj.(*v1beta1.DeviceDiscoveryPolicyList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested deviceDiscoveryPolicies.
+func (c *FakeDeviceDiscoveryPolicies) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchActionWithOptions(devicediscoverypoliciesResource, opts))
+}
+
+// Create takes the representation of a deviceDiscoveryPolicy and creates it.  Returns the server's representation of the deviceDiscoveryPolicy, and an error, if there is any.
+func (c *FakeDeviceDiscoveryPolicies) Create(ctx context.Context, deviceDiscoveryPolicy *v1beta1.DeviceDiscoveryPolicy, opts v1.CreateOptions) (result *v1beta1.DeviceDiscoveryPolicy, err error) {
+	emptyResult := &v1beta1.DeviceDiscoveryPolicy{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateActionWithOptions(devicediscoverypoliciesResource, deviceDiscoveryPolicy, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.DeviceDiscoveryPolicy), err
+}
+
+// Update takes the representation of a deviceDiscoveryPolicy and updates it. Returns the server's representation of the deviceDiscoveryPolicy, and an error, if there is any.
+func (c *FakeDeviceDiscoveryPolicies) Update(ctx context.Context, deviceDiscoveryPolicy *v1beta1.DeviceDiscoveryPolicy, opts v1.UpdateOptions) (result *v1beta1.DeviceDiscoveryPolicy, err error) {
+	emptyResult := &v1beta1.DeviceDiscoveryPolicy{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateActionWithOptions(devicediscoverypoliciesResource, deviceDiscoveryPolicy, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.DeviceDiscoveryPolicy), err
+}
+
+// Delete takes name of the deviceDiscoveryPolicy and deletes it. Returns an error if one occurs.
+func (c *FakeDeviceDiscoveryPolicies) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteActionWithOptions(devicediscoverypoliciesResource, name, opts), &v1beta1.DeviceDiscoveryPolicy{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeDeviceDiscoveryPolicies) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionActionWithOptions(devicediscoverypoliciesResource, opts, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.DeviceDiscoveryPolicyList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched deviceDiscoveryPolicy.
+func (c *FakeDeviceDiscoveryPolicies) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.DeviceDiscoveryPolicy, err error) {
+	emptyResult := &v1beta1.DeviceDiscoveryPolicy{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceActionWithOptions(devicediscoverypoliciesResource, name, pt, data, opts, subresources...), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.DeviceDiscoveryPolicy), err
+}
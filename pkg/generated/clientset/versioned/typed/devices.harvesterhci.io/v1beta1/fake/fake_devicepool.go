@@ -0,0 +1,126 @@
+/*
+Copyright 2022 Rancher Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeDevicePools implements DevicePoolInterface
+type FakeDevicePools struct {
+	Fake *FakeDevicesV1beta1
+}
+
+var devicepoolsResource = v1beta1.SchemeGroupVersion.WithResource("devicepools")
+
+var devicepoolsKind = v1beta1.SchemeGroupVersion.WithKind("DevicePool")
+
+// Get takes name of the devicePool, and returns the corresponding devicePool object, and an error if there is any.
+func (c *FakeDevicePools) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.DevicePool, err error) {
+	emptyResult := &v1beta1.DevicePool{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetActionWithOptions(devicepoolsResource, name, options), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.DevicePool), err
+}
+
+// List takes label and field selectors, and returns the list of DevicePools that match those selectors.
+func (c *FakeDevicePools) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.DevicePoolList, err error) {
+	emptyResult := &v1beta1.DevicePoolList{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListActionWithOptions(devicepoolsResource, devicepoolsKind, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.DevicePoolList{ListMeta: obj.(*v1beta1.DevicePoolList).ListMeta}
+	for _, item := range obj.(*v1beta1.DevicePoolList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested devicePools.
+func (c *FakeDevicePools) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchActionWithOptions(devicepoolsResource, opts))
+}
+
+// Create takes the representation of a devicePool and creates it.  Returns the server's representation of the devicePool, and an error, if there is any.
+func (c *FakeDevicePools) Create(ctx context.Context, devicePool *v1beta1.DevicePool, opts v1.CreateOptions) (result *v1beta1.DevicePool, err error) {
+	emptyResult := &v1beta1.DevicePool{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateActionWithOptions(devicepoolsResource, devicePool, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.DevicePool), err
+}
+
+// Update takes the representation of a devicePool and updates it. Returns the server's representation of the devicePool, and an error, if there is any.
+func (c *FakeDevicePools) Update(ctx context.Context, devicePool *v1beta1.DevicePool, opts v1.UpdateOptions) (result *v1beta1.DevicePool, err error) {
+	emptyResult := &v1beta1.DevicePool{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateActionWithOptions(devicepoolsResource, devicePool, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.DevicePool), err
+}
+
+// Delete takes name of the devicePool and deletes it. Returns an error if one occurs.
+func (c *FakeDevicePools) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteActionWithOptions(devicepoolsResource, name, opts), &v1beta1.DevicePool{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeDevicePools) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionActionWithOptions(devicepoolsResource, opts, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.DevicePoolList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched devicePool.
+func (c *FakeDevicePools) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.DevicePool, err error) {
+	emptyResult := &v1beta1.DevicePool{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceActionWithOptions(devicepoolsResource, name, pt, data, opts, subresources...), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.DevicePool), err
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2022 Rancher Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeDeviceQuotas implements DeviceQuotaInterface
+type FakeDeviceQuotas struct {
+	Fake *FakeDevicesV1beta1
+	ns   string
+}
+
+var devicequotasResource = v1beta1.SchemeGroupVersion.WithResource("devicequotas")
+
+var devicequotasKind = v1beta1.SchemeGroupVersion.WithKind("DeviceQuota")
+
+// Get takes name of the deviceQuota, and returns the corresponding deviceQuota object, and an error if there is any.
+func (c *FakeDeviceQuotas) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.DeviceQuota, err error) {
+	emptyResult := &v1beta1.DeviceQuota{}
+	obj, err := c.Fake.
+		Invokes(testing.NewGetActionWithOptions(devicequotasResource, c.ns, name, options), emptyResult)
+
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.DeviceQuota), err
+}
+
+// List takes label and field selectors, and returns the list of DeviceQuotas that match those selectors.
+func (c *FakeDeviceQuotas) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.DeviceQuotaList, err error) {
+	emptyResult := &v1beta1.DeviceQuotaList{}
+	obj, err := c.Fake.
+		Invokes(testing.NewListActionWithOptions(devicequotasResource, devicequotasKind, c.ns, opts), emptyResult)
+
+	if obj == nil {
+		return emptyResult, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.DeviceQuotaList{ListMeta: obj.(*v1beta1.DeviceQuotaList).ListMeta}
+	for _, item := range obj.(*v1beta1.DeviceQuotaList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested deviceQuotas.
+func (c *FakeDeviceQuotas) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchActionWithOptions(devicequotasResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a deviceQuota and creates it.  Returns the server's representation of the deviceQuota, and an error, if there is any.
+func (c *FakeDeviceQuotas) Create(ctx context.Context, deviceQuota *v1beta1.DeviceQuota, opts v1.CreateOptions) (result *v1beta1.DeviceQuota, err error) {
+	emptyResult := &v1beta1.DeviceQuota{}
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateActionWithOptions(devicequotasResource, c.ns, deviceQuota, opts), emptyResult)
+
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.DeviceQuota), err
+}
+
+// Update takes the representation of a deviceQuota and updates it. Returns the server's representation of the deviceQuota, and an error, if there is any.
+func (c *FakeDeviceQuotas) Update(ctx context.Context, deviceQuota *v1beta1.DeviceQuota, opts v1.UpdateOptions) (result *v1beta1.DeviceQuota, err error) {
+	emptyResult := &v1beta1.DeviceQuota{}
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateActionWithOptions(devicequotasResource, c.ns, deviceQuota, opts), emptyResult)
+
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.DeviceQuota), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeDeviceQuotas) UpdateStatus(ctx context.Context, deviceQuota *v1beta1.DeviceQuota, opts v1.UpdateOptions) (result *v1beta1.DeviceQuota, err error) {
+	emptyResult := &v1beta1.DeviceQuota{}
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceActionWithOptions(devicequotasResource, "status", c.ns, deviceQuota, opts), emptyResult)
+
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.DeviceQuota), err
+}
+
+// Delete takes name of the deviceQuota and deletes it. Returns an error if one occurs.
+func (c *FakeDeviceQuotas) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteActionWithOptions(devicequotasResource, c.ns, name, opts), &v1beta1.DeviceQuota{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeDeviceQuotas) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionActionWithOptions(devicequotasResource, c.ns, opts, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.DeviceQuotaList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched deviceQuota.
+func (c *FakeDeviceQuotas) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.DeviceQuota, err error) {
+	emptyResult := &v1beta1.DeviceQuota{}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceActionWithOptions(devicequotasResource, c.ns, name, pt, data, opts, subresources...), emptyResult)
+
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.DeviceQuota), err
+}
@@ -28,6 +28,30 @@ type FakeDevicesV1beta1 struct {
 	*testing.Fake
 }
 
+func (c *FakeDevicesV1beta1) AutoClaimPolicies() v1beta1.AutoClaimPolicyInterface {
+	return &FakeAutoClaimPolicies{c}
+}
+
+func (c *FakeDevicesV1beta1) DeviceAccessPolicies() v1beta1.DeviceAccessPolicyInterface {
+	return &FakeDeviceAccessPolicies{c}
+}
+
+func (c *FakeDevicesV1beta1) DeviceDiscoveryPolicies() v1beta1.DeviceDiscoveryPolicyInterface {
+	return &FakeDeviceDiscoveryPolicies{c}
+}
+
+func (c *FakeDevicesV1beta1) DevicePools() v1beta1.DevicePoolInterface {
+	return &FakeDevicePools{c}
+}
+
+func (c *FakeDevicesV1beta1) DeviceQuotas(namespace string) v1beta1.DeviceQuotaInterface {
+	return &FakeDeviceQuotas{c, namespace}
+}
+
+func (c *FakeDevicesV1beta1) IOMMUGroups() v1beta1.IOMMUGroupInterface {
+	return &FakeIOMMUGroups{c}
+}
+
 func (c *FakeDevicesV1beta1) MigConfigurations() v1beta1.MigConfigurationInterface {
 	return &FakeMigConfigurations{c}
 }
@@ -44,6 +68,14 @@ func (c *FakeDevicesV1beta1) PCIDeviceClaims() v1beta1.PCIDeviceClaimInterface {
 	return &FakePCIDeviceClaims{c}
 }
 
+func (c *FakeDevicesV1beta1) PCIDeviceClaimSets() v1beta1.PCIDeviceClaimSetInterface {
+	return &FakePCIDeviceClaimSets{c}
+}
+
+func (c *FakeDevicesV1beta1) ResourceNameRules() v1beta1.ResourceNameRuleInterface {
+	return &FakeResourceNameRules{c}
+}
+
 func (c *FakeDevicesV1beta1) SRIOVGPUDevices() v1beta1.SRIOVGPUDeviceInterface {
 	return &FakeSRIOVGPUDevices{c}
 }
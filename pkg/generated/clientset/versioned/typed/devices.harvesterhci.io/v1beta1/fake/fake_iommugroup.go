@@ -0,0 +1,126 @@
+/*
+Copyright 2022 Rancher Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeIOMMUGroups implements IOMMUGroupInterface
+type FakeIOMMUGroups struct {
+	Fake *FakeDevicesV1beta1
+}
+
+var iommugroupsResource = v1beta1.SchemeGroupVersion.WithResource("iommugroups")
+
+var iommugroupsKind = v1beta1.SchemeGroupVersion.WithKind("IOMMUGroup")
+
+// Get takes name of the iOMMUGroup, and returns the corresponding iOMMUGroup object, and an error if there is any.
+func (c *FakeIOMMUGroups) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.IOMMUGroup, err error) {
+	emptyResult := &v1beta1.IOMMUGroup{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetActionWithOptions(iommugroupsResource, name, options), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.IOMMUGroup), err
+}
+
+// List takes label and field selectors, and returns the list of IOMMUGroups that match those selectors.
+func (c *FakeIOMMUGroups) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.IOMMUGroupList, err error) {
+	emptyResult := &v1beta1.IOMMUGroupList{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListActionWithOptions(iommugroupsResource, iommugroupsKind, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.IOMMUGroupList{ListMeta: obj.(*v1beta1.IOMMUGroupList).ListMeta}
+	for _, item := range obj.(*v1beta1.IOMMUGroupList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested iOMMUGroups.
+func (c *FakeIOMMUGroups) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchActionWithOptions(iommugroupsResource, opts))
+}
+
+// Create takes the representation of a iOMMUGroup and creates it.  Returns the server's representation of the iOMMUGroup, and an error, if there is any.
+func (c *FakeIOMMUGroups) Create(ctx context.Context, iOMMUGroup *v1beta1.IOMMUGroup, opts v1.CreateOptions) (result *v1beta1.IOMMUGroup, err error) {
+	emptyResult := &v1beta1.IOMMUGroup{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateActionWithOptions(iommugroupsResource, iOMMUGroup, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.IOMMUGroup), err
+}
+
+// Update takes the representation of a iOMMUGroup and updates it. Returns the server's representation of the iOMMUGroup, and an error, if there is any.
+func (c *FakeIOMMUGroups) Update(ctx context.Context, iOMMUGroup *v1beta1.IOMMUGroup, opts v1.UpdateOptions) (result *v1beta1.IOMMUGroup, err error) {
+	emptyResult := &v1beta1.IOMMUGroup{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateActionWithOptions(iommugroupsResource, iOMMUGroup, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.IOMMUGroup), err
+}
+
+// Delete takes name of the iOMMUGroup and deletes it. Returns an error if one occurs.
+func (c *FakeIOMMUGroups) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteActionWithOptions(iommugroupsResource, name, opts), &v1beta1.IOMMUGroup{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeIOMMUGroups) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionActionWithOptions(iommugroupsResource, opts, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.IOMMUGroupList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched iOMMUGroup.
+func (c *FakeIOMMUGroups) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.IOMMUGroup, err error) {
+	emptyResult := &v1beta1.IOMMUGroup{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceActionWithOptions(iommugroupsResource, name, pt, data, opts, subresources...), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.IOMMUGroup), err
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2022 Rancher Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakePCIDeviceClaimSets implements PCIDeviceClaimSetInterface
+type FakePCIDeviceClaimSets struct {
+	Fake *FakeDevicesV1beta1
+}
+
+var pcideviceclaimsetsResource = v1beta1.SchemeGroupVersion.WithResource("pcideviceclaimsets")
+
+var pcideviceclaimsetsKind = v1beta1.SchemeGroupVersion.WithKind("PCIDeviceClaimSet")
+
+// Get takes name of the pCIDeviceClaimSet, and returns the corresponding pCIDeviceClaimSet object, and an error if there is any.
+func (c *FakePCIDeviceClaimSets) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.PCIDeviceClaimSet, err error) {
+	emptyResult := &v1beta1.PCIDeviceClaimSet{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetActionWithOptions(pcideviceclaimsetsResource, name, options), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.PCIDeviceClaimSet), err
+}
+
+// List takes label and field selectors, and returns the list of PCIDeviceClaimSets that match those selectors.
+func (c *FakePCIDeviceClaimSets) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.PCIDeviceClaimSetList, err error) {
+	emptyResult := &v1beta1.PCIDeviceClaimSetList{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListActionWithOptions(pcideviceclaimsetsResource, pcideviceclaimsetsKind, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.PCIDeviceClaimSetList{ListMeta: obj.(*v1beta1.PCIDeviceClaimSetList).ListMeta}
+	for _, item := range obj.(*v1beta1.PCIDeviceClaimSetList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested pCIDeviceClaimSets.
+func (c *FakePCIDeviceClaimSets) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchActionWithOptions(pcideviceclaimsetsResource, opts))
+}
+
+// Create takes the representation of a pCIDeviceClaimSet and creates it.  Returns the server's representation of the pCIDeviceClaimSet, and an error, if there is any.
+func (c *FakePCIDeviceClaimSets) Create(ctx context.Context, pCIDeviceClaimSet *v1beta1.PCIDeviceClaimSet, opts v1.CreateOptions) (result *v1beta1.PCIDeviceClaimSet, err error) {
+	emptyResult := &v1beta1.PCIDeviceClaimSet{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateActionWithOptions(pcideviceclaimsetsResource, pCIDeviceClaimSet, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.PCIDeviceClaimSet), err
+}
+
+// Update takes the representation of a pCIDeviceClaimSet and updates it. Returns the server's representation of the pCIDeviceClaimSet, and an error, if there is any.
+func (c *FakePCIDeviceClaimSets) Update(ctx context.Context, pCIDeviceClaimSet *v1beta1.PCIDeviceClaimSet, opts v1.UpdateOptions) (result *v1beta1.PCIDeviceClaimSet, err error) {
+	emptyResult := &v1beta1.PCIDeviceClaimSet{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateActionWithOptions(pcideviceclaimsetsResource, pCIDeviceClaimSet, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.PCIDeviceClaimSet), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakePCIDeviceClaimSets) UpdateStatus(ctx context.Context, pCIDeviceClaimSet *v1beta1.PCIDeviceClaimSet, opts v1.UpdateOptions) (result *v1beta1.PCIDeviceClaimSet, err error) {
+	emptyResult := &v1beta1.PCIDeviceClaimSet{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateSubresourceActionWithOptions(pcideviceclaimsetsResource, "status", pCIDeviceClaimSet, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.PCIDeviceClaimSet), err
+}
+
+// Delete takes name of the pCIDeviceClaimSet and deletes it. Returns an error if one occurs.
+func (c *FakePCIDeviceClaimSets) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteActionWithOptions(pcideviceclaimsetsResource, name, opts), &v1beta1.PCIDeviceClaimSet{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakePCIDeviceClaimSets) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionActionWithOptions(pcideviceclaimsetsResource, opts, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.PCIDeviceClaimSetList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched pCIDeviceClaimSet.
+func (c *FakePCIDeviceClaimSets) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.PCIDeviceClaimSet, err error) {
+	emptyResult := &v1beta1.PCIDeviceClaimSet{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceActionWithOptions(pcideviceclaimsetsResource, name, pt, data, opts, subresources...), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.PCIDeviceClaimSet), err
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2022 Rancher Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeResourceNameRules implements ResourceNameRuleInterface
+type FakeResourceNameRules struct {
+	Fake *FakeDevicesV1beta1
+}
+
+var resourcenamerulesResource = v1beta1.SchemeGroupVersion.WithResource("resourcenamerules")
+
+var resourcenamerulesKind = v1beta1.SchemeGroupVersion.WithKind("ResourceNameRule")
+
+// Get takes name of the resourceNameRule, and returns the corresponding resourceNameRule object, and an error if there is any.
+func (c *FakeResourceNameRules) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.ResourceNameRule, err error) {
+	emptyResult := &v1beta1.ResourceNameRule{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetActionWithOptions(resourcenamerulesResource, name, options), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.ResourceNameRule), err
+}
+
+// List takes label and field selectors, and returns the list of ResourceNameRules that match those selectors.
+func (c *FakeResourceNameRules) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.ResourceNameRuleList, err error) {
+	emptyResult := &v1beta1.ResourceNameRuleList{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListActionWithOptions(resourcenamerulesResource, resourcenamerulesKind, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.ResourceNameRuleList{ListMeta: obj.(*v1beta1.ResourceNameRuleList).ListMeta}
+	for _, item := range obj.(*v1beta1.ResourceNameRuleList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested resourceNameRules.
+func (c *FakeResourceNameRules) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchActionWithOptions(resourcenamerulesResource, opts))
+}
+
+// Create takes the representation of a resourceNameRule and creates it.  Returns the server's representation of the resourceNameRule, and an error, if there is any.
+func (c *FakeResourceNameRules) Create(ctx context.Context, resourceNameRule *v1beta1.ResourceNameRule, opts v1.CreateOptions) (result *v1beta1.ResourceNameRule, err error) {
+	emptyResult := &v1beta1.ResourceNameRule{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateActionWithOptions(resourcenamerulesResource, resourceNameRule, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.ResourceNameRule), err
+}
+
+// Update takes the representation of a resourceNameRule and updates it. Returns the server's representation of the resourceNameRule, and an error, if there is any.
+func (c *FakeResourceNameRules) Update(ctx context.Context, resourceNameRule *v1beta1.ResourceNameRule, opts v1.UpdateOptions) (result *v1beta1.ResourceNameRule, err error) {
+	emptyResult := &v1beta1.ResourceNameRule{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateActionWithOptions(resourcenamerulesResource, resourceNameRule, opts), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.ResourceNameRule), err
+}
+
+// Delete takes name of the resourceNameRule and deletes it. Returns an error if one occurs.
+func (c *FakeResourceNameRules) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteActionWithOptions(resourcenamerulesResource, name, opts), &v1beta1.ResourceNameRule{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeResourceNameRules) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionActionWithOptions(resourcenamerulesResource, opts, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.ResourceNameRuleList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched resourceNameRule.
+func (c *FakeResourceNameRules) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.ResourceNameRule, err error) {
+	emptyResult := &v1beta1.ResourceNameRule{}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceActionWithOptions(resourcenamerulesResource, name, pt, data, opts, subresources...), emptyResult)
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.ResourceNameRule), err
+}
@@ -18,6 +18,18 @@ limitations under the License.
 
 package v1beta1
 
+type AutoClaimPolicyExpansion interface{}
+
+type DeviceAccessPolicyExpansion interface{}
+
+type DeviceDiscoveryPolicyExpansion interface{}
+
+type DevicePoolExpansion interface{}
+
+type DeviceQuotaExpansion interface{}
+
+type IOMMUGroupExpansion interface{}
+
 type MigConfigurationExpansion interface{}
 
 type NodeExpansion interface{}
@@ -26,6 +38,10 @@ type PCIDeviceExpansion interface{}
 
 type PCIDeviceClaimExpansion interface{}
 
+type PCIDeviceClaimSetExpansion interface{}
+
+type ResourceNameRuleExpansion interface{}
+
 type SRIOVGPUDeviceExpansion interface{}
 
 type SRIOVNetworkDeviceExpansion interface{}
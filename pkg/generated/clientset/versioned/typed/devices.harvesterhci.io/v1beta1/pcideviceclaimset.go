@@ -0,0 +1,69 @@
+/*
+Copyright 2022 Rancher Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"context"
+
+	v1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	scheme "github.com/harvester/pcidevices/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// PCIDeviceClaimSetsGetter has a method to return a PCIDeviceClaimSetInterface.
+// A group's client should implement this interface.
+type PCIDeviceClaimSetsGetter interface {
+	PCIDeviceClaimSets() PCIDeviceClaimSetInterface
+}
+
+// PCIDeviceClaimSetInterface has methods to work with PCIDeviceClaimSet resources.
+type PCIDeviceClaimSetInterface interface {
+	Create(ctx context.Context, pCIDeviceClaimSet *v1beta1.PCIDeviceClaimSet, opts v1.CreateOptions) (*v1beta1.PCIDeviceClaimSet, error)
+	Update(ctx context.Context, pCIDeviceClaimSet *v1beta1.PCIDeviceClaimSet, opts v1.UpdateOptions) (*v1beta1.PCIDeviceClaimSet, error)
+	// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+	UpdateStatus(ctx context.Context, pCIDeviceClaimSet *v1beta1.PCIDeviceClaimSet, opts v1.UpdateOptions) (*v1beta1.PCIDeviceClaimSet, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.PCIDeviceClaimSet, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.PCIDeviceClaimSetList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.PCIDeviceClaimSet, err error)
+	PCIDeviceClaimSetExpansion
+}
+
+// pCIDeviceClaimSets implements PCIDeviceClaimSetInterface
+type pCIDeviceClaimSets struct {
+	*gentype.ClientWithList[*v1beta1.PCIDeviceClaimSet, *v1beta1.PCIDeviceClaimSetList]
+}
+
+// newPCIDeviceClaimSets returns a PCIDeviceClaimSets
+func newPCIDeviceClaimSets(c *DevicesV1beta1Client) *pCIDeviceClaimSets {
+	return &pCIDeviceClaimSets{
+		gentype.NewClientWithList[*v1beta1.PCIDeviceClaimSet, *v1beta1.PCIDeviceClaimSetList](
+			"pcideviceclaimsets",
+			c.RESTClient(),
+			scheme.ParameterCodec,
+			"",
+			func() *v1beta1.PCIDeviceClaimSet { return &v1beta1.PCIDeviceClaimSet{} },
+			func() *v1beta1.PCIDeviceClaimSetList { return &v1beta1.PCIDeviceClaimSetList{} }),
+	}
+}
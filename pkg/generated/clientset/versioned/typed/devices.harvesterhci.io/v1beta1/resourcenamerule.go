@@ -0,0 +1,67 @@
+/*
+Copyright 2022 Rancher Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"context"
+
+	v1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	scheme "github.com/harvester/pcidevices/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// ResourceNameRulesGetter has a method to return a ResourceNameRuleInterface.
+// A group's client should implement this interface.
+type ResourceNameRulesGetter interface {
+	ResourceNameRules() ResourceNameRuleInterface
+}
+
+// ResourceNameRuleInterface has methods to work with ResourceNameRule resources.
+type ResourceNameRuleInterface interface {
+	Create(ctx context.Context, resourceNameRule *v1beta1.ResourceNameRule, opts v1.CreateOptions) (*v1beta1.ResourceNameRule, error)
+	Update(ctx context.Context, resourceNameRule *v1beta1.ResourceNameRule, opts v1.UpdateOptions) (*v1beta1.ResourceNameRule, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.ResourceNameRule, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.ResourceNameRuleList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.ResourceNameRule, err error)
+	ResourceNameRuleExpansion
+}
+
+// resourceNameRules implements ResourceNameRuleInterface
+type resourceNameRules struct {
+	*gentype.ClientWithList[*v1beta1.ResourceNameRule, *v1beta1.ResourceNameRuleList]
+}
+
+// newResourceNameRules returns a ResourceNameRules
+func newResourceNameRules(c *DevicesV1beta1Client) *resourceNameRules {
+	return &resourceNameRules{
+		gentype.NewClientWithList[*v1beta1.ResourceNameRule, *v1beta1.ResourceNameRuleList](
+			"resourcenamerules",
+			c.RESTClient(),
+			scheme.ParameterCodec,
+			"",
+			func() *v1beta1.ResourceNameRule { return &v1beta1.ResourceNameRule{} },
+			func() *v1beta1.ResourceNameRuleList { return &v1beta1.ResourceNameRuleList{} }),
+	}
+}
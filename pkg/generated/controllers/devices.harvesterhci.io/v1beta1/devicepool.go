@@ -0,0 +1,39 @@
+/*
+Copyright 2022 Rancher Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/rancher/wrangler/v3/pkg/generic"
+)
+
+// DevicePoolController interface for managing DevicePool resources.
+type DevicePoolController interface {
+	generic.NonNamespacedControllerInterface[*v1beta1.DevicePool, *v1beta1.DevicePoolList]
+}
+
+// DevicePoolClient interface for managing DevicePool resources in Kubernetes.
+type DevicePoolClient interface {
+	generic.NonNamespacedClientInterface[*v1beta1.DevicePool, *v1beta1.DevicePoolList]
+}
+
+// DevicePoolCache interface for retrieving DevicePool resources in memory.
+type DevicePoolCache interface {
+	generic.NonNamespacedCacheInterface[*v1beta1.DevicePool]
+}
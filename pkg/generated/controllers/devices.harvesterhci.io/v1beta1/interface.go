@@ -31,10 +31,18 @@ func init() {
 }
 
 type Interface interface {
+	AutoClaimPolicy() AutoClaimPolicyController
+	DeviceAccessPolicy() DeviceAccessPolicyController
+	DeviceDiscoveryPolicy() DeviceDiscoveryPolicyController
+	DevicePool() DevicePoolController
+	DeviceQuota() DeviceQuotaController
+	IOMMUGroup() IOMMUGroupController
 	MigConfiguration() MigConfigurationController
 	Node() NodeController
 	PCIDevice() PCIDeviceController
 	PCIDeviceClaim() PCIDeviceClaimController
+	PCIDeviceClaimSet() PCIDeviceClaimSetController
+	ResourceNameRule() ResourceNameRuleController
 	SRIOVGPUDevice() SRIOVGPUDeviceController
 	SRIOVNetworkDevice() SRIOVNetworkDeviceController
 	USBDevice() USBDeviceController
@@ -52,6 +60,30 @@ type version struct {
 	controllerFactory controller.SharedControllerFactory
 }
 
+func (v *version) AutoClaimPolicy() AutoClaimPolicyController {
+	return generic.NewNonNamespacedController[*v1beta1.AutoClaimPolicy, *v1beta1.AutoClaimPolicyList](schema.GroupVersionKind{Group: "devices.harvesterhci.io", Version: "v1beta1", Kind: "AutoClaimPolicy"}, "autoclaimpolicies", v.controllerFactory)
+}
+
+func (v *version) DeviceAccessPolicy() DeviceAccessPolicyController {
+	return generic.NewNonNamespacedController[*v1beta1.DeviceAccessPolicy, *v1beta1.DeviceAccessPolicyList](schema.GroupVersionKind{Group: "devices.harvesterhci.io", Version: "v1beta1", Kind: "DeviceAccessPolicy"}, "deviceaccesspolicies", v.controllerFactory)
+}
+
+func (v *version) DeviceDiscoveryPolicy() DeviceDiscoveryPolicyController {
+	return generic.NewNonNamespacedController[*v1beta1.DeviceDiscoveryPolicy, *v1beta1.DeviceDiscoveryPolicyList](schema.GroupVersionKind{Group: "devices.harvesterhci.io", Version: "v1beta1", Kind: "DeviceDiscoveryPolicy"}, "devicediscoverypolicies", v.controllerFactory)
+}
+
+func (v *version) DevicePool() DevicePoolController {
+	return generic.NewNonNamespacedController[*v1beta1.DevicePool, *v1beta1.DevicePoolList](schema.GroupVersionKind{Group: "devices.harvesterhci.io", Version: "v1beta1", Kind: "DevicePool"}, "devicepools", v.controllerFactory)
+}
+
+func (v *version) DeviceQuota() DeviceQuotaController {
+	return generic.NewController[*v1beta1.DeviceQuota, *v1beta1.DeviceQuotaList](schema.GroupVersionKind{Group: "devices.harvesterhci.io", Version: "v1beta1", Kind: "DeviceQuota"}, "devicequotas", true, v.controllerFactory)
+}
+
+func (v *version) IOMMUGroup() IOMMUGroupController {
+	return generic.NewNonNamespacedController[*v1beta1.IOMMUGroup, *v1beta1.IOMMUGroupList](schema.GroupVersionKind{Group: "devices.harvesterhci.io", Version: "v1beta1", Kind: "IOMMUGroup"}, "iommugroups", v.controllerFactory)
+}
+
 func (v *version) MigConfiguration() MigConfigurationController {
 	return generic.NewNonNamespacedController[*v1beta1.MigConfiguration, *v1beta1.MigConfigurationList](schema.GroupVersionKind{Group: "devices.harvesterhci.io", Version: "v1beta1", Kind: "MigConfiguration"}, "migconfigurations", v.controllerFactory)
 }
@@ -68,6 +100,14 @@ func (v *version) PCIDeviceClaim() PCIDeviceClaimController {
 	return generic.NewNonNamespacedController[*v1beta1.PCIDeviceClaim, *v1beta1.PCIDeviceClaimList](schema.GroupVersionKind{Group: "devices.harvesterhci.io", Version: "v1beta1", Kind: "PCIDeviceClaim"}, "pcideviceclaims", v.controllerFactory)
 }
 
+func (v *version) PCIDeviceClaimSet() PCIDeviceClaimSetController {
+	return generic.NewNonNamespacedController[*v1beta1.PCIDeviceClaimSet, *v1beta1.PCIDeviceClaimSetList](schema.GroupVersionKind{Group: "devices.harvesterhci.io", Version: "v1beta1", Kind: "PCIDeviceClaimSet"}, "pcideviceclaimsets", v.controllerFactory)
+}
+
+func (v *version) ResourceNameRule() ResourceNameRuleController {
+	return generic.NewNonNamespacedController[*v1beta1.ResourceNameRule, *v1beta1.ResourceNameRuleList](schema.GroupVersionKind{Group: "devices.harvesterhci.io", Version: "v1beta1", Kind: "ResourceNameRule"}, "resourcenamerules", v.controllerFactory)
+}
+
 func (v *version) SRIOVGPUDevice() SRIOVGPUDeviceController {
 	return generic.NewNonNamespacedController[*v1beta1.SRIOVGPUDevice, *v1beta1.SRIOVGPUDeviceList](schema.GroupVersionKind{Group: "devices.harvesterhci.io", Version: "v1beta1", Kind: "SRIOVGPUDevice"}, "sriovgpudevices", v.controllerFactory)
 }
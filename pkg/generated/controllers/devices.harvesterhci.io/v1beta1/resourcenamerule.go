@@ -0,0 +1,39 @@
+/*
+Copyright 2022 Rancher Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/rancher/wrangler/v3/pkg/generic"
+)
+
+// ResourceNameRuleController interface for managing ResourceNameRule resources.
+type ResourceNameRuleController interface {
+	generic.NonNamespacedControllerInterface[*v1beta1.ResourceNameRule, *v1beta1.ResourceNameRuleList]
+}
+
+// ResourceNameRuleClient interface for managing ResourceNameRule resources in Kubernetes.
+type ResourceNameRuleClient interface {
+	generic.NonNamespacedClientInterface[*v1beta1.ResourceNameRule, *v1beta1.ResourceNameRuleList]
+}
+
+// ResourceNameRuleCache interface for retrieving ResourceNameRule resources in memory.
+type ResourceNameRuleCache interface {
+	generic.NonNamespacedCacheInterface[*v1beta1.ResourceNameRule]
+}
@@ -3,6 +3,7 @@ package iommu
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -67,3 +68,49 @@ func GetGroupMap(address string) (string, error) {
 
 	return "", fmt.Errorf("missing group for address: %s", address)
 }
+
+const sysBusPCIDevices = "/sys/bus/pci/devices"
+
+// GroupForPCIDevice resolves the iommu group of a single device from its iommu_group link,
+// avoiding a walk of all groups when only one device needs to be looked up
+func GroupForPCIDevice(address string) (int, error) {
+	link, err := os.Readlink(filepath.Join(sysBusPCIDevices, address, "iommu_group"))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(filepath.Base(link))
+}
+
+// GroupMembers returns the addresses of all devices sharing the iommu group of address, including address
+func GroupMembers(address string) ([]string, error) {
+	return groupMembers(sysBusPCIDevices, address)
+}
+
+func groupMembers(pciDevicesPath string, address string) ([]string, error) {
+	devices, err := os.ReadDir(filepath.Join(pciDevicesPath, address, "iommu_group", "devices"))
+	if err != nil {
+		return nil, fmt.Errorf("error listing iommu group devices for %s: %w", address, err)
+	}
+
+	members := make([]string, 0, len(devices))
+	for _, device := range devices {
+		members = append(members, device.Name())
+	}
+	return members, nil
+}
+
+const pciBridgeClassPrefix = "0x0604"
+
+// IsPCIBridge checks the class of the device at address, as bridges share the iommu group of the
+// devices behind them though cannot be bound to vfio-pci
+func IsPCIBridge(address string) (bool, error) {
+	return isPCIBridge(sysBusPCIDevices, address)
+}
+
+func isPCIBridge(pciDevicesPath string, address string) (bool, error) {
+	class, err := os.ReadFile(filepath.Join(pciDevicesPath, address, "class")) // #nosec G304
+	if err != nil {
+		return false, fmt.Errorf("error reading class for device %s: %w", address, err)
+	}
+	return strings.HasPrefix(strings.TrimSpace(string(class)), pciBridgeClassPrefix), nil
+}
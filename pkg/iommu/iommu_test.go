@@ -1,6 +1,8 @@
 package iommu
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 )
@@ -44,3 +46,47 @@ func TestGroupMapForPCIDevices(t *testing.T) {
 		})
 	}
 }
+
+func TestGroupMembers(t *testing.T) {
+	root := t.TempDir()
+	groupDevices := filepath.Join(root, "iommu_groups", "9", "devices")
+	pciDevices := filepath.Join(root, "devices")
+	classes := map[string]string{
+		"0000:00:1c.0": "0x060400",
+		"0000:06:00.0": "0x030000",
+		"0000:06:00.1": "0x040300",
+	}
+	for address, class := range classes {
+		if err := os.MkdirAll(filepath.Join(groupDevices, address), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(pciDevices, address), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(filepath.Join(root, "iommu_groups", "9"), filepath.Join(pciDevices, address, "iommu_group")); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(pciDevices, address, "class"), []byte(class+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	members, err := groupMembers(pciDevices, "0000:06:00.0")
+	if err != nil {
+		t.Fatalf("groupMembers() error = %v", err)
+	}
+	want := []string{"0000:00:1c.0", "0000:06:00.0", "0000:06:00.1"}
+	if !reflect.DeepEqual(members, want) {
+		t.Errorf("groupMembers() = %v, want %v", members, want)
+	}
+
+	for address, class := range classes {
+		bridge, err := isPCIBridge(pciDevices, address)
+		if err != nil {
+			t.Fatalf("isPCIBridge() error = %v", err)
+		}
+		if bridge != (class == "0x060400") {
+			t.Errorf("isPCIBridge(%s) = %v for class %s", address, bridge, class)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// AddressEnvVarName is the listen address for the metrics endpoint, metrics are not served if unset
+	AddressEnvVarName = "METRICS_ADDRESS"
+
+	metricsPath = "/metrics"
+	namespace   = "pcidevices"
+
+	ResourcePCIDevice = "pcidevice"
+)
+
+var (
+	// StatusUpdates counts status sub-resource writes sent to the api server
+	StatusUpdates = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "status_updates_total",
+		Help:      "Number of status updates written to the api server",
+	}, []string{"resource"})
+
+	// StatusUpdatesSkipped counts status writes skipped as the observed state was unchanged
+	StatusUpdatesSkipped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "status_updates_skipped_total",
+		Help:      "Number of status updates skipped as the status was unchanged",
+	}, []string{"resource"})
+)
+
+func init() {
+	prometheus.MustRegister(StatusUpdates, StatusUpdatesSkipped)
+}
+
+// Serve exposes the default prometheus registry on the address defined by METRICS_ADDRESS
+// until ctx is cancelled
+func Serve(ctx context.Context) {
+	addr := os.Getenv(AddressEnvVarName)
+	if addr == "" {
+		logrus.Debugf("%s not set, skipping metrics endpoint", AddressEnvVarName)
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, promhttp.Handler())
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+
+	go func() {
+		logrus.Infof("serving metrics on %s%s", addr, metricsPath)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.Errorf("error serving metrics: %v", err)
+		}
+	}()
+}
@@ -0,0 +1,82 @@
+package agentstatus
+
+import (
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+)
+
+// Subsystems are reported on the Node status in this order
+var Subsystems = []v1beta1.NodeSubsystem{
+	v1beta1.SubsystemPCI,
+	v1beta1.SubsystemUSB,
+	v1beta1.SubsystemSRIOV,
+	v1beta1.SubsystemVGPU,
+	v1beta1.SubsystemMIG,
+}
+
+// Default is the recorder shared by the controllers scanning devices in the agent
+var Default = NewRecorder()
+
+// Recorder tracks the outcome of device scans performed by the agent, so they can be reported on the Node status
+type Recorder struct {
+	mu      sync.Mutex
+	results map[v1beta1.NodeSubsystem]*v1beta1.NodeSubsystemStatus
+	now     func() metav1.Time
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{
+		results: make(map[v1beta1.NodeSubsystem]*v1beta1.NodeSubsystemStatus),
+		now:     metav1.Now,
+	}
+}
+
+// Record stores the outcome of a scan of subsystem, err is nil for successful scans
+func (r *Recorder) Record(subsystem v1beta1.NodeSubsystem, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, ok := r.results[subsystem]
+	if !ok {
+		result = &v1beta1.NodeSubsystemStatus{Name: subsystem}
+		r.results[subsystem] = result
+	}
+
+	if err != nil {
+		result.ErrorCount++
+		result.LastError = err.Error()
+		return
+	}
+
+	now := r.now()
+	result.LastSuccessfulScanTime = &now
+	result.LastError = ""
+}
+
+// Status returns the status of all subsystems. The last successful scan times in previous are retained
+// for subsystems which have not been scanned successfully since the agent was started
+func (r *Recorder) Status(previous []v1beta1.NodeSubsystemStatus) []v1beta1.NodeSubsystemStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lastScans := make(map[v1beta1.NodeSubsystem]*metav1.Time, len(previous))
+	for _, v := range previous {
+		lastScans[v.Name] = v.LastSuccessfulScanTime
+	}
+
+	status := make([]v1beta1.NodeSubsystemStatus, 0, len(Subsystems))
+	for _, subsystem := range Subsystems {
+		result := v1beta1.NodeSubsystemStatus{Name: subsystem}
+		if v, ok := r.results[subsystem]; ok {
+			result = *v.DeepCopy()
+		}
+		if result.LastSuccessfulScanTime == nil {
+			result.LastSuccessfulScanTime = lastScans[subsystem]
+		}
+		status = append(status, result)
+	}
+	return status
+}
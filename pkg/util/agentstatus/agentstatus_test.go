@@ -0,0 +1,48 @@
+package agentstatus
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+)
+
+func Test_Recorder(t *testing.T) {
+	assert := require.New(t)
+	scanTime := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	previousScanTime := metav1.NewTime(scanTime.Add(-time.Hour))
+	r := NewRecorder()
+	r.now = func() metav1.Time { return scanTime }
+
+	r.Record(v1beta1.SubsystemPCI, nil)
+	r.Record(v1beta1.SubsystemUSB, errors.New("usb scan failed"))
+	r.Record(v1beta1.SubsystemUSB, errors.New("usb scan failed again"))
+
+	status := r.Status([]v1beta1.NodeSubsystemStatus{
+		{Name: v1beta1.SubsystemPCI, LastSuccessfulScanTime: &previousScanTime},
+		{Name: v1beta1.SubsystemUSB, LastSuccessfulScanTime: &previousScanTime},
+	})
+	assert.Len(status, len(Subsystems), "expected all subsystems to be reported")
+
+	assert.Equal(v1beta1.SubsystemPCI, status[0].Name)
+	assert.Equal(scanTime, *status[0].LastSuccessfulScanTime)
+	assert.Zero(status[0].ErrorCount)
+
+	assert.Equal(v1beta1.SubsystemUSB, status[1].Name)
+	assert.Equal(previousScanTime, *status[1].LastSuccessfulScanTime, "expected last successful scan to be retained")
+	assert.Equal(int64(2), status[1].ErrorCount)
+	assert.Equal("usb scan failed again", status[1].LastError)
+
+	assert.Nil(status[4].LastSuccessfulScanTime, "expected no scan time for subsystems which were never scanned")
+
+	// a successful scan clears the last error but retains the error count
+	r.Record(v1beta1.SubsystemUSB, nil)
+	status = r.Status(nil)
+	assert.Equal(scanTime, *status[1].LastSuccessfulScanTime)
+	assert.Equal(int64(2), status[1].ErrorCount)
+	assert.Empty(status[1].LastError)
+}
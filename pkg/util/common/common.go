@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubevirtv1 "kubevirt.io/api/core/v1"
 
 	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
@@ -19,6 +20,15 @@ const (
 	defaultVFCheckFile      = "sriov_vf_device"
 )
 
+// Indexes registered on the VirtualMachine cache used by the controllers
+const (
+	VMByPCIDeviceClaim = "harvesterhci.io/vm-by-pcideviceclaim"
+	VMByUSBDeviceClaim = "harvesterhci.io/vm-by-usbdeviceclaim"
+)
+
+// PCIDeviceByResourceName is the index registered on the PCIDevice cache used by the controllers
+const PCIDeviceByResourceName = "harvesterhcio.io/pcidevice-by-resource-name"
+
 // IsDeviceSRIOVCapable checks for existence of `sriov_vf_device` file in the pcidevice tree
 func IsDeviceSRIOVCapable(devicePath string) (bool, error) {
 	vfCheckFilePath := filepath.Join(devicePath, defaultVFCheckFile)
@@ -84,6 +94,30 @@ func GetVFList(pfDir string) (vfList []string, err error) {
 	return
 }
 
+// IsControlledBy returns true if the VMI was created by kubevirt for the VM. The controller reference of a VMI is set by
+// whoever creates it, so it is only trusted if it refers to both the name and the uid of an existing VM
+func IsControlledBy(vmi *kubevirtv1.VirtualMachineInstance, vm *kubevirtv1.VirtualMachine) bool {
+	owner := metav1.GetControllerOf(vmi)
+	return vm != nil && owner != nil && owner.Kind == kubevirtv1.VirtualMachineGroupVersionKind.Kind &&
+		owner.Name == vm.Name && owner.UID == vm.UID
+}
+
+// VMForVMI wraps the spec of the VMI in a VM, so the checks of VMs can be applied to VMIs created without a VM
+func VMForVMI(vmi *kubevirtv1.VirtualMachineInstance) *kubevirtv1.VirtualMachine {
+	return &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              vmi.Name,
+			Namespace:         vmi.Namespace,
+			DeletionTimestamp: vmi.DeletionTimestamp,
+		},
+		Spec: kubevirtv1.VirtualMachineSpec{
+			Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+				Spec: vmi.Spec,
+			},
+		},
+	}
+}
+
 func VMBySpecHostDeviceName(obj *kubevirtv1.VirtualMachine) ([]string, error) {
 	hostDeviceName := make([]string, 0, len(obj.Spec.Template.Spec.Domain.Devices.HostDevices))
 	for _, hostDevice := range obj.Spec.Template.Spec.Domain.Devices.HostDevices {
@@ -143,6 +177,10 @@ func generateHostDeviceAllocation(obj *kubevirtv1.VirtualMachine, allocationDeta
 	return nil, nil
 }
 
+func PCIDeviceResourceName(obj *v1beta1.PCIDevice) ([]string, error) {
+	return []string{obj.Status.ResourceName}, nil
+}
+
 func USBDeviceByResourceName(obj *v1beta1.USBDevice) ([]string, error) {
 	return []string{obj.Status.ResourceName}, nil
 }
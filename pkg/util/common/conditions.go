@@ -0,0 +1,60 @@
+package common
+
+import (
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+)
+
+// SetCondition sets condType on conditions, recording the generation of the object it was observed on.
+// LastTransitionTime is only updated when the status of the condition changes
+func SetCondition(conditions *[]metav1.Condition, generation int64, condType string, status bool, reason, message string) bool {
+	condStatus := metav1.ConditionFalse
+	if status {
+		condStatus = metav1.ConditionTrue
+	}
+
+	return SetConditionStatus(conditions, generation, condType, condStatus, reason, message)
+}
+
+// SetConditionStatus is similar to SetCondition, but allows the condition to be set to Unknown
+func SetConditionStatus(conditions *[]metav1.Condition, generation int64, condType string, status metav1.ConditionStatus, reason, message string) bool {
+	return meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               condType,
+		Status:             status,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// SetDegraded marks conditions as Degraded with reason when err is not nil, and clears it otherwise
+func SetDegraded(conditions *[]metav1.Condition, generation int64, reason string, err error) bool {
+	if err != nil {
+		return SetCondition(conditions, generation, v1beta1.ConditionDegraded, true, reason, err.Error())
+	}
+	return SetCondition(conditions, generation, v1beta1.ConditionDegraded, false, v1beta1.ReasonReconciled, "")
+}
+
+// MarkDegraded marks conditions as not Ready and Degraded with reason and the message of reconcileErr, so reconcile
+// failures are visible without the agent logs
+func MarkDegraded(conditions *[]metav1.Condition, generation int64, reason string, reconcileErr error) bool {
+	changed := SetCondition(conditions, generation, v1beta1.ConditionReady, false, reason, reconcileErr.Error())
+	return SetDegraded(conditions, generation, reason, reconcileErr) || changed
+}
+
+// UpdateDegradedStatus updates the status of obj, after its conditions were marked with MarkDegraded. Errors updating
+// the status are only logged, and reconcileErr is always returned to ensure obj is requeued
+func UpdateDegradedStatus[T metav1.Object](kind string, obj T, reconcileErr error, updateStatus func(T) (T, error)) (T, error) {
+	if _, err := updateStatus(obj); err != nil {
+		logrus.Errorf("error updating conditions on %s %s: %v", kind, obj.GetName(), err)
+	}
+	return obj, reconcileErr
+}
+
+// IsConditionTrue returns true if condType is present and true in conditions
+func IsConditionTrue(conditions []metav1.Condition, condType string) bool {
+	return meta.IsStatusConditionTrue(conditions, condType)
+}
@@ -0,0 +1,115 @@
+package devicequota
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/labels"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	ctl "github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/util/common"
+)
+
+// Counter counts the devices requested by VMs against DeviceQuotas
+type Counter struct {
+	pdCache  ctl.PCIDeviceCache
+	usbCache ctl.USBDeviceCache
+}
+
+func NewCounter(pdCache ctl.PCIDeviceCache, usbCache ctl.USBDeviceCache) *Counter {
+	return &Counter{
+		pdCache:  pdCache,
+		usbCache: usbCache,
+	}
+}
+
+// Usage returns the devices requested by vms. VMs being deleted no longer hold their devices, and are not counted
+func (c *Counter) Usage(vms ...*kubevirtv1.VirtualMachine) (v1beta1.DeviceQuotaResources, error) {
+	usage := v1beta1.DeviceQuotaResources{}
+	classes, err := c.resourceClasses()
+	if err != nil {
+		return usage, err
+	}
+
+	for _, vm := range vms {
+		if vm == nil || vm.DeletionTimestamp != nil || vm.Spec.Template == nil {
+			continue
+		}
+		for _, hostDevice := range vm.Spec.Template.Spec.Domain.Devices.HostDevices {
+			usage.Add(hostDevice.DeviceName, classes[hostDevice.DeviceName], 1)
+		}
+		for _, gpu := range vm.Spec.Template.Spec.Domain.Devices.GPUs {
+			usage.Add(gpu.DeviceName, v1beta1.DeviceClassVGPU, 1)
+		}
+	}
+	return usage, nil
+}
+
+// resourceClasses maps the resource names of pci and usb devices to the class they are counted as. A resource name
+// shared by devices of different classes, e.g. a DevicePool of GPUs and NIC VFs, is counted as the first of the
+// classes in alphabetical order, so the class does not depend on the order in which devices are listed
+func (c *Counter) resourceClasses() (map[string]v1beta1.DeviceClass, error) {
+	classes := make(map[string]v1beta1.DeviceClass)
+	add := func(resourceName string, class v1beta1.DeviceClass) {
+		if current, ok := classes[resourceName]; !ok || class < current {
+			classes[resourceName] = class
+		}
+	}
+
+	pds, err := c.pdCache.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error listing pcidevices: %w", err)
+	}
+	for _, pd := range pds {
+		if class := pd.QuotaClass(); class != "" {
+			add(pd.Status.ResourceName, class)
+		}
+	}
+
+	usbs, err := c.usbCache.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error listing usbdevices: %w", err)
+	}
+	for _, usb := range usbs {
+		add(usb.Status.ResourceName, v1beta1.DeviceClassUSB)
+	}
+	return classes, nil
+}
+
+// Workloads returns the VMs and the VMIs created without a VM, which both hold devices counted against DeviceQuotas.
+// VMIs controlled by one of the VMs are counted through their VM, all other VMIs are counted on their own
+func Workloads(vms []*kubevirtv1.VirtualMachine, vmis []*kubevirtv1.VirtualMachineInstance) []*kubevirtv1.VirtualMachine {
+	vmsByName := make(map[string]*kubevirtv1.VirtualMachine, len(vms))
+	for _, vm := range vms {
+		vmsByName[vm.Name] = vm
+	}
+
+	workloads := make([]*kubevirtv1.VirtualMachine, 0, len(vms)+len(vmis))
+	workloads = append(workloads, vms...)
+	for _, vmi := range vmis {
+		if !common.IsControlledBy(vmi, vmsByName[vmi.Name]) {
+			workloads = append(workloads, common.VMForVMI(vmi))
+		}
+	}
+	return workloads
+}
+
+// Exceeded returns the resource names and classes limited by quota for which used is over the limit, and requested
+// more devices than current. Devices already held past the limit, e.g. when the quota was lowered, are not reported
+func Exceeded(quota *v1beta1.DeviceQuota, used, current, requested v1beta1.DeviceQuotaResources) []string {
+	var exceeded []string
+	for name, limit := range quota.Spec.Hard.ResourceNames {
+		if used.ResourceNames[name] > limit && requested.ResourceNames[name] > current.ResourceNames[name] {
+			exceeded = append(exceeded, fmt.Sprintf("%s %d/%d", name, used.ResourceNames[name], limit))
+		}
+	}
+	for class, limit := range quota.Spec.Hard.Classes {
+		if used.Classes[class] > limit && requested.Classes[class] > current.Classes[class] {
+			exceeded = append(exceeded, fmt.Sprintf("%s %d/%d", class, used.Classes[class], limit))
+		}
+	}
+	sort.Strings(exceeded)
+	return exceeded
+}
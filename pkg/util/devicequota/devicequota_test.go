@@ -0,0 +1,41 @@
+package devicequota
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/pcidevices/pkg/util/fakeclients"
+)
+
+func Test_resourceClasses(t *testing.T) {
+	assert := require.New(t)
+	newDevice := func(name, classID, physFn string) *v1beta1.PCIDevice {
+		return &v1beta1.PCIDevice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: v1beta1.PCIDeviceStatus{
+				ClassID:      classID,
+				PhysFn:       physFn,
+				ResourceName: "example.com/POOL",
+			},
+		}
+	}
+
+	// the devices of the pool are listed in a different order on each node
+	for _, devices := range [][]*v1beta1.PCIDevice{
+		{newDevice("node1-000004010", "0200", "0000:04:00.0"), newDevice("node1-000081000", "0302", "")},
+		{newDevice("node1-000081000", "0200", "0000:81:00.0"), newDevice("node1-000004010", "0302", "")},
+	} {
+		client := fake.NewSimpleClientset(devices[0], devices[1])
+		counter := NewCounter(fakeclients.PCIDevicesCache(client.DevicesV1beta1().PCIDevices),
+			fakeclients.USBDeviceCache(client.DevicesV1beta1().USBDevices))
+		classes, err := counter.resourceClasses()
+		assert.NoError(err)
+		assert.Equal(v1beta1.DeviceClassGPU, classes["example.com/POOL"], "expected mixed pool to be counted as the same class regardless of order")
+	}
+}
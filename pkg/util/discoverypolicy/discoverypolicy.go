@@ -0,0 +1,82 @@
+package discoverypolicy
+
+import (
+	"fmt"
+
+	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	ctl "github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
+)
+
+// Evaluator looks up the DeviceDiscoveryPolicies which apply to a node
+type Evaluator struct {
+	nodeName    string
+	nodeCache   ctlcorev1.NodeCache
+	policyCache ctl.DeviceDiscoveryPolicyCache
+}
+
+func NewEvaluator(nodeName string, nodeCache ctlcorev1.NodeCache, policyCache ctl.DeviceDiscoveryPolicyCache) *Evaluator {
+	return &Evaluator{
+		nodeName:    nodeName,
+		nodeCache:   nodeCache,
+		policyCache: policyCache,
+	}
+}
+
+// Filter is the set of policies which apply to a node
+type Filter []*v1beta1.DeviceDiscoveryPolicy
+
+// Filter returns the policies which apply to the node. A nil Evaluator returns a Filter which allows all devices
+func (e *Evaluator) Filter() (Filter, error) {
+	if e == nil {
+		return nil, nil
+	}
+
+	policies, err := e.policyCache.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error listing devicediscoverypolicies: %w", err)
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+
+	node, err := e.nodeCache.Get(e.nodeName)
+	if err != nil {
+		return nil, fmt.Errorf("error looking up node %s: %w", e.nodeName, err)
+	}
+
+	var filter Filter
+	for _, policy := range policies {
+		if policy.DeletionTimestamp != nil {
+			continue
+		}
+		if policy.Spec.NodeSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(policy.Spec.NodeSelector)
+			if err != nil {
+				// invalid selectors are rejected by the webhook
+				logrus.Warnf("ignoring devicediscoverypolicy %s with invalid node selector: %v", policy.Name, err)
+				continue
+			}
+			if !selector.Matches(labels.Set(node.Labels)) {
+				continue
+			}
+		}
+		filter = append(filter, policy)
+	}
+	return filter, nil
+}
+
+// Allows returns true if dev is allowed by all policies in the filter
+func (f Filter) Allows(dev v1beta1.DiscoveredDevice) bool {
+	for _, policy := range f {
+		if !policy.Spec.Allows(dev) {
+			logrus.Debugf("%s device %s is excluded by devicediscoverypolicy %s", dev.Subsystem, dev.Address, policy.Name)
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package discoverypolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/pcidevices/pkg/util/fakeclients"
+)
+
+func Test_Filter(t *testing.T) {
+	assert := require.New(t)
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node1",
+			Labels: map[string]string{"storage": "true"},
+		},
+	}
+	storageNodes := &v1beta1.DeviceDiscoveryPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "no-usb-on-storage-nodes",
+		},
+		Spec: v1beta1.DeviceDiscoveryPolicySpec{
+			NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"storage": "true"}},
+			Exclude:      []v1beta1.DeviceDiscoveryRule{{Subsystem: v1beta1.SubsystemUSB}},
+		},
+	}
+	gpuNodes := &v1beta1.DeviceDiscoveryPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "only-gpus",
+		},
+		Spec: v1beta1.DeviceDiscoveryPolicySpec{
+			NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "true"}},
+			Include:      []v1beta1.DeviceDiscoveryRule{{Subsystem: v1beta1.SubsystemPCI, ClassID: "03"}},
+		},
+	}
+	client := fake.NewSimpleClientset(storageNodes, gpuNodes)
+	coreClient := corefake.NewSimpleClientset(node)
+
+	e := NewEvaluator(node.Name, fakeclients.NodeCache(coreClient.CoreV1().Nodes), fakeclients.DeviceDiscoveryPoliciesCache(client.DevicesV1beta1().DeviceDiscoveryPolicies))
+	filter, err := e.Filter()
+	assert.NoError(err, "expected no error building filter")
+	assert.Len(filter, 1, "expected only policies selecting the node to apply")
+	assert.False(filter.Allows(v1beta1.DiscoveredDevice{Subsystem: v1beta1.SubsystemUSB, VendorID: "0951", Address: "/dev/bus/usb/001/002"}))
+	assert.True(filter.Allows(v1beta1.DiscoveredDevice{Subsystem: v1beta1.SubsystemPCI, VendorID: "8086", ClassID: "0200", Address: "0000:04:00.0"}))
+
+	var nilEvaluator *Evaluator
+	filter, err = nilEvaluator.Filter()
+	assert.NoError(err)
+	assert.True(filter.Allows(v1beta1.DiscoveredDevice{Subsystem: v1beta1.SubsystemUSB}), "expected all devices to be allowed without an evaluator")
+}
@@ -0,0 +1,82 @@
+package fakeclients
+
+import (
+	"context"
+
+	"github.com/rancher/wrangler/v3/pkg/generic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+
+	devicev1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/typed/devices.harvesterhci.io/v1beta1"
+)
+
+type AutoClaimPoliciesClient func() v1beta1.AutoClaimPolicyInterface
+
+func (p AutoClaimPoliciesClient) Update(d *devicev1beta1.AutoClaimPolicy) (*devicev1beta1.AutoClaimPolicy, error) {
+	return p().Update(context.TODO(), d, metav1.UpdateOptions{})
+}
+
+func (p AutoClaimPoliciesClient) Get(name string, options metav1.GetOptions) (*devicev1beta1.AutoClaimPolicy, error) {
+	return p().Get(context.TODO(), name, options)
+}
+
+func (p AutoClaimPoliciesClient) Create(d *devicev1beta1.AutoClaimPolicy) (*devicev1beta1.AutoClaimPolicy, error) {
+	return p().Create(context.TODO(), d, metav1.CreateOptions{})
+}
+
+func (p AutoClaimPoliciesClient) Delete(name string, options *metav1.DeleteOptions) error {
+	return p().Delete(context.TODO(), name, *options)
+}
+
+func (p AutoClaimPoliciesClient) List(opts metav1.ListOptions) (*devicev1beta1.AutoClaimPolicyList, error) {
+	return p().List(context.TODO(), opts)
+}
+
+func (p AutoClaimPoliciesClient) Watch(metav1.ListOptions) (watch.Interface, error) {
+	panic("implement me")
+}
+
+func (p AutoClaimPoliciesClient) Patch(_ string, _ types.PatchType, _ []byte, _ ...string) (result *devicev1beta1.AutoClaimPolicy, err error) {
+	panic("implement me")
+}
+
+func (p AutoClaimPoliciesClient) UpdateStatus(d *devicev1beta1.AutoClaimPolicy) (*devicev1beta1.AutoClaimPolicy, error) {
+	return p().UpdateStatus(context.TODO(), d, metav1.UpdateOptions{})
+}
+
+func (p AutoClaimPoliciesClient) WithImpersonation(_ rest.ImpersonationConfig) (generic.NonNamespacedClientInterface[*devicev1beta1.AutoClaimPolicy, *devicev1beta1.AutoClaimPolicyList], error) {
+	panic("implement me")
+}
+
+type AutoClaimPoliciesCache func() v1beta1.AutoClaimPolicyInterface
+
+func (p AutoClaimPoliciesCache) Get(name string) (*devicev1beta1.AutoClaimPolicy, error) {
+	return p().Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (p AutoClaimPoliciesCache) List(selector labels.Selector) ([]*devicev1beta1.AutoClaimPolicy, error) {
+	policies, err := p().List(context.TODO(), metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*devicev1beta1.AutoClaimPolicy, 0, len(policies.Items))
+	for i := range policies.Items {
+		result = append(result, &policies.Items[i])
+	}
+	return result, nil
+}
+
+func (p AutoClaimPoliciesCache) AddIndexer(_ string, _ generic.Indexer[*devicev1beta1.AutoClaimPolicy]) {
+	panic("implement me")
+}
+
+func (p AutoClaimPoliciesCache) GetByIndex(_, _ string) ([]*devicev1beta1.AutoClaimPolicy, error) {
+	panic("implement me")
+}
@@ -0,0 +1,42 @@
+package fakeclients
+
+import (
+	"context"
+
+	"github.com/rancher/wrangler/v3/pkg/generic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	devicev1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/typed/devices.harvesterhci.io/v1beta1"
+)
+
+type DeviceAccessPoliciesCache func() v1beta1.DeviceAccessPolicyInterface
+
+func (p DeviceAccessPoliciesCache) Get(name string) (*devicev1beta1.DeviceAccessPolicy, error) {
+	return p().Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (p DeviceAccessPoliciesCache) List(selector labels.Selector) ([]*devicev1beta1.DeviceAccessPolicy, error) {
+	policies, err := p().List(context.TODO(), metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*devicev1beta1.DeviceAccessPolicy, 0, len(policies.Items))
+	for _, policy := range policies.Items {
+		obj := policy
+		result = append(result, &obj)
+	}
+	return result, nil
+}
+
+func (p DeviceAccessPoliciesCache) AddIndexer(_ string, _ generic.Indexer[*devicev1beta1.DeviceAccessPolicy]) {
+	panic("implement me")
+}
+
+func (p DeviceAccessPoliciesCache) GetByIndex(_, _ string) ([]*devicev1beta1.DeviceAccessPolicy, error) {
+	panic("implement me")
+}
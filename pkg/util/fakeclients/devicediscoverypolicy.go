@@ -0,0 +1,42 @@
+package fakeclients
+
+import (
+	"context"
+
+	"github.com/rancher/wrangler/v3/pkg/generic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	devicev1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/typed/devices.harvesterhci.io/v1beta1"
+)
+
+type DeviceDiscoveryPoliciesCache func() v1beta1.DeviceDiscoveryPolicyInterface
+
+func (p DeviceDiscoveryPoliciesCache) Get(name string) (*devicev1beta1.DeviceDiscoveryPolicy, error) {
+	return p().Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (p DeviceDiscoveryPoliciesCache) List(selector labels.Selector) ([]*devicev1beta1.DeviceDiscoveryPolicy, error) {
+	policies, err := p().List(context.TODO(), metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*devicev1beta1.DeviceDiscoveryPolicy, 0, len(policies.Items))
+	for _, policy := range policies.Items {
+		obj := policy
+		result = append(result, &obj)
+	}
+	return result, nil
+}
+
+func (p DeviceDiscoveryPoliciesCache) AddIndexer(_ string, _ generic.Indexer[*devicev1beta1.DeviceDiscoveryPolicy]) {
+	panic("implement me")
+}
+
+func (p DeviceDiscoveryPoliciesCache) GetByIndex(_, _ string) ([]*devicev1beta1.DeviceDiscoveryPolicy, error) {
+	panic("implement me")
+}
@@ -0,0 +1,42 @@
+package fakeclients
+
+import (
+	"context"
+
+	"github.com/rancher/wrangler/v3/pkg/generic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	devicev1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/typed/devices.harvesterhci.io/v1beta1"
+)
+
+type DevicePoolsCache func() v1beta1.DevicePoolInterface
+
+func (p DevicePoolsCache) Get(name string) (*devicev1beta1.DevicePool, error) {
+	return p().Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (p DevicePoolsCache) List(selector labels.Selector) ([]*devicev1beta1.DevicePool, error) {
+	pools, err := p().List(context.TODO(), metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*devicev1beta1.DevicePool, 0, len(pools.Items))
+	for _, pool := range pools.Items {
+		obj := pool
+		result = append(result, &obj)
+	}
+	return result, nil
+}
+
+func (p DevicePoolsCache) AddIndexer(_ string, _ generic.Indexer[*devicev1beta1.DevicePool]) {
+	panic("implement me")
+}
+
+func (p DevicePoolsCache) GetByIndex(_, _ string) ([]*devicev1beta1.DevicePool, error) {
+	panic("implement me")
+}
@@ -0,0 +1,83 @@
+package fakeclients
+
+import (
+	"context"
+
+	"github.com/rancher/wrangler/v3/pkg/generic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+
+	devicev1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/typed/devices.harvesterhci.io/v1beta1"
+)
+
+type DeviceQuotasClient func(string) v1beta1.DeviceQuotaInterface
+
+func (c DeviceQuotasClient) Update(d *devicev1beta1.DeviceQuota) (*devicev1beta1.DeviceQuota, error) {
+	return c(d.Namespace).Update(context.TODO(), d, metav1.UpdateOptions{})
+}
+
+func (c DeviceQuotasClient) Get(namespace, name string, options metav1.GetOptions) (*devicev1beta1.DeviceQuota, error) {
+	return c(namespace).Get(context.TODO(), name, options)
+}
+
+func (c DeviceQuotasClient) Create(d *devicev1beta1.DeviceQuota) (*devicev1beta1.DeviceQuota, error) {
+	return c(d.Namespace).Create(context.TODO(), d, metav1.CreateOptions{})
+}
+
+func (c DeviceQuotasClient) Delete(namespace, name string, options *metav1.DeleteOptions) error {
+	return c(namespace).Delete(context.TODO(), name, *options)
+}
+
+func (c DeviceQuotasClient) List(namespace string, opts metav1.ListOptions) (*devicev1beta1.DeviceQuotaList, error) {
+	return c(namespace).List(context.TODO(), opts)
+}
+
+func (c DeviceQuotasClient) UpdateStatus(d *devicev1beta1.DeviceQuota) (*devicev1beta1.DeviceQuota, error) {
+	return c(d.Namespace).UpdateStatus(context.TODO(), d, metav1.UpdateOptions{})
+}
+
+func (c DeviceQuotasClient) Watch(_ string, _ metav1.ListOptions) (watch.Interface, error) {
+	panic("implement me")
+}
+
+func (c DeviceQuotasClient) Patch(_, _ string, _ types.PatchType, _ []byte, _ ...string) (result *devicev1beta1.DeviceQuota, err error) {
+	panic("implement me")
+}
+
+func (c DeviceQuotasClient) WithImpersonation(_ rest.ImpersonationConfig) (generic.ClientInterface[*devicev1beta1.DeviceQuota, *devicev1beta1.DeviceQuotaList], error) {
+	panic("implement me")
+}
+
+type DeviceQuotasCache func(string) v1beta1.DeviceQuotaInterface
+
+func (c DeviceQuotasCache) Get(namespace, name string) (*devicev1beta1.DeviceQuota, error) {
+	return c(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (c DeviceQuotasCache) List(namespace string, selector labels.Selector) ([]*devicev1beta1.DeviceQuota, error) {
+	quotas, err := c(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*devicev1beta1.DeviceQuota, 0, len(quotas.Items))
+	for _, quota := range quotas.Items {
+		obj := quota
+		result = append(result, &obj)
+	}
+	return result, nil
+}
+
+func (c DeviceQuotasCache) AddIndexer(_ string, _ generic.Indexer[*devicev1beta1.DeviceQuota]) {
+	panic("implement me")
+}
+
+func (c DeviceQuotasCache) GetByIndex(_, _ string) ([]*devicev1beta1.DeviceQuota, error) {
+	panic("implement me")
+}
@@ -0,0 +1,83 @@
+package fakeclients
+
+import (
+	"context"
+
+	"github.com/rancher/wrangler/v3/pkg/generic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+
+	devicev1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/typed/devices.harvesterhci.io/v1beta1"
+)
+
+type IOMMUGroupsClient func() v1beta1.IOMMUGroupInterface
+
+func (p IOMMUGroupsClient) Update(g *devicev1beta1.IOMMUGroup) (*devicev1beta1.IOMMUGroup, error) {
+	return p().Update(context.TODO(), g, metav1.UpdateOptions{})
+}
+
+func (p IOMMUGroupsClient) Get(name string, options metav1.GetOptions) (*devicev1beta1.IOMMUGroup, error) {
+	return p().Get(context.TODO(), name, options)
+}
+
+func (p IOMMUGroupsClient) Create(g *devicev1beta1.IOMMUGroup) (*devicev1beta1.IOMMUGroup, error) {
+	return p().Create(context.TODO(), g, metav1.CreateOptions{})
+}
+
+func (p IOMMUGroupsClient) Delete(name string, options *metav1.DeleteOptions) error {
+	return p().Delete(context.TODO(), name, *options)
+}
+
+func (p IOMMUGroupsClient) List(opts metav1.ListOptions) (*devicev1beta1.IOMMUGroupList, error) {
+	return p().List(context.TODO(), opts)
+}
+
+func (p IOMMUGroupsClient) Watch(metav1.ListOptions) (watch.Interface, error) {
+	panic("implement me")
+}
+
+func (p IOMMUGroupsClient) Patch(_ string, _ types.PatchType, _ []byte, _ ...string) (result *devicev1beta1.IOMMUGroup, err error) {
+	panic("implement me")
+}
+
+func (p IOMMUGroupsClient) UpdateStatus(g *devicev1beta1.IOMMUGroup) (*devicev1beta1.IOMMUGroup, error) {
+	return p().Update(context.TODO(), g, metav1.UpdateOptions{})
+}
+
+func (p IOMMUGroupsClient) WithImpersonation(_ rest.ImpersonationConfig) (generic.NonNamespacedClientInterface[*devicev1beta1.IOMMUGroup, *devicev1beta1.IOMMUGroupList], error) {
+	panic("implement me")
+}
+
+type IOMMUGroupsCache func() v1beta1.IOMMUGroupInterface
+
+func (p IOMMUGroupsCache) Get(name string) (*devicev1beta1.IOMMUGroup, error) {
+	return p().Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (p IOMMUGroupsCache) List(selector labels.Selector) ([]*devicev1beta1.IOMMUGroup, error) {
+	groups, err := p().List(context.TODO(), metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*devicev1beta1.IOMMUGroup, 0, len(groups.Items))
+	for _, group := range groups.Items {
+		obj := group
+		result = append(result, &obj)
+	}
+	return result, nil
+}
+
+func (p IOMMUGroupsCache) AddIndexer(_ string, _ generic.Indexer[*devicev1beta1.IOMMUGroup]) {
+	panic("implement me")
+}
+
+func (p IOMMUGroupsCache) GetByIndex(_, _ string) ([]*devicev1beta1.IOMMUGroup, error) {
+	panic("implement me")
+}
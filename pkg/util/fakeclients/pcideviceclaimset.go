@@ -0,0 +1,82 @@
+package fakeclients
+
+import (
+	"context"
+
+	"github.com/rancher/wrangler/v3/pkg/generic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+
+	devicev1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/typed/devices.harvesterhci.io/v1beta1"
+)
+
+type PCIDeviceClaimSetsClient func() v1beta1.PCIDeviceClaimSetInterface
+
+func (p PCIDeviceClaimSetsClient) Update(d *devicev1beta1.PCIDeviceClaimSet) (*devicev1beta1.PCIDeviceClaimSet, error) {
+	return p().Update(context.TODO(), d, metav1.UpdateOptions{})
+}
+
+func (p PCIDeviceClaimSetsClient) Get(name string, options metav1.GetOptions) (*devicev1beta1.PCIDeviceClaimSet, error) {
+	return p().Get(context.TODO(), name, options)
+}
+
+func (p PCIDeviceClaimSetsClient) Create(d *devicev1beta1.PCIDeviceClaimSet) (*devicev1beta1.PCIDeviceClaimSet, error) {
+	return p().Create(context.TODO(), d, metav1.CreateOptions{})
+}
+
+func (p PCIDeviceClaimSetsClient) Delete(name string, options *metav1.DeleteOptions) error {
+	return p().Delete(context.TODO(), name, *options)
+}
+
+func (p PCIDeviceClaimSetsClient) List(opts metav1.ListOptions) (*devicev1beta1.PCIDeviceClaimSetList, error) {
+	return p().List(context.TODO(), opts)
+}
+
+func (p PCIDeviceClaimSetsClient) Watch(metav1.ListOptions) (watch.Interface, error) {
+	panic("implement me")
+}
+
+func (p PCIDeviceClaimSetsClient) Patch(_ string, _ types.PatchType, _ []byte, _ ...string) (result *devicev1beta1.PCIDeviceClaimSet, err error) {
+	panic("implement me")
+}
+
+func (p PCIDeviceClaimSetsClient) UpdateStatus(d *devicev1beta1.PCIDeviceClaimSet) (*devicev1beta1.PCIDeviceClaimSet, error) {
+	return p().UpdateStatus(context.TODO(), d, metav1.UpdateOptions{})
+}
+
+func (p PCIDeviceClaimSetsClient) WithImpersonation(_ rest.ImpersonationConfig) (generic.NonNamespacedClientInterface[*devicev1beta1.PCIDeviceClaimSet, *devicev1beta1.PCIDeviceClaimSetList], error) {
+	panic("implement me")
+}
+
+type PCIDeviceClaimSetsCache func() v1beta1.PCIDeviceClaimSetInterface
+
+func (p PCIDeviceClaimSetsCache) Get(name string) (*devicev1beta1.PCIDeviceClaimSet, error) {
+	return p().Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (p PCIDeviceClaimSetsCache) List(selector labels.Selector) ([]*devicev1beta1.PCIDeviceClaimSet, error) {
+	sets, err := p().List(context.TODO(), metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*devicev1beta1.PCIDeviceClaimSet, 0, len(sets.Items))
+	for i := range sets.Items {
+		result = append(result, &sets.Items[i])
+	}
+	return result, nil
+}
+
+func (p PCIDeviceClaimSetsCache) AddIndexer(_ string, _ generic.Indexer[*devicev1beta1.PCIDeviceClaimSet]) {
+	panic("implement me")
+}
+
+func (p PCIDeviceClaimSetsCache) GetByIndex(_, _ string) ([]*devicev1beta1.PCIDeviceClaimSet, error) {
+	panic("implement me")
+}
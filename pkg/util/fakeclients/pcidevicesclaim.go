@@ -60,8 +60,19 @@ func (p PCIDeviceClaimsCache) Get(name string) (*pcidevicev1beta1.PCIDeviceClaim
 	return p().Get(context.TODO(), name, metav1.GetOptions{})
 }
 
-func (p PCIDeviceClaimsCache) List(labels.Selector) ([]*pcidevicev1beta1.PCIDeviceClaim, error) {
-	panic("implement me")
+func (p PCIDeviceClaimsCache) List(selector labels.Selector) ([]*pcidevicev1beta1.PCIDeviceClaim, error) {
+	pdcs, err := p().List(context.TODO(), metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*pcidevicev1beta1.PCIDeviceClaim, 0, len(pdcs.Items))
+	for i := range pdcs.Items {
+		result = append(result, &pdcs.Items[i])
+	}
+	return result, nil
 }
 
 func (p PCIDeviceClaimsCache) AddIndexer(_ string, _ generic.Indexer[*pcidevicev1beta1.PCIDeviceClaim]) {
@@ -0,0 +1,42 @@
+package fakeclients
+
+import (
+	"context"
+
+	"github.com/rancher/wrangler/v3/pkg/generic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	devicev1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/typed/devices.harvesterhci.io/v1beta1"
+)
+
+type ResourceNameRulesCache func() v1beta1.ResourceNameRuleInterface
+
+func (p ResourceNameRulesCache) Get(name string) (*devicev1beta1.ResourceNameRule, error) {
+	return p().Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (p ResourceNameRulesCache) List(selector labels.Selector) ([]*devicev1beta1.ResourceNameRule, error) {
+	rules, err := p().List(context.TODO(), metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*devicev1beta1.ResourceNameRule, 0, len(rules.Items))
+	for _, rule := range rules.Items {
+		obj := rule
+		result = append(result, &obj)
+	}
+	return result, nil
+}
+
+func (p ResourceNameRulesCache) AddIndexer(_ string, _ generic.Indexer[*devicev1beta1.ResourceNameRule]) {
+	panic("implement me")
+}
+
+func (p ResourceNameRulesCache) GetByIndex(_, _ string) ([]*devicev1beta1.ResourceNameRule, error) {
+	panic("implement me")
+}
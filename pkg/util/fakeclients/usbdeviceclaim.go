@@ -62,8 +62,10 @@ func (p USBDeviceClaimsCache) Get(name string) (*devicev1beta1.USBDeviceClaim, e
 	return p().Get(context.TODO(), name, metav1.GetOptions{})
 }
 
-func (p USBDeviceClaimsCache) List(labels.Selector) ([]*devicev1beta1.USBDeviceClaim, error) {
-	usbcs, err := p().List(context.TODO(), metav1.ListOptions{})
+func (p USBDeviceClaimsCache) List(selector labels.Selector) ([]*devicev1beta1.USBDeviceClaim, error) {
+	usbcs, err := p().List(context.TODO(), metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
 	if err != nil {
 		return nil, err
 	}
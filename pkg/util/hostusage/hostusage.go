@@ -0,0 +1,242 @@
+package hostusage
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+)
+
+const (
+	defaultSysPath           = "/sys"
+	defaultMountsPath        = "/host/proc/1/mounts"
+	defaultHostNetworkNSPath = "/host/proc/1/ns/net"
+	// maxMasterDepth limits how far addresses are looked up through bonds and bridges an interface is enslaved to
+	maxMasterDepth = 4
+)
+
+var pciAddressPattern = regexp.MustCompile(`^[0-9a-f]{4}:[0-9a-f]{2}:[0-9a-f]{2}\.[0-7]$`)
+
+// Detect identifies pci devices which are in use by the host, and returns their usage keyed by pci address
+func Detect() (map[string][]v1beta1.PCIDeviceHostUsage, error) {
+	addresses, err := hostInterfaceAddresses(defaultHostNetworkNSPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return detect(defaultSysPath, defaultMountsPath, addresses)
+}
+
+func detect(sysPath, mountsPath string, interfaceAddresses map[string][]string) (map[string][]v1beta1.PCIDeviceHostUsage, error) {
+	usage := make(map[string][]v1beta1.PCIDeviceHostUsage)
+	if err := detectMounts(sysPath, mountsPath, usage); err != nil {
+		return nil, err
+	}
+	detectNetworkAddresses(sysPath, interfaceAddresses, usage)
+	detectConsoles(sysPath, usage)
+	return usage, nil
+}
+
+// detectMounts reports storage controllers backing a block device mounted on the host
+func detectMounts(sysPath, mountsPath string, usage map[string][]v1beta1.PCIDeviceHostUsage) error {
+	// #nosec G304 No risk for path injection. Reading the mount table of the host
+	fd, err := os.Open(mountsPath)
+	if err != nil {
+		return fmt.Errorf("error opening %s: %w", mountsPath, err)
+	}
+	defer fd.Close()
+
+	mapperNames := deviceMapperNames(sysPath)
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(fd)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.HasPrefix(fields[0], "/dev/") {
+			continue
+		}
+
+		name := filepath.Base(fields[0])
+		if dm, ok := mapperNames[name]; ok {
+			name = dm
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		for _, address := range blockDevicePCIAddresses(sysPath, name, 0) {
+			usage[address] = append(usage[address], v1beta1.PCIDeviceHostUsage{
+				Type:    v1beta1.HostUsageMount,
+				Name:    name,
+				Message: fmt.Sprintf("%s is mounted at %s", fields[0], fields[1]),
+			})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading %s: %w", mountsPath, err)
+	}
+	return nil
+}
+
+// deviceMapperNames maps device mapper names, as they appear under /dev/mapper, to their dm-N block device
+func deviceMapperNames(sysPath string) map[string]string {
+	names := make(map[string]string)
+	devices, _ := filepath.Glob(filepath.Join(sysPath, "class", "block", "dm-*"))
+	for _, v := range devices {
+		// #nosec G304 No risk for path injection. Reading static sysfs attributes of a block device
+		contents, err := os.ReadFile(filepath.Join(v, "dm", "name"))
+		if err == nil {
+			names[strings.TrimSpace(string(contents))] = filepath.Base(v)
+		}
+	}
+	return names
+}
+
+// blockDevicePCIAddresses returns the pci devices backing a block device. Virtual block devices such as
+// device mapper and md arrays are followed through their slaves
+func blockDevicePCIAddresses(sysPath, name string, depth int) []string {
+	path := filepath.Join(sysPath, "class", "block", name)
+	if address := pciAddressForDevice(path); address != "" {
+		return []string{address}
+	}
+
+	if depth >= maxMasterDepth {
+		return nil
+	}
+
+	slaves, err := os.ReadDir(filepath.Join(path, "slaves"))
+	if err != nil {
+		return nil
+	}
+
+	var addresses []string
+	for _, v := range slaves {
+		addresses = append(addresses, blockDevicePCIAddresses(sysPath, v.Name(), depth+1)...)
+	}
+	return addresses
+}
+
+// detectNetworkAddresses reports network devices with host ip addresses
+func detectNetworkAddresses(sysPath string, interfaceAddresses map[string][]string, usage map[string][]v1beta1.PCIDeviceHostUsage) {
+	names := make([]string, 0, len(interfaceAddresses))
+	for name := range interfaceAddresses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		address := pciAddressForDevice(filepath.Join(sysPath, "class", "net", name))
+		if address == "" || len(interfaceAddresses[name]) == 0 {
+			continue
+		}
+		usage[address] = append(usage[address], v1beta1.PCIDeviceHostUsage{
+			Type:    v1beta1.HostUsageNetworkAddress,
+			Name:    name,
+			Message: fmt.Sprintf("%s has addresses %s", name, strings.Join(interfaceAddresses[name], ", ")),
+		})
+	}
+}
+
+// detectConsoles reports display devices backing a framebuffer console, and the boot vga device
+func detectConsoles(sysPath string, usage map[string][]v1beta1.PCIDeviceHostUsage) {
+	framebuffers, _ := filepath.Glob(filepath.Join(sysPath, "class", "graphics", "fb*"))
+	for _, v := range framebuffers {
+		if address := pciAddressForDevice(v); address != "" {
+			usage[address] = append(usage[address], v1beta1.PCIDeviceHostUsage{
+				Type:    v1beta1.HostUsageConsole,
+				Name:    filepath.Base(v),
+				Message: "device backs a framebuffer console",
+			})
+		}
+	}
+
+	bootVGA, _ := filepath.Glob(filepath.Join(sysPath, "bus", "pci", "devices", "*", "boot_vga"))
+	for _, v := range bootVGA {
+		// #nosec G304 No risk for path injection. Reading static sysfs attributes of a device
+		contents, err := os.ReadFile(v)
+		if err != nil || strings.TrimSpace(string(contents)) != "1" {
+			continue
+		}
+		address := filepath.Base(filepath.Dir(v))
+		usage[address] = append(usage[address], v1beta1.PCIDeviceHostUsage{
+			Type:    v1beta1.HostUsageConsole,
+			Name:    "boot_vga",
+			Message: "device is the vga device used by the host console",
+		})
+	}
+}
+
+// pciAddressForDevice resolves a sysfs class device to the closest pci device it is attached to
+func pciAddressForDevice(path string) string {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return ""
+	}
+
+	parts := strings.Split(resolved, string(filepath.Separator))
+	for i := len(parts) - 1; i >= 0; i-- {
+		if pciAddressPattern.MatchString(parts[i]) {
+			return parts[i]
+		}
+	}
+	return ""
+}
+
+// hostInterfaceAddresses returns the global unicast addresses of each interface in the host network namespace.
+// Interfaces enslaved to a bond or bridge are reported with the addresses of their masters
+func hostInterfaceAddresses(nsPath string) (map[string][]string, error) {
+	hostProcessNS, err := netns.GetFromPath(nsPath)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching host network namespace: %w", err)
+	}
+	defer hostProcessNS.Close()
+
+	handler, err := netlink.NewHandleAt(hostProcessNS)
+	if err != nil {
+		return nil, fmt.Errorf("error generating handler for host network namespace: %w", err)
+	}
+	defer handler.Close()
+
+	links, err := handler.LinkList()
+	if err != nil {
+		return nil, fmt.Errorf("error listing links: %w", err)
+	}
+
+	linksByIndex := make(map[int]netlink.Link, len(links))
+	addressesByIndex := make(map[int][]string, len(links))
+	for _, link := range links {
+		linksByIndex[link.Attrs().Index] = link
+		addrs, err := handler.AddrList(link, netlink.FAMILY_ALL)
+		if err != nil {
+			return nil, fmt.Errorf("error listing addresses for %s: %w", link.Attrs().Name, err)
+		}
+		for _, addr := range addrs {
+			if addr.IP.IsGlobalUnicast() {
+				addressesByIndex[link.Attrs().Index] = append(addressesByIndex[link.Attrs().Index], addr.IPNet.String())
+			}
+		}
+	}
+
+	result := make(map[string][]string)
+	for _, link := range links {
+		var addresses []string
+		current := link
+		for depth := 0; current != nil && depth <= maxMasterDepth; depth++ {
+			addresses = append(addresses, addressesByIndex[current.Attrs().Index]...)
+			current = linksByIndex[current.Attrs().MasterIndex]
+		}
+		if len(addresses) != 0 {
+			result[link.Attrs().Name] = addresses
+		}
+	}
+	return result, nil
+}
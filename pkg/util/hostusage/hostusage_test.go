@@ -0,0 +1,67 @@
+package hostusage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+)
+
+// linkClassDevice creates a sysfs class entry for name pointing at devicePath under the fake devices tree
+func linkClassDevice(t *testing.T, sysPath, class, name, devicePath string) {
+	t.Helper()
+	target := filepath.Join(sysPath, "devices", devicePath)
+	if err := os.MkdirAll(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(sysPath, "class", class), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, filepath.Join(sysPath, "class", class, name)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_detect(t *testing.T) {
+	assert := require.New(t)
+	sysPath := t.TempDir()
+	linkClassDevice(t, sysPath, "block", "nvme0n1p2", "pci0000:00/0000:00:1d.0/0000:3d:00.0/nvme/nvme0/nvme0n1/nvme0n1p2")
+	linkClassDevice(t, sysPath, "block", "sdb1", "pci0000:00/0000:00:17.0/ata2/host1/target1:0:0/1:0:0:0/block/sdb/sdb1")
+	linkClassDevice(t, sysPath, "block", "dm-0", "virtual/block/dm-0")
+	assert.NoError(os.MkdirAll(filepath.Join(sysPath, "devices", "virtual", "block", "dm-0", "slaves", "sdb1"), 0755))
+	assert.NoError(os.MkdirAll(filepath.Join(sysPath, "devices", "virtual", "block", "dm-0", "dm"), 0755))
+	assert.NoError(os.WriteFile(filepath.Join(sysPath, "devices", "virtual", "block", "dm-0", "dm", "name"), []byte("longhorn\n"), 0600))
+	linkClassDevice(t, sysPath, "block", "sdc", "pci0000:00/0000:00:17.1/ata3/host2/target2:0:0/2:0:0:0/block/sdc")
+	linkClassDevice(t, sysPath, "net", "eno1", "pci0000:00/0000:00:1c.0/0000:04:00.0/net/eno1")
+	linkClassDevice(t, sysPath, "net", "eno2", "pci0000:00/0000:00:1c.0/0000:04:00.1/net/eno2")
+	linkClassDevice(t, sysPath, "net", "mgmt-br", "virtual/net/mgmt-br")
+	linkClassDevice(t, sysPath, "graphics", "fb0", "pci0000:00/0000:00:02.0/drm/card0/fb0")
+	assert.NoError(os.MkdirAll(filepath.Join(sysPath, "bus", "pci", "devices", "0000:08:00.0"), 0755))
+	assert.NoError(os.WriteFile(filepath.Join(sysPath, "bus", "pci", "devices", "0000:08:00.0", "boot_vga"), []byte("1\n"), 0600))
+
+	mountsPath := filepath.Join(t.TempDir(), "mounts")
+	assert.NoError(os.WriteFile(mountsPath, []byte(`/dev/nvme0n1p2 / ext4 rw 0 0
+/dev/nvme0n1p2 /var/lib/kubelet ext4 rw 0 0
+/dev/mapper/longhorn /var/lib/harvester/defaultdisk ext4 rw 0 0
+tmpfs /run tmpfs rw 0 0
+`), 0600))
+
+	usage, err := detect(sysPath, mountsPath, map[string][]string{
+		"eno1":    {"10.0.0.2/24"},
+		"mgmt-br": {"10.0.0.2/24"},
+	})
+	assert.NoError(err, "expected no error detecting host usage")
+
+	assert.Equal([]v1beta1.PCIDeviceHostUsage{{Type: v1beta1.HostUsageMount, Name: "nvme0n1p2", Message: "/dev/nvme0n1p2 is mounted at /"}}, usage["0000:3d:00.0"])
+	assert.Len(usage["0000:00:17.0"], 1, "expected device mapper mount to be attributed to the backing controller")
+	assert.Equal("dm-0", usage["0000:00:17.0"][0].Name)
+	assert.Empty(usage["0000:00:17.1"], "expected unmounted disk to not be in use")
+	assert.Equal(v1beta1.HostUsageNetworkAddress, usage["0000:04:00.0"][0].Type)
+	assert.Empty(usage["0000:04:00.1"], "expected interface without addresses to not be in use")
+	assert.Equal(v1beta1.HostUsageConsole, usage["0000:00:02.0"][0].Type)
+	assert.Equal("boot_vga", usage["0000:08:00.0"][0].Name)
+	assert.Len(usage, 5)
+}
@@ -0,0 +1,83 @@
+package podresources
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	podresourcesv1 "k8s.io/kubelet/pkg/apis/podresources/v1"
+)
+
+const (
+	// DefaultSocketPath is the kubelet pod resources api socket, available on every node
+	DefaultSocketPath = "/var/lib/kubelet/pod-resources/kubelet.sock"
+	defaultTimeout    = 10 * time.Second
+	// maxMessageSize matches the limit used by kubelet for pod resources clients, as the list covers all pods on the node
+	maxMessageSize = 1024 * 1024 * 16
+)
+
+// Lister looks up the devices kubelet has allocated to a pod
+type Lister interface {
+	// PodDevices returns the ids of the devices allocated to all containers of the pod, keyed by resource name
+	PodDevices(ctx context.Context, namespace, name string) (map[string][]string, error)
+}
+
+type client struct {
+	socketPath string
+	timeout    time.Duration
+}
+
+// NewLister returns a Lister querying the kubelet pod resources api over socketPath
+func NewLister(socketPath string) Lister {
+	return &client{
+		socketPath: socketPath,
+		timeout:    defaultTimeout,
+	}
+}
+
+func (c *client) PodDevices(ctx context.Context, namespace, name string) (map[string][]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, c.socketPath,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", addr)
+		}),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxMessageSize)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to kubelet pod resources socket %s: %w", c.socketPath, err)
+	}
+	defer conn.Close()
+
+	resp, err := podresourcesv1.NewPodResourcesListerClient(conn).List(ctx, &podresourcesv1.ListPodResourcesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing pod resources: %w", err)
+	}
+	return podDevices(resp, namespace, name)
+}
+
+// podDevices collects the devices allocated to the containers of pod namespace/name from a pod resources list
+func podDevices(resp *podresourcesv1.ListPodResourcesResponse, namespace, name string) (map[string][]string, error) {
+	for _, pod := range resp.GetPodResources() {
+		if pod.GetNamespace() != namespace || pod.GetName() != name {
+			continue
+		}
+		result := make(map[string][]string)
+		for _, container := range pod.GetContainers() {
+			for _, device := range container.GetDevices() {
+				for _, id := range device.GetDeviceIds() {
+					if !slices.Contains(result[device.GetResourceName()], id) {
+						result[device.GetResourceName()] = append(result[device.GetResourceName()], id)
+					}
+				}
+			}
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("pod %s/%s not found in kubelet pod resources", namespace, name)
+}
@@ -0,0 +1,53 @@
+package podresources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	podresourcesv1 "k8s.io/kubelet/pkg/apis/podresources/v1"
+)
+
+func Test_podDevices(t *testing.T) {
+	assert := require.New(t)
+	resp := &podresourcesv1.ListPodResourcesResponse{
+		PodResources: []*podresourcesv1.PodResources{
+			{
+				Name:      "virt-launcher-vm1-abcde",
+				Namespace: "other",
+				Containers: []*podresourcesv1.ContainerResources{
+					{
+						Name:    "compute",
+						Devices: []*podresourcesv1.ContainerDevices{{ResourceName: "nvidia.com/GA100", DeviceIds: []string{"0000:82:00.0"}}},
+					},
+				},
+			},
+			{
+				Name:      "virt-launcher-vm1-abcde",
+				Namespace: "default",
+				Containers: []*podresourcesv1.ContainerResources{
+					{
+						Name: "compute",
+						Devices: []*podresourcesv1.ContainerDevices{
+							{ResourceName: "nvidia.com/GA100", DeviceIds: []string{"0000:81:00.0", "0000:81:00.0"}},
+							{ResourceName: "nvidia.com/NVIDIA_A2-4Q", DeviceIds: []string{"e898f311-6b9e-46a2-b728-144d01af1a7c"}},
+						},
+					},
+					{
+						Name:    "hotplug",
+						Devices: []*podresourcesv1.ContainerDevices{{ResourceName: "nvidia.com/GA100", DeviceIds: []string{"0000:83:00.0"}}},
+					},
+				},
+			},
+		},
+	}
+
+	devices, err := podDevices(resp, "default", "virt-launcher-vm1-abcde")
+	assert.NoError(err)
+	assert.Equal(map[string][]string{
+		"nvidia.com/GA100":        {"0000:81:00.0", "0000:83:00.0"},
+		"nvidia.com/NVIDIA_A2-4Q": {"e898f311-6b9e-46a2-b728-144d01af1a7c"},
+	}, devices, "expected devices of all containers in the pod, without duplicates")
+
+	_, err = podDevices(resp, "default", "virt-launcher-vm2-fghij")
+	assert.Error(err, "expected error for pod unknown to kubelet")
+}
@@ -0,0 +1,121 @@
+package resourcenamerule
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	ctl "github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
+)
+
+// Resolver looks up the ResourceNameRules and DevicePools used to name pci devices
+type Resolver struct {
+	ruleCache ctl.ResourceNameRuleCache
+	poolCache ctl.DevicePoolCache
+}
+
+func NewResolver(ruleCache ctl.ResourceNameRuleCache, poolCache ctl.DevicePoolCache) *Resolver {
+	return &Resolver{
+		ruleCache: ruleCache,
+		poolCache: poolCache,
+	}
+}
+
+// Rules is the set of ResourceNameRules applied to the devices on a node
+type Rules []*v1beta1.ResourceNameRule
+
+// Rules returns the current rules. A nil Resolver returns no rules, so resource names are generated
+func (r *Resolver) Rules() (Rules, error) {
+	if r == nil {
+		return nil, nil
+	}
+
+	rules, err := r.ruleCache.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error listing resourcenamerules: %w", err)
+	}
+
+	var result Rules
+	for _, rule := range rules {
+		if rule.DeletionTimestamp != nil {
+			continue
+		}
+		result = append(result, rule)
+	}
+	return result, nil
+}
+
+// ResourceName returns the resource name of the most specific rule matching the device, or an empty string
+// if no rule matches. Conflicting rules are rejected by the webhook, if they are present regardless
+// the rule with the lowest name is applied so all nodes agree on the resource name
+func (rs Rules) ResourceName(status *v1beta1.PCIDeviceStatus) string {
+	var selected *v1beta1.ResourceNameRule
+	for _, rule := range rs {
+		if !rule.Spec.Matches(status) {
+			continue
+		}
+		if selected == nil || rule.Spec.Specificity() > selected.Spec.Specificity() {
+			selected = rule
+			continue
+		}
+		if rule.Spec.Specificity() < selected.Spec.Specificity() {
+			continue
+		}
+		if rule.Spec.ResourceName != selected.Spec.ResourceName {
+			logrus.Warnf("resourcenamerules %s and %s both match device %s:%s with different resource names",
+				selected.Name, rule.Name, status.VendorID, status.DeviceID)
+		}
+		if rule.Name < selected.Name {
+			selected = rule
+		}
+	}
+
+	if selected == nil {
+		return ""
+	}
+	return selected.Spec.ResourceName
+}
+
+// Pools is the set of DevicePools applied to the devices on a node
+type Pools []*v1beta1.DevicePool
+
+// Pools returns the current pools. A nil Resolver returns no pools
+func (r *Resolver) Pools() (Pools, error) {
+	if r == nil {
+		return nil, nil
+	}
+
+	pools, err := r.poolCache.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error listing devicepools: %w", err)
+	}
+
+	var result Pools
+	for _, pool := range pools {
+		if pool.DeletionTimestamp != nil {
+			continue
+		}
+		result = append(result, pool)
+	}
+	return result, nil
+}
+
+// Pool returns the pool selecting pd, or nil if pd is not pooled. Overlapping pools are rejected by the webhook,
+// if they are present regardless the pool with the lowest name is applied
+func (ps Pools) Pool(pd *v1beta1.PCIDevice) *v1beta1.DevicePool {
+	var selected *v1beta1.DevicePool
+	for _, pool := range ps {
+		if !pool.Spec.Matches(pd) {
+			continue
+		}
+		if selected != nil {
+			logrus.Warnf("devicepools %s and %s both select pcidevice %s", selected.Name, pool.Name, pd.Name)
+		}
+		if selected == nil || pool.Name < selected.Name {
+			selected = pool
+		}
+	}
+	return selected
+}
@@ -0,0 +1,77 @@
+package resourcenamerule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/pcidevices/pkg/util/fakeclients"
+)
+
+func Test_ResourceName(t *testing.T) {
+	assert := require.New(t)
+	a100 := &v1beta1.ResourceNameRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a100",
+		},
+		Spec: v1beta1.ResourceNameRuleSpec{
+			VendorID:     "10de",
+			DeviceID:     "20b0",
+			ResourceName: "nvidia.com/A100",
+		},
+	}
+	a100Board := &v1beta1.ResourceNameRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a100-sxm4",
+		},
+		Spec: v1beta1.ResourceNameRuleSpec{
+			VendorID:          "10de",
+			DeviceID:          "20b0",
+			SubsystemVendorID: "10de",
+			SubsystemDeviceID: "134f",
+			ResourceName:      "nvidia.com/A100_SXM4",
+		},
+	}
+	client := fake.NewSimpleClientset(a100, a100Board)
+
+	rules, err := NewResolver(fakeclients.ResourceNameRulesCache(client.DevicesV1beta1().ResourceNameRules),
+		fakeclients.DevicePoolsCache(client.DevicesV1beta1().DevicePools)).Rules()
+	assert.NoError(err, "expected no error listing rules")
+	assert.Len(rules, 2)
+
+	assert.Equal("nvidia.com/A100", rules.ResourceName(&v1beta1.PCIDeviceStatus{VendorID: "10de", DeviceID: "20b0"}))
+	assert.Equal("nvidia.com/A100_SXM4", rules.ResourceName(&v1beta1.PCIDeviceStatus{VendorID: "10de", DeviceID: "20B0", SubsystemVendorID: "10de", SubsystemDeviceID: "134f"}),
+		"expected rule matching the subsystem to take precedence")
+	assert.Empty(rules.ResourceName(&v1beta1.PCIDeviceStatus{VendorID: "8086", DeviceID: "1521"}), "expected no resource name for devices without a rule")
+
+	var nilResolver *Resolver
+	rules, err = nilResolver.Rules()
+	assert.NoError(err)
+	assert.Empty(rules.ResourceName(&v1beta1.PCIDeviceStatus{VendorID: "10de", DeviceID: "20b0"}))
+}
+
+func Test_Pool(t *testing.T) {
+	assert := require.New(t)
+	gpus := &v1beta1.DevicePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "gpus",
+		},
+		Spec: v1beta1.DevicePoolSpec{
+			Devices:      []v1beta1.PCIDeviceFilter{{VendorID: "10de", DeviceID: "20b0"}, {VendorID: "10de", DeviceID: "20b5"}},
+			ResourceName: "nvidia.com/DATACENTER_GPU",
+		},
+	}
+	client := fake.NewSimpleClientset(gpus)
+
+	pools, err := NewResolver(fakeclients.ResourceNameRulesCache(client.DevicesV1beta1().ResourceNameRules),
+		fakeclients.DevicePoolsCache(client.DevicesV1beta1().DevicePools)).Pools()
+	assert.NoError(err, "expected no error listing pools")
+
+	a100 := &v1beta1.PCIDevice{Status: v1beta1.PCIDeviceStatus{VendorID: "10de", DeviceID: "20b5", ClassID: "0302"}}
+	assert.Equal(gpus.Name, pools.Pool(a100).Name)
+	nic := &v1beta1.PCIDevice{Status: v1beta1.PCIDeviceStatus{VendorID: "8086", DeviceID: "1521", ClassID: "0200"}}
+	assert.Nil(pools.Pool(nic), "expected devices outside the pool to not be pooled")
+}
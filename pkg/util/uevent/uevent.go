@@ -0,0 +1,130 @@
+package uevent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	ActionAdd    = "add"
+	ActionRemove = "remove"
+	ActionChange = "change"
+	ActionBind   = "bind"
+	ActionUnbind = "unbind"
+
+	SubsystemPCI = "pci"
+
+	// kernel uevents are multicast on group 1 of the NETLINK_KOBJECT_UEVENT family
+	kernelEventGroup = 1
+	receiveBufSize   = 64 * 1024
+	receiveTimeout   = time.Second
+)
+
+// Event is a kobject uevent as broadcast by the kernel
+type Event struct {
+	Action    string
+	DevPath   string
+	Subsystem string
+	Env       map[string]string
+}
+
+// PCIAddress returns the PCI address of the device an event was generated for
+func (e *Event) PCIAddress() string {
+	return e.Env["PCI_SLOT_NAME"]
+}
+
+// Parse decodes a raw kernel uevent of the form "action@devpath\0KEY=VALUE\0..."
+func Parse(msg []byte) (*Event, error) {
+	fields := bytes.Split(msg, []byte{0})
+	header := strings.SplitN(string(fields[0]), "@", 2)
+	if len(header) != 2 {
+		return nil, fmt.Errorf("invalid uevent header %q", fields[0])
+	}
+
+	e := &Event{
+		Env: make(map[string]string),
+	}
+	for _, field := range fields[1:] {
+		kv := strings.SplitN(string(field), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		e.Env[kv[0]] = kv[1]
+	}
+
+	e.Action = e.Env["ACTION"]
+	if e.Action == "" {
+		e.Action = header[0]
+	}
+	e.DevPath = e.Env["DEVPATH"]
+	if e.DevPath == "" {
+		e.DevPath = header[1]
+	}
+	e.Subsystem = e.Env["SUBSYSTEM"]
+	return e, nil
+}
+
+// Listen subscribes to kernel uevents and delivers the ones for subsystem on the returned channel.
+// The channel is closed once ctx is cancelled or the socket can no longer be read
+func Listen(ctx context.Context, subsystem string) (<-chan *Event, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_KOBJECT_UEVENT)
+	if err != nil {
+		return nil, fmt.Errorf("error creating uevent socket: %w", err)
+	}
+
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: kernelEventGroup}); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("error binding uevent socket: %w", err)
+	}
+
+	// netlink sockets do not support shutdown, so a receive timeout is used to periodically check ctx
+	timeout := unix.NsecToTimeval(receiveTimeout.Nanoseconds())
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &timeout); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("error setting uevent socket timeout: %w", err)
+	}
+
+	events := make(chan *Event)
+	go func() {
+		defer close(events)
+		defer unix.Close(fd)
+		buf := make([]byte, receiveBufSize)
+		for {
+			n, _, err := unix.Recvfrom(fd, buf, 0)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				if err == unix.EAGAIN || err == unix.EINTR || err == unix.ENOBUFS {
+					// ENOBUFS means events were dropped, consumers rely on periodic resync to catch up
+					continue
+				}
+				logrus.Errorf("error reading uevent socket: %v", err)
+				return
+			}
+			e, err := Parse(buf[:n])
+			if err != nil {
+				logrus.Debugf("skipping uevent: %v", err)
+				continue
+			}
+
+			if e.Subsystem != subsystem {
+				continue
+			}
+
+			select {
+			case events <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
@@ -0,0 +1,36 @@
+package uevent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Parse(t *testing.T) {
+	assert := require.New(t)
+	raw := strings.Join([]string{
+		"remove@/devices/pci0000:00/0000:00:01.0/0000:01:00.0",
+		"ACTION=remove",
+		"DEVPATH=/devices/pci0000:00/0000:00:01.0/0000:01:00.0",
+		"SUBSYSTEM=pci",
+		"PCI_CLASS=30000",
+		"PCI_ID=10DE:1EB8",
+		"PCI_SLOT_NAME=0000:01:00.0",
+		"SEQNUM=4211",
+	}, "\x00")
+
+	e, err := Parse([]byte(raw))
+	assert.NoError(err, "expected no error parsing uevent")
+	assert.Equal(ActionRemove, e.Action)
+	assert.Equal(SubsystemPCI, e.Subsystem)
+	assert.Equal("/devices/pci0000:00/0000:00:01.0/0000:01:00.0", e.DevPath)
+	assert.Equal("0000:01:00.0", e.PCIAddress())
+	assert.Equal("4211", e.Env["SEQNUM"])
+}
+
+func Test_ParseInvalidHeader(t *testing.T) {
+	assert := require.New(t)
+	_, err := Parse([]byte("libudev\x00garbage"))
+	assert.Error(err, "expected error parsing non kernel uevent")
+}
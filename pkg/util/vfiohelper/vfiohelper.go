@@ -0,0 +1,80 @@
+package vfiohelper
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+)
+
+const (
+	pciDriversPath = "/sys/bus/pci/drivers"
+	modulesPath    = "/lib/modules"
+	osReleasePath  = "/proc/sys/kernel/osrelease"
+)
+
+// AvailableDrivers returns the vfio-pci and vfio variant drivers which are either loaded,
+// or are shipped as modules for the running kernel
+func AvailableDrivers() ([]string, error) {
+	release, err := os.ReadFile(osReleasePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading kernel release: %w", err)
+	}
+
+	return availableDrivers(pciDriversPath, filepath.Join(modulesPath, strings.TrimSpace(string(release)), "modules.dep"))
+}
+
+func availableDrivers(driversPath, modulesDepPath string) ([]string, error) {
+	drivers := make(map[string]struct{})
+	entries, err := os.ReadDir(driversPath)
+	if err != nil {
+		return nil, fmt.Errorf("error listing pci drivers: %w", err)
+	}
+	for _, v := range entries {
+		if v1beta1.IsVFIODriver(v.Name()) {
+			drivers[v.Name()] = struct{}{}
+		}
+	}
+
+	// #nosec G304 path is generated from the running kernel release
+	fd, err := os.Open(modulesDepPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error opening %s: %w", modulesDepPath, err)
+	}
+	if err == nil {
+		defer fd.Close()
+		scanner := bufio.NewScanner(fd)
+		for scanner.Scan() {
+			module, _, _ := strings.Cut(scanner.Text(), ":")
+			if driver := driverForModule(module); v1beta1.IsVFIODriver(driver) {
+				drivers[driver] = struct{}{}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", modulesDepPath, err)
+		}
+	}
+
+	result := make([]string, 0, len(drivers))
+	for driver := range drivers {
+		result = append(result, driver)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// driverForModule converts a module path from modules.dep to the name the driver registers in sysfs
+func driverForModule(module string) string {
+	name := filepath.Base(module)
+	name, _, _ = strings.Cut(name, ".ko")
+	name = strings.ReplaceAll(name, "-", "_")
+	// vfio-pci is the only vfio driver which registers itself with a dash
+	if name == "vfio_pci" {
+		return v1beta1.VFIOPCIDriver
+	}
+	return name
+}
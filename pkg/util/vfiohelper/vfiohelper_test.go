@@ -0,0 +1,34 @@
+package vfiohelper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_availableDrivers(t *testing.T) {
+	assert := require.New(t)
+	root := t.TempDir()
+	driversPath := filepath.Join(root, "drivers")
+	for _, driver := range []string{"vfio-pci", "ixgbe", "nvme"} {
+		assert.NoError(os.MkdirAll(filepath.Join(driversPath, driver), 0755))
+	}
+
+	modulesDep := filepath.Join(root, "modules.dep")
+	assert.NoError(os.WriteFile(modulesDep, []byte(`kernel/drivers/vfio/pci/vfio-pci.ko.zst: kernel/drivers/vfio/pci/vfio-pci-core.ko.zst
+kernel/drivers/vfio/pci/vfio-pci-core.ko.zst: kernel/drivers/vfio/vfio.ko.zst
+kernel/drivers/vfio/pci/mlx5/mlx5-vfio-pci.ko.zst: kernel/drivers/vfio/pci/vfio-pci-core.ko.zst
+kernel/drivers/vfio/pci/nvgrace-gpu/nvgrace-gpu-vfio-pci.ko.zst: kernel/drivers/vfio/pci/vfio-pci-core.ko.zst
+kernel/drivers/net/ethernet/intel/ixgbe/ixgbe.ko.zst:
+`), 0600))
+
+	drivers, err := availableDrivers(driversPath, modulesDep)
+	assert.NoError(err, "expected no error listing vfio drivers")
+	assert.Equal([]string{"mlx5_vfio_pci", "nvgrace_gpu_vfio_pci", "vfio-pci"}, drivers)
+
+	drivers, err = availableDrivers(driversPath, filepath.Join(root, "missing"))
+	assert.NoError(err, "expected missing modules.dep to be ignored")
+	assert.Equal([]string{"vfio-pci"}, drivers)
+}
@@ -0,0 +1,4 @@
+package version
+
+// Version is the version of the pcidevices controller, and is overridden at build time
+var Version = "v0.1.0"
@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"fmt"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+type autoClaimPolicyValidator struct {
+	types.DefaultValidator
+}
+
+func NewAutoClaimPolicyValidator() types.Validator {
+	return &autoClaimPolicyValidator{}
+}
+
+func (a *autoClaimPolicyValidator) Resource() types.Resource {
+	return types.Resource{
+		Names:      []string{"autoclaimpolicies"},
+		Scope:      admissionregv1.ClusterScope,
+		APIGroup:   devicesv1beta1.SchemeGroupVersion.Group,
+		APIVersion: devicesv1beta1.SchemeGroupVersion.Version,
+		ObjectType: &devicesv1beta1.AutoClaimPolicy{},
+		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
+			admissionregv1.Update,
+		},
+	}
+}
+
+func (a *autoClaimPolicyValidator) Create(_ *types.Request, newObj runtime.Object) error {
+	return validateAutoClaimPolicy(newObj.(*devicesv1beta1.AutoClaimPolicy))
+}
+
+func (a *autoClaimPolicyValidator) Update(_ *types.Request, _ runtime.Object, newObj runtime.Object) error {
+	return validateAutoClaimPolicy(newObj.(*devicesv1beta1.AutoClaimPolicy))
+}
+
+func validateAutoClaimPolicy(policy *devicesv1beta1.AutoClaimPolicy) error {
+	if policy.Spec.NodeSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(policy.Spec.NodeSelector); err != nil {
+			return fmt.Errorf("autoclaimpolicy %s has an invalid node selector: %w", policy.Name, err)
+		}
+	}
+
+	// claiming any device could bind devices needed by the host, such as bridges, to vfio
+	if len(policy.Spec.Devices) == 0 {
+		return fmt.Errorf("autoclaimpolicy %s must set device rules", policy.Name)
+	}
+
+	for i, rule := range policy.Spec.Devices {
+		if rule.VendorID == "" && rule.DeviceID == "" && rule.ClassID == "" && rule.Address == "" {
+			return fmt.Errorf("autoclaimpolicy %s device rule %d matches all devices", policy.Name, i)
+		}
+		switch rule.Subsystem {
+		case "", devicesv1beta1.SubsystemPCI:
+		case devicesv1beta1.SubsystemUSB:
+			if rule.ClassID != "" {
+				return fmt.Errorf("autoclaimpolicy %s device rule %d cannot match usb devices by classID", policy.Name, i)
+			}
+		default:
+			return fmt.Errorf("autoclaimpolicy %s device rule %d has unsupported subsystem %s", policy.Name, i, rule.Subsystem)
+		}
+		if err := validateDeviceDiscoveryRule(rule); err != nil {
+			return fmt.Errorf("autoclaimpolicy %s has an invalid device rule %d: %w", policy.Name, i, err)
+		}
+	}
+	return nil
+}
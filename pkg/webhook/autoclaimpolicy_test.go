@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+)
+
+func Test_AutoClaimPolicyValidator(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		spec        devicesv1beta1.AutoClaimPolicySpec
+		expectError bool
+	}{
+		{
+			name: "valid policy",
+			spec: devicesv1beta1.AutoClaimPolicySpec{
+				NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "true"}},
+				Devices: []devicesv1beta1.DeviceDiscoveryRule{
+					{Subsystem: devicesv1beta1.SubsystemPCI, VendorID: "10de", ClassID: "03"},
+					{Subsystem: devicesv1beta1.SubsystemUSB, VendorID: "1050"},
+				},
+			},
+		},
+		{
+			name:        "no device rules",
+			spec:        devicesv1beta1.AutoClaimPolicySpec{},
+			expectError: true,
+		},
+		{
+			name: "rule matching all devices",
+			spec: devicesv1beta1.AutoClaimPolicySpec{
+				Devices: []devicesv1beta1.DeviceDiscoveryRule{{Subsystem: devicesv1beta1.SubsystemPCI}},
+			},
+			expectError: true,
+		},
+		{
+			name: "usb rule with class id",
+			spec: devicesv1beta1.AutoClaimPolicySpec{
+				Devices: []devicesv1beta1.DeviceDiscoveryRule{{Subsystem: devicesv1beta1.SubsystemUSB, ClassID: "03"}},
+			},
+			expectError: true,
+		},
+		{
+			name: "unsupported subsystem",
+			spec: devicesv1beta1.AutoClaimPolicySpec{
+				Devices: []devicesv1beta1.DeviceDiscoveryRule{{Subsystem: devicesv1beta1.SubsystemMIG, VendorID: "10de"}},
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid device id",
+			spec: devicesv1beta1.AutoClaimPolicySpec{
+				Devices: []devicesv1beta1.DeviceDiscoveryRule{{VendorID: "10de", DeviceID: "ga100"}},
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid node selector",
+			spec: devicesv1beta1.AutoClaimPolicySpec{
+				NodeSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "gpu", Operator: "Invalid"}}},
+				Devices:      []devicesv1beta1.DeviceDiscoveryRule{{VendorID: "10de"}},
+			},
+			expectError: true,
+		},
+	}
+
+	validator := NewAutoClaimPolicyValidator()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := &devicesv1beta1.AutoClaimPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "gpus"},
+				Spec:       tc.spec,
+			}
+			err := validator.Create(nil, policy)
+			if tc.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
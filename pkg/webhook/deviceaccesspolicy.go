@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"fmt"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+type deviceAccessPolicyValidator struct {
+	types.DefaultValidator
+}
+
+func NewDeviceAccessPolicyValidator() types.Validator {
+	return &deviceAccessPolicyValidator{}
+}
+
+func (d *deviceAccessPolicyValidator) Resource() types.Resource {
+	return types.Resource{
+		Names:      []string{"deviceaccesspolicies"},
+		Scope:      admissionregv1.ClusterScope,
+		APIGroup:   devicesv1beta1.SchemeGroupVersion.Group,
+		APIVersion: devicesv1beta1.SchemeGroupVersion.Version,
+		ObjectType: &devicesv1beta1.DeviceAccessPolicy{},
+		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
+			admissionregv1.Update,
+		},
+	}
+}
+
+func (d *deviceAccessPolicyValidator) Create(_ *types.Request, newObj runtime.Object) error {
+	return validateDeviceAccessPolicy(newObj.(*devicesv1beta1.DeviceAccessPolicy))
+}
+
+func (d *deviceAccessPolicyValidator) Update(_ *types.Request, _ runtime.Object, newObj runtime.Object) error {
+	return validateDeviceAccessPolicy(newObj.(*devicesv1beta1.DeviceAccessPolicy))
+}
+
+func validateDeviceAccessPolicy(policy *devicesv1beta1.DeviceAccessPolicy) error {
+	if len(policy.Spec.Devices) == 0 {
+		return fmt.Errorf("deviceaccesspolicy %s must set device rules", policy.Name)
+	}
+
+	for i, rule := range policy.Spec.Devices {
+		switch rule.Kind {
+		case devicesv1beta1.DeviceAccessKindPCIDevice, devicesv1beta1.DeviceAccessKindUSBDevice, devicesv1beta1.DeviceAccessKindVGPUDevice:
+		default:
+			return fmt.Errorf("deviceaccesspolicy %s device rule %d has unsupported kind %s", policy.Name, i, rule.Kind)
+		}
+		if rule.ResourceName == "" && len(rule.Names) == 0 {
+			return fmt.Errorf("deviceaccesspolicy %s device rule %d must set a resourceName or names", policy.Name, i)
+		}
+	}
+
+	for i, sa := range policy.Spec.ServiceAccounts {
+		if sa.Namespace == "" || sa.Name == "" {
+			return fmt.Errorf("deviceaccesspolicy %s service account %d must set a namespace and name", policy.Name, i)
+		}
+	}
+	return nil
+}
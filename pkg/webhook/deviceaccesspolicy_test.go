@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+)
+
+func Test_DeviceAccessPolicyValidator(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		spec        devicesv1beta1.DeviceAccessPolicySpec
+		expectError bool
+	}{
+		{
+			name: "valid policy",
+			spec: devicesv1beta1.DeviceAccessPolicySpec{
+				Namespaces:      []string{"tenant-a"},
+				ServiceAccounts: []devicesv1beta1.ServiceAccountReference{{Namespace: "ci", Name: "gpu-tests"}},
+				Devices: []devicesv1beta1.DeviceAccessRule{
+					{Kind: devicesv1beta1.DeviceAccessKindPCIDevice, ResourceName: "nvidia.com/GA100"},
+					{Kind: devicesv1beta1.DeviceAccessKindUSBDevice, Names: []string{"node1-0001-0003"}},
+				},
+			},
+		},
+		{
+			name:        "no device rules",
+			spec:        devicesv1beta1.DeviceAccessPolicySpec{Namespaces: []string{"tenant-a"}},
+			expectError: true,
+		},
+		{
+			name: "unsupported kind",
+			spec: devicesv1beta1.DeviceAccessPolicySpec{
+				Devices: []devicesv1beta1.DeviceAccessRule{{Kind: "NetworkDevice", ResourceName: "nvidia.com/GA100"}},
+			},
+			expectError: true,
+		},
+		{
+			name: "rule selecting all devices of a kind",
+			spec: devicesv1beta1.DeviceAccessPolicySpec{
+				Devices: []devicesv1beta1.DeviceAccessRule{{Kind: devicesv1beta1.DeviceAccessKindVGPUDevice}},
+			},
+			expectError: true,
+		},
+		{
+			name: "service account without namespace",
+			spec: devicesv1beta1.DeviceAccessPolicySpec{
+				ServiceAccounts: []devicesv1beta1.ServiceAccountReference{{Name: "gpu-tests"}},
+				Devices:         []devicesv1beta1.DeviceAccessRule{{Kind: devicesv1beta1.DeviceAccessKindPCIDevice, ResourceName: "nvidia.com/GA100"}},
+			},
+			expectError: true,
+		},
+	}
+
+	validator := NewDeviceAccessPolicyValidator()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := require.New(t)
+			policy := &devicesv1beta1.DeviceAccessPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "policy",
+				},
+				Spec: tc.spec,
+			}
+			err := validator.Create(nil, policy)
+			if tc.expectError {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+type deviceDiscoveryPolicyValidator struct {
+	types.DefaultValidator
+}
+
+func NewDeviceDiscoveryPolicyValidator() types.Validator {
+	return &deviceDiscoveryPolicyValidator{}
+}
+
+func (d *deviceDiscoveryPolicyValidator) Resource() types.Resource {
+	return types.Resource{
+		Names:      []string{"devicediscoverypolicies"},
+		Scope:      admissionregv1.ClusterScope,
+		APIGroup:   devicesv1beta1.SchemeGroupVersion.Group,
+		APIVersion: devicesv1beta1.SchemeGroupVersion.Version,
+		ObjectType: &devicesv1beta1.DeviceDiscoveryPolicy{},
+		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
+			admissionregv1.Update,
+		},
+	}
+}
+
+func (d *deviceDiscoveryPolicyValidator) Create(_ *types.Request, newObj runtime.Object) error {
+	return validateDeviceDiscoveryPolicy(newObj.(*devicesv1beta1.DeviceDiscoveryPolicy))
+}
+
+func (d *deviceDiscoveryPolicyValidator) Update(_ *types.Request, _ runtime.Object, newObj runtime.Object) error {
+	return validateDeviceDiscoveryPolicy(newObj.(*devicesv1beta1.DeviceDiscoveryPolicy))
+}
+
+func validateDeviceDiscoveryPolicy(policy *devicesv1beta1.DeviceDiscoveryPolicy) error {
+	if policy.Spec.NodeSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(policy.Spec.NodeSelector); err != nil {
+			return fmt.Errorf("devicediscoverypolicy %s has an invalid node selector: %w", policy.Name, err)
+		}
+	}
+
+	for i, rule := range policy.Spec.Include {
+		if err := validateDeviceDiscoveryRule(rule); err != nil {
+			return fmt.Errorf("devicediscoverypolicy %s has an invalid include rule %d: %w", policy.Name, i, err)
+		}
+	}
+
+	for i, rule := range policy.Spec.Exclude {
+		if err := validateDeviceDiscoveryRule(rule); err != nil {
+			return fmt.Errorf("devicediscoverypolicy %s has an invalid exclude rule %d: %w", policy.Name, i, err)
+		}
+	}
+	return nil
+}
+
+func validateDeviceDiscoveryRule(rule devicesv1beta1.DeviceDiscoveryRule) error {
+	ids := []struct {
+		name  string
+		value string
+	}{
+		{name: "vendorID", value: rule.VendorID},
+		{name: "deviceID", value: rule.DeviceID},
+		{name: "classID", value: rule.ClassID},
+	}
+	for _, id := range ids {
+		if id.value == "" {
+			continue
+		}
+		if _, err := strconv.ParseUint(id.value, 16, 32); err != nil {
+			return fmt.Errorf("%s %s is not a hex id", id.name, id.value)
+		}
+	}
+
+	if _, err := path.Match(rule.Address, ""); errors.Is(err, path.ErrBadPattern) {
+		return fmt.Errorf("address %s is not a valid pattern", rule.Address)
+	}
+	return nil
+}
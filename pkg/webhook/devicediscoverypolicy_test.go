@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+)
+
+func Test_DeviceDiscoveryPolicyValidator(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		spec        devicesv1beta1.DeviceDiscoveryPolicySpec
+		expectError bool
+	}{
+		{
+			name: "valid policy",
+			spec: devicesv1beta1.DeviceDiscoveryPolicySpec{
+				NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "true"}},
+				Include:      []devicesv1beta1.DeviceDiscoveryRule{{Subsystem: devicesv1beta1.SubsystemPCI, ClassID: "03"}},
+				Exclude:      []devicesv1beta1.DeviceDiscoveryRule{{VendorID: "8086", Address: "0000:00:*"}},
+			},
+		},
+		{
+			name: "invalid node selector",
+			spec: devicesv1beta1.DeviceDiscoveryPolicySpec{
+				NodeSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "gpu", Operator: "Unknown"}}},
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid vendor id",
+			spec: devicesv1beta1.DeviceDiscoveryPolicySpec{
+				Include: []devicesv1beta1.DeviceDiscoveryRule{{VendorID: "intel"}},
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid address pattern",
+			spec: devicesv1beta1.DeviceDiscoveryPolicySpec{
+				Exclude: []devicesv1beta1.DeviceDiscoveryRule{{Address: "0000:[00"}},
+			},
+			expectError: true,
+		},
+	}
+
+	validator := NewDeviceDiscoveryPolicyValidator()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := &devicesv1beta1.DeviceDiscoveryPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+				Spec:       tc.spec,
+			}
+			err := validator.Create(nil, policy)
+			if tc.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
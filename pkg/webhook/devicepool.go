@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"fmt"
+	"strconv"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
+
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+type devicePoolValidator struct {
+	types.DefaultValidator
+	poolCache   v1beta1.DevicePoolCache
+	ruleCache   v1beta1.ResourceNameRuleCache
+	deviceCache v1beta1.PCIDeviceCache
+}
+
+func NewDevicePoolValidator(poolCache v1beta1.DevicePoolCache, ruleCache v1beta1.ResourceNameRuleCache, deviceCache v1beta1.PCIDeviceCache) types.Validator {
+	return &devicePoolValidator{
+		poolCache:   poolCache,
+		ruleCache:   ruleCache,
+		deviceCache: deviceCache,
+	}
+}
+
+func (d *devicePoolValidator) Resource() types.Resource {
+	return types.Resource{
+		Names:      []string{"devicepools"},
+		Scope:      admissionregv1.ClusterScope,
+		APIGroup:   devicesv1beta1.SchemeGroupVersion.Group,
+		APIVersion: devicesv1beta1.SchemeGroupVersion.Version,
+		ObjectType: &devicesv1beta1.DevicePool{},
+		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
+			admissionregv1.Update,
+		},
+	}
+}
+
+func (d *devicePoolValidator) Create(_ *types.Request, newObj runtime.Object) error {
+	return d.validateDevicePool(newObj.(*devicesv1beta1.DevicePool))
+}
+
+func (d *devicePoolValidator) Update(_ *types.Request, _ runtime.Object, newObj runtime.Object) error {
+	return d.validateDevicePool(newObj.(*devicesv1beta1.DevicePool))
+}
+
+func (d *devicePoolValidator) validateDevicePool(pool *devicesv1beta1.DevicePool) error {
+	if pool.Spec.Selector == nil && len(pool.Spec.Devices) == 0 {
+		return fmt.Errorf("devicepool %s must set a selector or device filters", pool.Name)
+	}
+
+	if pool.Spec.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(pool.Spec.Selector); err != nil {
+			return fmt.Errorf("devicepool %s has an invalid selector: %w", pool.Name, err)
+		}
+	}
+
+	for _, v := range pool.Spec.Devices {
+		for _, id := range []string{v.VendorID, v.DeviceID, v.ClassID} {
+			if id == "" {
+				continue
+			}
+			if _, err := strconv.ParseUint(id, 16, 16); err != nil {
+				return fmt.Errorf("devicepool %s device filter id %q is not a hex id", pool.Name, id)
+			}
+		}
+	}
+
+	if err := devicesv1beta1.ValidateResourceName(pool.Spec.ResourceName); err != nil {
+		return fmt.Errorf("devicepool %s: %w", pool.Name, err)
+	}
+
+	// devices outside the pool must not share the resource name, or they would be mixed with the pool
+	rules, err := d.ruleCache.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error listing resourcenamerules: %w", err)
+	}
+	for _, v := range rules {
+		if v.Spec.ResourceName == pool.Spec.ResourceName {
+			return fmt.Errorf("resource name %s is already used by resourcenamerule %s", pool.Spec.ResourceName, v.Name)
+		}
+	}
+
+	pools, err := d.poolCache.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error listing devicepools: %w", err)
+	}
+
+	devices, err := d.deviceCache.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error listing pcidevices: %w", err)
+	}
+
+	for _, v := range pools {
+		if v.Name == pool.Name {
+			continue
+		}
+		if v.Spec.ResourceName == pool.Spec.ResourceName {
+			return fmt.Errorf("resource name %s is already used by devicepool %s", pool.Spec.ResourceName, v.Name)
+		}
+		for _, pd := range devices {
+			if pool.Spec.Matches(pd) && v.Spec.Matches(pd) {
+				return fmt.Errorf("devicepool %s selects pcidevice %s which is already in devicepool %s", pool.Name, pd.Name, v.Name)
+			}
+		}
+	}
+	return nil
+}
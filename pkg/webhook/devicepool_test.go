@@ -0,0 +1,87 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/pcidevices/pkg/util/fakeclients"
+)
+
+func Test_DevicePoolValidator(t *testing.T) {
+	gpuPool := &devicesv1beta1.DevicePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "gpus",
+		},
+		Spec: devicesv1beta1.DevicePoolSpec{
+			Devices:      []devicesv1beta1.PCIDeviceFilter{{ClassID: "03"}},
+			ResourceName: "example.com/GPU",
+		},
+	}
+
+	var testCases = []struct {
+		name        string
+		spec        devicesv1beta1.DevicePoolSpec
+		expectError bool
+	}{
+		{
+			name: "valid pool",
+			spec: devicesv1beta1.DevicePoolSpec{
+				Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{devicesv1beta1.NodeKeyName: "node1"}},
+				Devices:      []devicesv1beta1.PCIDeviceFilter{{VendorID: "8086", ClassID: "02"}},
+				ResourceName: "example.com/NIC",
+			},
+		},
+		{
+			name:        "pool without selector or filters",
+			spec:        devicesv1beta1.DevicePoolSpec{ResourceName: "example.com/ALL"},
+			expectError: true,
+		},
+		{
+			name: "resource name used by a rule",
+			spec: devicesv1beta1.DevicePoolSpec{
+				Devices:      []devicesv1beta1.PCIDeviceFilter{{VendorID: "10de"}},
+				ResourceName: a100Rule.Spec.ResourceName,
+			},
+			expectError: true,
+		},
+		{
+			name: "resource name used by a pool",
+			spec: devicesv1beta1.DevicePoolSpec{
+				Devices:      []devicesv1beta1.PCIDeviceFilter{{VendorID: "10de"}},
+				ResourceName: gpuPool.Spec.ResourceName,
+			},
+			expectError: true,
+		},
+		{
+			name: "device already pooled",
+			spec: devicesv1beta1.DevicePoolSpec{
+				Devices:      []devicesv1beta1.PCIDeviceFilter{{VendorID: "8086", ClassID: "0300"}},
+				ResourceName: "example.com/INTEL_GPU",
+			},
+			expectError: true,
+		},
+	}
+
+	fakeClient := fake.NewSimpleClientset(gpuPool, a100Rule, parentGPU, node1dev1)
+	validator := NewDevicePoolValidator(fakeclients.DevicePoolsCache(fakeClient.DevicesV1beta1().DevicePools),
+		fakeclients.ResourceNameRulesCache(fakeClient.DevicesV1beta1().ResourceNameRules),
+		fakeclients.PCIDevicesCache(fakeClient.DevicesV1beta1().PCIDevices))
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := &devicesv1beta1.DevicePool{
+				ObjectMeta: metav1.ObjectMeta{Name: "pool"},
+				Spec:       tc.spec,
+			}
+			err := validator.Create(nil, pool)
+			if tc.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"fmt"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+type deviceQuotaValidator struct {
+	types.DefaultValidator
+}
+
+func NewDeviceQuotaValidator() types.Validator {
+	return &deviceQuotaValidator{}
+}
+
+func (d *deviceQuotaValidator) Resource() types.Resource {
+	return types.Resource{
+		Names:      []string{"devicequotas"},
+		Scope:      admissionregv1.NamespacedScope,
+		APIGroup:   devicesv1beta1.SchemeGroupVersion.Group,
+		APIVersion: devicesv1beta1.SchemeGroupVersion.Version,
+		ObjectType: &devicesv1beta1.DeviceQuota{},
+		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
+			admissionregv1.Update,
+		},
+	}
+}
+
+func (d *deviceQuotaValidator) Create(_ *types.Request, newObj runtime.Object) error {
+	return validateDeviceQuota(newObj.(*devicesv1beta1.DeviceQuota))
+}
+
+func (d *deviceQuotaValidator) Update(_ *types.Request, _ runtime.Object, newObj runtime.Object) error {
+	return validateDeviceQuota(newObj.(*devicesv1beta1.DeviceQuota))
+}
+
+func validateDeviceQuota(quota *devicesv1beta1.DeviceQuota) error {
+	hard := quota.Spec.Hard
+	if len(hard.ResourceNames) == 0 && len(hard.Classes) == 0 {
+		return fmt.Errorf("devicequota %s/%s must set limits", quota.Namespace, quota.Name)
+	}
+
+	for name, limit := range hard.ResourceNames {
+		if name == "" {
+			return fmt.Errorf("devicequota %s/%s has a limit for an empty resource name", quota.Namespace, quota.Name)
+		}
+		if limit < 0 {
+			return fmt.Errorf("devicequota %s/%s has a negative limit for resource name %s", quota.Namespace, quota.Name, name)
+		}
+	}
+
+	for class, limit := range hard.Classes {
+		switch class {
+		case devicesv1beta1.DeviceClassGPU, devicesv1beta1.DeviceClassNICVF, devicesv1beta1.DeviceClassUSB, devicesv1beta1.DeviceClassVGPU:
+		default:
+			return fmt.Errorf("devicequota %s/%s has a limit for unsupported device class %s", quota.Namespace, quota.Name, class)
+		}
+		if limit < 0 {
+			return fmt.Errorf("devicequota %s/%s has a negative limit for device class %s", quota.Namespace, quota.Name, class)
+		}
+	}
+	return nil
+}
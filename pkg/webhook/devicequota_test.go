@@ -0,0 +1,60 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+)
+
+func Test_DeviceQuotaValidator(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		hard        devicesv1beta1.DeviceQuotaResources
+		expectError bool
+	}{
+		{
+			name: "valid quota",
+			hard: devicesv1beta1.DeviceQuotaResources{
+				ResourceNames: map[string]int{"nvidia.com/GA100": 2},
+				Classes:       map[devicesv1beta1.DeviceClass]int{devicesv1beta1.DeviceClassUSB: 0},
+			},
+		},
+		{
+			name:        "no limits",
+			expectError: true,
+		},
+		{
+			name: "negative limit",
+			hard: devicesv1beta1.DeviceQuotaResources{
+				ResourceNames: map[string]int{"nvidia.com/GA100": -1},
+			},
+			expectError: true,
+		},
+		{
+			name: "unsupported class",
+			hard: devicesv1beta1.DeviceQuotaResources{
+				Classes: map[devicesv1beta1.DeviceClass]int{"FPGA": 1},
+			},
+			expectError: true,
+		},
+	}
+
+	validator := NewDeviceQuotaValidator()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quota := &devicesv1beta1.DeviceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "devices", Namespace: "tenant-a"},
+				Spec:       devicesv1beta1.DeviceQuotaSpec{Hard: tc.hard},
+			}
+			err := validator.Create(nil, quota)
+			if tc.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -2,11 +2,14 @@ package webhook
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	ctlcorev1 "github.com/rancher/wrangler/v3/pkg/generated/controllers/core/v1"
 	"github.com/sirupsen/logrus"
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 
@@ -15,8 +18,12 @@ import (
 
 	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
 	"github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/util/common"
 )
 
+// pciResetMethods are the reset methods which can be written to the reset_method sysfs attribute of a device
+var pciResetMethods = []string{"device_specific", "acpi", "flr", "af_flr", "pm", "bus", "cxl_bus"}
+
 const (
 	VGPUDeviceKind                    = "VGPUDevice"
 	HarvesterPCIDevicesControllerUser = "system:serviceaccount:harvester-system:harvester-pcidevices-controller"
@@ -29,15 +36,19 @@ type pciDeviceClaimValidator struct {
 	usbDeviceClaimCache v1beta1.USBDeviceClaimCache
 	usbDeviceCache      v1beta1.USBDeviceCache
 	nodeCache           ctlcorev1.NodeCache
+	claimCache          v1beta1.PCIDeviceClaimCache
+	nodeDeviceCache     v1beta1.NodeCache
 }
 
-func NewPCIDeviceClaimValidator(deviceCache v1beta1.PCIDeviceCache, kubevirtCache kubevirtctl.VirtualMachineCache, usbDeviceClaimCache v1beta1.USBDeviceClaimCache, usbDeviceCache v1beta1.USBDeviceCache, nodeCache ctlcorev1.NodeCache) types.Validator {
+func NewPCIDeviceClaimValidator(deviceCache v1beta1.PCIDeviceCache, kubevirtCache kubevirtctl.VirtualMachineCache, usbDeviceClaimCache v1beta1.USBDeviceClaimCache, usbDeviceCache v1beta1.USBDeviceCache, nodeCache ctlcorev1.NodeCache, claimCache v1beta1.PCIDeviceClaimCache, nodeDeviceCache v1beta1.NodeCache) types.Validator {
 	return &pciDeviceClaimValidator{
 		deviceCache:         deviceCache,
 		usbDeviceClaimCache: usbDeviceClaimCache,
 		usbDeviceCache:      usbDeviceCache,
 		kubevirtCache:       kubevirtCache,
 		nodeCache:           nodeCache,
+		claimCache:          claimCache,
+		nodeDeviceCache:     nodeDeviceCache,
 	}
 }
 
@@ -50,6 +61,7 @@ func (pdc *pciDeviceClaimValidator) Resource() types.Resource {
 		ObjectType: &devicesv1beta1.PCIDeviceClaim{},
 		OperationTypes: []admissionregv1.OperationType{
 			admissionregv1.Create,
+			admissionregv1.Update,
 			admissionregv1.Delete,
 		},
 	}
@@ -67,6 +79,31 @@ func (pdc *pciDeviceClaimValidator) Create(_ *types.Request, newObj runtime.Obje
 		return fmt.Errorf("pcidevice %s has no iommuGroup available", pciDev.Name)
 	}
 
+	if err := pdc.validateDriver(pciClaimObj); err != nil {
+		logrus.Error(err.Error())
+		return err
+	}
+
+	if err := validateResetPolicy(pciClaimObj.Spec); err != nil {
+		logrus.Error(err.Error())
+		return err
+	}
+
+	if err := validateReleasePolicy(pciClaimObj.Spec); err != nil {
+		logrus.Error(err.Error())
+		return err
+	}
+
+	if err := validateLeaseDuration(pciClaimObj.Spec.LeaseDuration); err != nil {
+		logrus.Error(err.Error())
+		return err
+	}
+
+	if err := validateHostUsage(pciClaimObj, pciDev); err != nil {
+		logrus.Error(err.Error())
+		return err
+	}
+
 	key := fmt.Sprintf("%s-%s", pciDev.Status.NodeName, pciDev.Status.Address)
 	usbClaimDevs, err := pdc.usbDeviceClaimCache.GetByIndex(USBDeviceByAddress, key)
 	if err != nil {
@@ -107,6 +144,181 @@ func (pdc *pciDeviceClaimValidator) Create(_ *types.Request, newObj runtime.Obje
 		logrus.Error(err.Error())
 		return err
 	}
+
+	if err := pdc.validateResourceName(pciClaimObj, pciDev); err != nil {
+		logrus.Error(err.Error())
+		return err
+	}
+
+	return pdc.validateIOMMUGroupClaims(pciClaimObj, pciDev)
+}
+
+// claimResourceName returns the resource name the device plugin for a claimed device is registered with
+func claimResourceName(pciClaimObj *devicesv1beta1.PCIDeviceClaim, pciDev *devicesv1beta1.PCIDevice) string {
+	if val, ok := pciClaimObj.Annotations[devicesv1beta1.PCIDeviceOverrideResourceName]; ok {
+		return val
+	}
+	return pciDev.Status.ResourceName
+}
+
+// validateResourceName blocks claims which would add a device to a device plugin already serving claimed devices
+// with different vendor and device ids on the same node, as kubelet would pool unrelated hardware under one resource.
+// Devices in the same DevicePool may share a device plugin
+func (pdc *pciDeviceClaimValidator) validateResourceName(pciClaimObj *devicesv1beta1.PCIDeviceClaim, pciDev *devicesv1beta1.PCIDevice) error {
+	resourceName := claimResourceName(pciClaimObj, pciDev)
+	devices, err := pdc.deviceCache.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error listing pcidevices: %w", err)
+	}
+
+	for _, v := range devices {
+		if v.Name == pciDev.Name || v.Status.NodeName != pciDev.Status.NodeName || v.Status.PoolingKey() == pciDev.Status.PoolingKey() {
+			continue
+		}
+		claim, err := pdc.claimCache.Get(v.Name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("error looking up pcideviceclaim %s: %w", v.Name, err)
+		}
+		if claimResourceName(claim, v) == resourceName {
+			return fmt.Errorf("pcidevice %s (%s) can't be claimed as resource name %s is already served for claimed pcidevice %s (%s). \n Assign a unique resource name to each device type with a ResourceNameRule",
+				pciDev.Name, pciDev.Status.PoolingKey(), resourceName, v.Name, v.Status.PoolingKey())
+		}
+	}
+	return nil
+}
+
+// validateDriver ensures a claim requesting a vfio variant driver can be served by the node
+func (pdc *pciDeviceClaimValidator) validateDriver(pciClaimObj *devicesv1beta1.PCIDeviceClaim) error {
+	driver := pciClaimObj.Spec.VFIODriver()
+	if driver == devicesv1beta1.VFIOPCIDriver {
+		return nil
+	}
+
+	if !devicesv1beta1.IsVFIODriver(driver) {
+		return fmt.Errorf("driver %s is not vfio-pci or a vfio variant driver", driver)
+	}
+
+	node, err := pdc.nodeDeviceCache.Get(pciClaimObj.Spec.NodeName)
+	if err != nil {
+		return fmt.Errorf("error looking up node %s: %w", pciClaimObj.Spec.NodeName, err)
+	}
+
+	if !node.Status.SupportsVFIODriver(driver) {
+		return fmt.Errorf("driver %s is not available on node %s", driver, pciClaimObj.Spec.NodeName)
+	}
+	return nil
+}
+
+// validateResetPolicy ensures the reset policy and methods are understood by the node agent and the kernel
+func validateResetPolicy(spec devicesv1beta1.PCIDeviceClaimSpec) error {
+	switch spec.ResetPolicy {
+	case "", devicesv1beta1.ResetPolicyNever, devicesv1beta1.ResetPolicyOnRelease, devicesv1beta1.ResetPolicyBeforeAndAfter:
+	default:
+		return fmt.Errorf("unsupported resetPolicy %s", spec.ResetPolicy)
+	}
+
+	for _, method := range spec.ResetMethods {
+		if !slices.Contains(pciResetMethods, method) {
+			return fmt.Errorf("unsupported reset method %s, supported methods are %s", method, strings.Join(pciResetMethods, ", "))
+		}
+	}
+	return nil
+}
+
+// validateReleasePolicy ensures the release policy is understood by the claim release controller
+func validateReleasePolicy(spec devicesv1beta1.PCIDeviceClaimSpec) error {
+	switch spec.ReleasePolicy {
+	case "", devicesv1beta1.ReleasePolicyRetain, devicesv1beta1.ReleasePolicyReleaseOnVMDelete, devicesv1beta1.ReleasePolicyReleaseAfterIdle:
+	default:
+		return fmt.Errorf("unsupported releasePolicy %s", spec.ReleasePolicy)
+	}
+
+	if spec.IdleTimeout == nil {
+		return nil
+	}
+	if spec.ReleasePolicy != devicesv1beta1.ReleasePolicyReleaseAfterIdle {
+		return fmt.Errorf("idleTimeout is only supported with releasePolicy %s", devicesv1beta1.ReleasePolicyReleaseAfterIdle)
+	}
+	if spec.IdleTimeout.Duration <= 0 {
+		return fmt.Errorf("idleTimeout %s must be positive", spec.IdleTimeout.Duration)
+	}
+	return nil
+}
+
+// Update only validates the release policy and lease, which may be changed on existing claims
+func (pdc *pciDeviceClaimValidator) Update(_ *types.Request, _ runtime.Object, newObj runtime.Object) error {
+	pciClaimObj := newObj.(*devicesv1beta1.PCIDeviceClaim)
+	if err := validateReleasePolicy(pciClaimObj.Spec); err != nil {
+		return err
+	}
+	return validateLeaseDuration(pciClaimObj.Spec.LeaseDuration)
+}
+
+// validateLeaseDuration ensures the lease of a claim does not expire as soon as it is created
+func validateLeaseDuration(duration *metav1.Duration) error {
+	if duration != nil && duration.Duration <= 0 {
+		return fmt.Errorf("leaseDuration %s must be positive", duration.Duration)
+	}
+	return nil
+}
+
+// validateHostUsage blocks claims for devices which are in use by the host, such as the disk holding the OS
+// or the NIC carrying host addresses, unless the claim is forced
+func validateHostUsage(pciClaimObj *devicesv1beta1.PCIDeviceClaim, pciDev *devicesv1beta1.PCIDevice) error {
+	if len(pciDev.Status.HostUsage) == 0 {
+		return nil
+	}
+
+	if pciClaimObj.Annotations[devicesv1beta1.ForceClaimAnnotationKey] == "true" {
+		logrus.Warnf("pcideviceclaim %s forces claiming pcidevice %s which is in use by the host", pciClaimObj.Name, pciDev.Name)
+		return nil
+	}
+
+	var used []string
+	for _, v := range pciDev.Status.HostUsage {
+		used = append(used, fmt.Sprintf("%s (%s)", v.Name, v.Type))
+	}
+	return fmt.Errorf("pcidevice %s is in use by the host [%s], so it can't be claimed. \n If you need to claim this device, set the annotation %s=true on the pcideviceclaim",
+		pciDev.Name, strings.Join(used, ", "), devicesv1beta1.ForceClaimAnnotationKey)
+}
+
+// validateIOMMUGroupClaims blocks a claim for an entire iommu group if any other device in the group is already claimed,
+// and blocks claims for devices in a group which is already claimed as a whole
+func (pdc *pciDeviceClaimValidator) validateIOMMUGroupClaims(pciClaimObj *devicesv1beta1.PCIDeviceClaim, pciDev *devicesv1beta1.PCIDevice) error {
+	devices, err := pdc.deviceCache.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	for _, dev := range devices {
+		if dev.Name == pciDev.Name || dev.Status.NodeName != pciDev.Status.NodeName || dev.Status.IOMMUGroup != pciDev.Status.IOMMUGroup {
+			continue
+		}
+
+		claim, err := pdc.claimCache.Get(dev.Name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+
+		if pciClaimObj.Spec.ClaimIOMMUGroup {
+			err = fmt.Errorf("pcidevice %s in iommu group %s is already claimed by %s, so the group of pcidevice %s can't be claimed", dev.Name, pciDev.Status.IOMMUGroup, claim.Name, pciDev.Name)
+			logrus.Error(err.Error())
+			return err
+		}
+
+		if claim.Spec.ClaimIOMMUGroup {
+			err = fmt.Errorf("iommu group %s is already claimed by %s, so pcidevice %s can't be claimed", pciDev.Status.IOMMUGroup, claim.Name, pciDev.Name)
+			logrus.Error(err.Error())
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -126,7 +338,7 @@ func (pdc *pciDeviceClaimValidator) Delete(req *types.Request, oldObj runtime.Ob
 		return nil
 	}
 
-	vms, err := pdc.kubevirtCache.GetByIndex(VMByPCIDeviceClaim, pciClaimObj.Name)
+	vms, err := pdc.kubevirtCache.GetByIndex(common.VMByPCIDeviceClaim, pciClaimObj.Name)
 	if err != nil {
 		return err
 	}
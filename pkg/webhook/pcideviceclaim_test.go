@@ -2,6 +2,7 @@ package webhook
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -119,7 +120,7 @@ func Test_PCIDeviceClaimWithoutIommu(t *testing.T) {
 	pciDeviceCache := fakeclients.PCIDevicesCache(fakeClient.DevicesV1beta1().PCIDevices)
 	usbDeviceClaimCache := fakeclients.USBDeviceClaimsCache(fakeClient.DevicesV1beta1().USBDeviceClaims)
 	usbDeviceCache := fakeclients.USBDeviceCache(fakeClient.DevicesV1beta1().USBDevices)
-	pciValidator := NewPCIDeviceClaimValidator(pciDeviceCache, nil, usbDeviceClaimCache, usbDeviceCache, nodeCache)
+	pciValidator := NewPCIDeviceClaimValidator(pciDeviceCache, nil, usbDeviceClaimCache, usbDeviceCache, nodeCache, fakeclients.PCIDeviceClaimsCache(fakeClient.DevicesV1beta1().PCIDeviceClaims), fakeclients.NodeDevicesCache(fakeClient.DevicesV1beta1().Nodes))
 
 	err := pciValidator.Create(nil, node1NoIommuClaim)
 	assert.Error(err, "expected to find error")
@@ -132,7 +133,7 @@ func Test_PCIDeviceClaimWithIommu(t *testing.T) {
 	usbDeviceClaimCache := fakeclients.USBDeviceClaimsCache(fakeClient.DevicesV1beta1().USBDeviceClaims)
 	pciDeviceCache := fakeclients.PCIDevicesCache(fakeClient.DevicesV1beta1().PCIDevices)
 	usbDeviceCache := fakeclients.USBDeviceCache(fakeClient.DevicesV1beta1().USBDevices)
-	pciValidator := NewPCIDeviceClaimValidator(pciDeviceCache, nil, usbDeviceClaimCache, usbDeviceCache, nodeCache)
+	pciValidator := NewPCIDeviceClaimValidator(pciDeviceCache, nil, usbDeviceClaimCache, usbDeviceCache, nodeCache, fakeclients.PCIDeviceClaimsCache(fakeClient.DevicesV1beta1().PCIDeviceClaims), fakeclients.NodeDevicesCache(fakeClient.DevicesV1beta1().Nodes))
 
 	err := pciValidator.Create(nil, node1dev1Claim)
 	assert.NoError(err, "expected to find no error")
@@ -146,7 +147,7 @@ func Test_CreatePCIDeviceClaimWhenUSBInUse(t *testing.T) {
 	usbDeviceClaimCache := fakeclients.USBDeviceClaimsCache(fakeClient.DevicesV1beta1().USBDeviceClaims)
 	usbDeviceCache := fakeclients.USBDeviceCache(fakeClient.DevicesV1beta1().USBDevices)
 	nodeCache := fakeclients.NodeCache(k8sClient.CoreV1().Nodes)
-	pciValidator := NewPCIDeviceClaimValidator(pciDeviceCache, nil, usbDeviceClaimCache, usbDeviceCache, nodeCache)
+	pciValidator := NewPCIDeviceClaimValidator(pciDeviceCache, nil, usbDeviceClaimCache, usbDeviceCache, nodeCache, fakeclients.PCIDeviceClaimsCache(fakeClient.DevicesV1beta1().PCIDeviceClaims), fakeclients.NodeDevicesCache(fakeClient.DevicesV1beta1().Nodes))
 
 	err := pciValidator.Create(nil, node1dev1Claim)
 	assert.Error(err, "expected to get error")
@@ -161,7 +162,7 @@ func Test_DeletePCIDeviceClaimInUse(t *testing.T) {
 	usbDeviceClaimCache := fakeclients.USBDeviceClaimsCache(fakeClient.DevicesV1beta1().USBDeviceClaims)
 	usbDeviceCache := fakeclients.USBDeviceCache(fakeClient.DevicesV1beta1().USBDevices)
 	vmCache := fakeclients.VirtualMachineCache(harvesterfakeClient.KubevirtV1().VirtualMachines)
-	pciValidator := NewPCIDeviceClaimValidator(pciDeviceCache, vmCache, usbDeviceClaimCache, usbDeviceCache, nodeCache)
+	pciValidator := NewPCIDeviceClaimValidator(pciDeviceCache, vmCache, usbDeviceClaimCache, usbDeviceCache, nodeCache, fakeclients.PCIDeviceClaimsCache(fakeClient.DevicesV1beta1().PCIDeviceClaims), fakeclients.NodeDevicesCache(fakeClient.DevicesV1beta1().Nodes))
 
 	err := pciValidator.Delete(nil, node1dev1Claim)
 	assert.Error(err, "expected to get error")
@@ -176,7 +177,7 @@ func Test_DeletePCIDeviceClaimNotInUse(t *testing.T) {
 	vmCache := fakeclients.VirtualMachineCache(harvesterfakeClient.KubevirtV1().VirtualMachines)
 	usbDeviceClaimCache := fakeclients.USBDeviceClaimsCache(fakeClient.DevicesV1beta1().USBDeviceClaims)
 	usbDeviceCache := fakeclients.USBDeviceCache(fakeClient.DevicesV1beta1().USBDevices)
-	pciValidator := NewPCIDeviceClaimValidator(pciDeviceCache, vmCache, usbDeviceClaimCache, usbDeviceCache, nodeCache)
+	pciValidator := NewPCIDeviceClaimValidator(pciDeviceCache, vmCache, usbDeviceClaimCache, usbDeviceCache, nodeCache, fakeclients.PCIDeviceClaimsCache(fakeClient.DevicesV1beta1().PCIDeviceClaims), fakeclients.NodeDevicesCache(fakeClient.DevicesV1beta1().Nodes))
 
 	err := pciValidator.Delete(nil, node1dev1Claim)
 	assert.NoError(err, "expected no error during validation")
@@ -191,7 +192,7 @@ func Test_DeletePCIDeviceClaimInUseOnDeletedNode(t *testing.T) {
 	usbDeviceClaimCache := fakeclients.USBDeviceClaimsCache(fakeClient.DevicesV1beta1().USBDeviceClaims)
 	usbDeviceCache := fakeclients.USBDeviceCache(fakeClient.DevicesV1beta1().USBDevices)
 	vmCache := fakeclients.VirtualMachineCache(harvesterfakeClient.KubevirtV1().VirtualMachines)
-	pciValidator := NewPCIDeviceClaimValidator(pciDeviceCache, vmCache, usbDeviceClaimCache, usbDeviceCache, nodeCache)
+	pciValidator := NewPCIDeviceClaimValidator(pciDeviceCache, vmCache, usbDeviceClaimCache, usbDeviceCache, nodeCache, fakeclients.PCIDeviceClaimsCache(fakeClient.DevicesV1beta1().PCIDeviceClaims), fakeclients.NodeDevicesCache(fakeClient.DevicesV1beta1().Nodes))
 
 	err := pciValidator.Delete(nil, node2dev1Claim)
 	assert.NoError(err, "expected no error during validation")
@@ -203,7 +204,177 @@ func Test_CreatePCIDeviceClaimWhenVGPUExist(t *testing.T) {
 	pciDeviceCache := fakeclients.PCIDevicesCache(fakeClient.DevicesV1beta1().PCIDevices)
 	usbDeviceClaimCache := fakeclients.USBDeviceClaimsCache(fakeClient.DevicesV1beta1().USBDeviceClaims)
 	usbDeviceCache := fakeclients.USBDeviceCache(fakeClient.DevicesV1beta1().USBDevices)
-	pciValidator := NewPCIDeviceClaimValidator(pciDeviceCache, nil, usbDeviceClaimCache, usbDeviceCache, nodeCache)
+	pciValidator := NewPCIDeviceClaimValidator(pciDeviceCache, nil, usbDeviceClaimCache, usbDeviceCache, nodeCache, fakeclients.PCIDeviceClaimsCache(fakeClient.DevicesV1beta1().PCIDeviceClaims), fakeclients.NodeDevicesCache(fakeClient.DevicesV1beta1().Nodes))
 	err := pciValidator.Create(nil, parentGPUClaim)
 	assert.Error(err, "expected to get error")
 }
+
+func Test_CreatePCIDeviceClaimInClaimedIOMMUGroup(t *testing.T) {
+	assert := require.New(t)
+	groupMember := parentGPU.DeepCopy()
+	groupMember.Name = "node1audio1"
+	groupMember.Status.Address = "0000:04:10.1"
+	groupMember.Status.ClassID = "0403"
+	groupMemberClaim := parentGPUClaim.DeepCopy()
+	groupMemberClaim.Name = groupMember.Name
+	groupMemberClaim.Spec.Address = groupMember.Status.Address
+	groupClaim := parentGPUClaim.DeepCopy()
+	groupClaim.Spec.ClaimIOMMUGroup = true
+
+	fakeClient := fake.NewSimpleClientset(parentGPU, groupMember, groupMemberClaim)
+	pciDeviceCache := fakeclients.PCIDevicesCache(fakeClient.DevicesV1beta1().PCIDevices)
+	usbDeviceClaimCache := fakeclients.USBDeviceClaimsCache(fakeClient.DevicesV1beta1().USBDeviceClaims)
+	usbDeviceCache := fakeclients.USBDeviceCache(fakeClient.DevicesV1beta1().USBDevices)
+	pciValidator := NewPCIDeviceClaimValidator(pciDeviceCache, nil, usbDeviceClaimCache, usbDeviceCache, nodeCache, fakeclients.PCIDeviceClaimsCache(fakeClient.DevicesV1beta1().PCIDeviceClaims), fakeclients.NodeDevicesCache(fakeClient.DevicesV1beta1().Nodes))
+	assert.Error(pciValidator.Create(nil, groupClaim), "expected group claim to be rejected when a group member is claimed")
+	assert.NoError(pciValidator.Create(nil, parentGPUClaim), "expected claim for a single device to be allowed")
+
+	groupMemberClaim.Spec.ClaimIOMMUGroup = true
+	fakeClient = fake.NewSimpleClientset(parentGPU, groupMember, groupMemberClaim)
+	pciValidator = NewPCIDeviceClaimValidator(pciDeviceCache, nil, usbDeviceClaimCache, usbDeviceCache, nodeCache, fakeclients.PCIDeviceClaimsCache(fakeClient.DevicesV1beta1().PCIDeviceClaims), fakeclients.NodeDevicesCache(fakeClient.DevicesV1beta1().Nodes))
+	assert.Error(pciValidator.Create(nil, parentGPUClaim), "expected claim to be rejected when the group is claimed")
+}
+
+func Test_CreatePCIDeviceClaimWithVariantDriver(t *testing.T) {
+	assert := require.New(t)
+	node := &devicesv1beta1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1",
+		},
+		Status: devicesv1beta1.NodeStatus{
+			VFIODrivers: []string{"mlx5_vfio_pci", "vfio-pci"},
+		},
+	}
+	fakeClient := fake.NewSimpleClientset(node1dev1, node)
+	pciDeviceCache := fakeclients.PCIDevicesCache(fakeClient.DevicesV1beta1().PCIDevices)
+	usbDeviceClaimCache := fakeclients.USBDeviceClaimsCache(fakeClient.DevicesV1beta1().USBDeviceClaims)
+	usbDeviceCache := fakeclients.USBDeviceCache(fakeClient.DevicesV1beta1().USBDevices)
+	pciValidator := NewPCIDeviceClaimValidator(pciDeviceCache, nil, usbDeviceClaimCache, usbDeviceCache, nodeCache, fakeclients.PCIDeviceClaimsCache(fakeClient.DevicesV1beta1().PCIDeviceClaims), fakeclients.NodeDevicesCache(fakeClient.DevicesV1beta1().Nodes))
+
+	claim := node1dev1Claim.DeepCopy()
+	claim.Spec.Driver = "mlx5_vfio_pci"
+	assert.NoError(pciValidator.Create(nil, claim), "expected claim for an available variant driver to be allowed")
+
+	claim.Spec.Driver = "hisi_acc_vfio_pci"
+	assert.Error(pciValidator.Create(nil, claim), "expected claim for a missing variant driver to be rejected")
+
+	claim.Spec.Driver = "nouveau"
+	assert.Error(pciValidator.Create(nil, claim), "expected claim for a non vfio driver to be rejected")
+}
+
+func Test_CreatePCIDeviceClaimWithResetPolicy(t *testing.T) {
+	assert := require.New(t)
+	fakeClient := fake.NewSimpleClientset(node1dev1)
+	pciDeviceCache := fakeclients.PCIDevicesCache(fakeClient.DevicesV1beta1().PCIDevices)
+	usbDeviceClaimCache := fakeclients.USBDeviceClaimsCache(fakeClient.DevicesV1beta1().USBDeviceClaims)
+	usbDeviceCache := fakeclients.USBDeviceCache(fakeClient.DevicesV1beta1().USBDevices)
+	pciValidator := NewPCIDeviceClaimValidator(pciDeviceCache, nil, usbDeviceClaimCache, usbDeviceCache, nodeCache, fakeclients.PCIDeviceClaimsCache(fakeClient.DevicesV1beta1().PCIDeviceClaims), fakeclients.NodeDevicesCache(fakeClient.DevicesV1beta1().Nodes))
+
+	claim := node1dev1Claim.DeepCopy()
+	claim.Spec.ResetPolicy = devicesv1beta1.ResetPolicyBeforeAndAfter
+	claim.Spec.ResetMethods = []string{"flr", "bus"}
+	assert.NoError(pciValidator.Create(nil, claim), "expected valid reset policy to be allowed")
+
+	claim.Spec.ResetMethods = []string{"hot"}
+	assert.Error(pciValidator.Create(nil, claim), "expected unknown reset method to be rejected")
+
+	claim.Spec.ResetMethods = nil
+	claim.Spec.ResetPolicy = "Sometimes"
+	assert.Error(pciValidator.Create(nil, claim), "expected unknown reset policy to be rejected")
+}
+
+func Test_CreatePCIDeviceClaimWithReleasePolicy(t *testing.T) {
+	assert := require.New(t)
+	fakeClient := fake.NewSimpleClientset(node1dev1)
+	pciDeviceCache := fakeclients.PCIDevicesCache(fakeClient.DevicesV1beta1().PCIDevices)
+	usbDeviceClaimCache := fakeclients.USBDeviceClaimsCache(fakeClient.DevicesV1beta1().USBDeviceClaims)
+	usbDeviceCache := fakeclients.USBDeviceCache(fakeClient.DevicesV1beta1().USBDevices)
+	pciValidator := NewPCIDeviceClaimValidator(pciDeviceCache, nil, usbDeviceClaimCache, usbDeviceCache, nodeCache, fakeclients.PCIDeviceClaimsCache(fakeClient.DevicesV1beta1().PCIDeviceClaims), fakeclients.NodeDevicesCache(fakeClient.DevicesV1beta1().Nodes))
+
+	claim := node1dev1Claim.DeepCopy()
+	claim.Spec.ReleasePolicy = devicesv1beta1.ReleasePolicyReleaseAfterIdle
+	claim.Spec.IdleTimeout = &metav1.Duration{Duration: 30 * time.Minute}
+	assert.NoError(pciValidator.Create(nil, claim), "expected valid release policy to be allowed")
+
+	claim.Spec.IdleTimeout = &metav1.Duration{}
+	assert.Error(pciValidator.Create(nil, claim), "expected zero idle timeout to be rejected")
+
+	claim.Spec.ReleasePolicy = devicesv1beta1.ReleasePolicyReleaseOnVMDelete
+	claim.Spec.IdleTimeout = &metav1.Duration{Duration: 30 * time.Minute}
+	assert.Error(pciValidator.Create(nil, claim), "expected idle timeout without ReleaseAfterIdle to be rejected")
+
+	claim.Spec.IdleTimeout = nil
+	claim.Spec.ReleasePolicy = "Never"
+	assert.Error(pciValidator.Create(nil, claim), "expected unknown release policy to be rejected")
+}
+
+func Test_PCIDeviceClaimLeaseDuration(t *testing.T) {
+	assert := require.New(t)
+	fakeClient := fake.NewSimpleClientset(node1dev1)
+	pciDeviceCache := fakeclients.PCIDevicesCache(fakeClient.DevicesV1beta1().PCIDevices)
+	usbDeviceClaimCache := fakeclients.USBDeviceClaimsCache(fakeClient.DevicesV1beta1().USBDeviceClaims)
+	usbDeviceCache := fakeclients.USBDeviceCache(fakeClient.DevicesV1beta1().USBDevices)
+	pciValidator := NewPCIDeviceClaimValidator(pciDeviceCache, nil, usbDeviceClaimCache, usbDeviceCache, nodeCache, fakeclients.PCIDeviceClaimsCache(fakeClient.DevicesV1beta1().PCIDeviceClaims), fakeclients.NodeDevicesCache(fakeClient.DevicesV1beta1().Nodes))
+
+	claim := node1dev1Claim.DeepCopy()
+	claim.Spec.LeaseDuration = &metav1.Duration{Duration: 8 * time.Hour}
+	assert.NoError(pciValidator.Create(nil, claim), "expected claim with a lease to be allowed")
+
+	extended := claim.DeepCopy()
+	extended.Spec.LeaseDuration = &metav1.Duration{Duration: 24 * time.Hour}
+	assert.NoError(pciValidator.Update(nil, claim, extended), "expected lease to be extended")
+
+	extended.Spec.LeaseDuration = &metav1.Duration{}
+	assert.Error(pciValidator.Update(nil, claim, extended), "expected zero lease duration to be rejected")
+}
+
+func Test_CreatePCIDeviceClaimForHostDevice(t *testing.T) {
+	assert := require.New(t)
+	hostDisk := node1dev1.DeepCopy()
+	hostDisk.Status.HostUsage = []devicesv1beta1.PCIDeviceHostUsage{
+		{
+			Type:    devicesv1beta1.HostUsageMount,
+			Name:    "nvme0n1p2",
+			Message: "/dev/nvme0n1p2 is mounted at /",
+		},
+	}
+	fakeClient := fake.NewSimpleClientset(hostDisk)
+	pciDeviceCache := fakeclients.PCIDevicesCache(fakeClient.DevicesV1beta1().PCIDevices)
+	usbDeviceClaimCache := fakeclients.USBDeviceClaimsCache(fakeClient.DevicesV1beta1().USBDeviceClaims)
+	usbDeviceCache := fakeclients.USBDeviceCache(fakeClient.DevicesV1beta1().USBDevices)
+	pciValidator := NewPCIDeviceClaimValidator(pciDeviceCache, nil, usbDeviceClaimCache, usbDeviceCache, nodeCache, fakeclients.PCIDeviceClaimsCache(fakeClient.DevicesV1beta1().PCIDeviceClaims), fakeclients.NodeDevicesCache(fakeClient.DevicesV1beta1().Nodes))
+
+	claim := node1dev1Claim.DeepCopy()
+	assert.Error(pciValidator.Create(nil, claim), "expected claim for a device in use by the host to be rejected")
+
+	claim.Annotations = map[string]string{
+		devicesv1beta1.ForceClaimAnnotationKey: "true",
+	}
+	assert.NoError(pciValidator.Create(nil, claim), "expected forced claim to be allowed")
+}
+
+func Test_CreatePCIDeviceClaimWithSharedResourceName(t *testing.T) {
+	assert := require.New(t)
+	otherNIC := node1dev1.DeepCopy()
+	otherNIC.Name = "node1othernic"
+	otherNIC.Status.Address = "0000:05:00.0"
+	otherNIC.Status.DeviceID = "1572"
+	otherNIC.Status.IOMMUGroup = "90"
+	otherNICClaim := node1dev1Claim.DeepCopy()
+	otherNICClaim.Name = otherNIC.Name
+	otherNICClaim.Spec.Address = otherNIC.Status.Address
+
+	fakeClient := fake.NewSimpleClientset(node1dev1, otherNIC, otherNICClaim)
+	pciDeviceCache := fakeclients.PCIDevicesCache(fakeClient.DevicesV1beta1().PCIDevices)
+	usbDeviceClaimCache := fakeclients.USBDeviceClaimsCache(fakeClient.DevicesV1beta1().USBDeviceClaims)
+	usbDeviceCache := fakeclients.USBDeviceCache(fakeClient.DevicesV1beta1().USBDevices)
+	pciValidator := NewPCIDeviceClaimValidator(pciDeviceCache, nil, usbDeviceClaimCache, usbDeviceCache, nodeCache, fakeclients.PCIDeviceClaimsCache(fakeClient.DevicesV1beta1().PCIDeviceClaims), fakeclients.NodeDevicesCache(fakeClient.DevicesV1beta1().Nodes))
+	assert.Error(pciValidator.Create(nil, node1dev1Claim), "expected claim to be rejected when the device plugin would serve different devices")
+
+	// a distinct resource name on the claim keeps the devices in separate device plugins
+	claim := node1dev1Claim.DeepCopy()
+	claim.Annotations = map[string]string{
+		devicesv1beta1.PCIDeviceOverrideResourceName: "fake.com/node1dev1",
+	}
+	assert.NoError(pciValidator.Create(nil, claim), "expected claim with a unique resource name to be allowed")
+}
@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+type pciDeviceClaimSetValidator struct {
+	types.DefaultValidator
+}
+
+func NewPCIDeviceClaimSetValidator() types.Validator {
+	return &pciDeviceClaimSetValidator{}
+}
+
+func (p *pciDeviceClaimSetValidator) Resource() types.Resource {
+	return types.Resource{
+		Names:      []string{"pcideviceclaimsets"},
+		Scope:      admissionregv1.ClusterScope,
+		APIGroup:   devicesv1beta1.SchemeGroupVersion.Group,
+		APIVersion: devicesv1beta1.SchemeGroupVersion.Version,
+		ObjectType: &devicesv1beta1.PCIDeviceClaimSet{},
+		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
+			admissionregv1.Update,
+		},
+	}
+}
+
+func (p *pciDeviceClaimSetValidator) Create(_ *types.Request, newObj runtime.Object) error {
+	return validatePCIDeviceClaimSet(newObj.(*devicesv1beta1.PCIDeviceClaimSet))
+}
+
+// Update only allows the count to be changed, as the controller does not re-evaluate devices which are already claimed
+func (p *pciDeviceClaimSetValidator) Update(_ *types.Request, oldObj runtime.Object, newObj runtime.Object) error {
+	oldSet := oldObj.(*devicesv1beta1.PCIDeviceClaimSet)
+	newSet := newObj.(*devicesv1beta1.PCIDeviceClaimSet)
+	if newSet.DeletionTimestamp != nil {
+		return nil
+	}
+
+	if !reflect.DeepEqual(oldSet.Spec.Devices, newSet.Spec.Devices) ||
+		!reflect.DeepEqual(oldSet.Spec.NodeSelector, newSet.Spec.NodeSelector) ||
+		oldSet.Spec.UserName != newSet.Spec.UserName {
+		return fmt.Errorf("pcideviceclaimset %s only allows the count to be changed", newSet.Name)
+	}
+	return validatePCIDeviceClaimSet(newSet)
+}
+
+func validatePCIDeviceClaimSet(set *devicesv1beta1.PCIDeviceClaimSet) error {
+	if set.Spec.Count < 1 {
+		return fmt.Errorf("pcideviceclaimset %s must claim at least one device", set.Name)
+	}
+
+	// claiming any device could bind devices needed by the host, such as bridges, to vfio
+	if len(set.Spec.Devices) == 0 {
+		return fmt.Errorf("pcideviceclaimset %s must set device filters", set.Name)
+	}
+
+	for _, v := range set.Spec.Devices {
+		if v == (devicesv1beta1.PCIDeviceFilter{}) {
+			return fmt.Errorf("pcideviceclaimset %s has an empty device filter", set.Name)
+		}
+		for _, id := range []string{v.VendorID, v.DeviceID, v.ClassID} {
+			if id == "" {
+				continue
+			}
+			if _, err := strconv.ParseUint(id, 16, 16); err != nil {
+				return fmt.Errorf("pcideviceclaimset %s device filter id %q is not a hex id", set.Name, id)
+			}
+		}
+	}
+
+	if set.Spec.NodeSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(set.Spec.NodeSelector); err != nil {
+			return fmt.Errorf("pcideviceclaimset %s has an invalid node selector: %w", set.Name, err)
+		}
+	}
+	return nil
+}
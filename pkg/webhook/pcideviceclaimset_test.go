@@ -0,0 +1,97 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+)
+
+func Test_PCIDeviceClaimSetValidator(t *testing.T) {
+	validSpec := devicesv1beta1.PCIDeviceClaimSetSpec{
+		Count:        2,
+		Devices:      []devicesv1beta1.PCIDeviceFilter{{VendorID: "10de", DeviceID: "20b5"}},
+		NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "a100"}},
+	}
+
+	var testCases = []struct {
+		name        string
+		spec        func(*devicesv1beta1.PCIDeviceClaimSetSpec)
+		expectError bool
+	}{
+		{
+			name: "valid set",
+			spec: func(*devicesv1beta1.PCIDeviceClaimSetSpec) {},
+		},
+		{
+			name:        "no devices requested",
+			spec:        func(s *devicesv1beta1.PCIDeviceClaimSetSpec) { s.Count = 0 },
+			expectError: true,
+		},
+		{
+			name:        "no device filters",
+			spec:        func(s *devicesv1beta1.PCIDeviceClaimSetSpec) { s.Devices = nil },
+			expectError: true,
+		},
+		{
+			name:        "empty device filter",
+			spec:        func(s *devicesv1beta1.PCIDeviceClaimSetSpec) { s.Devices = []devicesv1beta1.PCIDeviceFilter{{}} },
+			expectError: true,
+		},
+		{
+			name:        "invalid vendor id",
+			spec:        func(s *devicesv1beta1.PCIDeviceClaimSetSpec) { s.Devices[0].VendorID = "nvidia" },
+			expectError: true,
+		},
+		{
+			name: "invalid node selector",
+			spec: func(s *devicesv1beta1.PCIDeviceClaimSetSpec) {
+				s.NodeSelector = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "gpu", Operator: "Invalid"}}}
+			},
+			expectError: true,
+		},
+	}
+
+	validator := NewPCIDeviceClaimSetValidator()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			set := &devicesv1beta1.PCIDeviceClaimSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "a100"},
+				Spec:       *validSpec.DeepCopy(),
+			}
+			tc.spec(&set.Spec)
+			err := validator.Create(nil, set)
+			if tc.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_UpdatePCIDeviceClaimSet(t *testing.T) {
+	assert := require.New(t)
+	oldSet := &devicesv1beta1.PCIDeviceClaimSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "a100"},
+		Spec: devicesv1beta1.PCIDeviceClaimSetSpec{
+			Count:   2,
+			Devices: []devicesv1beta1.PCIDeviceFilter{{VendorID: "10de", DeviceID: "20b5"}},
+		},
+	}
+	validator := NewPCIDeviceClaimSetValidator()
+
+	newSet := oldSet.DeepCopy()
+	newSet.Spec.Count = 4
+	assert.NoError(validator.Update(nil, oldSet, newSet), "expected count to be changed")
+
+	newSet = oldSet.DeepCopy()
+	newSet.Spec.Devices[0].DeviceID = "2236"
+	assert.Error(validator.Update(nil, oldSet, newSet), "expected device filters to be immutable")
+
+	newSet = oldSet.DeepCopy()
+	newSet.Spec.NodeSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "a100"}}
+	assert.Error(validator.Update(nil, oldSet, newSet), "expected node selector to be immutable")
+}
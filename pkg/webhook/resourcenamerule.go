@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
+
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+type resourceNameRuleValidator struct {
+	types.DefaultValidator
+	ruleCache v1beta1.ResourceNameRuleCache
+	poolCache v1beta1.DevicePoolCache
+}
+
+func NewResourceNameRuleValidator(ruleCache v1beta1.ResourceNameRuleCache, poolCache v1beta1.DevicePoolCache) types.Validator {
+	return &resourceNameRuleValidator{
+		ruleCache: ruleCache,
+		poolCache: poolCache,
+	}
+}
+
+func (r *resourceNameRuleValidator) Resource() types.Resource {
+	return types.Resource{
+		Names:      []string{"resourcenamerules"},
+		Scope:      admissionregv1.ClusterScope,
+		APIGroup:   devicesv1beta1.SchemeGroupVersion.Group,
+		APIVersion: devicesv1beta1.SchemeGroupVersion.Version,
+		ObjectType: &devicesv1beta1.ResourceNameRule{},
+		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
+			admissionregv1.Update,
+		},
+	}
+}
+
+func (r *resourceNameRuleValidator) Create(_ *types.Request, newObj runtime.Object) error {
+	return r.validateResourceNameRule(newObj.(*devicesv1beta1.ResourceNameRule))
+}
+
+func (r *resourceNameRuleValidator) Update(_ *types.Request, _ runtime.Object, newObj runtime.Object) error {
+	return r.validateResourceNameRule(newObj.(*devicesv1beta1.ResourceNameRule))
+}
+
+func (r *resourceNameRuleValidator) validateResourceNameRule(rule *devicesv1beta1.ResourceNameRule) error {
+	ids := []struct {
+		name     string
+		value    string
+		required bool
+	}{
+		{name: "vendorId", value: rule.Spec.VendorID, required: true},
+		{name: "deviceId", value: rule.Spec.DeviceID, required: true},
+		{name: "subsystemVendorId", value: rule.Spec.SubsystemVendorID},
+		{name: "subsystemDeviceId", value: rule.Spec.SubsystemDeviceID},
+	}
+	for _, id := range ids {
+		if id.value == "" && !id.required {
+			continue
+		}
+		if _, err := strconv.ParseUint(id.value, 16, 16); err != nil {
+			return fmt.Errorf("resourcenamerule %s %s %q is not a hex id", rule.Name, id.name, id.value)
+		}
+	}
+
+	if err := devicesv1beta1.ValidateResourceName(rule.Spec.ResourceName); err != nil {
+		return fmt.Errorf("resourcenamerule %s: %w", rule.Name, err)
+	}
+
+	rules, err := r.ruleCache.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error listing resourcenamerules: %w", err)
+	}
+
+	// rules matching the same devices would make the resource name ambiguous, and a resource name shared by
+	// different devices would pool unrelated hardware in a single device plugin
+	for _, v := range rules {
+		if v.Name == rule.Name {
+			continue
+		}
+		if v.Spec.SameDevices(rule.Spec) {
+			return fmt.Errorf("resourcenamerule %s matches the same devices as resourcenamerule %s", rule.Name, v.Name)
+		}
+		if v.Spec.ResourceName == rule.Spec.ResourceName &&
+			(!strings.EqualFold(v.Spec.VendorID, rule.Spec.VendorID) || !strings.EqualFold(v.Spec.DeviceID, rule.Spec.DeviceID)) {
+			return fmt.Errorf("resource name %s is already used by resourcenamerule %s for device %s:%s",
+				rule.Spec.ResourceName, v.Name, v.Spec.VendorID, v.Spec.DeviceID)
+		}
+	}
+
+	pools, err := r.poolCache.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error listing devicepools: %w", err)
+	}
+	for _, v := range pools {
+		if v.Spec.ResourceName == rule.Spec.ResourceName {
+			return fmt.Errorf("resource name %s is already used by devicepool %s", rule.Spec.ResourceName, v.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/pcidevices/pkg/util/fakeclients"
+)
+
+var a100Rule = &devicesv1beta1.ResourceNameRule{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: "a100",
+	},
+	Spec: devicesv1beta1.ResourceNameRuleSpec{
+		VendorID:     "10de",
+		DeviceID:     "20b0",
+		ResourceName: "nvidia.com/A100",
+	},
+}
+
+func Test_ResourceNameRuleValidator(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		spec        devicesv1beta1.ResourceNameRuleSpec
+		expectError bool
+	}{
+		{
+			name: "rule for a specific board",
+			spec: devicesv1beta1.ResourceNameRuleSpec{VendorID: "10de", DeviceID: "20b0", SubsystemVendorID: "10de", SubsystemDeviceID: "134f",
+				ResourceName: "nvidia.com/A100_SXM4"},
+		},
+		{
+			name:        "missing device id",
+			spec:        devicesv1beta1.ResourceNameRuleSpec{VendorID: "10de", ResourceName: "nvidia.com/GPU"},
+			expectError: true,
+		},
+		{
+			name:        "resource name without a domain",
+			spec:        devicesv1beta1.ResourceNameRuleSpec{VendorID: "10de", DeviceID: "2236", ResourceName: "A10"},
+			expectError: true,
+		},
+		{
+			name:        "duplicate match",
+			spec:        devicesv1beta1.ResourceNameRuleSpec{VendorID: "10DE", DeviceID: "20B0", ResourceName: "nvidia.com/GPU"},
+			expectError: true,
+		},
+		{
+			name:        "resource name used by different hardware",
+			spec:        devicesv1beta1.ResourceNameRuleSpec{VendorID: "10de", DeviceID: "2236", ResourceName: "nvidia.com/A100"},
+			expectError: true,
+		},
+	}
+
+	client := fake.NewSimpleClientset(a100Rule)
+	validator := NewResourceNameRuleValidator(fakeclients.ResourceNameRulesCache(client.DevicesV1beta1().ResourceNameRules),
+		fakeclients.DevicePoolsCache(client.DevicesV1beta1().DevicePools))
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := &devicesv1beta1.ResourceNameRule{
+				ObjectMeta: metav1.ObjectMeta{Name: "rule"},
+				Spec:       tc.spec,
+			}
+			err := validator.Create(nil, rule)
+			if tc.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	// updating a rule does not collide with itself
+	require.NoError(t, validator.Update(nil, a100Rule, a100Rule))
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/harvester/harvester/pkg/webhook/types"
 
 	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/util/common"
 )
 
 type usbDeviceClaimValidator struct {
@@ -29,6 +30,7 @@ func (udc *usbDeviceClaimValidator) Resource() types.Resource {
 		APIVersion: devicesv1beta1.SchemeGroupVersion.Version,
 		ObjectType: &devicesv1beta1.USBDeviceClaim{},
 		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
 			admissionregv1.Update,
 			admissionregv1.Delete,
 		},
@@ -42,6 +44,15 @@ func NewUSBDeviceClaimValidator(vmCache kubevirtctl.VirtualMachineCache, nodeCac
 	}
 }
 
+func (udc *usbDeviceClaimValidator) Create(_ *types.Request, newObj runtime.Object) error {
+	usbClaimObj := newObj.(*devicesv1beta1.USBDeviceClaim)
+	if err := validateLeaseDuration(usbClaimObj.Spec.LeaseDuration); err != nil {
+		logrus.Error(err.Error())
+		return err
+	}
+	return nil
+}
+
 func (udc *usbDeviceClaimValidator) Delete(_ *types.Request, oldObj runtime.Object) error {
 	usbClaimObj := oldObj.(*devicesv1beta1.USBDeviceClaim)
 
@@ -58,7 +69,7 @@ func (udc *usbDeviceClaimValidator) Delete(_ *types.Request, oldObj runtime.Obje
 		return nil
 	}
 
-	vms, err := udc.vmCache.GetByIndex(VMByUSBDeviceClaim, usbClaimObj.Name)
+	vms, err := udc.vmCache.GetByIndex(common.VMByUSBDeviceClaim, usbClaimObj.Name)
 	if err != nil {
 		return err
 	}
@@ -81,5 +92,10 @@ func (udc *usbDeviceClaimValidator) Update(_ *types.Request, oldObj runtime.Obje
 		return err
 	}
 
+	if err := validateLeaseDuration(newUsbClaimObj.Spec.LeaseDuration); err != nil {
+		logrus.Error(err.Error())
+		return err
+	}
+
 	return nil
 }
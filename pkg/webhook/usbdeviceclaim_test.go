@@ -2,6 +2,7 @@ package webhook
 
 import (
 	"testing"
+	"time"
 
 	harvesterfake "github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
 	"github.com/stretchr/testify/require"
@@ -125,6 +126,23 @@ func Test_UploadUSBDeviceClaimNotInUse(t *testing.T) {
 	assert.Error(err, "expected error when updating the userName")
 }
 
+func Test_USBDeviceClaimLeaseDuration(t *testing.T) {
+	assert := require.New(t)
+	harvesterfakeClient := harvesterfake.NewSimpleClientset()
+	vmCache := fakeclients.VirtualMachineCache(harvesterfakeClient.KubevirtV1().VirtualMachines)
+	usbValidator := NewUSBDeviceClaimValidator(vmCache, nodeCache)
+	claim := usbdeviceclaim1.DeepCopy()
+	claim.Spec.LeaseDuration = &metav1.Duration{Duration: 8 * time.Hour}
+	assert.NoError(usbValidator.Create(nil, claim), "expected claim with a lease to be allowed")
+
+	extended := claim.DeepCopy()
+	extended.Spec.LeaseDuration = &metav1.Duration{Duration: 24 * time.Hour}
+	assert.NoError(usbValidator.Update(nil, claim, extended), "expected lease to be extended")
+
+	extended.Spec.LeaseDuration = &metav1.Duration{Duration: -time.Hour}
+	assert.Error(usbValidator.Update(nil, claim, extended), "expected negative lease duration to be rejected")
+}
+
 func Test_DeleteUSBDeviceClaimInUse(t *testing.T) {
 	assert := require.New(t)
 	harvesterfakeClient := harvesterfake.NewSimpleClientset(vmWithValidUSBDeviceName)
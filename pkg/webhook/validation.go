@@ -18,6 +18,8 @@ func Validation(clients *Clients) (http.Handler, []types.Resource, error) {
 			clients.DeviceFactory.Devices().V1beta1().USBDeviceClaim().Cache(),
 			clients.DeviceFactory.Devices().V1beta1().USBDevice().Cache(),
 			clients.CoreFactory.Core().V1().Node().Cache(),
+			clients.DeviceFactory.Devices().V1beta1().PCIDeviceClaim().Cache(),
+			clients.DeviceFactory.Devices().V1beta1().Node().Cache(),
 		),
 		NewVGPUValidator(clients.KubevirtFactory.Kubevirt().V1().VirtualMachine().Cache(),
 			clients.CoreFactory.Core().V1().Node().Cache()),
@@ -29,9 +31,32 @@ func Validation(clients *Clients) (http.Handler, []types.Resource, error) {
 			clients.DeviceFactory.Devices().V1beta1().USBDevice().Cache(),
 			clients.DeviceFactory.Devices().V1beta1().PCIDevice().Cache(),
 			clients.DeviceFactory.Devices().V1beta1().VGPUDevice().Cache(),
+			clients.DeviceFactory.Devices().V1beta1().DeviceAccessPolicy().Cache(),
+			clients.DeviceFactory.Devices().V1beta1().DeviceQuota().Cache(),
+			clients.KubevirtFactory.Kubevirt().V1().VirtualMachine().Cache(),
+			clients.KubevirtFactory.Kubevirt().V1().VirtualMachineInstance().Cache(),
+		),
+		NewVMIDeviceHostValidation(
+			clients.DeviceFactory.Devices().V1beta1().USBDevice().Cache(),
+			clients.DeviceFactory.Devices().V1beta1().PCIDevice().Cache(),
+			clients.DeviceFactory.Devices().V1beta1().VGPUDevice().Cache(),
+			clients.DeviceFactory.Devices().V1beta1().DeviceAccessPolicy().Cache(),
+			clients.DeviceFactory.Devices().V1beta1().DeviceQuota().Cache(),
+			clients.KubevirtFactory.Kubevirt().V1().VirtualMachine().Cache(),
+			clients.KubevirtFactory.Kubevirt().V1().VirtualMachineInstance().Cache(),
 		),
 		NewUSBDeviceValidator(clients.CoreFactory.Core().V1().Node().Cache()),
 		NewMIGConfigurationValidator(clients.DeviceFactory.Devices().V1beta1().VGPUDevice().Cache()),
+		NewDeviceDiscoveryPolicyValidator(),
+		NewResourceNameRuleValidator(clients.DeviceFactory.Devices().V1beta1().ResourceNameRule().Cache(),
+			clients.DeviceFactory.Devices().V1beta1().DevicePool().Cache()),
+		NewDevicePoolValidator(clients.DeviceFactory.Devices().V1beta1().DevicePool().Cache(),
+			clients.DeviceFactory.Devices().V1beta1().ResourceNameRule().Cache(),
+			clients.DeviceFactory.Devices().V1beta1().PCIDevice().Cache()),
+		NewPCIDeviceClaimSetValidator(),
+		NewAutoClaimPolicyValidator(),
+		NewDeviceAccessPolicyValidator(),
+		NewDeviceQuotaValidator(),
 	}
 
 	router := webhook.NewRouter()
@@ -14,6 +14,7 @@ import (
 	"github.com/harvester/harvester/pkg/webhook/types"
 
 	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/util/common"
 )
 
 type vgpuValidator struct {
@@ -83,7 +84,7 @@ func (v *vgpuValidator) Delete(_ *types.Request, obj runtime.Object) error {
 }
 
 func checkVGPUUsage(kc kubevirtctl.VirtualMachineCache, deviceName string) error {
-	objs, err := kc.GetByIndex(VMByPCIDeviceClaim, deviceName)
+	objs, err := kc.GetByIndex(common.VMByPCIDeviceClaim, deviceName)
 	if err != nil {
 		logrus.Errorf("error fetching VMs from cache: %v", err)
 		return err
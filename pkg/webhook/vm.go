@@ -164,7 +164,7 @@ func (vm *vmPCIMutator) generateHostDevicesPatch(vmObj *kubevirtv1.VirtualMachin
 	}
 
 	for _, v := range devicesNeeded {
-		if err := vm.findAndCreateClaim(v.device, v.owner); err != nil {
+		if err := vm.findAndCreateClaim(v.device, v.owner, vmObj); err != nil {
 			return nil, fmt.Errorf("error during findAndCreateClaim: %v", err)
 		}
 	}
@@ -211,11 +211,11 @@ func identifyAdditionalPCIDevices(pciDevicesInVM []string, possiblePCIDeviceRequ
 	return additionalDevicesNeeded
 }
 
-func (vm *vmPCIMutator) findAndCreateClaim(dev *devicesv1beta1.PCIDevice, owner string) error {
+func (vm *vmPCIMutator) findAndCreateClaim(dev *devicesv1beta1.PCIDevice, owner string, vmObj *kubevirtv1.VirtualMachine) error {
 	_, err := vm.pciClaimCache.Get(dev.Name)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			newClaim := generatePCIDeviceClaim(dev, owner)
+			newClaim := generatePCIDeviceClaim(dev, owner, vmObj)
 			_, createErr := vm.pciClaimClient.Create(newClaim)
 			return createErr
 		}
@@ -226,10 +226,14 @@ func (vm *vmPCIMutator) findAndCreateClaim(dev *devicesv1beta1.PCIDevice, owner
 	return nil
 }
 
-func generatePCIDeviceClaim(dev *devicesv1beta1.PCIDevice, owner string) *devicesv1beta1.PCIDeviceClaim {
+func generatePCIDeviceClaim(dev *devicesv1beta1.PCIDevice, owner string, vmObj *kubevirtv1.VirtualMachine) *devicesv1beta1.PCIDeviceClaim {
 	return &devicesv1beta1.PCIDeviceClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: dev.Name,
+			// the vm may never be created or started, so the claim release controller needs to know which vm to wait for
+			Annotations: map[string]string{
+				devicesv1beta1.PCIDeviceClaimCreatedForVMKey: fmt.Sprintf("%s/%s", vmObj.Namespace, vmObj.Name),
+			},
 			OwnerReferences: []metav1.OwnerReference{
 				{
 					APIVersion: dev.APIVersion,
@@ -243,6 +247,8 @@ func generatePCIDeviceClaim(dev *devicesv1beta1.PCIDevice, owner string) *device
 			NodeName: dev.Status.NodeName,
 			Address:  dev.Status.Address,
 			UserName: owner,
+			// the claim is only needed by the VM it was created for
+			ReleasePolicy: devicesv1beta1.ReleasePolicyReleaseOnVMDelete,
 		},
 	}
 }
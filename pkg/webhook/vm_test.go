@@ -295,6 +295,7 @@ func Test_VMWithIommuDevices(t *testing.T) {
 	newPCIDeviceClaimObj, err := vmPCIMutator.pciClaimCache.Get(node1dev2.Name)
 	assert.NoError(err, "expect no error while looking up claim for node1dev2")
 	assert.Equal(node1dev1Claim.Spec.UserName, newPCIDeviceClaimObj.Spec.UserName, "expected username to be copied")
+	assert.Equal("default/vm-with-iommu-devices", newPCIDeviceClaimObj.Annotations[devicesv1beta1.PCIDeviceClaimCreatedForVMKey], "expected vm to be recorded")
 }
 
 func Test_VMWithAllIommuDevices(t *testing.T) {
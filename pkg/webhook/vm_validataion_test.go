@@ -2,10 +2,18 @@ package webhook
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
+	harvesterfake "github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/webhook/types"
+	"github.com/rancher/wrangler/v3/pkg/webhook"
 	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 	kubevirtv1 "kubevirt.io/api/core/v1"
 
 	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
@@ -179,7 +187,12 @@ func Test_CreateVM(t *testing.T) {
 		pciCache := fakeclients.PCIDevicesCache(fakeClient.DevicesV1beta1().PCIDevices)
 		vGPUCache := fakeclients.VGPUDeviceCache(fakeClient.DevicesV1beta1().VGPUDevices)
 
-		validator := NewDeviceHostValidation(usbCache, pciCache, vGPUCache)
+		policyCache := fakeclients.DeviceAccessPoliciesCache(fakeClient.DevicesV1beta1().DeviceAccessPolicies)
+		quotaCache := fakeclients.DeviceQuotasCache(fakeClient.DevicesV1beta1().DeviceQuotas)
+		harvesterClient := harvesterfake.NewSimpleClientset()
+		vmCache := fakeclients.VirtualMachineCache(harvesterClient.KubevirtV1().VirtualMachines)
+		vmiCache := fakeclients.VirtualMachineInstanceCache(harvesterClient.KubevirtV1().VirtualMachineInstances)
+		validator := NewDeviceHostValidation(usbCache, pciCache, vGPUCache, policyCache, quotaCache, vmCache, vmiCache)
 		err := validator.Create(nil, in.vm)
 
 		assert.Equal(t, tc.err, err, tc.name)
@@ -275,9 +288,373 @@ func Test_UpdateVM(t *testing.T) {
 		pciCache := fakeclients.PCIDevicesCache(fakeClient.DevicesV1beta1().PCIDevices)
 		vGPUCache := fakeclients.VGPUDeviceCache(fakeClient.DevicesV1beta1().VGPUDevices)
 
-		validator := NewDeviceHostValidation(usbCache, pciCache, vGPUCache)
+		policyCache := fakeclients.DeviceAccessPoliciesCache(fakeClient.DevicesV1beta1().DeviceAccessPolicies)
+		quotaCache := fakeclients.DeviceQuotasCache(fakeClient.DevicesV1beta1().DeviceQuotas)
+		harvesterClient := harvesterfake.NewSimpleClientset()
+		vmCache := fakeclients.VirtualMachineCache(harvesterClient.KubevirtV1().VirtualMachines)
+		vmiCache := fakeclients.VirtualMachineInstanceCache(harvesterClient.KubevirtV1().VirtualMachineInstances)
+		validator := NewDeviceHostValidation(usbCache, pciCache, vGPUCache, policyCache, quotaCache, vmCache, vmiCache)
 		err := validator.Update(nil, nil, in.vm)
 
 		assert.Equal(t, tc.err, err, tc.name)
 	}
 }
+
+func Test_VMDeviceAccessPolicy(t *testing.T) {
+	tenantGPU := &devicesv1beta1.PCIDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1-000081000",
+		},
+		Status: devicesv1beta1.PCIDeviceStatus{
+			Address:      "0000:81:00.0",
+			NodeName:     "node1",
+			ResourceName: "nvidia.com/GA100",
+			VendorID:     "10de",
+		},
+	}
+	tenantPolicy := &devicesv1beta1.DeviceAccessPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "tenant-a-gpus",
+		},
+		Spec: devicesv1beta1.DeviceAccessPolicySpec{
+			Namespaces:      []string{"tenant-a"},
+			ServiceAccounts: []devicesv1beta1.ServiceAccountReference{{Namespace: "ci", Name: "gpu-tests"}},
+			Devices: []devicesv1beta1.DeviceAccessRule{
+				{Kind: devicesv1beta1.DeviceAccessKindPCIDevice, ResourceName: tenantGPU.Status.ResourceName},
+				{Kind: devicesv1beta1.DeviceAccessKindVGPUDevice, Names: []string{vgpudeviceinnode1.Name}},
+			},
+		},
+	}
+
+	newVM := func(namespace string, hostDevices []kubevirtv1.HostDevice, gpus []kubevirtv1.GPU) *kubevirtv1.VirtualMachine {
+		return &kubevirtv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vm1",
+				Namespace: namespace,
+			},
+			Spec: kubevirtv1.VirtualMachineSpec{
+				Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+					Spec: kubevirtv1.VirtualMachineInstanceSpec{
+						Domain: kubevirtv1.DomainSpec{
+							Devices: kubevirtv1.Devices{
+								HostDevices: hostDevices,
+								GPUs:        gpus,
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	controller := true
+	newVMI := func(namespace string, hostDevices []kubevirtv1.HostDevice, owners ...metav1.OwnerReference) *kubevirtv1.VirtualMachineInstance {
+		vm := newVM(namespace, hostDevices, nil)
+		return &kubevirtv1.VirtualMachineInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            vm.Name,
+				Namespace:       vm.Namespace,
+				OwnerReferences: owners,
+			},
+			Spec: vm.Spec.Template.Spec,
+		}
+	}
+	gpuHostDevices := []kubevirtv1.HostDevice{{Name: tenantGPU.Name, DeviceName: tenantGPU.Status.ResourceName}}
+	nicHostDevices := []kubevirtv1.HostDevice{{Name: pcideviceinnode1.Name, DeviceName: pcideviceinnode1.Status.ResourceName}}
+	vGPUs := []kubevirtv1.GPU{{Name: "vgpu1", DeviceName: "nvidia.com/NVIDIA_A2-2Q"}}
+	// vms which may own vmis in the tenant-b namespace
+	ownerVM := newVM("tenant-b", gpuHostDevices, nil)
+	ownerVM.Name, ownerVM.UID = "gpu-owner", "gpu-owner-uid"
+	nicOwnerVM := newVM("tenant-b", nicHostDevices, nil)
+	nicOwnerVM.Name, nicOwnerVM.UID = "nic-owner", "nic-owner-uid"
+	ownedBy := func(name string, uid k8stypes.UID) metav1.OwnerReference {
+		return metav1.OwnerReference{
+			APIVersion: kubevirtv1.VirtualMachineGroupVersionKind.GroupVersion().String(),
+			Kind:       kubevirtv1.VirtualMachineGroupVersionKind.Kind,
+			Name:       name,
+			UID:        uid,
+			Controller: &controller,
+		}
+	}
+
+	testcases := []struct {
+		name        string
+		vm          *kubevirtv1.VirtualMachine
+		oldVM       *kubevirtv1.VirtualMachine
+		vmi         *kubevirtv1.VirtualMachineInstance
+		username    string
+		expectError bool
+	}{
+		{
+			name: "granted namespace",
+			vm:   newVM("tenant-a", gpuHostDevices, vGPUs),
+		},
+		{
+			name:        "other namespace",
+			vm:          newVM("tenant-b", gpuHostDevices, nil),
+			expectError: true,
+		},
+		{
+			name:        "other namespace using vgpu",
+			vm:          newVM("tenant-b", nil, vGPUs),
+			expectError: true,
+		},
+		{
+			name:     "granted service account",
+			vm:       newVM("tenant-b", gpuHostDevices, nil),
+			username: "system:serviceaccount:ci:gpu-tests",
+		},
+		{
+			name: "device not governed by any policy",
+			vm:   newVM("tenant-b", nicHostDevices, nil),
+		},
+		{
+			name:  "device already used before the policy was added",
+			vm:    newVM("tenant-b", gpuHostDevices, nil),
+			oldVM: newVM("tenant-b", gpuHostDevices, nil),
+		},
+		{
+			name:        "device added to existing vm",
+			vm:          newVM("tenant-b", append(nicHostDevices, gpuHostDevices...), nil),
+			oldVM:       newVM("tenant-b", nicHostDevices, nil),
+			expectError: true,
+		},
+		{
+			name: "vmi in granted namespace",
+			vmi:  newVMI("tenant-a", gpuHostDevices),
+		},
+		{
+			name:        "vmi in other namespace",
+			vmi:         newVMI("tenant-b", gpuHostDevices),
+			expectError: true,
+		},
+		{
+			name: "vmi controlled by a vm",
+			vmi:  newVMI("tenant-b", gpuHostDevices, ownedBy(ownerVM.Name, ownerVM.UID)),
+		},
+		{
+			name:        "vmi with a forged owner uid",
+			vmi:         newVMI("tenant-b", gpuHostDevices, ownedBy(ownerVM.Name, "forged-uid")),
+			expectError: true,
+		},
+		{
+			name:        "vmi owned by a missing vm",
+			vmi:         newVMI("tenant-b", gpuHostDevices, ownedBy("missing", "missing-uid")),
+			expectError: true,
+		},
+		{
+			name:        "vmi requesting other devices than its vm",
+			vmi:         newVMI("tenant-b", gpuHostDevices, ownedBy(nicOwnerVM.Name, nicOwnerVM.UID)),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewSimpleClientset(tenantGPU, pcideviceinnode1, vgpudeviceinnode1, tenantPolicy)
+			harvesterClient := harvesterfake.NewSimpleClientset(ownerVM, nicOwnerVM)
+			validator := NewDeviceHostValidation(fakeclients.USBDeviceCache(fakeClient.DevicesV1beta1().USBDevices),
+				fakeclients.PCIDevicesCache(fakeClient.DevicesV1beta1().PCIDevices),
+				fakeclients.VGPUDeviceCache(fakeClient.DevicesV1beta1().VGPUDevices),
+				fakeclients.DeviceAccessPoliciesCache(fakeClient.DevicesV1beta1().DeviceAccessPolicies),
+				fakeclients.DeviceQuotasCache(fakeClient.DevicesV1beta1().DeviceQuotas),
+				fakeclients.VirtualMachineCache(harvesterClient.KubevirtV1().VirtualMachines),
+				fakeclients.VirtualMachineInstanceCache(harvesterClient.KubevirtV1().VirtualMachineInstances))
+			req := types.NewRequest(&webhook.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{UserInfo: authenticationv1.UserInfo{Username: tc.username}},
+			}, nil)
+
+			var err error
+			switch {
+			case tc.vmi != nil:
+				err = NewVMIDeviceHostValidation(fakeclients.USBDeviceCache(fakeClient.DevicesV1beta1().USBDevices),
+					fakeclients.PCIDevicesCache(fakeClient.DevicesV1beta1().PCIDevices),
+					fakeclients.VGPUDeviceCache(fakeClient.DevicesV1beta1().VGPUDevices),
+					fakeclients.DeviceAccessPoliciesCache(fakeClient.DevicesV1beta1().DeviceAccessPolicies),
+					fakeclients.DeviceQuotasCache(fakeClient.DevicesV1beta1().DeviceQuotas),
+					fakeclients.VirtualMachineCache(harvesterClient.KubevirtV1().VirtualMachines),
+					fakeclients.VirtualMachineInstanceCache(harvesterClient.KubevirtV1().VirtualMachineInstances)).Create(req, tc.vmi)
+			case tc.oldVM != nil:
+				err = validator.Update(req, tc.oldVM, tc.vm)
+			default:
+				err = validator.Create(req, tc.vm)
+			}
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_VMDeviceQuota(t *testing.T) {
+	gpu := &devicesv1beta1.PCIDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1-000081000",
+		},
+		Status: devicesv1beta1.PCIDeviceStatus{
+			Address:      "0000:81:00.0",
+			NodeName:     "node1",
+			ClassID:      "0302",
+			ResourceName: "nvidia.com/GA100",
+		},
+	}
+	quota := &devicesv1beta1.DeviceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "gpus",
+			Namespace: "tenant-a",
+		},
+		Spec: devicesv1beta1.DeviceQuotaSpec{
+			Hard: devicesv1beta1.DeviceQuotaResources{
+				Classes: map[devicesv1beta1.DeviceClass]int{devicesv1beta1.DeviceClassGPU: 2},
+			},
+		},
+	}
+
+	newVM := func(name string, gpus int) *kubevirtv1.VirtualMachine {
+		vm := &kubevirtv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "tenant-a",
+				UID:       k8stypes.UID(name + "-uid"),
+			},
+			Spec: kubevirtv1.VirtualMachineSpec{
+				Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{},
+			},
+		}
+		for i := 0; i < gpus; i++ {
+			vm.Spec.Template.Spec.Domain.Devices.HostDevices = append(vm.Spec.Template.Spec.Domain.Devices.HostDevices,
+				kubevirtv1.HostDevice{Name: fmt.Sprintf("gpu%d", i), DeviceName: gpu.Status.ResourceName})
+		}
+		return vm
+	}
+	newVMI := func(name string, gpus int, owners ...metav1.OwnerReference) *kubevirtv1.VirtualMachineInstance {
+		vm := newVM(name, gpus)
+		return &kubevirtv1.VirtualMachineInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            vm.Name,
+				Namespace:       vm.Namespace,
+				OwnerReferences: owners,
+			},
+			Spec: vm.Spec.Template.Spec,
+		}
+	}
+	controller := true
+	ownedBy := func(name string, uid k8stypes.UID) metav1.OwnerReference {
+		return metav1.OwnerReference{
+			APIVersion: kubevirtv1.VirtualMachineGroupVersionKind.GroupVersion().String(),
+			Kind:       kubevirtv1.VirtualMachineGroupVersionKind.Kind,
+			Name:       name,
+			UID:        uid,
+			Controller: &controller,
+		}
+	}
+
+	testcases := []struct {
+		name        string
+		existing    []runtime.Object
+		vm          *kubevirtv1.VirtualMachine
+		oldVM       *kubevirtv1.VirtualMachine
+		vmi         *kubevirtv1.VirtualMachineInstance
+		expectError bool
+	}{
+		{
+			name:     "within quota",
+			existing: []runtime.Object{newVM("vm1", 1)},
+			vm:       newVM("vm2", 1),
+		},
+		{
+			name:        "exceeds quota",
+			existing:    []runtime.Object{newVM("vm1", 1)},
+			vm:          newVM("vm2", 2),
+			expectError: true,
+		},
+		{
+			name:     "update counts the vm once",
+			existing: []runtime.Object{newVM("vm1", 1)},
+			vm:       newVM("vm1", 2),
+			oldVM:    newVM("vm1", 1),
+		},
+		{
+			name:     "update of a vm in a namespace over quota",
+			existing: []runtime.Object{newVM("vm1", 2), newVM("vm2", 1)},
+			vm:       newVM("vm2", 1),
+			oldVM:    newVM("vm2", 1),
+		},
+		{
+			name:        "standalone vmi counted against quota",
+			existing:    []runtime.Object{newVM("vm1", 1), newVMI("vmi1", 1)},
+			vm:          newVM("vm2", 1),
+			expectError: true,
+		},
+		{
+			name:     "vmi of a vm counted once",
+			existing: []runtime.Object{newVM("vm1", 1), newVMI("vm1", 1, ownedBy("vm1", "vm1-uid"))},
+			vm:       newVM("vm2", 1),
+		},
+		{
+			name:     "update of a running vm counts its vmi once",
+			existing: []runtime.Object{newVM("vm1", 1), newVMI("vm1", 1, ownedBy("vm1", "vm1-uid"))},
+			vm:       newVM("vm1", 2),
+			oldVM:    newVM("vm1", 1),
+		},
+		{
+			name:        "vmi with a forged owner uid counted against quota",
+			existing:    []runtime.Object{newVM("vm1", 1), newVMI("vm1", 1, ownedBy("vm1", "forged-uid"))},
+			vm:          newVM("vm2", 1),
+			expectError: true,
+		},
+		{
+			name:        "vmi with a forged owner exceeds quota",
+			existing:    []runtime.Object{newVM("vm1", 2)},
+			vmi:         newVMI("vmi1", 1, ownedBy("vm1", "vm1-uid")),
+			expectError: true,
+		},
+		{
+			name:        "standalone vmi exceeds quota",
+			existing:    []runtime.Object{newVM("vm1", 2)},
+			vmi:         newVMI("vmi1", 1),
+			expectError: true,
+		},
+		{
+			name:     "standalone vmi within quota",
+			existing: []runtime.Object{newVM("vm1", 1)},
+			vmi:      newVMI("vmi1", 1),
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewSimpleClientset(gpu, quota)
+			harvesterClient := harvesterfake.NewSimpleClientset(tc.existing...)
+			validator := NewDeviceHostValidation(fakeclients.USBDeviceCache(fakeClient.DevicesV1beta1().USBDevices),
+				fakeclients.PCIDevicesCache(fakeClient.DevicesV1beta1().PCIDevices),
+				fakeclients.VGPUDeviceCache(fakeClient.DevicesV1beta1().VGPUDevices),
+				fakeclients.DeviceAccessPoliciesCache(fakeClient.DevicesV1beta1().DeviceAccessPolicies),
+				fakeclients.DeviceQuotasCache(fakeClient.DevicesV1beta1().DeviceQuotas),
+				fakeclients.VirtualMachineCache(harvesterClient.KubevirtV1().VirtualMachines),
+				fakeclients.VirtualMachineInstanceCache(harvesterClient.KubevirtV1().VirtualMachineInstances))
+
+			var err error
+			switch {
+			case tc.vmi != nil:
+				err = NewVMIDeviceHostValidation(fakeclients.USBDeviceCache(fakeClient.DevicesV1beta1().USBDevices),
+					fakeclients.PCIDevicesCache(fakeClient.DevicesV1beta1().PCIDevices),
+					fakeclients.VGPUDeviceCache(fakeClient.DevicesV1beta1().VGPUDevices),
+					fakeclients.DeviceAccessPoliciesCache(fakeClient.DevicesV1beta1().DeviceAccessPolicies),
+					fakeclients.DeviceQuotasCache(fakeClient.DevicesV1beta1().DeviceQuotas),
+					fakeclients.VirtualMachineCache(harvesterClient.KubevirtV1().VirtualMachines),
+					fakeclients.VirtualMachineInstanceCache(harvesterClient.KubevirtV1().VirtualMachineInstances)).Create(nil, tc.vmi)
+			case tc.oldVM != nil:
+				err = validator.Update(nil, tc.oldVM, tc.vm)
+			default:
+				err = validator.Create(nil, tc.vm)
+			}
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -2,23 +2,36 @@ package webhook
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
+	kubevirtctl "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
 	"github.com/harvester/harvester/pkg/webhook/types"
 	"github.com/sirupsen/logrus"
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	kubevirtv1 "kubevirt.io/api/core/v1"
 
+	devicesv1beta1 "github.com/harvester/pcidevices/pkg/apis/devices.harvesterhci.io/v1beta1"
 	"github.com/harvester/pcidevices/pkg/generated/controllers/devices.harvesterhci.io/v1beta1"
+	"github.com/harvester/pcidevices/pkg/util/common"
+	"github.com/harvester/pcidevices/pkg/util/devicequota"
 )
 
 type vmDeviceHostValidator struct {
 	types.DefaultValidator
 
-	usbCache  v1beta1.USBDeviceCache
-	pciCache  v1beta1.PCIDeviceCache
-	vgpuCache v1beta1.VGPUDeviceCache
+	usbCache    v1beta1.USBDeviceCache
+	pciCache    v1beta1.PCIDeviceCache
+	vgpuCache   v1beta1.VGPUDeviceCache
+	policyCache v1beta1.DeviceAccessPolicyCache
+	quotaCache  v1beta1.DeviceQuotaCache
+	vmCache     kubevirtctl.VirtualMachineCache
+	vmiCache    kubevirtctl.VirtualMachineInstanceCache
+	counter     *devicequota.Counter
 }
 
 func (vmValidator *vmDeviceHostValidator) Resource() types.Resource {
@@ -35,22 +48,123 @@ func (vmValidator *vmDeviceHostValidator) Resource() types.Resource {
 	}
 }
 
-func NewDeviceHostValidation(usbCache v1beta1.USBDeviceCache, pciCache v1beta1.PCIDeviceCache, vgpuCache v1beta1.VGPUDeviceCache) types.Validator {
+func NewDeviceHostValidation(usbCache v1beta1.USBDeviceCache, pciCache v1beta1.PCIDeviceCache, vgpuCache v1beta1.VGPUDeviceCache,
+	policyCache v1beta1.DeviceAccessPolicyCache, quotaCache v1beta1.DeviceQuotaCache, vmCache kubevirtctl.VirtualMachineCache,
+	vmiCache kubevirtctl.VirtualMachineInstanceCache) types.Validator {
+	return newVMDeviceHostValidator(usbCache, pciCache, vgpuCache, policyCache, quotaCache, vmCache, vmiCache)
+}
+
+func newVMDeviceHostValidator(usbCache v1beta1.USBDeviceCache, pciCache v1beta1.PCIDeviceCache, vgpuCache v1beta1.VGPUDeviceCache,
+	policyCache v1beta1.DeviceAccessPolicyCache, quotaCache v1beta1.DeviceQuotaCache, vmCache kubevirtctl.VirtualMachineCache,
+	vmiCache kubevirtctl.VirtualMachineInstanceCache) *vmDeviceHostValidator {
 	return &vmDeviceHostValidator{
-		usbCache:  usbCache,
-		pciCache:  pciCache,
-		vgpuCache: vgpuCache,
+		usbCache:    usbCache,
+		pciCache:    pciCache,
+		vgpuCache:   vgpuCache,
+		policyCache: policyCache,
+		quotaCache:  quotaCache,
+		vmCache:     vmCache,
+		vmiCache:    vmiCache,
+		counter:     devicequota.NewCounter(pciCache, usbCache),
 	}
 }
 
-func (vmValidator *vmDeviceHostValidator) Create(_ *types.Request, newObj runtime.Object) error {
+func (vmValidator *vmDeviceHostValidator) Create(req *types.Request, newObj runtime.Object) error {
 	vmObj := newObj.(*kubevirtv1.VirtualMachine)
-	return vmValidator.validateDevices(vmObj)
+	if err := vmValidator.validateDevices(vmObj); err != nil {
+		return err
+	}
+	if err := vmValidator.validateDeviceAccess(req, nil, vmObj); err != nil {
+		return err
+	}
+	return vmValidator.validateDeviceQuota(nil, vmObj, false)
 }
 
-func (vmValidator *vmDeviceHostValidator) Update(_ *types.Request, _ runtime.Object, newObj runtime.Object) error {
+func (vmValidator *vmDeviceHostValidator) Update(req *types.Request, oldObj runtime.Object, newObj runtime.Object) error {
 	vmObj := newObj.(*kubevirtv1.VirtualMachine)
-	return vmValidator.validateDevices(vmObj)
+	if err := vmValidator.validateDevices(vmObj); err != nil {
+		return err
+	}
+	oldVM, _ := oldObj.(*kubevirtv1.VirtualMachine)
+	if err := vmValidator.validateDeviceAccess(req, oldVM, vmObj); err != nil {
+		return err
+	}
+	return vmValidator.validateDeviceQuota(oldVM, vmObj, false)
+}
+
+// vmiDeviceHostValidator applies the device access checks of VMs to VMIs created directly, without a VM
+type vmiDeviceHostValidator struct {
+	types.DefaultValidator
+
+	vmValidator *vmDeviceHostValidator
+}
+
+func (vmiValidator *vmiDeviceHostValidator) Resource() types.Resource {
+	return types.Resource{
+		Names:      []string{"virtualmachineinstances"},
+		Scope:      admissionregv1.NamespacedScope,
+		APIGroup:   kubevirtv1.SchemeGroupVersion.Group,
+		APIVersion: kubevirtv1.SchemeGroupVersion.Version,
+		ObjectType: &kubevirtv1.VirtualMachineInstance{},
+		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
+		},
+	}
+}
+
+func NewVMIDeviceHostValidation(usbCache v1beta1.USBDeviceCache, pciCache v1beta1.PCIDeviceCache, vgpuCache v1beta1.VGPUDeviceCache,
+	policyCache v1beta1.DeviceAccessPolicyCache, quotaCache v1beta1.DeviceQuotaCache, vmCache kubevirtctl.VirtualMachineCache,
+	vmiCache kubevirtctl.VirtualMachineInstanceCache) types.Validator {
+	return &vmiDeviceHostValidator{
+		vmValidator: newVMDeviceHostValidator(usbCache, pciCache, vgpuCache, policyCache, quotaCache, vmCache, vmiCache),
+	}
+}
+
+// Create skips VMIs created by kubevirt for a VM, as the devices of the VM were validated when it was created or updated
+func (vmiValidator *vmiDeviceHostValidator) Create(req *types.Request, newObj runtime.Object) error {
+	vmiObj := newObj.(*kubevirtv1.VirtualMachineInstance)
+	createdForVM, err := vmiValidator.createdForVM(vmiObj)
+	if err != nil || createdForVM {
+		return err
+	}
+	vmObj := common.VMForVMI(vmiObj)
+	if err := vmiValidator.vmValidator.validateDeviceAccess(req, nil, vmObj); err != nil {
+		return err
+	}
+	return vmiValidator.vmValidator.validateDeviceQuota(nil, vmObj, true)
+}
+
+// createdForVM checks the VM referenced by the controller reference of the VMI exists with the same uid, and requests
+// the same devices as the VMI. Otherwise the reference may have been forged to bypass the checks of the VM
+func (vmiValidator *vmiDeviceHostValidator) createdForVM(vmiObj *kubevirtv1.VirtualMachineInstance) (bool, error) {
+	owner := metav1.GetControllerOf(vmiObj)
+	if owner == nil || owner.Kind != kubevirtv1.VirtualMachineGroupVersionKind.Kind {
+		return false, nil
+	}
+	vm, err := vmiValidator.vmValidator.vmCache.Get(vmiObj.Namespace, owner.Name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error looking up vm %s/%s: %w", vmiObj.Namespace, owner.Name, err)
+	}
+	if !common.IsControlledBy(vmiObj, vm) || vm.Spec.Template == nil {
+		return false, nil
+	}
+	return slices.Equal(requestedDevices(&vm.Spec.Template.Spec), requestedDevices(&vmiObj.Spec)), nil
+}
+
+// requestedDevices returns the sorted resource names of the host devices and gpus in spec
+func requestedDevices(spec *kubevirtv1.VirtualMachineInstanceSpec) []string {
+	var devices []string
+	for _, v := range spec.Domain.Devices.HostDevices {
+		devices = append(devices, v.DeviceName)
+	}
+	for _, v := range spec.Domain.Devices.GPUs {
+		devices = append(devices, v.DeviceName)
+	}
+	slices.Sort(devices)
+	return devices
 }
 
 func (vmValidator *vmDeviceHostValidator) validateDevices(vmObj *kubevirtv1.VirtualMachine) error {
@@ -135,7 +249,7 @@ func (vmValidator *vmDeviceHostValidator) validateHostDevices(vmObj *kubevirtv1.
 }
 
 func (vmValidator *vmDeviceHostValidator) validatePCIDevice(resourceName string) (found bool, err error) {
-	pciDeviceObjs, err := vmValidator.pciCache.GetByIndex(PCIDeviceByResourceName, resourceName)
+	pciDeviceObjs, err := vmValidator.pciCache.GetByIndex(common.PCIDeviceByResourceName, resourceName)
 	if err != nil {
 		return false, fmt.Errorf("error looking up pcidevice %s from cache: %v", resourceName, err)
 	}
@@ -159,3 +273,156 @@ func (vmValidator *vmDeviceHostValidator) validateUSBDevice(resourceName string)
 
 	return true, nil
 }
+
+// validateDeviceAccess ensures the VM is granted all devices governed by DeviceAccessPolicies, which it may be allocated.
+// A VM requesting a resource name may be allocated any device exposed under it, so every such device is checked.
+// Resource names already requested by oldVM are not checked again, so existing VMs can still be updated once a policy is added
+func (vmValidator *vmDeviceHostValidator) validateDeviceAccess(req *types.Request, oldVM, vmObj *kubevirtv1.VirtualMachine) error {
+	policies, err := vmValidator.policyCache.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error listing deviceaccesspolicies: %w", err)
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+
+	var username string
+	if req != nil && req.Request != nil {
+		username = req.Username()
+	}
+
+	existing := make(map[string]bool)
+	if oldVM != nil {
+		for _, v := range oldVM.Spec.Template.Spec.Domain.Devices.HostDevices {
+			existing[v.DeviceName] = true
+		}
+		for _, v := range oldVM.Spec.Template.Spec.Domain.Devices.GPUs {
+			existing[v.DeviceName] = true
+		}
+	}
+
+	devices, err := vmValidator.allocatableDevices(vmObj, existing)
+	if err != nil {
+		return err
+	}
+
+	for _, dev := range devices {
+		var selected, granted bool
+		for _, policy := range policies {
+			if policy.Spec.Selects(dev.kind, dev.name, dev.resourceName) {
+				selected = true
+				granted = granted || policy.Spec.Grants(vmObj.Namespace, username)
+			}
+		}
+		if selected && !granted {
+			return fmt.Errorf("vm %s/%s is not granted %s %s with resource name %s by any deviceaccesspolicy",
+				vmObj.Namespace, vmObj.Name, dev.kind, dev.name, dev.resourceName)
+		}
+	}
+	return nil
+}
+
+// allocatableDevice is a device which may be allocated to a VM for one of its host devices or gpus
+type allocatableDevice struct {
+	kind         devicesv1beta1.DeviceAccessKind
+	name         string
+	resourceName string
+}
+
+// allocatableDevices returns the devices exposed under the resource names requested by vmObj, except for resource names in skip
+func (vmValidator *vmDeviceHostValidator) allocatableDevices(vmObj *kubevirtv1.VirtualMachine, skip map[string]bool) ([]allocatableDevice, error) {
+	var devices []allocatableDevice
+	for _, hostDevice := range vmObj.Spec.Template.Spec.Domain.Devices.HostDevices {
+		if skip[hostDevice.DeviceName] {
+			continue
+		}
+
+		pds, err := vmValidator.pciCache.GetByIndex(common.PCIDeviceByResourceName, hostDevice.DeviceName)
+		if err != nil {
+			return nil, fmt.Errorf("error looking up pcidevice %s from cache: %w", hostDevice.DeviceName, err)
+		}
+		for _, v := range pds {
+			devices = append(devices, allocatableDevice{kind: devicesv1beta1.DeviceAccessKindPCIDevice, name: v.Name, resourceName: v.Status.ResourceName})
+		}
+
+		usbs, err := vmValidator.usbCache.GetByIndex(USBDeviceByResourceName, hostDevice.DeviceName)
+		if err != nil {
+			return nil, fmt.Errorf("error looking up usbdevice %s from cache: %w", hostDevice.DeviceName, err)
+		}
+		for _, v := range usbs {
+			devices = append(devices, allocatableDevice{kind: devicesv1beta1.DeviceAccessKindUSBDevice, name: v.Name, resourceName: v.Status.ResourceName})
+		}
+	}
+
+	for _, gpu := range vmObj.Spec.Template.Spec.Domain.Devices.GPUs {
+		if skip[gpu.DeviceName] {
+			continue
+		}
+
+		vgpus, err := vmValidator.vgpuCache.GetByIndex(vGPUDeviceByResourceName, gpu.DeviceName)
+		if err != nil {
+			return nil, fmt.Errorf("error looking up vgpudevice %s from cache: %w", gpu.DeviceName, err)
+		}
+		for _, v := range vgpus {
+			devices = append(devices, allocatableDevice{kind: devicesv1beta1.DeviceAccessKindVGPUDevice, name: v.Name,
+				resourceName: common.GeneratevGPUDeviceName(v.Status.ConfiguredVGPUTypeName)})
+		}
+	}
+	return devices, nil
+}
+
+// validateDeviceQuota ensures the devices requested by the VM fit in the DeviceQuotas of its namespace, together with
+// the devices requested by the other VMs and standalone VMIs of the namespace. vmObj wraps a VMI created without a VM
+// if standalone is set. Only limits for which the VM requests more devices than oldVM are checked, so VMs can still be
+// updated in a namespace which is over its quota.
+// Usage is counted from the cache without reserving devices, so the quota is best-effort: VMs admitted concurrently
+// may together exceed it, and the usage reported in the status of the quota is then over the limit
+func (vmValidator *vmDeviceHostValidator) validateDeviceQuota(oldVM, vmObj *kubevirtv1.VirtualMachine, standalone bool) error {
+	quotas, err := vmValidator.quotaCache.List(vmObj.Namespace, labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error listing devicequotas in namespace %s: %w", vmObj.Namespace, err)
+	}
+	if len(quotas) == 0 {
+		return nil
+	}
+
+	vms, err := vmValidator.vmCache.List(vmObj.Namespace, labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error listing vms in namespace %s: %w", vmObj.Namespace, err)
+	}
+	vmis, err := vmValidator.vmiCache.List(vmObj.Namespace, labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error listing vmis in namespace %s: %w", vmObj.Namespace, err)
+	}
+	var others []*kubevirtv1.VirtualMachine
+	if standalone {
+		vmis = slices.DeleteFunc(vmis, func(vmi *kubevirtv1.VirtualMachineInstance) bool { return vmi.Name == vmObj.Name })
+		others = devicequota.Workloads(vms, vmis)
+	} else {
+		// the VMI of the VM is still counted through the cached VM, which is then replaced by vmObj
+		others = devicequota.Workloads(vms, vmis)
+		if i := slices.IndexFunc(vms, func(vm *kubevirtv1.VirtualMachine) bool { return vm.Name == vmObj.Name }); i >= 0 {
+			others = slices.DeleteFunc(others, func(vm *kubevirtv1.VirtualMachine) bool { return vm == vms[i] })
+		}
+	}
+
+	current, err := vmValidator.counter.Usage(oldVM)
+	if err != nil {
+		return err
+	}
+	requested, err := vmValidator.counter.Usage(vmObj)
+	if err != nil {
+		return err
+	}
+	used, err := vmValidator.counter.Usage(append(others, vmObj)...)
+	if err != nil {
+		return err
+	}
+
+	for _, quota := range quotas {
+		if exceeded := devicequota.Exceeded(quota, used, current, requested); len(exceeded) > 0 {
+			return fmt.Errorf("vm %s/%s exceeds devicequota %s: %s", vmObj.Namespace, vmObj.Name, quota.Name, strings.Join(exceeded, ", "))
+		}
+	}
+	return nil
+}